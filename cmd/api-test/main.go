@@ -1,18 +1,21 @@
 package main
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"io"
-	"net/http"
 	"time"
+
+	"diary/pkg/client"
 )
 
 const baseURL = "http://localhost:8080"
 
-var authToken string
-var createdDiaryID uint
+var (
+	ctx            = context.Background()
+	api            = client.New(baseURL)
+	createdDiaryID int64
+)
 
 func main() {
 	fmt.Println("开始 API 接口测试...")
@@ -22,7 +25,9 @@ func main() {
 	register()
 
 	// 2. 登录
-	login()
+	if !login() {
+		return
+	}
 
 	// 3. 创建标签
 	createTag("测试标签")
@@ -58,166 +63,147 @@ func main() {
 }
 
 func register() {
-	fmt.Println("[1/9] 测试注册接口...")
+	fmt.Println("[1/11] 测试注册接口...")
 	username := fmt.Sprintf("testuser_%d", time.Now().Unix())
-	body := map[string]string{
-		"username": username,
-		"password": "password123",
-	}
-	resp := request("POST", "/api/register", nil, body)
-	printResponse(resp)
+	_, err := api.Register(ctx, client.RegisterRequest{
+		Username: username,
+		Password: "password123",
+	})
+	report(err, "注册成功: "+username)
 }
 
-func login() {
-	fmt.Println("[2/9] 测试登录接口...")
+func login() bool {
+	fmt.Println("[2/11] 测试登录接口...")
 	fixedUser := "apitest_user"
 	fixedPass := "123456"
 
-	// 尝试注册固定账号
-	request("POST", "/api/register", nil, map[string]string{
-		"username": fixedUser,
-		"password": fixedPass,
-	})
-
-	// 登录
-	body := map[string]string{
-		"username": fixedUser,
-		"password": fixedPass,
+	// 尝试注册固定账号，已存在时忽略
+	_, err := api.Register(ctx, client.RegisterRequest{Username: fixedUser, Password: fixedPass})
+	if err != nil && !client.IsErrorCode(err, "USER_ALREADY_EXISTS") {
+		report(err, "")
 	}
-	resp := request("POST", "/api/login", nil, body)
-
-	if resp != nil {
-		if token, ok := resp["data"].(map[string]interface{})["token"].(string); ok {
-			authToken = token
-			fmt.Println("✅ 登录成功，Token获取成功")
-		} else {
-			fmt.Println("❌ 登录失败或未获取到Token")
-		}
+
+	resp, err := api.Login(ctx, client.LoginRequest{Username: fixedUser, Password: fixedPass})
+	if err != nil || resp.Token == "" {
+		report(err, "")
+		fmt.Println("❌ 登录失败或未获取到Token")
+		return false
 	}
+	api.SetToken(resp.Token)
+	fmt.Println("✅ 登录成功，Token获取成功")
+	return true
 }
 
 func createTag(name string) {
-	fmt.Println("[3/9] 测试创建标签...")
-	body := map[string]string{
-		"name": name,
+	fmt.Println("[3/11] 测试创建标签...")
+	_, err := api.CreateTag(ctx, client.CreateTagRequest{Name: name})
+	if client.IsErrorCode(err, "TAG_ALREADY_EXISTS") {
+		fmt.Println("✅ 标签已存在")
+		return
 	}
-	request("POST", "/api/tags", &authToken, body)
+	report(err, "标签创建成功")
 }
 
 func createTodo(title, desc string) {
-	fmt.Println("[4/9] 测试创建待办...")
-	body := map[string]interface{}{
-		"title":       title,
-		"description": desc,
-		"due_date":    time.Now().Add(24 * time.Hour),
+	fmt.Println("[4/11] 测试创建待办...")
+	due := time.Now().Add(24 * time.Hour)
+	todo, err := api.CreateTodo(ctx, client.CreateTodoRequest{
+		Title:       title,
+		Description: desc,
+		DueDate:     &due,
+	})
+	if err == nil {
+		report(nil, fmt.Sprintf("待办创建成功，ID: %d", todo.ID))
+		return
 	}
-	request("POST", "/api/todos", &authToken, body)
+	report(err, "")
 }
 
 func createDiary(title, content, tagName string) {
-	fmt.Println("[5/9] 测试创建日记...")
-	body := map[string]interface{}{
-		"title":     title,
-		"content":   content,
-		"weather":   "Sunny",
-		"mood":      "Happy",
-		"location":  "Home",
-		"date":      time.Now(),
-		"is_public": true,
-		"tags":      []string{tagName},
-	}
-	resp := request("POST", "/api/diaries", &authToken, body)
-	if resp != nil {
-		if data, ok := resp["data"].(map[string]interface{}); ok {
-			if id, ok := data["id"].(float64); ok {
-				createdDiaryID = uint(id)
-				fmt.Printf("✅ 日记创建成功，ID: %d\n", createdDiaryID)
-			}
-		}
+	fmt.Println("[5/11] 测试创建日记...")
+	diary, err := api.CreateDiary(ctx, client.CreateDiaryRequest{
+		Title:    title,
+		Content:  content,
+		Weather:  "Sunny",
+		Mood:     "Happy",
+		Location: "Home",
+		Date:     time.Now(),
+		IsPublic: true,
+		Tags:     []string{tagName},
+	})
+	if err != nil {
+		report(err, "")
+		return
 	}
+	createdDiaryID = diary.ID
+	fmt.Printf("✅ 日记创建成功，ID: %d\n", createdDiaryID)
 }
 
 func listDiaries() {
 	fmt.Println("[6/11] 测试获取日记列表...")
-	request("GET", "/api/diaries?page=1&page_size=10", &authToken, nil)
+	list, err := api.ListDiaries(ctx, &client.ListDiariesParams{Page: client.Int(1), PageSize: client.Int(10)})
+	if err != nil {
+		report(err, "")
+		return
+	}
+	report(nil, fmt.Sprintf("共 %d 篇日记", list.Total))
 }
 
 func searchDiary(keyword string) {
 	fmt.Printf("[7/11] 测试搜索日记 (Keyword: %s)...\n", keyword)
-	request("GET", "/api/diaries/search?q="+keyword+"&page=1&page_size=10", &authToken, nil)
+	list, err := api.SearchDiaries(ctx, &client.SearchDiariesParams{Q: keyword, Page: client.Int(1), PageSize: client.Int(10)})
+	if err != nil {
+		report(err, "")
+		return
+	}
+	report(nil, fmt.Sprintf("找到 %d 篇日记", list.Total))
 }
 
 func getDashboardStats() {
 	fmt.Println("[8/11] 测试获取统计看板...")
-	request("GET", "/api/stats/dashboard", &authToken, nil)
+	stats, err := api.GetDashboardStats(ctx)
+	if err != nil {
+		report(err, "")
+		return
+	}
+	report(nil, fmt.Sprintf("日记 %d 篇，待办完成率 %.0f%%", stats.DiaryCount, stats.TodoCompletedRate*100))
 }
 
-func getDiary(id uint) {
+func getDiary(id int64) {
 	fmt.Printf("[9/11] 测试获取日记详情 (ID: %d)...\n", id)
-	request("GET", fmt.Sprintf("/api/diaries/%d", id), &authToken, nil)
+	diary, err := api.GetDiary(ctx, id, nil)
+	if err != nil {
+		report(err, "")
+		return
+	}
+	report(nil, "标题: "+diary.Title)
 }
 
-func updateDiary(id uint, title, content string) {
+func updateDiary(id int64, title, content string) {
 	fmt.Printf("[10/11] 测试更新日记 (ID: %d)...\n", id)
-	body := map[string]interface{}{
-		"title":   title,
-		"content": content,
-		"weather": "Rainy", // 修改天气
-	}
-	request("PUT", fmt.Sprintf("/api/diaries/%d", id), &authToken, body)
+	_, err := api.UpdateDiary(ctx, id, client.UpdateDiaryRequest{
+		Title:   title,
+		Content: content,
+		Weather: "Rainy", // 修改天气
+		Date:    time.Now(),
+	})
+	report(err, "更新成功")
 }
 
-func deleteDiary(id uint) {
+func deleteDiary(id int64) {
 	fmt.Printf("[11/11] 测试删除日记 (ID: %d)...\n", id)
-	request("DELETE", fmt.Sprintf("/api/diaries/%d", id), &authToken, nil)
+	report(api.DeleteDiary(ctx, id), "删除成功")
 }
 
-func request(method, path string, token *string, body interface{}) map[string]interface{} {
-	var bodyReader io.Reader
-	if body != nil {
-		jsonBytes, _ := json.Marshal(body)
-		bodyReader = bytes.NewBuffer(jsonBytes)
-	}
-
-	req, err := http.NewRequest(method, baseURL+path, bodyReader)
-	if err != nil {
-		fmt.Printf("❌ 创建请求失败: %v\n", err)
-		return nil
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	if token != nil && *token != "" {
-		req.Header.Set("Authorization", "Bearer "+*token)
-	}
-
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
+// report 打印结果，服务端错误显示状态码和错误码
+func report(err error, ok string) {
+	var apiErr *client.APIError
+	switch {
+	case err == nil:
+		fmt.Println("✅ " + ok)
+	case errors.As(err, &apiErr):
+		fmt.Printf("❌ Status: %d, Code: %s, Msg: %s\n", apiErr.StatusCode, apiErr.ErrorCode, apiErr.Message)
+	default:
 		fmt.Printf("❌ 请求失败: %v (请确保服务器已启动)\n", err)
-		return nil
-	}
-	defer resp.Body.Close()
-
-	respBytes, _ := io.ReadAll(resp.Body)
-
-	fmt.Printf("➡️ %s %s Status: %d\n", method, path, resp.StatusCode)
-
-	var result map[string]interface{}
-	if err := json.Unmarshal(respBytes, &result); err == nil {
-		// 简单打印一下 code 和 message
-		if code, ok := result["code"].(float64); ok {
-			msg, _ := result["message"].(string)
-			fmt.Printf("   Code: %d, Msg: %s\n", int(code), msg)
-		} else {
-			fmt.Printf("   Resp: %s\n", string(respBytes))
-		}
-		return result
-	} else {
-		fmt.Printf("   Resp: %s\n", string(respBytes))
 	}
-	return nil
-}
-
-func printResponse(resp map[string]interface{}) {
-	// 辅助打印，request里已经打印了
 }
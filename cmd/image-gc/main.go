@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"diary/config"
+	database "diary/internal/database"
+	"diary/internal/models"
+	"diary/internal/repository/mysql"
+	"diary/internal/service"
+)
+
+// 手动执行一次图片回收
+//
+//	go run ./cmd/image-gc -dry-run   只输出将被清理的内容
+//	go run ./cmd/image-gc            实际删除
+func main() {
+	dryRun := flag.Bool("dry-run", false, "只报告将被清理的文件，不做任何删除")
+	flag.Parse()
+
+	cfg := config.LoadConfig()
+	db := database.InitDB(cfg)
+	defer database.CloseDB(db)
+
+	if err := models.AutoMigrate(db); err != nil {
+		log.Fatalf("migrate failed: %v", err)
+	}
+
+	gc := service.NewImageGCService(mysql.NewImageRepository(db), cfg)
+	report, err := gc.Run(context.Background(), *dryRun)
+	if err != nil {
+		log.Fatalf("image gc failed: %v", err)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		log.Fatalf("write report failed: %v", err)
+	}
+}
@@ -0,0 +1,43 @@
+// openapi-gen 根据 internal/apidoc 生成 Go 客户端，也可以导出 OpenAPI 文档。
+// 用法：go run ./cmd/openapi-gen -client pkg/client/client_gen.go [-spec openapi.json]
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"diary/internal/apidoc"
+)
+
+func main() {
+	clientPath := flag.String("client", "", "生成的客户端文件路径")
+	pkg := flag.String("package", "client", "客户端包名")
+	specPath := flag.String("spec", "", "导出 OpenAPI 文档的路径")
+	flag.Parse()
+
+	if *clientPath == "" && *specPath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if *clientPath != "" {
+		src, err := apidoc.GenerateClient(apidoc.Build(), *pkg)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := os.WriteFile(*clientPath, src, 0o644); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if *specPath != "" {
+		spec, err := apidoc.JSON()
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := os.WriteFile(*specPath, spec, 0o644); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+)
+
+// 本地测试用的 SMTP 服务器，不投递邮件，只把收到的内容打印出来
+//
+//	go run ./cmd/smtp-sink -addr :2525
+//	SMTP_HOST=localhost SMTP_PORT=2525 go run .
+func main() {
+	addr := flag.String("addr", ":2525", "监听地址")
+	flag.Parse()
+
+	ln, err := net.Listen("tcp", *addr)
+	if err != nil {
+		log.Fatalf("listen failed: %v", err)
+	}
+	log.Printf("smtp sink listening at %s", *addr)
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			log.Printf("accept failed: %v", err)
+			continue
+		}
+		go serve(conn)
+	}
+}
+
+func serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(format string, args ...interface{}) {
+		fmt.Fprintf(conn, format+"\r\n", args...)
+	}
+
+	reply("220 smtp-sink ready")
+	var from string
+	var to []string
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		cmd := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"):
+			reply("250-smtp-sink")
+			reply("250 AUTH PLAIN LOGIN")
+		case strings.HasPrefix(cmd, "HELO"):
+			reply("250 smtp-sink")
+		case strings.HasPrefix(cmd, "AUTH"):
+			// 接受任意凭据
+			reply("235 ok")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			from = strings.TrimSpace(line[len("MAIL FROM:"):])
+			to = nil
+			reply("250 ok")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			to = append(to, strings.TrimSpace(line[len("RCPT TO:"):]))
+			reply("250 ok")
+		case cmd == "DATA":
+			reply("354 end with <CRLF>.<CRLF>")
+			var body strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if strings.TrimRight(l, "\r\n") == "." {
+					break
+				}
+				body.WriteString(strings.TrimPrefix(l, "."))
+			}
+			log.Printf("mail from %s to %s\n%s", from, strings.Join(to, ", "), body.String())
+			reply("250 ok")
+		case cmd == "RSET":
+			from, to = "", nil
+			reply("250 ok")
+		case cmd == "NOOP":
+			reply("250 ok")
+		case cmd == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 command not implemented")
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -17,6 +18,40 @@ type Config struct {
 	UploadDir          string
 	JWTExpireHours     int
 	EnableRegistration bool
+	// 图片垃圾回收
+	ImageGCGraceHours      int // 未关联日记的图片保留时长
+	ImageGCRetentionHours  int // 软删除图片的保留时长
+	ImageGCIntervalMinutes int // 后台回收间隔，0 表示不启动
+	// 对外访问
+	PublicBaseURL          string // 服务对外地址，用于生成绝对链接，可为空
+	SignedURLExpireMinutes int    // 图片签名链接有效期
+	FeedURLExpireHours     int    // 订阅源中图片签名链接有效期，阅读器会长期缓存条目，需要远长于普通链接
+	MarkdownCacheSize      int    // Markdown 渲染结果缓存条数
+	// 断点续传
+	UploadTmpDir           string // 分片临时目录（不要放在 UploadDir 下，避免被静态服务暴露）
+	ResumableMaxMB         int    // 单个续传文件大小上限
+	UploadExpireHours      int    // 未完成的上传会话保留时长
+	// 附件大小上限（MB）
+	MaxImageMB int
+	MaxAudioMB int
+	MaxVideoMB int
+	// 导入
+	ImportMaxMB int // 导入文件大小上限
+	// 异步导出
+	ExportDir            string // 导出文件目录（不要放在 UploadDir 下）
+	ExportJobExpireHours int    // 导出文件保留时长
+	ExportWorkers        int    // 同时执行的导出任务数
+	PDFFontPath          string // PDF 导出使用的中文 TrueType 字体，为空时不支持 PDF 导出
+	// 提醒和通知
+	ReminderIntervalSeconds int    // 调度器检查间隔，0 表示不启动
+	WebhookSecret           string // Webhook 请求签名密钥，为空时不签名
+	SMTPHost                string // 为空时不发送邮件
+	SMTPPort                int
+	SMTPUsername            string // 为空时不做认证（本地测试服务器）
+	SMTPPassword            string
+	SMTPFrom                string
+	// 接口文档
+	SwaggerUIDir string // swagger-ui-dist 的本地目录，配置后文档页从本站加载静态资源，不依赖 CDN
 }
 
 func LoadConfig() *Config {
@@ -28,6 +63,32 @@ func LoadConfig() *Config {
 	uploadDir := getEnv("UPLOAD_DIR", "./uploads")
 	jwtExpireHours := toInt(getEnv("JWT_EXPIRE_HOURS", "72"))
 	enableRegistration := getEnv("ENABLE_REGISTRATION", "true") == "true"
+	imageGCGraceHours := toInt(getEnv("IMAGE_GC_GRACE_HOURS", "24"))
+	imageGCRetentionHours := toInt(getEnv("IMAGE_GC_RETENTION_HOURS", "720"))
+	imageGCIntervalMinutes := toInt(getEnv("IMAGE_GC_INTERVAL_MINUTES", "60"))
+	publicBaseURL := strings.TrimRight(getEnv("PUBLIC_BASE_URL", ""), "/")
+	signedURLExpireMinutes := toInt(getEnv("SIGNED_URL_EXPIRE_MINUTES", "60"))
+	feedURLExpireHours := toInt(getEnv("FEED_URL_EXPIRE_HOURS", "8760"))
+	markdownCacheSize := toInt(getEnv("MARKDOWN_CACHE_SIZE", "1000"))
+	uploadTmpDir := getEnv("UPLOAD_TMP_DIR", "./tmp/uploads")
+	resumableMaxMB := toInt(getEnv("RESUMABLE_MAX_MB", "200"))
+	uploadExpireHours := toInt(getEnv("UPLOAD_EXPIRE_HOURS", "24"))
+	maxImageMB := toInt(getEnv("MAX_IMAGE_MB", "10"))
+	maxAudioMB := toInt(getEnv("MAX_AUDIO_MB", "50"))
+	maxVideoMB := toInt(getEnv("MAX_VIDEO_MB", "200"))
+	importMaxMB := toInt(getEnv("IMPORT_MAX_MB", "500"))
+	exportDir := getEnv("EXPORT_DIR", "./tmp/exports")
+	exportJobExpireHours := toInt(getEnv("EXPORT_JOB_EXPIRE_HOURS", "24"))
+	exportWorkers := toInt(getEnv("EXPORT_WORKERS", "2"))
+	pdfFontPath := getEnv("PDF_FONT_PATH", "")
+	reminderIntervalSeconds := toInt(getEnv("REMINDER_INTERVAL_SECONDS", "30"))
+	webhookSecret := getEnv("WEBHOOK_SECRET", "")
+	smtpHost := getEnv("SMTP_HOST", "")
+	smtpPort := toInt(getEnv("SMTP_PORT", "25"))
+	smtpUsername := getEnv("SMTP_USERNAME", "")
+	smtpPassword := getEnv("SMTP_PASSWORD", "")
+	smtpFrom := getEnv("SMTP_FROM", "diary@localhost")
+	swaggerUIDir := getEnv("SWAGGER_UI_DIR", "")
 
 	var aesKey []byte
 	if aesBase64 != "" {
@@ -52,6 +113,40 @@ func LoadConfig() *Config {
 		UploadDir:          uploadDir,
 		JWTExpireHours:     jwtExpireHours,
 		EnableRegistration: enableRegistration,
+
+		ImageGCGraceHours:      imageGCGraceHours,
+		ImageGCRetentionHours:  imageGCRetentionHours,
+		ImageGCIntervalMinutes: imageGCIntervalMinutes,
+
+		PublicBaseURL:          publicBaseURL,
+		SignedURLExpireMinutes: signedURLExpireMinutes,
+		FeedURLExpireHours:     feedURLExpireHours,
+		MarkdownCacheSize:      markdownCacheSize,
+
+		UploadTmpDir:      uploadTmpDir,
+		ResumableMaxMB:    resumableMaxMB,
+		UploadExpireHours: uploadExpireHours,
+
+		MaxImageMB: maxImageMB,
+		MaxAudioMB: maxAudioMB,
+		MaxVideoMB: maxVideoMB,
+
+		ImportMaxMB: importMaxMB,
+
+		ExportDir:            exportDir,
+		ExportJobExpireHours: exportJobExpireHours,
+		ExportWorkers:        exportWorkers,
+		PDFFontPath:          pdfFontPath,
+
+		ReminderIntervalSeconds: reminderIntervalSeconds,
+		WebhookSecret:           webhookSecret,
+		SMTPHost:                smtpHost,
+		SMTPPort:                smtpPort,
+		SMTPUsername:            smtpUsername,
+		SMTPPassword:            smtpPassword,
+		SMTPFrom:                smtpFrom,
+
+		SwaggerUIDir: swaggerUIDir,
 	}
 }
 
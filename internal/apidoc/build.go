@@ -0,0 +1,145 @@
+package apidoc
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"sync"
+
+	"diary/internal/handler/dto"
+)
+
+const bearerAuth = "bearerAuth"
+
+// Build 根据接口列表生成 OpenAPI 文档
+func Build() *Document {
+	reg := newSchemaRegistry()
+	reg.schemaOf(reflect.TypeOf(dto.Response{}), false)
+	reg.schemaOf(reflect.TypeOf(dto.ErrorResponse{}), false)
+
+	doc := &Document{
+		OpenAPI: "3.0.3",
+		Info: Info{
+			Title:   "Diary API",
+			Version: "1.0.0",
+			Description: "成功响应统一为 {code, message, data}；失败响应为 ErrorResponse，" +
+				"客户端应根据 error_code 判断错误类型。message 按 Accept-Language（zh、en）返回。",
+		},
+		Paths: map[string]*PathItem{},
+		Components: Components{
+			Schemas: reg.schemas,
+			SecuritySchemes: map[string]*SecurityScheme{
+				bearerAuth: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+		},
+		Security: []map[string][]string{{bearerAuth: {}}},
+		Tags:     tags,
+	}
+
+	for _, rt := range routes {
+		item, ok := doc.Paths[rt.path]
+		if !ok {
+			item = &PathItem{}
+			doc.Paths[rt.path] = item
+		}
+		item.set(rt.method, buildOperation(reg, rt))
+	}
+	return doc
+}
+
+func buildOperation(reg *schemaRegistry, rt route) *Operation {
+	op := &Operation{
+		OperationID: rt.id,
+		Summary:     rt.summary,
+		Tags:        []string{rt.tag},
+		Parameters:  rt.params,
+		Responses:   map[string]*Response{},
+		XNoClient:   rt.noClient,
+	}
+	if rt.public {
+		op.Security = &[]map[string][]string{}
+	}
+
+	switch {
+	case rt.body != nil:
+		op.RequestBody = &RequestBody{Required: true, Content: map[string]*MediaType{
+			"application/json": {Schema: reg.schemaOf(reflect.TypeOf(rt.body), true)},
+		}}
+	case rt.form != nil:
+		op.RequestBody = &RequestBody{Required: true, Content: map[string]*MediaType{
+			"multipart/form-data": {Schema: formSchema(rt.form)},
+		}}
+	case rt.binary != "":
+		op.RequestBody = &RequestBody{Required: true, Content: map[string]*MediaType{
+			rt.binary: {Schema: &Schema{Type: "string", Format: "binary"}},
+		}}
+	}
+
+	status := rt.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	resp := &Response{Description: http.StatusText(status)}
+	switch {
+	case rt.file != "":
+		resp.Content = map[string]*MediaType{rt.file: {Schema: &Schema{Type: "string", Format: "binary"}}}
+	case status == http.StatusNoContent || rt.method == "HEAD":
+	default:
+		resp.Content = map[string]*MediaType{"application/json": {Schema: envelope(reg, rt.result)}}
+	}
+	op.Responses[strconv.Itoa(status)] = resp
+	if rt.noContent != "" {
+		op.Responses["204"] = &Response{Description: rt.noContent}
+	}
+	op.Responses["default"] = &Response{
+		Description: "错误",
+		Content: map[string]*MediaType{
+			"application/json": {Schema: &Schema{Ref: refPrefix + "ErrorResponse"}},
+		},
+	}
+	return op
+}
+
+// envelope 成功响应的外层结构，data 为具体类型
+func envelope(reg *schemaRegistry, result interface{}) *Schema {
+	base := &Schema{Ref: refPrefix + "Response"}
+	if result == nil {
+		return base
+	}
+	return &Schema{AllOf: []*Schema{base, {
+		Type:       "object",
+		Properties: map[string]*Schema{"data": reg.schemaOf(reflect.TypeOf(result), false)},
+		Required:   []string{"data"},
+	}}}
+}
+
+func formSchema(fields []formField) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for _, f := range fields {
+		prop := &Schema{Type: f.typ, Description: f.desc, Enum: f.enum}
+		if f.typ == "file" {
+			prop = &Schema{Type: "string", Format: "binary", Description: f.desc}
+		}
+		s.Properties[f.name] = prop
+		s.XOrder = append(s.XOrder, f.name)
+		if f.required {
+			s.Required = append(s.Required, f.name)
+		}
+	}
+	return s
+}
+
+var (
+	specOnce sync.Once
+	specJSON []byte
+	specErr  error
+)
+
+// JSON 返回缩进后的文档，只生成一次
+func JSON() ([]byte, error) {
+	specOnce.Do(func() {
+		specJSON, specErr = json.MarshalIndent(Build(), "", "  ")
+	})
+	return specJSON, specErr
+}
@@ -0,0 +1,414 @@
+package apidoc
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// GenerateClient 根据文档生成 pkg/client 的类型和接口方法
+func GenerateClient(doc *Document, pkg string) ([]byte, error) {
+	g := &clientGen{doc: doc}
+	names := make([]string, 0, len(doc.Components.Schemas))
+	for name := range doc.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		g.structType(name, doc.Components.Schemas[name])
+	}
+
+	doc.EachOperation(func(path, method string, op *Operation) {
+		if !op.XNoClient {
+			g.operation(path, method, op)
+		}
+	})
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by cmd/openapi-gen. DO NOT EDIT.\n\npackage %s\n\nimport (\n", pkg)
+	for _, imp := range []string{"context", "io", "net/http", "net/url", "strconv", "time"} {
+		if usesPackage(g.buf.Bytes(), imp) {
+			fmt.Fprintf(&out, "%q\n", imp)
+		}
+	}
+	out.WriteString(")\n\n")
+	out.Write(g.buf.Bytes())
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated client: %w", err)
+	}
+	return src, nil
+}
+
+// usesPackage 生成的代码是否引用了该包，例如 "net/url" 检查 url.
+func usesPackage(src []byte, importPath string) bool {
+	name := importPath[strings.LastIndex(importPath, "/")+1:]
+	return regexp.MustCompile(`\b` + name + `\.[A-Z]`).Match(src)
+}
+
+type clientGen struct {
+	doc *Document
+	buf bytes.Buffer
+}
+
+func (g *clientGen) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+func (g *clientGen) structType(name string, s *Schema) {
+	if s.Description != "" {
+		g.printf("// %s %s\n", name, s.Description)
+	}
+	g.printf("type %s struct {\n", name)
+	for _, prop := range propertyNames(s) {
+		ps := s.Properties[prop]
+		required := contains(s.Required, prop)
+		typ := goType(ps, false)
+		if !required && typ == "time.Time" {
+			typ = "*time.Time" // omitempty 对结构体无效
+		}
+		tag := prop
+		if !required {
+			tag += ",omitempty"
+		}
+		g.printf("%s %s `json:\"%s\"`%s\n", goName(prop), typ, tag, fieldComment(ps))
+	}
+	g.printf("}\n\n")
+}
+
+func (g *clientGen) operation(path, method string, op *Operation) {
+	name := exportName(op.OperationID)
+
+	var pathParams, queryParams, headerParams []Parameter
+	for _, p := range op.Parameters {
+		switch p.In {
+		case "path":
+			pathParams = append(pathParams, p)
+		case "query":
+			queryParams = append(queryParams, p)
+		case "header":
+			headerParams = append(headerParams, p)
+		}
+	}
+
+	// 查询参数和请求头放在 <Name>Params 结构中，可选参数使用指针
+	paramsType := ""
+	if len(queryParams)+len(headerParams) > 0 {
+		paramsType = name + "Params"
+		g.printf("// %s %s 的参数，可选参数为 nil 时不发送\n", paramsType, name)
+		g.printf("type %s struct {\n", paramsType)
+		for _, p := range append(append([]Parameter{}, queryParams...), headerParams...) {
+			g.printf("%s %s%s\n", goName(p.Name), goType(p.Schema, !p.Required), paramComment(p))
+		}
+		g.printf("}\n\n")
+	}
+
+	bodyArg, formType, binary := "", "", ""
+	if op.RequestBody != nil {
+		for ct, mt := range op.RequestBody.Content {
+			switch {
+			case ct == "application/json":
+				bodyArg = goType(mt.Schema, false)
+			case ct == "multipart/form-data":
+				formType = name + "Form"
+				g.formType(formType, name, mt.Schema)
+			default:
+				binary = ct
+			}
+		}
+	}
+
+	result, resultType, isFile, hasNoContent := g.result(op)
+
+	// 签名
+	g.printf("// %s %s\n//\n// %s %s\n", name, op.Summary, method, path)
+	g.printf("func (c *Client) %s(ctx context.Context", name)
+	for _, p := range pathParams {
+		g.printf(", %s %s", lowerName(p.Name), goType(p.Schema, false))
+	}
+	if paramsType != "" {
+		g.printf(", params *%s", paramsType)
+	}
+	switch {
+	case bodyArg != "":
+		g.printf(", body %s", bodyArg)
+	case formType != "":
+		g.printf(", form %s", formType)
+	case binary != "":
+		g.printf(", body io.Reader")
+	}
+	switch {
+	case isFile:
+		g.printf(") (io.ReadCloser, error) {\n")
+	case resultType != "":
+		g.printf(") (%s, error) {\n", resultType)
+	default:
+		g.printf(") error {\n")
+	}
+
+	// 请求
+	g.printf("req := request{method: %q, path: %s}\n", method, pathExpr(path, pathParams))
+	if paramsType != "" {
+		if len(queryParams) > 0 {
+			g.printf("req.query = url.Values{}\n")
+		}
+		if len(headerParams) > 0 {
+			g.printf("req.header = http.Header{}\n")
+		}
+		g.printf("if params != nil {\n")
+		for _, p := range queryParams {
+			g.printf("addQuery(req.query, %q, params.%s)\n", p.Name, goName(p.Name))
+		}
+		for _, p := range headerParams {
+			g.printf("addHeader(req.header, %q, params.%s)\n", p.Name, goName(p.Name))
+		}
+		g.printf("}\n")
+	}
+	switch {
+	case bodyArg != "":
+		g.printf("req.json = body\n")
+	case formType != "":
+		g.formParts(op.RequestBody.Content["multipart/form-data"].Schema)
+	case binary != "":
+		g.printf("req.body, req.contentType = body, %q\n", binary)
+	}
+
+	// 响应
+	switch {
+	case isFile:
+		g.printf("return c.download(ctx, req)\n")
+	case resultType == "":
+		g.printf("_, err := c.do(ctx, req, nil)\nreturn err\n")
+	default:
+		g.printf("var out %s\n", result)
+		if hasNoContent {
+			g.printf("status, err := c.do(ctx, req, &out)\n")
+			g.printf("if err != nil || status == http.StatusNoContent {\nreturn nil, err\n}\n")
+		} else {
+			g.printf("if _, err := c.do(ctx, req, &out); err != nil {\nreturn nil, err\n}\n")
+		}
+		if strings.HasPrefix(resultType, "*") {
+			g.printf("return &out, nil\n")
+		} else {
+			g.printf("return out, nil\n")
+		}
+	}
+	g.printf("}\n\n")
+}
+
+// result 返回 data 的类型：结构体返回指针，数组直接返回
+func (g *clientGen) result(op *Operation) (result, resultType string, isFile, hasNoContent bool) {
+	_, hasNoContent = op.Responses["204"]
+	codes := make([]string, 0, len(op.Responses))
+	for code := range op.Responses {
+		if strings.HasPrefix(code, "2") && code != "204" {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+	if len(codes) == 0 {
+		return "", "", false, hasNoContent
+	}
+
+	for ct, mt := range op.Responses[codes[0]].Content {
+		if ct != "application/json" {
+			return "", "", true, hasNoContent
+		}
+		data := dataSchema(mt.Schema)
+		if data == nil {
+			return "", "", false, hasNoContent
+		}
+		result = goType(data, false)
+		if strings.HasPrefix(result, "[]") || strings.HasPrefix(result, "map[") {
+			return result, result, false, hasNoContent
+		}
+		return result, "*" + result, false, hasNoContent
+	}
+	return "", "", false, hasNoContent
+}
+
+// dataSchema 从 allOf [Response, {data}] 中取出 data 的 Schema
+func dataSchema(s *Schema) *Schema {
+	for _, part := range s.AllOf {
+		if data, ok := part.Properties["data"]; ok {
+			return data
+		}
+	}
+	return nil
+}
+
+func (g *clientGen) formType(typeName, opName string, s *Schema) {
+	g.printf("// %s %s 的表单，文件字段同时需要文件名\n", typeName, opName)
+	g.printf("type %s struct {\n", typeName)
+	for _, prop := range propertyNames(s) {
+		ps := s.Properties[prop]
+		if ps.Format == "binary" {
+			g.printf("%s io.Reader\n%sName string\n", goName(prop), goName(prop))
+			continue
+		}
+		g.printf("%s %s%s\n", goName(prop), goType(ps, !contains(s.Required, prop)), fieldComment(ps))
+	}
+	g.printf("}\n\n")
+}
+
+func (g *clientGen) formParts(s *Schema) {
+	g.printf("req.form = []formPart{\n")
+	for _, prop := range propertyNames(s) {
+		ps := s.Properties[prop]
+		if ps.Format == "binary" {
+			g.printf("{name: %q, file: form.%s, fileName: form.%sName},\n", prop, goName(prop), goName(prop))
+		} else {
+			g.printf("{name: %q, value: form.%s},\n", prop, goName(prop))
+		}
+	}
+	g.printf("}\n")
+}
+
+// pathExpr 生成拼接路径参数的表达式
+func pathExpr(path string, params []Parameter) string {
+	if len(params) == 0 {
+		return fmt.Sprintf("%q", path)
+	}
+	expr := fmt.Sprintf("%q", path)
+	for _, p := range params {
+		value := "url.PathEscape(" + lowerName(p.Name) + ")"
+		if p.Schema.Type == "integer" {
+			value = "strconv.FormatInt(" + lowerName(p.Name) + ", 10)"
+		}
+		expr = strings.Replace(expr, "{"+p.Name+"}", `" + `+value+` + "`, 1)
+	}
+	return strings.TrimSuffix(expr, ` + ""`)
+}
+
+// goType Schema 对应的 Go 类型，optional 为 true 时标量和结构体使用指针
+func goType(s *Schema, optional bool) string {
+	if len(s.AllOf) == 1 {
+		return "*" + goType(s.AllOf[0], false)
+	}
+	if name := s.RefName(); name != "" {
+		if optional {
+			return "*" + name
+		}
+		return name
+	}
+
+	ptr := ""
+	if s.Nullable || optional {
+		ptr = "*"
+	}
+	switch s.Type {
+	case "string":
+		switch s.Format {
+		case "date-time":
+			return ptr + "time.Time"
+		case "byte", "binary":
+			return "[]byte"
+		}
+		return ptr + "string"
+	case "integer":
+		if s.Format == "int32" {
+			return ptr + "int32"
+		}
+		return ptr + "int64"
+	case "number":
+		return ptr + "float64"
+	case "boolean":
+		return ptr + "bool"
+	case "array":
+		return "[]" + goType(s.Items, false)
+	case "object":
+		if s.AdditionalProperties != nil {
+			return "map[string]" + goType(s.AdditionalProperties, false)
+		}
+	}
+	return "interface{}"
+}
+
+func fieldComment(s *Schema) string {
+	var notes []string
+	if s.Description != "" {
+		notes = append(notes, s.Description)
+	}
+	if len(s.Enum) > 0 {
+		notes = append(notes, "可选值："+strings.Join(s.Enum, ", "))
+	}
+	if len(notes) == 0 {
+		return ""
+	}
+	return " // " + strings.Join(notes, "；")
+}
+
+func paramComment(p Parameter) string {
+	notes := []string{}
+	if p.Description != "" {
+		notes = append(notes, p.Description)
+	}
+	if len(p.Schema.Enum) > 0 {
+		notes = append(notes, "可选值："+strings.Join(p.Schema.Enum, ", "))
+	}
+	if p.In == "header" {
+		notes = append(notes, "请求头 "+p.Name)
+	}
+	if len(notes) == 0 {
+		return ""
+	}
+	return " // " + strings.Join(notes, "；")
+}
+
+func propertyNames(s *Schema) []string {
+	if len(s.XOrder) == len(s.Properties) {
+		return s.XOrder
+	}
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// initialisms 按 Go 命名习惯全部大写的词
+var initialisms = map[string]string{
+	"id": "ID", "ids": "IDs", "url": "URL", "html": "HTML", "json": "JSON", "api": "API", "uid": "UID",
+}
+
+// goName 把 json 字段名或请求头名转换为导出的 Go 名称，例如 image_ids -> ImageIDs
+func goName(name string) string {
+	var b strings.Builder
+	for _, word := range strings.FieldsFunc(name, func(r rune) bool { return r == '_' || r == '-' }) {
+		if v, ok := initialisms[strings.ToLower(word)]; ok {
+			b.WriteString(v)
+			continue
+		}
+		b.WriteString(exportName(strings.ToLower(word)))
+	}
+	return b.String()
+}
+
+func lowerName(name string) string {
+	n := goName(name)
+	if v, ok := initialisms[strings.ToLower(n)]; ok && v == n {
+		return strings.ToLower(n)
+	}
+	return strings.ToLower(n[:1]) + n[1:]
+}
+
+func exportName(name string) string {
+	if name == "" {
+		return name
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,379 @@
+package apidoc
+
+import (
+	"diary/internal/handler/dto"
+	"diary/internal/service"
+)
+
+// route 一个接口的描述。路径和 app.SetupRouter 一致，子路由的 "/" 不带结尾斜杠
+type route struct {
+	method, path string
+	id           string // operationId，也是生成客户端的方法名
+	tag          string
+	summary      string
+	public       bool // 不需要登录
+	noClient     bool // 不生成客户端方法
+
+	params []Parameter
+	body   interface{} // JSON 请求体
+	form   []formField // multipart/form-data 请求体
+	binary string      // 原始字节请求体的 Content-Type
+
+	status    int         // 成功状态码，默认 200
+	result    interface{} // 响应 data 的类型，nil 表示没有 data
+	file      string      // 响应为文件时的 Content-Type
+	noContent string      // 另外可能返回 204 时的说明
+}
+
+// formField multipart 表单字段，typ 为 string、integer、boolean 或 file
+type formField struct {
+	name     string
+	typ      string
+	required bool
+	desc     string
+	enum     []string
+}
+
+var tags = []Tag{
+	{Name: "users", Description: "注册、登录和账户"},
+	{Name: "diaries", Description: "日记"},
+	{Name: "shares", Description: "日记分享链接"},
+	{Name: "comments", Description: "评论和表情回应"},
+	{Name: "follows", Description: "关注、动态和屏蔽"},
+	{Name: "journals", Description: "多人共享的日记本"},
+	{Name: "feeds", Description: "公开日记的 Atom、RSS 和 JSON Feed 订阅源"},
+	{Name: "tags", Description: "标签"},
+	{Name: "todos", Description: "待办事项和清单"},
+	{Name: "attachments", Description: "图片、音视频附件和断点续传"},
+	{Name: "export", Description: "导出和导入"},
+	{Name: "notifications", Description: "提醒和通知"},
+	{Name: "calendar", Description: "日历订阅和 .ics 导入"},
+	{Name: "stats", Description: "统计"},
+	{Name: "docs", Description: "接口文档"},
+}
+
+var routes = []route{
+	// 文档
+	{method: "GET", path: "/api/openapi.json", id: "getOpenAPISpec", tag: "docs", summary: "OpenAPI 文档",
+		public: true, noClient: true, file: "application/json"},
+	{method: "GET", path: "/api/docs", id: "getAPIDocs", tag: "docs", summary: "Swagger UI 页面",
+		public: true, noClient: true, file: "text/html"},
+
+	// 用户
+	{method: "POST", path: "/api/register", id: "register", tag: "users", summary: "注册，成功后直接返回登录令牌",
+		public: true, body: dto.RegisterRequest{}, status: 201, result: dto.LoginResponse{}},
+	{method: "POST", path: "/api/login", id: "login", tag: "users", summary: "登录",
+		public: true, body: dto.LoginRequest{}, result: dto.LoginResponse{}},
+	{method: "GET", path: "/api/user/profile", id: "getProfile", tag: "users", summary: "当前用户信息",
+		result: dto.UserResponse{}},
+	{method: "PUT", path: "/api/user/username", id: "updateUsername", tag: "users", summary: "修改用户名",
+		body: dto.UpdateUsernameRequest{}},
+	{method: "PUT", path: "/api/user/password", id: "updatePassword", tag: "users", summary: "修改密码",
+		body: dto.UpdatePasswordRequest{}},
+	{method: "DELETE", path: "/api/user", id: "deleteAccount", tag: "users", summary: "注销账户"},
+	{method: "PUT", path: "/api/user/profile", id: "updateProfile", tag: "users", summary: "修改公开主页资料",
+		body: dto.UpdateProfileRequest{}, result: dto.ProfileResponse{}},
+	{method: "GET", path: "/api/users/{username}", id: "getPublicProfile", tag: "users", summary: "公开主页，未开启时返回 404",
+		public: true, params: []Parameter{pathUsername}, result: dto.ProfileResponse{}},
+	{method: "GET", path: "/api/users/{username}/diaries", id: "listUserPublicDiaries", tag: "diaries", summary: "某个用户的公开日记，需要对方开启公开主页",
+		public: true, params: append(append(append([]Parameter{pathUsername}, cursorPageParams()...), publicDiaryFilterParams...), renderParam),
+		result: dto.DiaryListResponse{}},
+
+	// 日记
+	{method: "GET", path: "/api/diaries/public", id: "listPublicDiaries", tag: "diaries", summary: "公开日记列表，作者开启了公开主页时返回 author",
+		public: true, params: append(append(cursorPageParams(), publicDiaryFilterParams...), renderParam), result: dto.DiaryListResponse{}},
+	{method: "POST", path: "/api/diaries", id: "createDiary", tag: "diaries", summary: "创建日记",
+		body: dto.CreateDiaryRequest{}, status: 201, result: dto.DiaryResponse{}},
+	{method: "GET", path: "/api/diaries", id: "listDiaries", tag: "diaries", summary: "我的日记列表",
+		params: cursorPageParams(), result: dto.DiaryListResponse{}},
+	{method: "GET", path: "/api/diaries/search", id: "searchDiaries", tag: "diaries", summary: "搜索日记",
+		params: append([]Parameter{query("q", "string", "关键词", true)}, cursorPageParams()...), result: dto.DiaryListResponse{}},
+	{method: "GET", path: "/api/diaries/{id}", id: "getDiary", tag: "diaries", summary: "日记详情，只能查看自己的或公开的",
+		params: []Parameter{pathID, renderParam}, result: dto.DiaryResponse{}},
+	{method: "PUT", path: "/api/diaries/{id}", id: "updateDiary", tag: "diaries", summary: "更新日记",
+		params: []Parameter{pathID}, body: dto.UpdateDiaryRequest{}, result: dto.DiaryResponse{}},
+	{method: "DELETE", path: "/api/diaries/{id}", id: "deleteDiary", tag: "diaries", summary: "删除日记",
+		params: []Parameter{pathID}},
+	{method: "POST", path: "/api/diaries/{id}/pin", id: "toggleDiaryPin", tag: "diaries", summary: "置顶或取消置顶",
+		params: []Parameter{pathID}, result: dto.PinResponse{}},
+
+	// 分享链接
+	{method: "GET", path: "/s/{token}", id: "viewSharedDiary", tag: "shares", summary: "通过分享链接查看日记，正文渲染为 HTML，附件返回签名地址",
+		public: true, params: []Parameter{
+			{Name: "token", In: "path", Required: true, Description: "分享令牌", Schema: &Schema{Type: "string"}},
+			header("X-Share-Password", "string", "访问密码，链接设置了密码时必填", false),
+		}, result: dto.DiaryResponse{}},
+	{method: "POST", path: "/api/diaries/{id}/shares", id: "createShareLink", tag: "shares", summary: "创建分享链接，url 只在这里返回一次",
+		params: []Parameter{pathID}, body: dto.CreateShareRequest{}, status: 201, result: dto.ShareLinkResponse{}},
+	{method: "GET", path: "/api/diaries/{id}/shares", id: "listShareLinks", tag: "shares", summary: "日记的分享链接，包括已过期和已撤销的",
+		params: []Parameter{pathID}, result: []dto.ShareLinkResponse{}},
+	{method: "DELETE", path: "/api/shares/{id}", id: "revokeShareLink", tag: "shares", summary: "撤销分享链接",
+		params: []Parameter{pathID}},
+
+	// 评论、表情回应
+	{method: "GET", path: "/api/diaries/{id}/comments", id: "listComments", tag: "comments", summary: "评论列表，按顶层评论分页，每条带上全部回复",
+		params: append(append([]Parameter{pathID}, pageParams()...), shareParams...), result: dto.CommentListResponse{}},
+	{method: "POST", path: "/api/diaries/{id}/comments", id: "createComment", tag: "comments", summary: "发表评论或回复，正文为 Markdown",
+		params: append([]Parameter{pathID}, shareParams...), body: dto.CreateCommentRequest{}, status: 201, result: dto.CommentResponse{}},
+	{method: "PUT", path: "/api/diaries/{id}/comment-settings", id: "updateCommentSettings", tag: "comments", summary: "开启或关闭日记的评论，只有作者可以设置",
+		params: []Parameter{pathID}, body: dto.CommentSettingsRequest{}, result: dto.CommentSettingsRequest{}},
+	{method: "PUT", path: "/api/comments/{id}", id: "updateComment", tag: "comments", summary: "修改自己的评论",
+		params: append([]Parameter{pathID}, shareParams...), body: dto.UpdateCommentRequest{}, result: dto.CommentResponse{}},
+	{method: "DELETE", path: "/api/comments/{id}", id: "deleteComment", tag: "comments", summary: "删除评论，评论者和日记作者都可以删除",
+		params: []Parameter{pathID}},
+	{method: "GET", path: "/api/diaries/{id}/reactions", id: "listReactions", tag: "comments", summary: "表情回应统计",
+		params: append([]Parameter{pathID}, shareParams...), result: []dto.ReactionCountResponse{}},
+	{method: "POST", path: "/api/diaries/{id}/reactions", id: "addReaction", tag: "comments", summary: "回应表情，重复回应不报错",
+		params: append([]Parameter{pathID}, shareParams...), body: dto.ReactionRequest{}, result: []dto.ReactionCountResponse{}},
+	{method: "DELETE", path: "/api/diaries/{id}/reactions", id: "removeReaction", tag: "comments", summary: "取消表情回应",
+		params: append([]Parameter{pathID, query("emoji", "string", "要取消的表情", true)}, shareParams...),
+		result: []dto.ReactionCountResponse{}},
+
+	// 关注、屏蔽
+	{method: "GET", path: "/api/feed", id: "getFeed", tag: "follows", summary: "关注的人的公开日记，按日期降序，用 next_cursor 翻页",
+		params: []Parameter{
+			query("cursor", "string", "上一页返回的 next_cursor，不传时从最新开始", false),
+			query("limit", "integer", "每页数量，默认 20，最多 100", false),
+			renderParam,
+		}, result: dto.FeedResponse{}},
+	{method: "POST", path: "/api/users/{username}/follow", id: "followUser", tag: "follows", summary: "关注用户，对方需要开启公开主页",
+		params: []Parameter{pathUsername}},
+	{method: "DELETE", path: "/api/users/{username}/follow", id: "unfollowUser", tag: "follows", summary: "取消关注",
+		params: []Parameter{pathUsername}},
+	{method: "POST", path: "/api/users/{username}/block", id: "blockUser", tag: "follows", summary: "屏蔽用户，对方不能再查看、评论和回应你的日记，双方的关注同时解除",
+		params: []Parameter{pathUsername}},
+	{method: "DELETE", path: "/api/users/{username}/block", id: "unblockUser", tag: "follows", summary: "取消屏蔽",
+		params: []Parameter{pathUsername}},
+	{method: "GET", path: "/api/user/blocks", id: "listBlockedUsers", tag: "follows", summary: "屏蔽列表",
+		result: []dto.BlockResponse{}},
+
+	// 订阅源
+	{method: "GET", path: "/feeds/{file}", id: "userFeed", tag: "feeds", summary: "用户最近的公开日记，需要开启公开主页；支持 If-None-Match 和 If-Modified-Since",
+		public: true, params: []Parameter{
+			{Name: "file", In: "path", Required: true, Description: "用户名加扩展名：.atom、.rss 或 .json（JSON Feed 1.1）", Schema: &Schema{Type: "string"}},
+		}, file: "application/atom+xml"},
+
+	// 共享日记本
+	{method: "POST", path: "/api/journals", id: "createJournal", tag: "journals", summary: "创建日记本，创建者成为所有者",
+		body: dto.CreateJournalRequest{}, status: 201, result: dto.JournalResponse{}},
+	{method: "GET", path: "/api/journals", id: "listJournals", tag: "journals", summary: "自己加入的日记本",
+		result: []dto.JournalResponse{}},
+	{method: "GET", path: "/api/journals/{id}", id: "getJournal", tag: "journals", summary: "日记本详情",
+		params: []Parameter{pathID}, result: dto.JournalResponse{}},
+	{method: "PUT", path: "/api/journals/{id}", id: "updateJournal", tag: "journals", summary: "修改名称和简介，只有所有者可以修改",
+		params: []Parameter{pathID}, body: dto.UpdateJournalRequest{}, result: dto.JournalResponse{}},
+	{method: "DELETE", path: "/api/journals/{id}", id: "deleteJournal", tag: "journals", summary: "删除日记本及其中的日记，只有所有者可以删除",
+		params: []Parameter{pathID}},
+	{method: "GET", path: "/api/journals/{id}/members", id: "listJournalMembers", tag: "journals", summary: "成员列表",
+		params: []Parameter{pathID}, result: []dto.JournalMemberResponse{}},
+	{method: "POST", path: "/api/journals/{id}/members", id: "addJournalMember", tag: "journals", summary: "添加编辑或只读成员，只有所有者可以添加",
+		params: []Parameter{pathID}, body: dto.AddJournalMemberRequest{}, status: 201, result: dto.JournalMemberResponse{}},
+	{method: "PUT", path: "/api/journals/{id}/members/{username}", id: "updateJournalMember", tag: "journals", summary: "修改成员角色",
+		params: []Parameter{pathID, pathUsername}, body: dto.UpdateJournalMemberRequest{}},
+	{method: "DELETE", path: "/api/journals/{id}/members/{username}", id: "removeJournalMember", tag: "journals", summary: "移除成员，成员传自己的用户名即为退出",
+		params: []Parameter{pathID, pathUsername}},
+	{method: "GET", path: "/api/journals/{id}/diaries", id: "listJournalDiaries", tag: "journals", summary: "日记本内所有成员的日记，带作者信息",
+		params: append(pageParams(), pathID, renderParam), result: dto.DiaryListResponse{}},
+	{method: "GET", path: "/api/journals/{id}/diaries/search", id: "searchJournalDiaries", tag: "journals", summary: "搜索日记本内的日记",
+		params: append([]Parameter{pathID, query("q", "string", "关键词", true)}, append(pageParams(), renderParam)...), result: dto.DiaryListResponse{}},
+
+	// 标签
+	{method: "POST", path: "/api/tags", id: "createTag", tag: "tags", summary: "创建标签",
+		body: dto.CreateTagRequest{}, status: 201, result: dto.TagResponse{}},
+	{method: "GET", path: "/api/tags", id: "listTags", tag: "tags", summary: "标签列表",
+		params: cursorPageParams(), result: dto.TagListResponse{}},
+	{method: "GET", path: "/api/tags/popular", id: "listPopularTags", tag: "tags", summary: "常用标签",
+		params: []Parameter{query("limit", "integer", "数量", false)}, result: []dto.TagResponse{}},
+	{method: "PUT", path: "/api/tags/{id}", id: "updateTag", tag: "tags", summary: "重命名标签",
+		params: []Parameter{pathID}, body: dto.UpdateTagRequest{}},
+	{method: "DELETE", path: "/api/tags/{id}", id: "deleteTag", tag: "tags", summary: "删除标签",
+		params: []Parameter{pathID}},
+
+	// 待办
+	{method: "POST", path: "/api/todos", id: "createTodo", tag: "todos", summary: "创建待办",
+		body: dto.CreateTodoRequest{}, status: 201, result: dto.TodoResponse{}},
+	{method: "GET", path: "/api/todos", id: "listTodos", tag: "todos", summary: "待办列表，顶层任务带子任务",
+		params: append(cursorPageParams(), todoFilterParams...), result: dto.TodoListResponse{}},
+	{method: "GET", path: "/api/todos/stats", id: "getTodoStats", tag: "todos", summary: "待办统计",
+		result: dto.TodoStatsResponse{}},
+	{method: "PUT", path: "/api/todos/order", id: "reorderTodos", tag: "todos", summary: "手动排序",
+		body: dto.ReorderTodosRequest{}},
+	{method: "POST", path: "/api/todos/recurrence/preview", id: "previewRecurrence", tag: "todos", summary: "预览重复规则",
+		body: dto.PreviewRecurrenceRequest{}, result: dto.OccurrencesResponse{}},
+	{method: "PUT", path: "/api/todos/{id}", id: "updateTodo", tag: "todos", summary: "更新待办",
+		params: []Parameter{pathID}, body: dto.UpdateTodoRequest{}, result: dto.TodoResponse{}},
+	{method: "DELETE", path: "/api/todos/{id}", id: "deleteTodo", tag: "todos", summary: "删除待办",
+		params: []Parameter{pathID}},
+	{method: "PATCH", path: "/api/todos/{id}/done", id: "markTodoDone", tag: "todos", summary: "标记完成，重复待办会生成下一次",
+		params: []Parameter{pathID}},
+	{method: "PATCH", path: "/api/todos/{id}/undone", id: "markTodoUndone", tag: "todos", summary: "标记未完成",
+		params: []Parameter{pathID}},
+	{method: "POST", path: "/api/todos/{id}/skip", id: "skipTodo", tag: "todos", summary: "跳过重复待办的本次",
+		params: []Parameter{pathID}},
+	{method: "GET", path: "/api/todos/{id}/occurrences", id: "listTodoOccurrences", tag: "todos", summary: "重复待办接下来的计划时间",
+		params: []Parameter{pathID, query("count", "integer", "数量，默认 10", false)}, result: dto.OccurrencesResponse{}},
+	{method: "POST", path: "/api/projects", id: "createProject", tag: "todos", summary: "创建清单",
+		body: dto.CreateProjectRequest{}, status: 201, result: dto.ProjectResponse{}},
+	{method: "GET", path: "/api/projects", id: "listProjects", tag: "todos", summary: "清单列表",
+		result: []dto.ProjectResponse{}},
+	{method: "PUT", path: "/api/projects/{id}", id: "updateProject", tag: "todos", summary: "更新清单",
+		params: []Parameter{pathID}, body: dto.UpdateProjectRequest{}, result: dto.ProjectResponse{}},
+	{method: "DELETE", path: "/api/projects/{id}", id: "deleteProject", tag: "todos", summary: "删除清单，其中的待办移出清单",
+		params: []Parameter{pathID}},
+
+	// 图片和附件
+	{method: "POST", path: "/api/images/upload", id: "uploadImage", tag: "attachments", summary: "上传图片",
+		form: []formField{
+			{name: "image", typ: "file", required: true},
+			{name: "diary_id", typ: "integer", desc: "上传后关联到日记"},
+		}, status: 201, result: dto.ImageResponse{}},
+	{method: "GET", path: "/api/images", id: "listImages", tag: "attachments", summary: "图片列表",
+		params: cursorPageParams(), result: dto.ImageListResponse{}},
+	{method: "DELETE", path: "/api/images/{id}", id: "deleteImage", tag: "attachments", summary: "删除图片",
+		params: []Parameter{pathID}},
+	{method: "POST", path: "/api/images/{id}/attach", id: "attachImage", tag: "attachments", summary: "图片关联到日记",
+		params: []Parameter{pathID}, body: dto.AttachImageRequest{}},
+	{method: "POST", path: "/api/attachments/upload", id: "uploadAttachment", tag: "attachments", summary: "上传图片、音频或视频",
+		form: []formField{
+			{name: "file", typ: "file", required: true},
+			{name: "diary_id", typ: "integer", desc: "上传后关联到日记"},
+		}, status: 201, result: dto.ImageResponse{}},
+	{method: "GET", path: "/api/attachments", id: "listAttachments", tag: "attachments", summary: "附件列表",
+		params: append(cursorPageParams(), query("kind", "string", "附件类型", false, "image", "audio", "video")),
+		result: dto.AttachmentListResponse{}},
+	{method: "DELETE", path: "/api/attachments/{id}", id: "deleteAttachment", tag: "attachments", summary: "删除附件",
+		params: []Parameter{pathID}},
+	{method: "POST", path: "/api/attachments/{id}/attach", id: "attachAttachment", tag: "attachments", summary: "附件关联到日记",
+		params: []Parameter{pathID}, body: dto.AttachImageRequest{}},
+	{method: "GET", path: "/media/images/{id}", id: "getSignedMedia", tag: "attachments", summary: "通过签名链接读取附件",
+		public: true, params: []Parameter{
+			pathID,
+			query("expires", "integer", "过期时间戳", true),
+			query("sig", "string", "签名", true),
+		}, file: "application/octet-stream"},
+
+	// 断点续传（tus 1.0.0）
+	{method: "POST", path: "/api/uploads", id: "createUpload", tag: "attachments", summary: "创建上传会话",
+		params: []Parameter{
+			header("Upload-Length", "integer", "文件总字节数", true),
+			header("Upload-Metadata", "string", "逗号分隔的 key base64(value)，需要 filename，可选 checksum", true),
+		}, status: 201, result: dto.UploadResponse{}},
+	{method: "HEAD", path: "/api/uploads/{id}", id: "getUploadOffset", tag: "attachments", summary: "查询进度，通过 Upload-Offset 响应头返回",
+		params: []Parameter{pathUploadID}, noClient: true},
+	{method: "PATCH", path: "/api/uploads/{id}", id: "uploadChunk", tag: "attachments", summary: "追加分片，最后一片返回生成的附件",
+		params: []Parameter{
+			pathUploadID,
+			header("Upload-Offset", "integer", "当前偏移量", true),
+			header("Upload-Checksum", "string", `分片校验，格式为 "sha256 <base64>"`, false),
+		}, binary: "application/offset+octet-stream", result: dto.ImageResponse{}, noContent: "分片已写入，上传未完成"},
+	{method: "DELETE", path: "/api/uploads/{id}", id: "cancelUpload", tag: "attachments", summary: "取消上传",
+		params: []Parameter{pathUploadID}, status: 204},
+
+	// 导出、导入
+	{method: "GET", path: "/api/diaries/{id}/export", id: "exportDiary", tag: "export", summary: "导出单篇日记",
+		params: []Parameter{pathID, query("format", "string", "默认 txt", false, "md", "txt", "csv", "pdf", "html")},
+		file:   "application/octet-stream"},
+	{method: "POST", path: "/api/diaries/export", id: "exportDiaries", tag: "export", summary: "批量导出，直接返回文件",
+		body: dto.ExportRequest{}, file: "application/octet-stream"},
+	{method: "POST", path: "/api/exports", id: "createExportJob", tag: "export", summary: "创建异步导出任务",
+		body: dto.ExportRequest{}, status: 202, result: dto.ExportJobResponse{}},
+	{method: "GET", path: "/api/exports/{id}", id: "getExportJob", tag: "export", summary: "导出任务状态",
+		params: []Parameter{pathJobID}, result: dto.ExportJobResponse{}},
+	{method: "GET", path: "/api/exports/{id}/download", id: "downloadExportJob", tag: "export", summary: "下载导出文件",
+		params: []Parameter{pathJobID}, file: "application/octet-stream"},
+	{method: "GET", path: "/api/export/archive", id: "exportArchive", tag: "export", summary: "导出整个账户的数据包",
+		file: "application/zip"},
+	{method: "POST", path: "/api/import", id: "importData", tag: "export", summary: "导入数据包或其他应用的导出",
+		form: []formField{
+			{name: "file", typ: "file", required: true},
+			{name: "format", typ: "string", desc: "默认 archive；auto 自动识别", enum: []string{"archive", "auto", "dayone", "journey", "markdown"}},
+			{name: "dry_run", typ: "boolean", desc: "只预览不写入"},
+		}, result: service.ImportReport{}},
+
+	// 通知
+	{method: "GET", path: "/api/notifications", id: "listNotifications", tag: "notifications", summary: "通知列表",
+		params: append(pageParams(), query("unread", "boolean", "只看未读", false)), result: dto.NotificationListResponse{}},
+	{method: "POST", path: "/api/notifications/read-all", id: "markAllNotificationsRead", tag: "notifications", summary: "全部标记为已读"},
+	{method: "POST", path: "/api/notifications/{id}/read", id: "markNotificationRead", tag: "notifications", summary: "标记为已读",
+		params: []Parameter{pathID}},
+	{method: "GET", path: "/api/notifications/settings", id: "getNotificationSettings", tag: "notifications", summary: "提醒设置",
+		result: dto.NotificationSettingResponse{}},
+	{method: "PUT", path: "/api/notifications/settings", id: "updateNotificationSettings", tag: "notifications", summary: "保存提醒设置",
+		body: dto.NotificationSettingRequest{}, result: dto.NotificationSettingResponse{}},
+	{method: "POST", path: "/api/notifications/test", id: "sendTestNotification", tag: "notifications", summary: "通过已开启的渠道发送测试通知"},
+
+	// 日历
+	{method: "GET", path: "/api/calendar.ics", id: "calendarFeed", tag: "calendar", summary: "iCalendar 订阅内容",
+		public: true, params: []Parameter{query("token", "string", "订阅令牌", true)}, file: "text/calendar"},
+	{method: "GET", path: "/api/calendar", id: "getCalendarSettings", tag: "calendar", summary: "订阅设置",
+		result: dto.CalendarFeedResponse{}},
+	{method: "PUT", path: "/api/calendar", id: "updateCalendarSettings", tag: "calendar", summary: "保存订阅设置",
+		body: dto.CalendarFeedRequest{}, result: dto.CalendarFeedResponse{}},
+	{method: "POST", path: "/api/calendar/token", id: "rotateCalendarToken", tag: "calendar", summary: "开启订阅或更换链接",
+		result: dto.CalendarFeedResponse{}},
+	{method: "DELETE", path: "/api/calendar/token", id: "revokeCalendarToken", tag: "calendar", summary: "关闭订阅"},
+	{method: "POST", path: "/api/calendar/import", id: "importCalendar", tag: "calendar", summary: "从 .ics 导入待办",
+		form: []formField{
+			{name: "file", typ: "file", required: true},
+			{name: "timezone", typ: "string", desc: "浮动时间使用的 IANA 时区"},
+			{name: "dry_run", typ: "boolean", desc: "只预览不写入"},
+		}, result: service.ImportReport{}},
+
+	// 统计
+	{method: "GET", path: "/api/stats/dashboard", id: "getDashboardStats", tag: "stats", summary: "统计看板",
+		result: dto.DashboardStatsResponse{}},
+}
+
+var (
+	pathID       = Parameter{Name: "id", In: "path", Required: true, Schema: &Schema{Type: "integer", Format: "int64"}}
+	pathJobID    = Parameter{Name: "id", In: "path", Required: true, Description: "导出任务ID", Schema: &Schema{Type: "string"}}
+	pathUploadID = Parameter{Name: "id", In: "path", Required: true, Description: "上传会话ID", Schema: &Schema{Type: "string"}}
+	pathUsername = Parameter{Name: "username", In: "path", Required: true, Schema: &Schema{Type: "string"}}
+	renderParam  = query("render", "string", "html 时返回服务端渲染的 content_html", false, "html")
+)
+
+var todoFilterParams = []Parameter{
+	query("done", "boolean", "按完成状态过滤", false),
+	query("priority", "integer", "0-3", false),
+	query("project_id", "string", "清单ID，none 表示未归入清单", false),
+	query("parent_id", "integer", "父任务ID；不传时只返回顶层任务", false),
+	query("due_from", "string", "截止日期起（YYYY-MM-DD）", false),
+	query("due_to", "string", "截止日期止（YYYY-MM-DD，包含当天）", false),
+	query("sort", "string", "排序字段，默认 created_at", false, "created_at", "due_date", "priority", "order", "title"),
+	query("order", "string", "排序方向", false, "asc", "desc"),
+}
+
+var publicDiaryFilterParams = []Parameter{
+	query("tag", "string", "标签名", false),
+	query("start_date", "string", "日期起（YYYY-MM-DD）", false),
+	query("end_date", "string", "日期止（YYYY-MM-DD，包含当天）", false),
+}
+
+// shareParams 通过分享链接访问非公开日记时携带的凭证
+var shareParams = []Parameter{
+	header("X-Share-Token", "string", "分享令牌，日记未公开时必填", false),
+	header("X-Share-Password", "string", "访问密码，链接设置了密码时必填", false),
+}
+
+func pageParams() []Parameter {
+	return []Parameter{
+		query("page", "integer", "页码，从 1 开始", false),
+		query("page_size", "integer", "每页数量，默认 10", false),
+	}
+}
+
+// cursorPageParams 同时支持页码分页和游标分页的列表参数
+func cursorPageParams() []Parameter {
+	return append(pageParams(),
+		query("cursor", "string", "上一页返回的 next_cursor，传 cursor 或 limit 时按游标分页", false),
+		query("limit", "integer", "游标分页每页数量，默认 20，最多 100", false),
+	)
+}
+
+func query(name, typ, desc string, required bool, enum ...string) Parameter {
+	return Parameter{Name: name, In: "query", Description: desc, Required: required, Schema: &Schema{Type: typ, Enum: enum}}
+}
+
+func header(name, typ, desc string, required bool) Parameter {
+	return Parameter{Name: name, In: "header", Description: desc, Required: required, Schema: &Schema{Type: typ}}
+}
@@ -0,0 +1,184 @@
+package apidoc
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// schemaRegistry 把 Go 类型转换为 Schema，具名结构体放入 components 并以 $ref 引用
+type schemaRegistry struct {
+	schemas map[string]*Schema
+}
+
+func newSchemaRegistry() *schemaRegistry {
+	return &schemaRegistry{schemas: map[string]*Schema{}}
+}
+
+// schemaOf 返回类型对应的 Schema。request 为 true 时按 binding 标签判断必填，
+// 否则没有 omitempty 的字段都视为必返回
+func (g *schemaRegistry) schemaOf(t reflect.Type, request bool) *Schema {
+	nullable := false
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		nullable = true
+	}
+
+	var s *Schema
+	switch {
+	case t == timeType:
+		s = &Schema{Type: "string", Format: "date-time"}
+	case t == rawMessageType:
+		s = &Schema{}
+	default:
+		s = g.schemaOfKind(t, request)
+	}
+	if nullable {
+		if s.Ref != "" {
+			return &Schema{AllOf: []*Schema{s}, Nullable: true}
+		}
+		s.Nullable = true
+	}
+	return s
+}
+
+func (g *schemaRegistry) schemaOfKind(t reflect.Type, request bool) *Schema {
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schemaOf(t.Elem(), request)}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaOf(t.Elem(), request)}
+	case reflect.Interface:
+		return &Schema{}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t, request)
+		}
+		if _, ok := g.schemas[t.Name()]; !ok {
+			g.schemas[t.Name()] = &Schema{} // 先占位，处理自引用（例如子任务）
+			g.schemas[t.Name()] = g.structSchema(t, request)
+		}
+		return &Schema{Ref: refPrefix + t.Name()}
+	}
+	return &Schema{}
+}
+
+func (g *schemaRegistry) structSchema(t reflect.Type, request bool) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		prop := g.schemaOf(f.Type, request)
+		required := applyBinding(prop, f.Tag.Get("binding"))
+		if !request {
+			required = !strings.Contains(opts, "omitempty")
+		}
+
+		s.Properties[name] = prop
+		s.XOrder = append(s.XOrder, name)
+		if required {
+			s.Required = append(s.Required, name)
+		}
+	}
+	return s
+}
+
+// applyBinding 把 binding 标签中的校验规则写入 Schema，返回字段是否必填。
+// dive 之后的规则作用于数组元素
+func applyBinding(s *Schema, tag string) bool {
+	if tag == "" {
+		return false
+	}
+	required := false
+	target := s
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		if target == nil {
+			break
+		}
+		switch name {
+		case "required":
+			if target == s {
+				required = true
+			}
+		case "dive":
+			target = target.Items
+		case "min", "gte":
+			setBound(target, param, true)
+		case "max", "lte":
+			setBound(target, param, false)
+		case "oneof":
+			target.Enum = strings.Fields(param)
+		case "email":
+			target.Format = "email"
+		case "url", "http_url":
+			target.Format = "uri"
+		case "datetime":
+			if param == "2006-01-02" {
+				target.Format = "date"
+			} else {
+				target.Description = "格式：" + param
+			}
+		}
+	}
+	return required
+}
+
+func setBound(s *Schema, param string, lower bool) {
+	n, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+	switch s.Type {
+	case "string":
+		v := int(n)
+		if lower {
+			s.MinLength = &v
+		} else {
+			s.MaxLength = &v
+		}
+	case "array":
+		v := int(n)
+		if lower {
+			s.MinItems = &v
+		} else {
+			s.MaxItems = &v
+		}
+	case "integer", "number":
+		if lower {
+			s.Minimum = &n
+		} else {
+			s.Maximum = &n
+		}
+	}
+}
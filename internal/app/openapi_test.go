@@ -0,0 +1,82 @@
+package app
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+
+	"diary/config"
+	"diary/internal/apidoc"
+
+	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
+)
+
+// undocumented 不写入 OpenAPI 文档的路径
+var undocumented = map[string]bool{
+	"/uploads/*":         true, // 静态文件
+	"/api/docs/assets/*": true, // Swagger UI 静态资源
+}
+
+// TestOpenAPIMatchesRouter 路由和 internal/apidoc 的接口列表必须一致
+func TestOpenAPIMatchesRouter(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.Config{UploadDir: dir, UploadTmpDir: dir, ExportDir: dir}
+	handler, _ := SetupRouter(&gorm.DB{}, cfg)
+	router := handler.(chi.Routes)
+
+	routes := map[string]bool{}
+	err := chi.Walk(router, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		if len(route) > 1 {
+			route = strings.TrimSuffix(route, "/")
+		}
+		if !undocumented[route] {
+			routes[method+" "+route] = true
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	documented := map[string]bool{}
+	apidoc.Build().EachOperation(func(path, method string, _ *apidoc.Operation) {
+		documented[method+" "+path] = true
+	})
+
+	if missing := difference(routes, documented); len(missing) > 0 {
+		t.Errorf("routes missing from internal/apidoc/operations.go:\n  %s", strings.Join(missing, "\n  "))
+	}
+	if stale := difference(documented, routes); len(stale) > 0 {
+		t.Errorf("documented operations without a route:\n  %s", strings.Join(stale, "\n  "))
+	}
+}
+
+// TestGeneratedClientUpToDate pkg/client/client_gen.go 必须和文档同步
+func TestGeneratedClientUpToDate(t *testing.T) {
+	want, err := apidoc.GenerateClient(apidoc.Build(), "client")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile("../../pkg/client/client_gen.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Error("pkg/client/client_gen.go is stale; run go generate ./pkg/client")
+	}
+}
+
+func difference(a, b map[string]bool) []string {
+	var out []string
+	for k := range a {
+		if !b[k] {
+			out = append(out, k)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
@@ -1,6 +1,7 @@
 package app
 
 import (
+	"context"
 	"net/http"
 	"time"
 
@@ -15,7 +16,9 @@ import (
 	"gorm.io/gorm"
 )
 
-func SetupRouter(db *gorm.DB, cfg *config.Config) http.Handler {
+// SetupRouter 创建路由，同时返回启动后台任务的函数。后台任务与处理请求共用同一组服务实例，
+// 例如清理过期上传时需要持有与 Append 相同的锁
+func SetupRouter(db *gorm.DB, cfg *config.Config) (http.Handler, func(ctx context.Context)) {
 	r := chi.NewRouter()
 
 	// middlewares
@@ -23,31 +26,73 @@ func SetupRouter(db *gorm.DB, cfg *config.Config) http.Handler {
 	r.Use(chimw.RealIP)
 	r.Use(chimw.Logger)
 	r.Use(chimw.Recoverer)
-	r.Use(chimw.Timeout(60 * time.Second))
+	r.Use(middleware.TimeoutExcept(60*time.Second,
+		"/api/diaries/export", "/api/export/", "/api/exports/", "/api/import", "/api/uploads/"))
 	r.Use(middleware.CORSMiddleware)
 
 	// Repositories
 	userRepo := mysql.NewUserRepository(db)
 	tagRepo := mysql.NewTagRepository(db)
 	todoRepo := mysql.NewTodoRepository(db)
+	projectRepo := mysql.NewTodoProjectRepository(db)
 	imageRepo := mysql.NewImageRepository(db)
 	diaryRepo := mysql.NewDiaryRepository(db)
+	uploadRepo := mysql.NewUploadRepository(db)
+	importRepo := mysql.NewImportRecordRepository(db)
+	exportJobRepo := mysql.NewExportJobRepository(db)
+	notificationRepo := mysql.NewNotificationRepository(db)
+	notificationSettingRepo := mysql.NewNotificationSettingRepository(db)
+	calendarFeedRepo := mysql.NewCalendarFeedRepository(db)
+	shareLinkRepo := mysql.NewShareLinkRepository(db)
+	commentRepo := mysql.NewCommentRepository(db)
+	reactionRepo := mysql.NewReactionRepository(db)
+	followRepo := mysql.NewFollowRepository(db)
+	blockRepo := mysql.NewBlockRepository(db)
+	journalRepo := mysql.NewJournalRepository(db)
 
 	// Services
 	userService := service.NewUserService(userRepo, cfg)
 	tagService := service.NewTagService(tagRepo)
-	todoService := service.NewTodoService(todoRepo)
+	todoService := service.NewTodoService(todoRepo, projectRepo)
+	projectService := service.NewProjectService(projectRepo, todoRepo)
 	imageService := service.NewImageService(imageRepo, cfg)
-	diaryService := service.NewDiaryService(diaryRepo, tagRepo, imageRepo, cfg)
+	uploadService := service.NewUploadService(uploadRepo, imageService, cfg)
+	diaryService := service.NewDiaryService(diaryRepo, tagRepo, imageRepo, commentRepo, reactionRepo, journalRepo, cfg)
+	archiveService := service.NewArchiveService(diaryService, todoService, projectService, imageService, importRepo, userRepo, cfg)
+	importService := service.NewImportService(diaryService, imageService, importRepo)
+	exportService := service.NewExportService(diaryService, imageService, exportJobRepo, notificationSettingRepo, cfg)
+	notificationService := service.NewNotificationService(notificationRepo, notificationSettingRepo, service.DefaultNotifiers(notificationRepo, cfg))
+	calendarService := service.NewCalendarService(calendarFeedRepo, todoService, diaryService, importRepo)
+	shareService := service.NewShareService(shareLinkRepo, diaryRepo, diaryService)
+	profileService := service.NewProfileService(userRepo, imageRepo, followRepo, diaryService, cfg)
+	commentService := service.NewCommentService(commentRepo, reactionRepo, diaryRepo, blockRepo, shareService, profileService, cfg)
+	followService := service.NewFollowService(userRepo, followRepo, blockRepo, diaryService)
+	journalService := service.NewJournalService(journalRepo, userRepo, diaryService, cfg)
+	syndicationService := service.NewSyndicationService(profileService, diaryService, cfg)
+	imageGCService := service.NewImageGCService(imageRepo, cfg)
+	reminderService := service.NewReminderService(mysql.NewReminderJobRepository(db), todoRepo, diaryRepo,
+		notificationSettingRepo, notificationService, cfg)
 
 	// Handlers
 	userHandler := handler.NewUserHandler(userService)
 	tagHandler := handler.NewTagHandler(tagService)
 	todoHandler := handler.NewTodoHandler(todoService)
-	imageHandler := handler.NewImageHandler(imageService)
-	diaryHandler := handler.NewDiaryHandler(diaryService)
+	projectHandler := handler.NewProjectHandler(projectService)
+	imageHandler := handler.NewImageHandler(imageService, diaryService, journalService, cfg.MaxVideoMB)
+	uploadHandler := handler.NewUploadHandler(uploadService)
+	diaryHandler := handler.NewDiaryHandler(diaryService, profileService, followService, journalService)
 	statsHandler := handler.NewStatsHandler(diaryRepo, todoRepo)
-	exportHandler := handler.NewExportHandler(diaryService)
+	exportHandler := handler.NewExportHandler(exportService)
+	archiveHandler := handler.NewArchiveHandler(archiveService, importService, cfg.ImportMaxMB)
+	notificationHandler := handler.NewNotificationHandler(notificationService)
+	calendarHandler := handler.NewCalendarHandler(calendarService, cfg.PublicBaseURL, cfg.ImportMaxMB)
+	profileHandler := handler.NewProfileHandler(profileService, diaryService)
+	shareHandler := handler.NewShareHandler(shareService, imageService, cfg.PublicBaseURL)
+	commentHandler := handler.NewCommentHandler(commentService)
+	followHandler := handler.NewFollowHandler(followService, profileService, diaryService)
+	journalHandler := handler.NewJournalHandler(journalService, profileService, diaryService)
+	syndicationHandler := handler.NewSyndicationHandler(syndicationService, cfg.PublicBaseURL)
+	docsHandler := handler.NewDocsHandler(cfg.SwaggerUIDir)
 
 	// public
 	// if cfg.EnableRegistration {
@@ -55,10 +100,19 @@ func SetupRouter(db *gorm.DB, cfg *config.Config) http.Handler {
 	// }
 	r.Post("/api/login", userHandler.Login)
 	r.Get("/api/diaries/public", diaryHandler.ListPublic)
+	r.Get("/api/users/{username}", profileHandler.Get)
+	r.Get("/api/users/{username}/diaries", profileHandler.ListDiaries)
+	r.Get("/api/calendar.ics", calendarHandler.Feed)
+	r.Get("/feeds/{file}", syndicationHandler.UserFeed)
+	r.Get("/s/{token}", shareHandler.View)
+	r.Get("/api/openapi.json", docsHandler.Spec)
+	r.Get("/api/docs", docsHandler.UI)
+	r.Get("/api/docs/assets/*", docsHandler.Assets)
 
 	// static
 	fs := http.FileServer(http.Dir(cfg.UploadDir))
-	r.Handle("/uploads/*", http.StripPrefix("/uploads/", fs))
+	r.Handle("/uploads/*", middleware.UntrustedContent(http.StripPrefix("/uploads/", fs)))
+	r.Get("/media/images/{id}", imageHandler.ServeSigned)
 
 	// protected
 	r.Route("/api", func(r chi.Router) {
@@ -67,9 +121,20 @@ func SetupRouter(db *gorm.DB, cfg *config.Config) http.Handler {
 		// User
 		r.Route("/user", func(r chi.Router) {
 			r.Get("/profile", userHandler.GetProfile)
+			r.Put("/profile", profileHandler.Update)
 			r.Put("/username", userHandler.UpdateUsername)
 			r.Put("/password", userHandler.UpdatePassword)
 			r.Delete("/", userHandler.DeleteUser)
+			r.Get("/blocks", followHandler.ListBlocks)
+		})
+
+		// Follows & blocks
+		r.Get("/feed", followHandler.Feed)
+		r.Route("/users/{username}", func(r chi.Router) {
+			r.Post("/follow", followHandler.Follow)
+			r.Delete("/follow", followHandler.Unfollow)
+			r.Post("/block", followHandler.Block)
+			r.Delete("/block", followHandler.Unblock)
 		})
 
 		// Stats
@@ -78,6 +143,34 @@ func SetupRouter(db *gorm.DB, cfg *config.Config) http.Handler {
 		// Export
 		r.Get("/diaries/{id}/export", exportHandler.ExportSingle)
 		r.Post("/diaries/export", exportHandler.ExportBatch)
+		r.Route("/exports", func(r chi.Router) {
+			r.Post("/", exportHandler.CreateJob)
+			r.Get("/{id}", exportHandler.GetJob)
+			r.Get("/{id}/download", exportHandler.DownloadJob)
+		})
+
+		// Account archive
+		r.Get("/export/archive", archiveHandler.Export)
+		r.Post("/import", archiveHandler.Import)
+
+		// Notifications
+		r.Route("/notifications", func(r chi.Router) {
+			r.Get("/", notificationHandler.List)
+			r.Post("/read-all", notificationHandler.MarkAllRead)
+			r.Post("/{id}/read", notificationHandler.MarkRead)
+			r.Get("/settings", notificationHandler.GetSettings)
+			r.Put("/settings", notificationHandler.UpdateSettings)
+			r.Post("/test", notificationHandler.SendTest)
+		})
+
+		// Calendar subscription & .ics import
+		r.Route("/calendar", func(r chi.Router) {
+			r.Get("/", calendarHandler.GetFeed)
+			r.Put("/", calendarHandler.UpdateFeed)
+			r.Post("/token", calendarHandler.RotateToken)
+			r.Delete("/token", calendarHandler.Revoke)
+			r.Post("/import", calendarHandler.Import)
+		})
 
 		// Tags
 		r.Route("/tags", func(r chi.Router) {
@@ -93,14 +186,26 @@ func SetupRouter(db *gorm.DB, cfg *config.Config) http.Handler {
 			r.Post("/", todoHandler.Create)
 			r.Get("/", todoHandler.List)
 			r.Get("/stats", todoHandler.GetStats)
+			r.Put("/order", todoHandler.Reorder)
+			r.Post("/recurrence/preview", todoHandler.PreviewRecurrence)
 			r.Route("/{id}", func(r chi.Router) {
 				r.Put("/", todoHandler.Update)
 				r.Delete("/", todoHandler.Delete)
 				r.Patch("/done", todoHandler.MarkAsDone)
 				r.Patch("/undone", todoHandler.MarkAsUndone)
+				r.Post("/skip", todoHandler.Skip)
+				r.Get("/occurrences", todoHandler.Occurrences)
 			})
 		})
 
+		// Todo projects
+		r.Route("/projects", func(r chi.Router) {
+			r.Post("/", projectHandler.Create)
+			r.Get("/", projectHandler.List)
+			r.Put("/{id}", projectHandler.Update)
+			r.Delete("/{id}", projectHandler.Delete)
+		})
+
 		// Images
 		r.Route("/images", func(r chi.Router) {
 			r.Post("/upload", imageHandler.Upload)
@@ -111,6 +216,26 @@ func SetupRouter(db *gorm.DB, cfg *config.Config) http.Handler {
 			})
 		})
 
+		// Attachments（图片、音频、视频）
+		r.Route("/attachments", func(r chi.Router) {
+			r.Post("/upload", imageHandler.UploadAttachment)
+			r.Get("/", imageHandler.ListAttachments)
+			r.Route("/{id}", func(r chi.Router) {
+				r.Delete("/", imageHandler.Delete)
+				r.Post("/attach", imageHandler.AttachToDiary)
+			})
+		})
+
+		// Resumable uploads (tus)
+		r.Route("/uploads", func(r chi.Router) {
+			r.Post("/", uploadHandler.Create)
+			r.Route("/{id}", func(r chi.Router) {
+				r.Head("/", uploadHandler.Head)
+				r.Patch("/", uploadHandler.Patch)
+				r.Delete("/", uploadHandler.Delete)
+			})
+		})
+
 		// Diaries
 		r.Route("/diaries", func(r chi.Router) {
 			r.Post("/", diaryHandler.Create)
@@ -121,9 +246,50 @@ func SetupRouter(db *gorm.DB, cfg *config.Config) http.Handler {
 				r.Put("/", diaryHandler.Update)
 				r.Delete("/", diaryHandler.Delete)
 				r.Post("/pin", diaryHandler.TogglePin)
+				r.Post("/shares", shareHandler.Create)
+				r.Get("/shares", shareHandler.List)
+				r.Get("/comments", commentHandler.List)
+				r.Post("/comments", commentHandler.Create)
+				r.Put("/comment-settings", commentHandler.UpdateSettings)
+				r.Get("/reactions", commentHandler.Reactions)
+				r.Post("/reactions", commentHandler.React)
+				r.Delete("/reactions", commentHandler.Unreact)
 			})
 		})
+
+		// Journals
+		r.Route("/journals", func(r chi.Router) {
+			r.Post("/", journalHandler.Create)
+			r.Get("/", journalHandler.List)
+			r.Route("/{id}", func(r chi.Router) {
+				r.Get("/", journalHandler.Get)
+				r.Put("/", journalHandler.Update)
+				r.Delete("/", journalHandler.Delete)
+				r.Get("/members", journalHandler.ListMembers)
+				r.Post("/members", journalHandler.AddMember)
+				r.Put("/members/{username}", journalHandler.UpdateMember)
+				r.Delete("/members/{username}", journalHandler.RemoveMember)
+				r.Get("/diaries", journalHandler.ListDiaries)
+				r.Get("/diaries/search", journalHandler.SearchDiaries)
+			})
+		})
+
+		// Comments
+		r.Route("/comments/{id}", func(r chi.Router) {
+			r.Put("/", commentHandler.Update)
+			r.Delete("/", commentHandler.Delete)
+		})
+
+		// Share links
+		r.Delete("/shares/{id}", shareHandler.Revoke)
 	})
 
-	return r
+	// 后台任务：清理孤立图片、过期的上传会话和导出文件，发送提醒
+	start := func(ctx context.Context) {
+		imageGCService.Start(ctx)
+		uploadService.Start(ctx)
+		exportService.Start(ctx)
+		reminderService.Start(ctx)
+	}
+	return r, start
 }
@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+// 待办在日历订阅中的组件类型，很多日历应用不显示 VTODO，默认使用 VEVENT
+const (
+	CalendarTodoAsEvent = "VEVENT"
+	CalendarTodoAsTodo  = "VTODO"
+)
+
+// CalendarFeed 日历订阅设置
+type CalendarFeed struct {
+	UserID         uint
+	TokenHash      string
+	IncludeDiaries bool
+	TodoComponent  string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
@@ -0,0 +1,38 @@
+package domain
+
+import "time"
+
+// Comment 日记评论。回复的 RootID 为所在楼层的顶层评论，便于一次取出整个楼层
+type Comment struct {
+	ID         uint
+	DiaryID    uint
+	UserID     uint
+	ParentID   *uint
+	RootID     *uint
+	Content    string // Markdown
+	EditedAt   *time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	IsDeleted  bool
+	DeleteTime time.Time
+	// 不存储：
+	ContentHTML string
+	Author      *User
+	Replies     []Comment
+}
+
+// Reaction 用户对日记的表情回应，同一用户对同一日记的同一表情只记一次
+type Reaction struct {
+	ID        uint
+	DiaryID   uint
+	UserID    uint
+	Emoji     string
+	CreatedAt time.Time
+}
+
+// ReactionCount 某个表情的回应数，Reacted 表示当前用户是否回应过
+type ReactionCount struct {
+	Emoji   string
+	Count   int64
+	Reacted bool
+}
@@ -0,0 +1,9 @@
+package domain
+
+import "time"
+
+// TimeCursor 按 (created_at, id) 倒序遍历列表时的游标，用于附件和标签
+type TimeCursor struct {
+	Time time.Time
+	ID   uint
+}
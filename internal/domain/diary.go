@@ -25,6 +25,26 @@ type Diary struct {
 	Tags        []Tag
 	PlainContent string
 	ContentHTML  string
+	CommentsDisabled bool
+	JournalID        *uint // 所属的共享日记本，为空表示个人日记
+	// 列表和详情中填充的评论数和表情回应数，不存储
+	CommentCount int64
+	Reactions    []ReactionCount
+}
+
+// PublicDiaryFilter 公开日记的筛选条件，零值表示不过滤
+type PublicDiaryFilter struct {
+	UserID   uint
+	Tag      string     // 标签名
+	DateFrom *time.Time // 包含
+	DateTo   *time.Time // 不包含
+}
+
+// DiaryCursor 按 (is_pinned, date, id) 倒序遍历日记时的游标，不区分置顶的列表忽略 Pinned
+type DiaryCursor struct {
+	Pinned bool
+	Date   time.Time
+	ID     uint
 }
 
 type MonthlyTrendItem struct {
@@ -0,0 +1,27 @@
+package domain
+
+import "time"
+
+// 导出任务状态
+const (
+	ExportJobPending = "pending"
+	ExportJobRunning = "running"
+	ExportJobDone    = "done"
+	ExportJobFailed  = "failed"
+)
+
+type ExportJob struct {
+	ID         string
+	UserID     uint
+	Status     string
+	Format     string
+	Request    string
+	FilePath   string
+	FileName   string
+	Size       int64
+	Error      string
+	ExpiresAt  time.Time
+	FinishedAt *time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
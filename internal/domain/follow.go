@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// Follow 关注关系，FollowerID 关注了 FolloweeID
+type Follow struct {
+	ID         uint
+	FollowerID uint
+	FolloweeID uint
+	CreatedAt  time.Time
+}
+
+// Block 屏蔽关系，被屏蔽的用户看不到屏蔽者的日记，也不能评论、回应或关注
+type Block struct {
+	ID        uint
+	BlockerID uint
+	BlockedID uint
+	CreatedAt time.Time
+	Blocked   *User // 不存储
+}
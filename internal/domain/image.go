@@ -2,12 +2,27 @@ package domain
 
 import "time"
 
+// 附件类型
+const (
+	AttachmentKindImage = "image"
+	AttachmentKindAudio = "audio"
+	AttachmentKindVideo = "video"
+)
+
+// Image 日记附件（图片、音频、视频）
 type Image struct {
-	ID        uint
-	UserID    uint
-	DiaryID   *uint
-	Path      string
-	CreatedAt time.Time
-	IsDeleted bool
-	DeleteTime time.Time
-}
\ No newline at end of file
+	ID           uint
+	UserID       uint
+	DiaryID      *uint
+	Path         string
+	Kind         string
+	MimeType     string
+	Size         int64
+	Duration     time.Duration
+	Width        int
+	Height       int
+	OriginalName string
+	CreatedAt    time.Time
+	IsDeleted    bool
+	DeleteTime   time.Time
+}
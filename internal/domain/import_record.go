@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// 导入对象类型
+const (
+	ImportKindDiary   = "diary"
+	ImportKindTodo    = "todo"
+	ImportKindImage   = "image"
+	ImportKindProject = "project"
+)
+
+type ImportRecord struct {
+	ID        uint
+	UserID    uint
+	Source    string
+	Kind      string
+	SourceID  string
+	TargetID  uint
+	CreatedAt time.Time
+}
@@ -0,0 +1,39 @@
+package domain
+
+import "time"
+
+// 日记本成员角色
+const (
+	JournalRoleOwner  = "owner"  // 管理成员，删除日记本和其中任何日记
+	JournalRoleEditor = "editor" // 写日记，修改日记本内的日记
+	JournalRoleViewer = "viewer" // 只读
+)
+
+// Journal 多人共享的日记本
+type Journal struct {
+	ID          uint
+	OwnerID     uint
+	Name        string
+	Description string
+	KeyEnc      string // 用服务端主密钥加密的数据密钥
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	IsDeleted   bool
+	DeleteTime  time.Time
+	Role        string // 当前用户的角色，不存储
+}
+
+// JournalMember 日记本成员
+type JournalMember struct {
+	ID        uint
+	JournalID uint
+	UserID    uint
+	Role      string
+	CreatedAt time.Time
+	User      *User // 不存储
+}
+
+// CanWrite 该角色是否可以在日记本中写日记
+func (m *JournalMember) CanWrite() bool {
+	return m.Role == JournalRoleOwner || m.Role == JournalRoleEditor
+}
@@ -0,0 +1,68 @@
+package domain
+
+import "time"
+
+// 提醒类型
+const (
+	ReminderKindTodoDue     = "todo_due"
+	ReminderKindDailyPrompt = "daily_prompt"
+)
+
+// 提醒状态
+const (
+	ReminderPending   = "pending"
+	ReminderSent      = "sent"
+	ReminderFailed    = "failed"
+	ReminderCancelled = "cancelled" // 触发时条件已不成立，例如待办已完成
+)
+
+// ReminderJob 待发送的提醒
+type ReminderJob struct {
+	ID        uint
+	UserID    uint
+	Kind      string
+	RefID     uint
+	FireAt    time.Time
+	Status    string
+	NextTryAt time.Time
+	Attempts  int
+	LastError string
+	Delivered string // 已发送成功的渠道，逗号分隔，重试时跳过
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Notification 站内通知
+type Notification struct {
+	ID        uint
+	UserID    uint
+	Kind      string
+	Title     string
+	Body      string
+	Link      string
+	ReadAt    *time.Time
+	CreatedAt time.Time
+}
+
+// NotificationSetting 用户的提醒设置
+type NotificationSetting struct {
+	UserID        uint
+	InApp         bool
+	WebhookURL    string
+	Email         string
+	TodoReminders bool
+	DailyPrompt   bool
+	PromptTime    string
+	Timezone      string
+	UpdatedAt     time.Time
+}
+
+// DefaultNotificationSetting 未设置过的用户使用的默认值
+func DefaultNotificationSetting(userID uint) *NotificationSetting {
+	return &NotificationSetting{
+		UserID:        userID,
+		InApp:         true,
+		TodoReminders: true,
+		PromptTime:    "21:00",
+	}
+}
@@ -25,6 +25,12 @@ type UserRepository interface {
 	Delete(ctx context.Context, id uint) error
 	// List 获取用户列表（分页）
 	List(ctx context.Context, offset, limit int) ([]User, int64, error)
+	// GetByIDs 根据ID列表批量获取用户
+	GetByIDs(ctx context.Context, ids []uint) ([]User, error)
+	// UpdateProfile 更新公开主页资料
+	UpdateProfile(ctx context.Context, user *User) error
+	// EnsureArchiveID 用户还没有导出包ID时保存 candidate，返回最终保存的ID
+	EnsureArchiveID(ctx context.Context, id uint, candidate string) (string, error)
 }
 
 // DiaryRepository 日记仓储接口
@@ -39,14 +45,28 @@ type DiaryRepository interface {
 	Delete(ctx context.Context, id uint) error
 	// ListByUserID 获取用户的日记列表（分页，按日期降序）
 	ListByUserID(ctx context.Context, userID uint, offset, limit int) ([]Diary, int64, error)
-	// ListPublic 获取公开日记列表（分页）
-	ListPublic(ctx context.Context, offset, limit int) ([]Diary, int64, error)
+	// ListByUserIDAfter 按 (is_pinned, date, id) 降序获取游标之后的日记，after 为 nil 时从头开始，不统计总数
+	ListByUserIDAfter(ctx context.Context, userID uint, after *DiaryCursor, limit int) ([]Diary, error)
+	// ListPublic 按条件获取公开日记列表（分页）
+	ListPublic(ctx context.Context, filter PublicDiaryFilter, offset, limit int) ([]Diary, int64, error)
+	// ListPublicAfter 按游标获取公开日记，不统计总数
+	ListPublicAfter(ctx context.Context, filter PublicDiaryFilter, after *DiaryCursor, limit int) ([]Diary, error)
 	// SearchByUserID 搜索用户的日记（通过标题和摘要）
 	SearchByUserID(ctx context.Context, userID uint, keyword string, offset, limit int) ([]Diary, int64, error)
+	// SearchByUserIDAfter 按游标搜索用户的日记
+	SearchByUserIDAfter(ctx context.Context, userID uint, keyword string, after *DiaryCursor, limit int) ([]Diary, error)
+	// ListByJournalID 获取日记本内的日记（分页），包括所有成员写的
+	ListByJournalID(ctx context.Context, journalID uint, offset, limit int) ([]Diary, int64, error)
+	// SearchByJournalID 搜索日记本内的日记
+	SearchByJournalID(ctx context.Context, journalID uint, keyword string, offset, limit int) ([]Diary, int64, error)
 	// GetByDateRange 获取指定日期范围的日记
 	GetByDateRange(ctx context.Context, userID uint, startDate, endDate time.Time) ([]Diary, error)
 	// GetByIDs 根据ID列表批量获取日记
 	GetByIDs(ctx context.Context, userID uint, ids []uint) ([]Diary, error)
+	// ListByDateRangeAfter 按 (date, id) 升序获取游标之后的一批日记，after 为 nil 时从头开始
+	ListByDateRangeAfter(ctx context.Context, userID uint, startDate, endDate time.Time, after *DiaryCursor, limit int) ([]Diary, error)
+	// ListFeed 按 (date, id) 降序获取关注的人在游标之前的公开日记，before 为 nil 时从最新开始
+	ListFeed(ctx context.Context, followerID uint, before *DiaryCursor, limit int) ([]Diary, error)
 	// GetByTags 根据标签获取日记
 	GetByTags(ctx context.Context, userID uint, tagIDs []uint, offset, limit int) ([]Diary, int64, error)
 	// AddTags 为日记添加标签
@@ -71,6 +91,8 @@ type DiaryRepository interface {
 	UpdatePinStatus(ctx context.Context, id uint, isPinned bool) error
 	// CountPinned 统计用户置顶日记数量
 	CountPinned(ctx context.Context, userID uint) (int64, error)
+	// UpdateCommentsDisabled 开启或关闭评论
+	UpdateCommentsDisabled(ctx context.Context, id uint, disabled bool) error
 }
 
 // TodoRepository 待办事项仓储接口
@@ -97,6 +119,44 @@ type TodoRepository interface {
 	CountByUserID(ctx context.Context, userID uint) (int64, error)
 	// CountPending 统计用户未完成的待办事项数
 	CountPending(ctx context.Context, userID uint) (int64, error)
+	// List 按条件筛选和排序待办事项
+	List(ctx context.Context, userID uint, filter TodoFilter, offset, limit int) ([]Todo, int64, error)
+	// ListAfter 按游标获取待办，after 的排序方式必须和 filter 一致
+	ListAfter(ctx context.Context, userID uint, filter TodoFilter, after *TodoCursor, limit int) ([]Todo, error)
+	// ListByParentIDs 批量获取多个父任务的子任务
+	ListByParentIDs(ctx context.Context, parentIDs []uint) ([]Todo, error)
+	// SetDoneByParentID 批量修改子任务的完成状态
+	SetDoneByParentID(ctx context.Context, parentID uint, done bool) error
+	// SetProjectByParentID 把父任务下的全部子任务移到同一清单，projectID 为 nil 表示移出清单
+	SetProjectByParentID(ctx context.Context, parentID uint, projectID *uint) error
+	// DeleteByParentID 软删除父任务下的全部子任务
+	DeleteByParentID(ctx context.Context, parentID uint) error
+	// ClearProject 将清单中的待办移出清单
+	ClearProject(ctx context.Context, projectID uint) error
+	// MaxSortOrder 获取同一父任务下（nil 为顶层）最大的排序值
+	MaxSortOrder(ctx context.Context, userID uint, parentID *uint) (int, error)
+	// UpdateSortOrder 按 ids 的顺序重新设置排序值，只修改属于该用户的待办
+	UpdateSortOrder(ctx context.Context, userID uint, ids []uint) error
+	// CountByProject 按清单分组统计总数和未完成数
+	CountByProject(ctx context.Context, userID uint) ([]TodoProjectStats, error)
+	// GetByOccurrence 查找重复系列中某次计划时间的待办，包括已删除（跳过）的
+	GetByOccurrence(ctx context.Context, seriesID uint, at time.Time) (*Todo, error)
+	// ListDueBetween 按 (due_date, id) 顺序获取所有用户截止时间在 (start, end] 之间的未完成待办，after 为上一批最后一条
+	ListDueBetween(ctx context.Context, start, end time.Time, after *TimeCursor, limit int) ([]Todo, error)
+}
+
+// TodoProjectRepository 待办清单仓储接口
+type TodoProjectRepository interface {
+	// Create 创建清单
+	Create(ctx context.Context, project *TodoProject) error
+	// GetByID 根据ID获取清单
+	GetByID(ctx context.Context, id uint) (*TodoProject, error)
+	// Update 更新清单
+	Update(ctx context.Context, project *TodoProject) error
+	// Delete 软删除清单
+	Delete(ctx context.Context, id uint) error
+	// ListByUserID 获取用户的全部清单
+	ListByUserID(ctx context.Context, userID uint) ([]TodoProject, error)
 }
 
 // TagRepository 标签仓储接口
@@ -113,6 +173,8 @@ type TagRepository interface {
 	Delete(ctx context.Context, id uint) error
 	// List 获取所有标签
 	List(ctx context.Context, offset, limit int) ([]Tag, int64, error)
+	// ListAfter 按游标获取标签
+	ListAfter(ctx context.Context, after *TimeCursor, limit int) ([]Tag, error)
 	// GetByIDs 根据ID列表批量获取标签
 	GetByIDs(ctx context.Context, ids []uint) ([]Tag, error)
 	// GetOrCreate 获取或创建标签（如果不存在则创建）
@@ -129,12 +191,18 @@ type ImageRepository interface {
 	Create(ctx context.Context, image *Image) error
 	// GetByID 根据ID获取图片
 	GetByID(ctx context.Context, id uint) (*Image, error)
+	// GetByIDs 根据ID列表批量获取图片
+	GetByIDs(ctx context.Context, ids []uint) ([]Image, error)
 	// Update 更新图片信息
 	Update(ctx context.Context, image *Image) error
 	// Delete 软删除图片
 	Delete(ctx context.Context, id uint) error
 	// ListByUserID 获取用户的图片列表
 	ListByUserID(ctx context.Context, userID uint, offset, limit int) ([]Image, int64, error)
+	// ListByUserIDAndKind 按附件类型获取用户的附件列表，kind 为空时不过滤
+	ListByUserIDAndKind(ctx context.Context, userID uint, kind string, offset, limit int) ([]Image, int64, error)
+	// ListByUserIDAndKindAfter 按游标获取用户的附件列表
+	ListByUserIDAndKindAfter(ctx context.Context, userID uint, kind string, after *TimeCursor, limit int) ([]Image, error)
 	// ListByDiaryID 获取日记关联的图片列表
 	ListByDiaryID(ctx context.Context, diaryID uint) ([]Image, error)
 	// ListUnattached 获取未关联日记的图片（用户上传但未使用的图片）
@@ -147,6 +215,202 @@ type ImageRepository interface {
 	CountByUserID(ctx context.Context, userID uint) (int64, error)
 	// DeleteByPath 根据路径删除图片
 	DeleteByPath(ctx context.Context, path string) error
+	// ListExpiredUnattached 按 ID 升序获取 afterID 之后创建时间早于 before 且仍未关联日记的图片（所有用户），用作头像的除外
+	ListExpiredUnattached(ctx context.Context, before time.Time, afterID uint, limit int) ([]Image, error)
+	// ListDeletedBefore 按 ID 升序获取 afterID 之后软删除时间早于 before 的图片
+	ListDeletedBefore(ctx context.Context, before time.Time, afterID uint, limit int) ([]Image, error)
+	// ListActiveAfterID 按 ID 升序获取未删除的图片，用于全量遍历
+	ListActiveAfterID(ctx context.Context, afterID uint, limit int) ([]Image, error)
+	// ListAllPaths 获取所有图片记录的路径（包括已软删除的）
+	ListAllPaths(ctx context.Context) ([]string, error)
+	// HardDelete 物理删除图片记录
+	HardDelete(ctx context.Context, id uint) error
+}
+
+// UploadRepository 断点续传会话仓储接口
+type UploadRepository interface {
+	// Create 创建上传会话
+	Create(ctx context.Context, upload *Upload) error
+	// GetByID 根据ID获取上传会话
+	GetByID(ctx context.Context, id string) (*Upload, error)
+	// UpdateOffset 仅当当前偏移量等于 from 时更新为 to，返回是否更新成功
+	UpdateOffset(ctx context.Context, id string, from, to int64) (bool, error)
+	// MarkCompleted 记录上传完成后生成的图片
+	MarkCompleted(ctx context.Context, id string, imageID uint) error
+	// ListExpired 获取已过期的上传会话（包括已完成的）
+	ListExpired(ctx context.Context, before time.Time, limit int) ([]Upload, error)
+	// Delete 删除上传会话
+	Delete(ctx context.Context, id string) error
+}
+
+// ImportRecordRepository 导入映射仓储接口
+type ImportRecordRepository interface {
+	// Get 查找某个来源对象已导入后的记录
+	Get(ctx context.Context, userID uint, source, kind, sourceID string) (*ImportRecord, error)
+	// Create 保存导入映射
+	Create(ctx context.Context, record *ImportRecord) error
+}
+
+// ExportJobRepository 异步导出任务仓储接口
+type ExportJobRepository interface {
+	// Create 创建导出任务
+	Create(ctx context.Context, job *ExportJob) error
+	// GetByID 根据ID获取导出任务
+	GetByID(ctx context.Context, id string) (*ExportJob, error)
+	// Update 更新任务状态和结果
+	Update(ctx context.Context, job *ExportJob) error
+	// FailUnfinished 将未完成的任务标记为失败（服务重启后调用）
+	FailUnfinished(ctx context.Context, reason string) (int64, error)
+	// ListExpired 获取已过期的任务
+	ListExpired(ctx context.Context, before time.Time, limit int) ([]ExportJob, error)
+	// Delete 删除任务记录
+	Delete(ctx context.Context, id string) error
+}
+
+// ReminderJobRepository 提醒任务仓储接口
+type ReminderJobRepository interface {
+	// Create 创建提醒任务，同一对象同一时间的任务已存在时忽略
+	Create(ctx context.Context, job *ReminderJob) error
+	// ListDue 获取到期待发送的任务
+	ListDue(ctx context.Context, now time.Time, limit int) ([]ReminderJob, error)
+	// Update 更新发送结果
+	Update(ctx context.Context, job *ReminderJob) error
+	// Watermark 调度器上次规划到的时间，从未规划过时返回零值
+	Watermark(ctx context.Context) (time.Time, error)
+	// SaveWatermark 记录调度器已规划到的时间
+	SaveWatermark(ctx context.Context, at time.Time) error
+}
+
+// NotificationRepository 站内通知仓储接口
+type NotificationRepository interface {
+	// Create 创建通知
+	Create(ctx context.Context, n *Notification) error
+	// ListByUserID 获取用户的通知，unreadOnly 时只返回未读
+	ListByUserID(ctx context.Context, userID uint, unreadOnly bool, offset, limit int) ([]Notification, int64, error)
+	// CountUnread 统计未读数
+	CountUnread(ctx context.Context, userID uint) (int64, error)
+	// MarkRead 标记单条已读
+	MarkRead(ctx context.Context, userID, id uint) error
+	// MarkAllRead 全部标记已读
+	MarkAllRead(ctx context.Context, userID uint) error
+}
+
+// NotificationSettingRepository 提醒设置仓储接口
+type NotificationSettingRepository interface {
+	// Get 获取用户设置，没有记录时返回错误
+	Get(ctx context.Context, userID uint) (*NotificationSetting, error)
+	// Save 保存设置（不存在时创建）
+	Save(ctx context.Context, setting *NotificationSetting) error
+	// ListDailyPrompt 获取开启了每日写作提醒的设置
+	ListDailyPrompt(ctx context.Context, afterUserID uint, limit int) ([]NotificationSetting, error)
+}
+
+// CalendarFeedRepository 日历订阅仓储接口
+type CalendarFeedRepository interface {
+	// GetByUserID 获取用户的订阅设置
+	GetByUserID(ctx context.Context, userID uint) (*CalendarFeed, error)
+	// GetByTokenHash 根据令牌哈希查找订阅
+	GetByTokenHash(ctx context.Context, tokenHash string) (*CalendarFeed, error)
+	// Save 保存订阅（不存在时创建）
+	Save(ctx context.Context, feed *CalendarFeed) error
+	// Delete 删除订阅，旧链接随即失效
+	Delete(ctx context.Context, userID uint) error
+}
+
+// ShareLinkRepository 日记分享链接仓储接口
+type ShareLinkRepository interface {
+	// Create 创建分享链接
+	Create(ctx context.Context, link *ShareLink) error
+	// GetByID 根据ID获取分享链接
+	GetByID(ctx context.Context, id uint) (*ShareLink, error)
+	// GetByTokenHash 根据令牌哈希查找分享链接，包括已撤销的
+	GetByTokenHash(ctx context.Context, tokenHash string) (*ShareLink, error)
+	// ListByDiaryID 获取日记的全部分享链接，按创建时间降序
+	ListByDiaryID(ctx context.Context, diaryID uint) ([]ShareLink, error)
+	// Revoke 撤销分享链接，已撤销的保持原撤销时间
+	Revoke(ctx context.Context, id uint, at time.Time) error
+	// RecordView 访问次数加一并记录访问时间
+	RecordView(ctx context.Context, id uint, at time.Time) error
+}
+
+// CommentRepository 日记评论仓储接口
+type CommentRepository interface {
+	// Create 创建评论
+	Create(ctx context.Context, comment *Comment) error
+	// GetByID 根据ID获取评论，包括已删除的
+	GetByID(ctx context.Context, id uint) (*Comment, error)
+	// UpdateContent 修改评论内容并记录编辑时间
+	UpdateContent(ctx context.Context, id uint, content string, editedAt time.Time) error
+	// Delete 软删除评论，回复保留
+	Delete(ctx context.Context, id uint) error
+	// ListRoots 按时间升序获取日记的顶层评论（分页），已删除且没有未删除回复的不返回
+	ListRoots(ctx context.Context, diaryID uint, offset, limit int) ([]Comment, int64, error)
+	// ListByRootIDs 批量获取多个楼层的全部回复，包括已删除的
+	ListByRootIDs(ctx context.Context, rootIDs []uint) ([]Comment, error)
+	// CountByDiaryIDs 批量统计日记未删除的评论数
+	CountByDiaryIDs(ctx context.Context, diaryIDs []uint) (map[uint]int64, error)
+}
+
+// ReactionRepository 表情回应仓储接口
+type ReactionRepository interface {
+	// Add 添加回应，已存在时忽略
+	Add(ctx context.Context, reaction *Reaction) error
+	// Remove 取消回应
+	Remove(ctx context.Context, diaryID, userID uint, emoji string) error
+	// CountByDiaryIDs 批量统计日记每种表情的回应数，按数量降序
+	CountByDiaryIDs(ctx context.Context, diaryIDs []uint) (map[uint][]ReactionCount, error)
+	// ListEmojis 获取用户在日记上回应过的表情
+	ListEmojis(ctx context.Context, diaryID, userID uint) ([]string, error)
+}
+
+// FollowRepository 关注关系仓储接口
+type FollowRepository interface {
+	// Create 关注，已关注时不报错
+	Create(ctx context.Context, follow *Follow) error
+	// Delete 取消关注
+	Delete(ctx context.Context, followerID, followeeID uint) error
+	// Exists 是否已关注
+	Exists(ctx context.Context, followerID, followeeID uint) (bool, error)
+	// CountByUserID 统计粉丝数和关注数，不包括已注销的用户
+	CountByUserID(ctx context.Context, userID uint) (followers, following int64, err error)
+}
+
+// BlockRepository 屏蔽关系仓储接口
+type BlockRepository interface {
+	// Create 屏蔽，已屏蔽时不报错
+	Create(ctx context.Context, block *Block) error
+	// Delete 取消屏蔽
+	Delete(ctx context.Context, blockerID, blockedID uint) error
+	// Exists blockerID 是否屏蔽了 blockedID
+	Exists(ctx context.Context, blockerID, blockedID uint) (bool, error)
+	// ListByBlockerID 获取屏蔽列表，按屏蔽时间降序
+	ListByBlockerID(ctx context.Context, blockerID uint) ([]Block, error)
+}
+
+// JournalRepository 共享日记本仓储接口
+type JournalRepository interface {
+	// Create 创建日记本，同时把 OwnerID 加为所有者
+	Create(ctx context.Context, journal *Journal) error
+	// GetByID 根据ID获取日记本
+	GetByID(ctx context.Context, id uint) (*Journal, error)
+	// GetKeyEnc 获取加密后的数据密钥，包括已删除的日记本
+	GetKeyEnc(ctx context.Context, id uint) (string, error)
+	// Update 修改名称和简介
+	Update(ctx context.Context, journal *Journal) error
+	// Delete 删除日记本及其中的日记（软删除）
+	Delete(ctx context.Context, id uint) error
+	// ListByUserID 获取用户加入的日记本，Role 为该用户的角色
+	ListByUserID(ctx context.Context, userID uint) ([]Journal, error)
+	// GetMember 获取用户在日记本中的成员记录
+	GetMember(ctx context.Context, journalID, userID uint) (*JournalMember, error)
+	// ListMembers 获取日记本的成员，按加入时间升序
+	ListMembers(ctx context.Context, journalID uint) ([]JournalMember, error)
+	// AddMember 添加成员
+	AddMember(ctx context.Context, member *JournalMember) error
+	// UpdateMemberRole 修改成员角色
+	UpdateMemberRole(ctx context.Context, journalID, userID uint, role string) error
+	// RemoveMember 移除成员
+	RemoveMember(ctx context.Context, journalID, userID uint) error
 }
 
 // Repository 聚合所有仓储接口
@@ -154,6 +418,20 @@ type Repository interface {
 	User() UserRepository
 	Diary() DiaryRepository
 	Todo() TodoRepository
+	TodoProject() TodoProjectRepository
 	Tag() TagRepository
 	Image() ImageRepository
+	Upload() UploadRepository
+	ImportRecord() ImportRecordRepository
+	ExportJob() ExportJobRepository
+	ReminderJob() ReminderJobRepository
+	Notification() NotificationRepository
+	NotificationSetting() NotificationSettingRepository
+	CalendarFeed() CalendarFeedRepository
+	ShareLink() ShareLinkRepository
+	Comment() CommentRepository
+	Reaction() ReactionRepository
+	Follow() FollowRepository
+	Block() BlockRepository
+	Journal() JournalRepository
 }
@@ -0,0 +1,27 @@
+package domain
+
+import "time"
+
+// ShareLink 单篇日记的分享链接
+type ShareLink struct {
+	ID           uint
+	UserID       uint
+	DiaryID      uint
+	TokenHash    string
+	PasswordHash string
+	ExpiresAt    *time.Time
+	ViewCount    int64
+	LastViewedAt *time.Time
+	RevokedAt    *time.Time
+	CreatedAt    time.Time
+}
+
+// HasPassword 访问是否需要密码
+func (l *ShareLink) HasPassword() bool {
+	return l.PasswordHash != ""
+}
+
+// Expired 在 now 时是否已过期
+func (l *ShareLink) Expired(now time.Time) bool {
+	return l.ExpiresAt != nil && !now.Before(*l.ExpiresAt)
+}
@@ -1,16 +1,90 @@
 package domain
 
-import "time"	
+import "time"
 
 type Todo struct {
-	ID          uint
-	UserID      uint
-	Title       string
-	Description string
-	Done        bool
-	DueDate     *time.Time
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	IsDeleted   bool
-	DeleteTime time.Time
-}
\ No newline at end of file
+	ID           uint
+	UserID       uint
+	Title        string
+	Description  string
+	Done         bool
+	DueDate      *time.Time
+	Priority     int
+	ProjectID    *uint
+	ParentID     *uint
+	SortOrder    int
+	RRule        string
+	Timezone     string
+	RecurStart   *time.Time
+	SeriesID     *uint
+	OccurrenceAt *time.Time
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	IsDeleted    bool
+	DeleteTime   time.Time
+}
+
+// 待办优先级
+const (
+	TodoPriorityNone = iota
+	TodoPriorityLow
+	TodoPriorityMedium
+	TodoPriorityHigh
+)
+
+// SeriesKey 重复系列的标识，第一个待办用自己的ID
+func (t *Todo) SeriesKey() uint {
+	if t.SeriesID != nil {
+		return *t.SeriesID
+	}
+	return t.ID
+}
+
+// TodoFilter 待办列表的筛选和排序条件，nil 表示不限
+type TodoFilter struct {
+	Done      *bool
+	Priority  *int
+	ProjectID *uint // 0 表示未归入清单
+	ParentID  *uint // 0 表示只看顶层任务
+	DueFrom   *time.Time
+	DueTo     *time.Time
+	Sort      string // created_at、due_date、priority、order、title
+	Desc      bool
+}
+
+// TodoCursor 待办列表的游标，Sort、Desc 记录生成游标时的排序方式，
+// 排序字段的值按类型放在 Time（created_at、due_date，截止日期为空时为 nil）、Int（priority、order）或 Text（title）
+type TodoCursor struct {
+	Sort string
+	Desc bool
+	Time *time.Time
+	Int  int
+	Text string
+	ID   uint
+}
+
+// TodoProject 待办清单
+type TodoProject struct {
+	ID        uint
+	UserID    uint
+	Name      string
+	Color     string
+	SortOrder int
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// TodoStats 待办统计，Projects 按清单细分
+type TodoStats struct {
+	Total    int64
+	Pending  int64
+	Projects []TodoProjectStats
+}
+
+// TodoProjectStats 单个清单的统计，ProjectID 为 0 表示未归入清单
+type TodoProjectStats struct {
+	ProjectID uint
+	Name      string
+	Total     int64
+	Pending   int64
+}
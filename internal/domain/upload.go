@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+type Upload struct {
+	ID        string
+	UserID    uint
+	Filename  string
+	Size      int64
+	Offset    int64
+	Checksum  string
+	ImageID   *uint
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -12,4 +12,13 @@ type User struct {
 	Todos     []Todo    
 	IsDeleted bool
 	DeleteTime time.Time
+	// 公开主页，ProfilePublic 为 false 时不对外展示
+	DisplayName   string
+	Bio           string
+	AvatarImageID *uint
+	ProfilePublic bool
+	AvatarURL     string // 头像的签名地址，不存储
+	// 粉丝数和关注数，不存储
+	FollowerCount  int64
+	FollowingCount int64
 }
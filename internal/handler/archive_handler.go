@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"diary/internal/service"
+	"diary/pkg/importer"
+)
+
+type ArchiveHandler struct {
+	archiveService service.ArchiveService
+	importService  service.ImportService
+	maxImportMB    int
+}
+
+func NewArchiveHandler(archiveService service.ArchiveService, importService service.ImportService, maxImportMB int) *ArchiveHandler {
+	return &ArchiveHandler{
+		archiveService: archiveService,
+		importService:  importService,
+		maxImportMB:    maxImportMB,
+	}
+}
+
+// Export 导出完整账户数据（日记、待办、附件）
+func (h *ArchiveHandler) Export(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uint)
+
+	filename := fmt.Sprintf("diary_archive_%s.zip", time.Now().Format("20060102150405"))
+	setAttachment(w, filename)
+	w.Header().Set("Content-Type", "application/zip")
+
+	// 响应已经开始写入，出错时只能记录日志
+	if err := h.archiveService.Export(r.Context(), userID, w); err != nil {
+		log.Printf("export archive for user %d failed: %v", userID, err)
+	}
+}
+
+// Import 导入数据，字段：
+//   - file: zip 文件
+//   - format: archive（默认，本应用的导出包）、dayone、journey、markdown、auto（自动识别第三方格式）
+//   - dry_run: 为 true 时只返回预览，不写入数据
+func (h *ArchiveHandler) Import(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, int64(h.maxImportMB)<<20)
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		respondError(w, r, http.StatusRequestEntityTooLarge, codeBodyTooLarge, "文件过大或格式错误")
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, codeBadRequest, "无法获取上传文件")
+		return
+	}
+	defer file.Close()
+
+	userID := r.Context().Value("user_id").(uint)
+
+	dryRun := r.FormValue("dry_run") == "true"
+
+	var report *service.ImportReport
+	switch format := r.FormValue("format"); format {
+	case "", "archive":
+		report, err = h.archiveService.Import(r.Context(), userID, file, header.Size, dryRun)
+	case "auto":
+		report, err = h.importService.Import(r.Context(), userID, "", file, header.Size, dryRun)
+	case importer.FormatDayOne, importer.FormatJourney, importer.FormatMarkdown:
+		report, err = h.importService.Import(r.Context(), userID, format, file, header.Size, dryRun)
+	default:
+		respondError(w, r, http.StatusBadRequest, codeBadRequest, "不支持的导入格式")
+		return
+	}
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	if dryRun {
+		respondSuccess(w, r, http.StatusOK, "预览成功", report)
+		return
+	}
+	respondSuccess(w, r, http.StatusOK, "导入完成", report)
+}
@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"diary/internal/handler/dto"
+	"diary/internal/i18n"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// maxJSONBodyBytes JSON 请求体上限，上传和导入走 multipart，各自有单独的限制
+const maxJSONBodyBytes = 2 << 20
+
+// validate 按 DTO 上的 binding 标签校验，字段名使用 json 名称
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.SetTagName("binding")
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name := strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
+		switch name {
+		case "-":
+			return ""
+		case "":
+			return f.Name
+		}
+		return name
+	})
+	return v
+}
+
+// decodeJSON 读取并校验 JSON 请求体：限制大小、拒绝未知字段和多余内容。
+// 失败时已写入错误响应，调用方直接返回
+func decodeJSON(w http.ResponseWriter, r *http.Request, dst interface{}) bool {
+	r.Body = http.MaxBytesReader(w, r.Body, maxJSONBodyBytes)
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(dst); err != nil {
+		respondDecodeError(w, r, err)
+		return false
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		respondError(w, r, http.StatusBadRequest, codeInvalidJSON, "请求体只能包含一个 JSON 对象")
+		return false
+	}
+
+	if err := validate.Struct(dst); err != nil {
+		var verrs validator.ValidationErrors
+		if !errors.As(err, &verrs) {
+			respondServiceError(w, r, err)
+			return false
+		}
+		lang := i18n.FromRequest(r)
+		details := make([]dto.FieldError, 0, len(verrs))
+		for _, fe := range verrs {
+			details = append(details, toFieldError(lang, fe))
+		}
+		respondValidationError(w, r, details)
+		return false
+	}
+	return true
+}
+
+func respondDecodeError(w http.ResponseWriter, r *http.Request, err error) {
+	lang := i18n.FromRequest(r)
+	var maxErr *http.MaxBytesError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &maxErr):
+		resp := newErrorResponse(r, http.StatusRequestEntityTooLarge, codeBodyTooLarge, "请求体过大")
+		resp.Error = fmt.Sprintf(i18n.T(lang, "不能超过 %d 字节"), maxErr.Limit)
+		writeError(w, resp)
+	case errors.As(err, &typeErr):
+		respondValidationError(w, r, []dto.FieldError{{
+			Field:   typeErr.Field,
+			Rule:    "type",
+			Message: fmt.Sprintf(i18n.T(lang, "类型应为 %s"), i18n.T(lang, jsonTypeName(typeErr.Type))),
+		}})
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		respondValidationError(w, r, []dto.FieldError{{Field: field, Rule: "unknown", Message: i18n.T(lang, "不支持的字段")}})
+	case err == io.EOF:
+		respondError(w, r, http.StatusBadRequest, codeInvalidJSON, "请求体不能为空")
+	default:
+		resp := newErrorResponse(r, http.StatusBadRequest, codeInvalidJSON, "请求格式错误")
+		resp.Error = err.Error()
+		writeError(w, resp)
+	}
+}
+
+// toFieldError 转换为接口返回的字段错误，field 为去掉顶层结构体名的路径，例如 tags[2]
+func toFieldError(lang string, fe validator.FieldError) dto.FieldError {
+	field := fe.Namespace()
+	if i := strings.IndexByte(field, '.'); i >= 0 {
+		field = field[i+1:]
+	}
+	return dto.FieldError{
+		Field:   field,
+		Rule:    fe.Tag(),
+		Param:   fe.Param(),
+		Message: fieldMessage(lang, fe),
+	}
+}
+
+// fieldMessage 按规则生成字段错误提示，格式串经 i18n 翻译后再填参数
+func fieldMessage(lang string, fe validator.FieldError) string {
+	t := func(msg string) string { return i18n.T(lang, msg) }
+	kind := fe.Kind()
+	isLength := kind == reflect.String
+	isCount := kind == reflect.Slice || kind == reflect.Map || kind == reflect.Array
+
+	switch fe.Tag() {
+	case "required", "required_if", "required_with", "required_unless":
+		return t("不能为空")
+	case "min", "gte":
+		switch {
+		case isLength:
+			return fmt.Sprintf(t("长度不能少于 %s 个字符"), fe.Param())
+		case isCount:
+			return fmt.Sprintf(t("至少 %s 项"), fe.Param())
+		}
+		return fmt.Sprintf(t("不能小于 %s"), fe.Param())
+	case "max", "lte":
+		switch {
+		case isLength:
+			return fmt.Sprintf(t("长度不能超过 %s 个字符"), fe.Param())
+		case isCount:
+			return fmt.Sprintf(t("最多 %s 项"), fe.Param())
+		}
+		return fmt.Sprintf(t("不能大于 %s"), fe.Param())
+	case "len":
+		if isCount {
+			return fmt.Sprintf(t("必须为 %s 项"), fe.Param())
+		}
+		return fmt.Sprintf(t("长度必须为 %s"), fe.Param())
+	case "oneof":
+		return fmt.Sprintf(t("必须是以下之一：%s"), strings.ReplaceAll(fe.Param(), " ", t("、")))
+	case "email":
+		return t("邮箱格式错误")
+	case "url", "http_url":
+		return t("URL 格式错误")
+	case "datetime":
+		return fmt.Sprintf(t("时间格式应为 %s"), fe.Param())
+	case "timezone":
+		return t("无效的时区")
+	}
+	return fmt.Sprintf(t("不满足规则 %s"), fe.Tag())
+}
+
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "字符串"
+	case reflect.Bool:
+		return "布尔值"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "数字"
+	case reflect.Slice, reflect.Array:
+		return "数组"
+	case reflect.Map, reflect.Struct:
+		return "对象"
+	}
+	return t.String()
+}
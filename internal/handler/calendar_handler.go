@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"diary/internal/domain"
+	"diary/internal/handler/dto"
+	"diary/internal/service"
+)
+
+type CalendarHandler struct {
+	calendarService service.CalendarService
+	baseURL         string
+	maxImportMB     int
+}
+
+func NewCalendarHandler(calendarService service.CalendarService, baseURL string, maxImportMB int) *CalendarHandler {
+	return &CalendarHandler{calendarService: calendarService, baseURL: baseURL, maxImportMB: maxImportMB}
+}
+
+// Feed 日历订阅，日历应用无法携带登录令牌，通过链接中的 token 鉴权
+func (h *CalendarHandler) Feed(w http.ResponseWriter, r *http.Request) {
+	cal, err := h.calendarService.Feed(r.Context(), r.URL.Query().Get("token"))
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	cal.Encode(w)
+}
+
+func (h *CalendarHandler) GetFeed(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uint)
+
+	feed, err := h.calendarService.GetFeed(r.Context(), userID)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondSuccess(w, r, http.StatusOK, "获取成功", toCalendarFeedResponse(feed))
+}
+
+func (h *CalendarHandler) UpdateFeed(w http.ResponseWriter, r *http.Request) {
+	var req dto.CalendarFeedRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	userID := r.Context().Value("user_id").(uint)
+
+	feed, err := h.calendarService.UpdateFeed(r.Context(), userID, req.IncludeDiaries, req.TodoComponent)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondSuccess(w, r, http.StatusOK, "保存成功", toCalendarFeedResponse(feed))
+}
+
+// RotateToken 开启订阅或更换链接，旧链接立即失效
+func (h *CalendarHandler) RotateToken(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uint)
+
+	token, feed, err := h.calendarService.RotateToken(r.Context(), userID)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	resp := toCalendarFeedResponse(feed)
+	resp.URL = h.feedURL(r, token)
+	resp.WebcalURL = "webcal://" + strings.SplitN(resp.URL, "://", 2)[1]
+	respondSuccess(w, r, http.StatusOK, "订阅链接已生成", resp)
+}
+
+func (h *CalendarHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uint)
+
+	if err := h.calendarService.Revoke(r.Context(), userID); err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondSuccess(w, r, http.StatusOK, "已关闭日历订阅", nil)
+}
+
+// Import 导入 .ics 文件中的事件和任务为待办，表单字段：file、timezone、dry_run
+func (h *CalendarHandler) Import(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, int64(h.maxImportMB)<<20)
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		respondError(w, r, http.StatusRequestEntityTooLarge, codeBodyTooLarge, "文件过大或格式错误")
+		return
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, codeBadRequest, "无法获取上传文件")
+		return
+	}
+	defer file.Close()
+
+	userID := r.Context().Value("user_id").(uint)
+
+	dryRun := r.FormValue("dry_run") == "true"
+	report, err := h.calendarService.Import(r.Context(), userID, file, r.FormValue("timezone"), dryRun)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	if dryRun {
+		respondSuccess(w, r, http.StatusOK, "预览成功", report)
+		return
+	}
+	respondSuccess(w, r, http.StatusOK, "导入完成", report)
+}
+
+func (h *CalendarHandler) feedURL(r *http.Request, token string) string {
+	return externalBaseURL(r, h.baseURL) + "/api/calendar.ics?token=" + url.QueryEscape(token)
+}
+
+func toCalendarFeedResponse(feed *domain.CalendarFeed) dto.CalendarFeedResponse {
+	return dto.CalendarFeedResponse{
+		IncludeDiaries: feed.IncludeDiaries,
+		TodoComponent:  feed.TodoComponent,
+		CreatedAt:      feed.CreatedAt,
+		UpdatedAt:      feed.UpdatedAt,
+	}
+}
@@ -0,0 +1,240 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"diary/internal/domain"
+	"diary/internal/handler/dto"
+	"diary/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// shareTokenHeader 通过分享链接参与非公开日记的评论时携带令牌的请求头
+const shareTokenHeader = "X-Share-Token"
+
+type CommentHandler struct {
+	commentService service.CommentService
+}
+
+func NewCommentHandler(commentService service.CommentService) *CommentHandler {
+	return &CommentHandler{commentService: commentService}
+}
+
+func (h *CommentHandler) List(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, codeInvalidID, "无效的ID")
+		return
+	}
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	comments, total, err := h.commentService.List(r.Context(), viewerFrom(r), uint(id), page, pageSize)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondSuccess(w, r, http.StatusOK, "获取成功", dto.CommentListResponse{
+		Comments: toCommentResponses(comments),
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	})
+}
+
+func (h *CommentHandler) Create(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, codeInvalidID, "无效的ID")
+		return
+	}
+
+	var req dto.CreateCommentRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	comment, err := h.commentService.Create(r.Context(), viewerFrom(r), uint(id), req.ParentID, req.Content)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondSuccess(w, r, http.StatusCreated, "评论成功", toCommentResponse(comment))
+}
+
+func (h *CommentHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, codeInvalidID, "无效的ID")
+		return
+	}
+
+	var req dto.UpdateCommentRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	comment, err := h.commentService.Update(r.Context(), viewerFrom(r), uint(id), req.Content)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondSuccess(w, r, http.StatusOK, "更新成功", toCommentResponse(comment))
+}
+
+func (h *CommentHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, codeInvalidID, "无效的ID")
+		return
+	}
+
+	userID := r.Context().Value("user_id").(uint)
+
+	if err := h.commentService.Delete(r.Context(), userID, uint(id)); err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondSuccess(w, r, http.StatusOK, "删除成功", nil)
+}
+
+// UpdateSettings 作者开启或关闭日记的评论，已有评论保留
+func (h *CommentHandler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, codeInvalidID, "无效的ID")
+		return
+	}
+
+	var req dto.CommentSettingsRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	userID := r.Context().Value("user_id").(uint)
+
+	if err := h.commentService.SetCommentsDisabled(r.Context(), userID, uint(id), req.CommentsDisabled); err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondSuccess(w, r, http.StatusOK, "设置已更新", req)
+}
+
+func (h *CommentHandler) Reactions(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, codeInvalidID, "无效的ID")
+		return
+	}
+
+	counts, err := h.commentService.Reactions(r.Context(), viewerFrom(r), uint(id))
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondSuccess(w, r, http.StatusOK, "获取成功", toReactionCountResponses(counts))
+}
+
+func (h *CommentHandler) React(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, codeInvalidID, "无效的ID")
+		return
+	}
+
+	var req dto.ReactionRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	counts, err := h.commentService.React(r.Context(), viewerFrom(r), uint(id), req.Emoji)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondSuccess(w, r, http.StatusOK, "回应成功", toReactionCountResponses(counts))
+}
+
+// Unreact 取消表情回应，表情通过 ?emoji= 传入
+func (h *CommentHandler) Unreact(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, codeInvalidID, "无效的ID")
+		return
+	}
+
+	emoji := r.URL.Query().Get("emoji")
+	if emoji == "" {
+		respondError(w, r, http.StatusBadRequest, codeBadRequest, "缺少表情参数")
+		return
+	}
+
+	counts, err := h.commentService.Unreact(r.Context(), viewerFrom(r), uint(id), emoji)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondSuccess(w, r, http.StatusOK, "已取消回应", toReactionCountResponses(counts))
+}
+
+// viewerFrom 当前登录用户，以及可能携带的分享链接令牌和密码
+func viewerFrom(r *http.Request) service.Viewer {
+	return service.Viewer{
+		UserID:        r.Context().Value("user_id").(uint),
+		ShareToken:    r.Header.Get(shareTokenHeader),
+		SharePassword: r.Header.Get(sharePasswordHeader),
+	}
+}
+
+func toCommentResponses(comments []domain.Comment) []dto.CommentResponse {
+	resp := make([]dto.CommentResponse, len(comments))
+	for i := range comments {
+		resp[i] = toCommentResponse(&comments[i])
+	}
+	return resp
+}
+
+func toCommentResponse(comment *domain.Comment) dto.CommentResponse {
+	resp := dto.CommentResponse{
+		ID:          comment.ID,
+		DiaryID:     comment.DiaryID,
+		ParentID:    comment.ParentID,
+		Content:     comment.Content,
+		ContentHTML: comment.ContentHTML,
+		Edited:      comment.EditedAt != nil,
+		Deleted:     comment.IsDeleted,
+		CreatedAt:   comment.CreatedAt,
+		UpdatedAt:   comment.UpdatedAt,
+	}
+	if comment.Author != nil {
+		resp.Author = toAuthorResponse(comment.Author)
+	}
+	if len(comment.Replies) > 0 {
+		resp.Replies = toCommentResponses(comment.Replies)
+	}
+	return resp
+}
+
+func toReactionCountResponses(counts []domain.ReactionCount) []dto.ReactionCountResponse {
+	resp := make([]dto.ReactionCountResponse, len(counts))
+	for i, c := range counts {
+		resp[i] = dto.ReactionCountResponse{Emoji: c.Emoji, Count: c.Count, Reacted: c.Reacted}
+	}
+	return resp
+}
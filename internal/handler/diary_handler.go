@@ -1,9 +1,10 @@
 package handler
 
 import (
-	"encoding/json"
 	"net/http"
+	"net/url"
 	"strconv"
+	"time"
 
 	"diary/internal/domain"
 	"diary/internal/handler/dto"
@@ -13,25 +14,35 @@ import (
 )
 
 type DiaryHandler struct {
-	diaryService service.DiaryService
+	diaryService   service.DiaryService
+	profileService service.ProfileService
+	followService  service.FollowService
+	journalService service.JournalService
 }
 
-func NewDiaryHandler(diaryService service.DiaryService) *DiaryHandler {
-	return &DiaryHandler{diaryService: diaryService}
+func NewDiaryHandler(diaryService service.DiaryService, profileService service.ProfileService, followService service.FollowService, journalService service.JournalService) *DiaryHandler {
+	return &DiaryHandler{diaryService: diaryService, profileService: profileService, followService: followService, journalService: journalService}
 }
 
 func (h *DiaryHandler) Create(w http.ResponseWriter, r *http.Request) {
 	var req dto.CreateDiaryRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondError(w, http.StatusBadRequest, "请求格式错误", err.Error())
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
 	userID := r.Context().Value("user_id").(uint)
 
+	if req.JournalID != nil {
+		if err := h.journalService.CheckWritable(r.Context(), userID, *req.JournalID); err != nil {
+			respondServiceError(w, r, err)
+			return
+		}
+	}
+
 	diary, err := h.diaryService.Create(
 		r.Context(),
 		userID,
+		req.JournalID,
 		req.Title,
 		req.Content,
 		req.Weather,
@@ -45,42 +56,43 @@ func (h *DiaryHandler) Create(w http.ResponseWriter, r *http.Request) {
 		req.Music,
 	)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, "创建日记失败", err.Error())
+		respondServiceError(w, r, err)
 		return
 	}
 
-	respondSuccess(w, http.StatusCreated, "创建成功", h.toDiaryResponse(diary, true))
+	respondSuccess(w, r, http.StatusCreated, "创建成功", toDiaryResponse(diary, true))
 }
 
 func (h *DiaryHandler) Update(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
 	if err != nil {
-		respondError(w, http.StatusBadRequest, "无效的ID", err.Error())
+		respondError(w, r, http.StatusBadRequest, codeInvalidID, "无效的ID")
 		return
 	}
 
 	var req dto.UpdateDiaryRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondError(w, http.StatusBadRequest, "请求格式错误", err.Error())
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
 	userID := r.Context().Value("user_id").(uint)
 
-	// 先检查是否存在且属于当前用户
+	// 先检查是否存在以及是否有权限
 	existing, err := h.diaryService.GetByID(r.Context(), uint(id))
 	if err != nil {
-		respondError(w, http.StatusNotFound, "日记不存在", err.Error())
+		respondServiceError(w, r, err)
 		return
 	}
 
-	if existing.UserID != userID {
-		respondError(w, http.StatusForbidden, "无权修改此日记", "")
+	// 作者，或者日记本的所有者和编辑
+	if !h.journalService.CanWrite(r.Context(), userID, existing) {
+		respondError(w, r, http.StatusForbidden, codeForbidden, "无权修改此日记")
 		return
 	}
 
 	err = h.diaryService.Update(
 		r.Context(),
+		userID,
 		uint(id),
 		req.Title,
 		req.Content,
@@ -94,7 +106,7 @@ func (h *DiaryHandler) Update(w http.ResponseWriter, r *http.Request) {
 		req.Music,
 	)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, "更新日记失败", err.Error())
+		respondServiceError(w, r, err)
 		return
 	}
 
@@ -102,46 +114,47 @@ func (h *DiaryHandler) Update(w http.ResponseWriter, r *http.Request) {
 	updated, err := h.diaryService.GetByID(r.Context(), uint(id))
 	if err != nil {
 		// 虽然更新成功但获取失败，返回成功但不带数据或带部分数据
-		respondSuccess(w, http.StatusOK, "更新成功", nil)
+		respondSuccess(w, r, http.StatusOK, "更新成功", nil)
 		return
 	}
 
-	respondSuccess(w, http.StatusOK, "更新成功", h.toDiaryResponse(updated, true))
+	respondSuccess(w, r, http.StatusOK, "更新成功", toDiaryResponse(updated, true))
 }
 
 func (h *DiaryHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
 	if err != nil {
-		respondError(w, http.StatusBadRequest, "无效的ID", err.Error())
+		respondError(w, r, http.StatusBadRequest, codeInvalidID, "无效的ID")
 		return
 	}
 
 	userID := r.Context().Value("user_id").(uint)
 
-	// 先检查是否存在且属于当前用户
+	// 先检查是否存在以及是否有权限
 	existing, err := h.diaryService.GetByID(r.Context(), uint(id))
 	if err != nil {
-		respondError(w, http.StatusNotFound, "日记不存在", err.Error())
+		respondServiceError(w, r, err)
 		return
 	}
 
-	if existing.UserID != userID {
-		respondError(w, http.StatusForbidden, "无权删除此日记", "")
+	// 作者，或者日记本的所有者
+	if !h.journalService.CanDelete(r.Context(), userID, existing) {
+		respondError(w, r, http.StatusForbidden, codeForbidden, "无权删除此日记")
 		return
 	}
 
 	if err := h.diaryService.Delete(r.Context(), uint(id)); err != nil {
-		respondError(w, http.StatusInternalServerError, "删除日记失败", err.Error())
+		respondServiceError(w, r, err)
 		return
 	}
 
-	respondSuccess(w, http.StatusOK, "删除成功", nil)
+	respondSuccess(w, r, http.StatusOK, "删除成功", nil)
 }
 
 func (h *DiaryHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
 	if err != nil {
-		respondError(w, http.StatusBadRequest, "无效的ID", err.Error())
+		respondError(w, r, http.StatusBadRequest, codeInvalidID, "无效的ID")
 		return
 	}
 
@@ -149,17 +162,34 @@ func (h *DiaryHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 
 	diary, err := h.diaryService.GetByID(r.Context(), uint(id))
 	if err != nil {
-		respondError(w, http.StatusNotFound, "日记不存在", err.Error())
+		respondServiceError(w, r, err)
 		return
 	}
 
-	// 只能看自己的，或者公开的
-	if diary.UserID != userID && !diary.IsPublic {
-		respondError(w, http.StatusForbidden, "无权查看此日记", "")
+	// 只能看自己的、公开的，或者所在日记本的
+	if diary.UserID != userID && !diary.IsPublic && !h.journalService.CanRead(r.Context(), userID, diary) {
+		respondError(w, r, http.StatusForbidden, codeForbidden, "无权查看此日记")
+		return
+	}
+	// 被作者屏蔽时公开日记也不可见
+	blocked, err := h.followService.IsBlocked(r.Context(), diary.UserID, userID)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+	if blocked {
+		respondError(w, r, http.StatusForbidden, codeForbidden, "无权查看此日记")
 		return
 	}
 
-	respondSuccess(w, http.StatusOK, "获取成功", h.toDiaryResponse(diary, true))
+	if wantsHTML(r) {
+		if err := h.diaryService.RenderHTML(r.Context(), diary); err != nil {
+			respondServiceError(w, r, err)
+			return
+		}
+	}
+
+	respondSuccess(w, r, http.StatusOK, "获取成功", toDiaryResponse(diary, true))
 }
 
 func (h *DiaryHandler) List(w http.ResponseWriter, r *http.Request) {
@@ -179,36 +209,41 @@ func (h *DiaryHandler) List(w http.ResponseWriter, r *http.Request) {
 
 	var diaries []domain.Diary
 	var total int64
+	var next string
 	var err error
 
-	if startDateStr != "" && endDateStr != "" {
+	if cursor, limit, ok := cursorQuery(r); ok {
+		page, pageSize = 0, 0
+		diaries, next, err = h.diaryService.ListByUserIDAfter(r.Context(), userID, cursor, limit)
+	} else if startDateStr != "" && endDateStr != "" {
 		diaries, total, err = h.diaryService.ListByUserID(r.Context(), userID, page, pageSize)
 	} else {
 		diaries, total, err = h.diaryService.ListByUserID(r.Context(), userID, page, pageSize)
 	}
 
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, "获取列表失败", err.Error())
+		respondServiceError(w, r, err)
 		return
 	}
 
 	var diaryResponses []dto.DiaryResponse
 	for _, d := range diaries {
-		diaryResponses = append(diaryResponses, h.toDiaryResponse(&d, true))
+		diaryResponses = append(diaryResponses, toDiaryResponse(&d, true))
 	}
 
-	respondSuccess(w, http.StatusOK, "获取成功", dto.DiaryListResponse{
-		Diaries:  diaryResponses,
-		Total:    total,
-		Page:     page,
-		PageSize: pageSize,
+	respondSuccess(w, r, http.StatusOK, "获取成功", dto.DiaryListResponse{
+		Diaries:    diaryResponses,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		NextCursor: next,
 	})
 }
 
 func (h *DiaryHandler) Search(w http.ResponseWriter, r *http.Request) {
 	keyword := r.URL.Query().Get("q")
 	if keyword == "" {
-		respondError(w, http.StatusBadRequest, "搜索关键词不能为空", "")
+		respondError(w, r, http.StatusBadRequest, codeBadRequest, "搜索关键词不能为空")
 		return
 	}
 
@@ -217,27 +252,38 @@ func (h *DiaryHandler) Search(w http.ResponseWriter, r *http.Request) {
 
 	userID := r.Context().Value("user_id").(uint)
 
-	diaries, total, err := h.diaryService.Search(r.Context(), userID, keyword, page, pageSize)
+	var diaries []domain.Diary
+	var total int64
+	var next string
+	var err error
+	if cursor, limit, ok := cursorQuery(r); ok {
+		page, pageSize = 0, 0
+		diaries, next, err = h.diaryService.SearchAfter(r.Context(), userID, keyword, cursor, limit)
+	} else {
+		diaries, total, err = h.diaryService.Search(r.Context(), userID, keyword, page, pageSize)
+	}
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, "搜索失败", err.Error())
+		respondServiceError(w, r, err)
 		return
 	}
 
 	diaryResponses := make([]dto.DiaryResponse, len(diaries))
 	for i, diary := range diaries {
-		diaryResponses[i] = h.toDiaryResponse(&diary, false)
+		diaryResponses[i] = toDiaryResponse(&diary, false)
 	}
 
 	response := dto.DiaryListResponse{
-		Diaries:  diaryResponses,
-		Total:    total,
-		Page:     page,
-		PageSize: pageSize,
+		Diaries:    diaryResponses,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		NextCursor: next,
 	}
 
-	respondSuccess(w, http.StatusOK, "搜索成功", response)
+	respondSuccess(w, r, http.StatusOK, "搜索成功", response)
 }
 
+// ListPublic 公开日记列表，可按 tag、start_date、end_date 筛选；作者开启了公开主页时返回作者信息
 func (h *DiaryHandler) ListPublic(w http.ResponseWriter, r *http.Request) {
 	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
 	if page < 1 {
@@ -248,22 +294,59 @@ func (h *DiaryHandler) ListPublic(w http.ResponseWriter, r *http.Request) {
 		pageSize = 10
 	}
 
-	diaries, total, err := h.diaryService.ListPublic(r.Context(), page, pageSize)
+	filter, err := parsePublicDiaryFilter(r.URL.Query())
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, codeBadRequest, "无效的筛选参数")
+		return
+	}
+
+	var diaries []domain.Diary
+	var total int64
+	var next string
+	if cursor, limit, ok := cursorQuery(r); ok {
+		page, pageSize = 0, 0
+		diaries, next, err = h.diaryService.ListPublicAfter(r.Context(), filter, cursor, limit)
+	} else {
+		diaries, total, err = h.diaryService.ListPublic(r.Context(), filter, page, pageSize)
+	}
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	// 作者一次查出，避免逐条查询
+	userIDs := make([]uint, 0, len(diaries))
+	for _, d := range diaries {
+		userIDs = append(userIDs, d.UserID)
+	}
+	authors, err := h.profileService.PublicAuthors(r.Context(), userIDs)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, "获取公开日记失败", err.Error())
+		respondServiceError(w, r, err)
 		return
 	}
 
+	renderHTML := wantsHTML(r)
 	var diaryResponses []dto.DiaryResponse
 	for _, d := range diaries {
-		diaryResponses = append(diaryResponses, h.toDiaryResponse(&d, false))
+		if renderHTML {
+			if err := h.diaryService.RenderHTML(r.Context(), &d); err != nil {
+				respondServiceError(w, r, err)
+				return
+			}
+		}
+		resp := toDiaryResponse(&d, false)
+		if author, ok := authors[d.UserID]; ok {
+			resp.Author = toAuthorResponse(author)
+		}
+		diaryResponses = append(diaryResponses, resp)
 	}
 
-	respondSuccess(w, http.StatusOK, "获取成功", dto.DiaryListResponse{
-		Diaries:  diaryResponses,
-		Total:    total,
-		Page:     page,
-		PageSize: pageSize,
+	respondSuccess(w, r, http.StatusOK, "获取成功", dto.DiaryListResponse{
+		Diaries:    diaryResponses,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		NextCursor: next,
 	})
 }
 
@@ -271,7 +354,7 @@ func (h *DiaryHandler) TogglePin(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		respondError(w, http.StatusBadRequest, "无效的ID", err.Error())
+		respondError(w, r, http.StatusBadRequest, codeInvalidID, "无效的ID")
 		return
 	}
 
@@ -279,7 +362,7 @@ func (h *DiaryHandler) TogglePin(w http.ResponseWriter, r *http.Request) {
 
 	newStatus, err := h.diaryService.TogglePin(r.Context(), userID, uint(id))
 	if err != nil {
-		respondError(w, http.StatusBadRequest, "操作失败", err.Error())
+		respondServiceError(w, r, err)
 		return
 	}
 
@@ -287,10 +370,10 @@ func (h *DiaryHandler) TogglePin(w http.ResponseWriter, r *http.Request) {
 	if !newStatus {
 		msg = "已取消置顶"
 	}
-	respondSuccess(w, http.StatusOK, msg, map[string]bool{"is_pinned": newStatus})
+	respondSuccess(w, r, http.StatusOK, msg, dto.PinResponse{IsPinned: newStatus})
 }
 
-func (h *DiaryHandler) toDiaryResponse(diary *domain.Diary, includeContent bool) dto.DiaryResponse {
+func toDiaryResponse(diary *domain.Diary, includeContent bool) dto.DiaryResponse {
 	resp := dto.DiaryResponse{
 		ID:         diary.ID,
 		Title:      diary.Title,
@@ -305,11 +388,17 @@ func (h *DiaryHandler) toDiaryResponse(diary *domain.Diary, includeContent bool)
 		Music:      diary.Music,
 		CreatedAt:  diary.CreatedAt,
 		UpdatedAt:  diary.UpdatedAt,
+
+		JournalID:        diary.JournalID,
+		CommentsDisabled: diary.CommentsDisabled,
+		CommentCount:     diary.CommentCount,
+		Reactions:        toReactionCountResponses(diary.Reactions),
 	}
 
 	if includeContent {
 		resp.Content = diary.PlainContent
 	}
+	resp.ContentHTML = diary.ContentHTML
 
 	if len(diary.Tags) > 0 {
 		var tags []dto.TagResponse
@@ -323,17 +412,63 @@ func (h *DiaryHandler) toDiaryResponse(diary *domain.Diary, includeContent bool)
 	}
 
 	if len(diary.Images) > 0 {
-		var images []dto.ImageResponse
+		attachments := &dto.DiaryAttachments{}
 		for _, img := range diary.Images {
-			images = append(images, dto.ImageResponse{
-				ID:        img.ID,
-				Path:      img.Path,
-				DiaryID:   img.DiaryID,
-				CreatedAt: img.CreatedAt,
-			})
+			item := toImageResponse(&img)
+			switch img.Kind {
+			case domain.AttachmentKindAudio:
+				attachments.Audio = append(attachments.Audio, item)
+			case domain.AttachmentKindVideo:
+				attachments.Video = append(attachments.Video, item)
+			default:
+				attachments.Images = append(attachments.Images, item)
+			}
 		}
-		resp.Images = images
+		resp.Images = attachments.Images
+		resp.Attachments = attachments
 	}
 
 	return resp
 }
+
+// parsePublicDiaryFilter 解析公开日记的筛选参数，日期格式为 2006-01-02，包含结束当天
+func parsePublicDiaryFilter(query url.Values) (domain.PublicDiaryFilter, error) {
+	filter := domain.PublicDiaryFilter{Tag: query.Get("tag")}
+	if v := query.Get("start_date"); v != "" {
+		t, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			return filter, err
+		}
+		filter.DateFrom = &t
+	}
+	if v := query.Get("end_date"); v != "" {
+		t, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			return filter, err
+		}
+		t = t.AddDate(0, 0, 1)
+		filter.DateTo = &t
+	}
+	return filter, nil
+}
+
+func toAuthorResponse(user *domain.User) *dto.AuthorResponse {
+	return &dto.AuthorResponse{
+		Username:    user.Username,
+		DisplayName: user.DisplayName,
+		AvatarURL:   user.AvatarURL,
+	}
+}
+
+// wantsHTML 客户端是否通过 ?render=html 请求服务端渲染
+func wantsHTML(r *http.Request) bool {
+	return r.URL.Query().Get("render") == "html"
+}
+
+// cursorQuery 请求带 cursor 或 limit 时按游标分页，否则按 page/page_size 分页（兼容旧客户端）
+func cursorQuery(r *http.Request) (cursor string, limit int, ok bool) {
+	query := r.URL.Query()
+	cursor = query.Get("cursor")
+	limit, _ = strconv.Atoi(query.Get("limit"))
+	return cursor, limit, cursor != "" || query.Has("limit")
+}
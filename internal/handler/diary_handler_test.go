@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"diary/internal/domain"
+	"diary/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type stubDiaryService struct {
+	service.DiaryService
+	diaries map[uint]*domain.Diary
+}
+
+func (s *stubDiaryService) GetByID(ctx context.Context, id uint) (*domain.Diary, error) {
+	d, ok := s.diaries[id]
+	if !ok {
+		return nil, service.ErrDiaryNotFound
+	}
+	copied := *d
+	return &copied, nil
+}
+
+// stubFollowService 键为 [屏蔽者, 被屏蔽者]
+type stubFollowService struct {
+	service.FollowService
+	blocked map[[2]uint]bool
+}
+
+func (s *stubFollowService) IsBlocked(ctx context.Context, ownerID, viewerID uint) (bool, error) {
+	return s.blocked[[2]uint{ownerID, viewerID}], nil
+}
+
+// stubJournalService members 为能查看日记本日记的用户
+type stubJournalService struct {
+	service.JournalService
+	members map[uint]bool
+}
+
+func (s *stubJournalService) CanRead(ctx context.Context, userID uint, diary *domain.Diary) bool {
+	return diary.JournalID != nil && s.members[userID]
+}
+
+func TestDiaryGetByIDBlocked(t *testing.T) {
+	journalID := uint(1)
+	diaries := &stubDiaryService{diaries: map[uint]*domain.Diary{
+		1: {ID: 1, UserID: 1},
+		2: {ID: 2, UserID: 1, IsPublic: true},
+		3: {ID: 3, UserID: 1, JournalID: &journalID},
+	}}
+	// 作者 1 屏蔽了用户 3 和日记本成员 4
+	follows := &stubFollowService{blocked: map[[2]uint]bool{{1, 3}: true, {1, 4}: true}}
+	journals := &stubJournalService{members: map[uint]bool{4: true, 5: true}}
+	h := NewDiaryHandler(diaries, nil, follows, journals)
+
+	tests := []struct {
+		name    string
+		userID  uint
+		diaryID string
+		want    int
+	}{
+		{"作者查看私密日记", 1, "1", http.StatusOK},
+		{"其他用户查看私密日记", 2, "1", http.StatusForbidden},
+		{"查看公开日记", 2, "2", http.StatusOK},
+		{"被屏蔽的用户查看公开日记", 3, "2", http.StatusForbidden},
+		{"日记本成员", 5, "3", http.StatusOK},
+		{"被屏蔽的日记本成员", 4, "3", http.StatusForbidden},
+		{"日记不存在", 2, "99", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.diaryID)
+			ctx := context.WithValue(context.Background(), chi.RouteCtxKey, rctx)
+			ctx = context.WithValue(ctx, "user_id", tt.userID)
+			req := httptest.NewRequest(http.MethodGet, "/api/diaries/"+tt.diaryID, nil).WithContext(ctx)
+			rec := httptest.NewRecorder()
+
+			h.GetByID(rec, req)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.want, rec.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"diary/internal/apidoc"
+)
+
+// swaggerUICDN 固定到具体版本，避免 CDN 上的浮动版本被替换后直接在文档页执行
+const swaggerUICDN = "https://unpkg.com/swagger-ui-dist@5.17.14"
+
+// DocsHandler 提供 OpenAPI 文档和 Swagger UI 页面
+type DocsHandler struct {
+	assetsDir string
+}
+
+// NewDocsHandler assetsDir 为 swagger-ui-dist 的本地目录，为空时从 CDN 加载
+func NewDocsHandler(assetsDir string) *DocsHandler {
+	return &DocsHandler{assetsDir: assetsDir}
+}
+
+// Spec 返回 OpenAPI 3 文档
+func (h *DocsHandler) Spec(w http.ResponseWriter, r *http.Request) {
+	spec, err := apidoc.JSON()
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Write(spec)
+}
+
+// UI Swagger UI 页面，配置了本地目录时从 /api/docs/assets 加载静态资源，否则从 CDN 的固定版本加载
+func (h *DocsHandler) UI(w http.ResponseWriter, r *http.Request) {
+	base := swaggerUICDN
+	if h.assetsDir != "" {
+		base = "/api/docs/assets"
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(strings.ReplaceAll(swaggerUIPage, "{{base}}", base)))
+}
+
+// Assets 本地的 Swagger UI 静态资源，未配置目录时返回 404
+func (h *DocsHandler) Assets(w http.ResponseWriter, r *http.Request) {
+	if h.assetsDir == "" {
+		http.NotFound(w, r)
+		return
+	}
+	http.StripPrefix("/api/docs/assets", http.FileServer(http.Dir(h.assetsDir))).ServeHTTP(w, r)
+}
+
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="zh">
+<head>
+<meta charset="utf-8">
+<title>Diary API</title>
+<link rel="stylesheet" href="{{base}}/swagger-ui.css" crossorigin="anonymous" referrerpolicy="no-referrer">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="{{base}}/swagger-ui-bundle.js" crossorigin="anonymous" referrerpolicy="no-referrer"></script>
+<script>
+window.ui = SwaggerUIBundle({
+  url: "/api/openapi.json",
+  dom_id: "#swagger-ui",
+  persistAuthorization: true
+});
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,18 @@
+package dto
+
+import "time"
+
+type CalendarFeedRequest struct {
+	IncludeDiaries bool   `json:"include_diaries"`
+	TodoComponent  string `json:"todo_component" binding:"omitempty,oneof=VEVENT VTODO"` // VEVENT（默认）或 VTODO
+}
+
+// CalendarFeedResponse 订阅设置，URL 只在创建或更换令牌时返回
+type CalendarFeedResponse struct {
+	IncludeDiaries bool      `json:"include_diaries"`
+	TodoComponent  string    `json:"todo_component"`
+	URL            string    `json:"url,omitempty"`
+	WebcalURL      string    `json:"webcal_url,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
@@ -0,0 +1,52 @@
+package dto
+
+import "time"
+
+// CreateCommentRequest 发表评论，ParentID 不为空时回复该评论
+type CreateCommentRequest struct {
+	Content  string `json:"content" binding:"required,max=5000"` // Markdown
+	ParentID *uint  `json:"parent_id"`
+}
+
+type UpdateCommentRequest struct {
+	Content string `json:"content" binding:"required,max=5000"`
+}
+
+// CommentResponse 评论，已删除但仍有回复的评论保留位置，内容为空
+type CommentResponse struct {
+	ID          uint              `json:"id"`
+	DiaryID     uint              `json:"diary_id"`
+	ParentID    *uint             `json:"parent_id,omitempty"`
+	Author      *AuthorResponse   `json:"author,omitempty"` // 已删除的评论不返回
+	Content     string            `json:"content"`
+	ContentHTML string            `json:"content_html"`
+	Edited      bool              `json:"edited"`
+	Deleted     bool              `json:"deleted"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+	Replies     []CommentResponse `json:"replies,omitempty"`
+}
+
+// CommentListResponse 按顶层评论分页，每条顶层评论带上全部回复
+type CommentListResponse struct {
+	Comments []CommentResponse `json:"comments"`
+	Total    int64             `json:"total"`
+	Page     int               `json:"page"`
+	PageSize int               `json:"page_size"`
+}
+
+// CommentSettingsRequest 日记的评论设置
+type CommentSettingsRequest struct {
+	CommentsDisabled bool `json:"comments_disabled"`
+}
+
+type ReactionRequest struct {
+	Emoji string `json:"emoji" binding:"required,max=32"`
+}
+
+// ReactionCountResponse 某个表情的回应数
+type ReactionCountResponse struct {
+	Emoji   string `json:"emoji"`
+	Count   int64  `json:"count"`
+	Reacted bool   `json:"reacted,omitempty"` // 当前用户是否回应过，仅回应列表返回
+}
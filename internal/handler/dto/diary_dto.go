@@ -5,52 +5,69 @@ import "time"
 type CreateDiaryRequest struct {
 	Title      string                 `json:"title" binding:"required,max=255"`
 	Content    string                 `json:"content"`
-	Weather    string                 `json:"weather"`
-	Mood       string                 `json:"mood"`
-	Location   string                 `json:"location"`
+	Weather    string                 `json:"weather" binding:"max=255"`
+	Mood       string                 `json:"mood" binding:"max=255"`
+	Location   string                 `json:"location" binding:"max=255"`
 	Date       time.Time              `json:"date" binding:"required"`
 	IsPublic   bool                   `json:"is_public"`
-	Tags       []string               `json:"tags"`
-	ImageIDs   []uint                 `json:"image_ids"`
+	Tags       []string               `json:"tags" binding:"max=50,dive,required,max=50"`
+	ImageIDs   []uint                 `json:"image_ids" binding:"max=200,dive,required"`
 	Properties map[string]interface{} `json:"properties"`
 	Music      string                 `json:"music"`
+	JournalID  *uint                  `json:"journal_id"` // 写入共享日记本，需要是所有者或编辑
 }
 
 type UpdateDiaryRequest struct {
 	Title      string                 `json:"title" binding:"required,max=255"`
 	Content    string                 `json:"content"`
-	Weather    string                 `json:"weather"`
-	Mood       string                 `json:"mood"`
-	Location   string                 `json:"location"`
+	Weather    string                 `json:"weather" binding:"max=255"`
+	Mood       string                 `json:"mood" binding:"max=255"`
+	Location   string                 `json:"location" binding:"max=255"`
 	Date       time.Time              `json:"date" binding:"required"`
 	IsPublic   bool                   `json:"is_public"`
-	Tags       []string               `json:"tags"`
+	Tags       []string               `json:"tags" binding:"max=50,dive,required,max=50"`
 	Properties map[string]interface{} `json:"properties"`
 	Music      string                 `json:"music"`
 }
 
 type DiaryResponse struct {
-	ID         uint                   `json:"id"`
-	Title      string                 `json:"title"`
-	Content    string                 `json:"content,omitempty"` // 仅详情返回
-	Summary    string                 `json:"summary"`           // 列表返回
-	Weather    string                 `json:"weather"`
-	Mood       string                 `json:"mood"`
-	Location   string                 `json:"location"`
-	Date       time.Time              `json:"date"`
-	IsPublic   bool                   `json:"is_public"`
-	IsPinned   bool                   `json:"is_pinned"`
-	Properties map[string]interface{} `json:"properties,omitempty"`
-	Music      string                 `json:"music,omitempty"`
-	CreatedAt  time.Time              `json:"created_at"`
-	UpdatedAt  time.Time              `json:"updated_at"`
-	Tags       []TagResponse          `json:"tags,omitempty"`
-	Images     []ImageResponse        `json:"images,omitempty"`
+	ID          uint                   `json:"id"`
+	Title       string                 `json:"title"`
+	Content     string                 `json:"content,omitempty"`      // 仅详情返回
+	ContentHTML string                 `json:"content_html,omitempty"` // ?render=html 时返回
+	Summary     string                 `json:"summary"`                // 列表返回
+	Weather     string                 `json:"weather"`
+	Mood        string                 `json:"mood"`
+	Location    string                 `json:"location"`
+	Date        time.Time              `json:"date"`
+	IsPublic    bool                   `json:"is_public"`
+	IsPinned    bool                   `json:"is_pinned"`
+	Properties  map[string]interface{} `json:"properties,omitempty"`
+	Music       string                 `json:"music,omitempty"`
+	CreatedAt   time.Time              `json:"created_at"`
+	UpdatedAt   time.Time              `json:"updated_at"`
+	Tags        []TagResponse          `json:"tags,omitempty"`
+	Images      []ImageResponse        `json:"images,omitempty"` // 仅图片，兼容旧客户端
+	Attachments *DiaryAttachments      `json:"attachments,omitempty"`
+	Author      *AuthorResponse        `json:"author,omitempty"` // 公开列表中作者开启了公开主页时返回
+
+	JournalID        *uint                   `json:"journal_id,omitempty"` // 所属的共享日记本
+	CommentsDisabled bool                    `json:"comments_disabled"`
+	CommentCount     int64                   `json:"comment_count"`
+	Reactions        []ReactionCountResponse `json:"reactions,omitempty"` // 按数量降序
 }
 
+// DiaryListResponse 日记列表。传 cursor 或 limit 时按游标分页，不统计总数，total、page、page_size 为 0；
+// next_cursor 为空表示没有更多。其他列表的分页方式相同
 type DiaryListResponse struct {
-	Diaries  []DiaryResponse `json:"diaries"`
-	Total    int64           `json:"total"`
-	Page     int             `json:"page"`
-	PageSize int             `json:"page_size"`
+	Diaries    []DiaryResponse `json:"diaries"`
+	Total      int64           `json:"total"`
+	Page       int             `json:"page"`
+	PageSize   int             `json:"page_size"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+}
+
+// PinResponse 置顶状态
+type PinResponse struct {
+	IsPinned bool `json:"is_pinned"`
 }
@@ -0,0 +1,26 @@
+package dto
+
+import "time"
+
+// ExportRequest 批量导出请求
+type ExportRequest struct {
+	Type      string `json:"type" binding:"required,oneof=all selected date_range"` // "all", "selected", "date_range"
+	IDs       []uint `json:"ids" binding:"required_if=Type selected,max=10000"`     // 当 type="selected" 时使用
+	StartDate string `json:"start_date" binding:"omitempty,datetime=2006-01-02"`    // 当 type="date_range" 或 "all" 时使用
+	EndDate   string `json:"end_date" binding:"omitempty,datetime=2006-01-02"`      // 当 type="date_range" 或 "all" 时使用
+	Format    string `json:"format" binding:"omitempty,oneof=md txt csv pdf html"`  // "md", "txt", "csv", "pdf", "html"
+}
+
+// ExportJobResponse 异步导出任务响应
+type ExportJobResponse struct {
+	ID          string     `json:"id"`
+	Status      string     `json:"status"` // pending/running/done/failed
+	Format      string     `json:"format"`
+	FileName    string     `json:"file_name,omitempty"`
+	Size        int64      `json:"size,omitempty"`
+	Error       string     `json:"error,omitempty"`
+	DownloadURL string     `json:"download_url,omitempty"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
@@ -0,0 +1,15 @@
+package dto
+
+import "time"
+
+// FeedResponse 关注动态，next_cursor 为空表示没有更多
+type FeedResponse struct {
+	Diaries    []DiaryResponse `json:"diaries"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+}
+
+// BlockResponse 屏蔽的用户
+type BlockResponse struct {
+	Username  string    `json:"username"`
+	BlockedAt time.Time `json:"blocked_at"`
+}
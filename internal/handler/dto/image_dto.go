@@ -2,21 +2,55 @@ package dto
 
 import "time"
 
-// ImageResponse 图片响应
+// ImageResponse 图片（附件）响应
 type ImageResponse struct {
-	ID        uint      `json:"id"`
-	Path      string    `json:"path"`
-	DiaryID   *uint     `json:"diary_id,omitempty"`
-	CreatedAt time.Time `json:"created_at"`
+	ID           uint      `json:"id"`
+	Path         string    `json:"path"`
+	DiaryID      *uint     `json:"diary_id,omitempty"`
+	Kind         string    `json:"kind,omitempty"`
+	MimeType     string    `json:"mime_type,omitempty"`
+	Size         int64     `json:"size,omitempty"`
+	Duration     float64   `json:"duration,omitempty"` // 秒，仅音视频
+	Width        int       `json:"width,omitempty"`
+	Height       int       `json:"height,omitempty"`
+	OriginalName string    `json:"original_name,omitempty"`
+	URL          string    `json:"url,omitempty"` // 签名访问地址，仅分享链接返回
+	CreatedAt    time.Time `json:"created_at"`
 }
 
 type ImageListResponse struct {
-	Images   []ImageResponse `json:"images"`
-	Total    int64           `json:"total"`
-	Page     int             `json:"page"`
-	PageSize int             `json:"page_size"`
+	Images     []ImageResponse `json:"images"`
+	Total      int64           `json:"total"`
+	Page       int             `json:"page"`
+	PageSize   int             `json:"page_size"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+}
+
+type AttachmentListResponse struct {
+	Attachments []ImageResponse `json:"attachments"`
+	Total       int64           `json:"total"`
+	Page        int             `json:"page"`
+	PageSize    int             `json:"page_size"`
+	NextCursor  string          `json:"next_cursor,omitempty"`
+}
+
+// DiaryAttachments 日记附件按类型分组
+type DiaryAttachments struct {
+	Images []ImageResponse `json:"images,omitempty"`
+	Audio  []ImageResponse `json:"audio,omitempty"`
+	Video  []ImageResponse `json:"video,omitempty"`
 }
 
 type AttachImageRequest struct {
 	DiaryID uint `json:"diary_id" binding:"required"`
 }
+
+// UploadResponse 断点续传会话响应
+type UploadResponse struct {
+	ID        string    `json:"id"`
+	Filename  string    `json:"filename"`
+	Size      int64     `json:"size"`
+	Offset    int64     `json:"offset"`
+	ImageID   *uint     `json:"image_id,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
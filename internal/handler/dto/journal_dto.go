@@ -0,0 +1,40 @@
+package dto
+
+import "time"
+
+type CreateJournalRequest struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description" binding:"max=500"`
+}
+
+type UpdateJournalRequest struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description" binding:"max=500"`
+}
+
+// JournalResponse 共享日记本，role 为当前用户的角色
+type JournalResponse struct {
+	ID          uint      `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Role        string    `json:"role"` // owner、editor 或 viewer
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// AddJournalMemberRequest 按用户名添加成员
+type AddJournalMemberRequest struct {
+	Username string `json:"username" binding:"required,max=100"`
+	Role     string `json:"role" binding:"required,oneof=editor viewer"`
+}
+
+type UpdateJournalMemberRequest struct {
+	Role string `json:"role" binding:"required,oneof=editor viewer"`
+}
+
+// JournalMemberResponse 日记本成员
+type JournalMemberResponse struct {
+	Username string    `json:"username"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joined_at"`
+}
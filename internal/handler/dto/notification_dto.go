@@ -0,0 +1,43 @@
+package dto
+
+import "time"
+
+type NotificationResponse struct {
+	ID        uint       `json:"id"`
+	Kind      string     `json:"kind"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	Link      string     `json:"link,omitempty"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type NotificationListResponse struct {
+	Notifications []NotificationResponse `json:"notifications"`
+	Total         int64                  `json:"total"`
+	Unread        int64                  `json:"unread"`
+	Page          int                    `json:"page"`
+	PageSize      int                    `json:"page_size"`
+}
+
+// NotificationSettingRequest 提醒设置，webhook_url、email 为空表示不使用该渠道
+type NotificationSettingRequest struct {
+	InApp         bool   `json:"in_app"`
+	WebhookURL    string `json:"webhook_url" binding:"omitempty,url,max=512"`
+	Email         string `json:"email" binding:"omitempty,email,max=255"`
+	TodoReminders bool   `json:"todo_reminders"`
+	DailyPrompt   bool   `json:"daily_prompt"`
+	PromptTime    string `json:"prompt_time" binding:"omitempty,datetime=15:04"` // HH:MM，默认 21:00
+	Timezone      string `json:"timezone" binding:"max=64"`                      // IANA 时区，默认服务器时区
+}
+
+type NotificationSettingResponse struct {
+	InApp         bool      `json:"in_app"`
+	WebhookURL    string    `json:"webhook_url"`
+	Email         string    `json:"email"`
+	TodoReminders bool      `json:"todo_reminders"`
+	DailyPrompt   bool      `json:"daily_prompt"`
+	PromptTime    string    `json:"prompt_time"`
+	Timezone      string    `json:"timezone"`
+	UpdatedAt     time.Time `json:"updated_at,omitempty"`
+}
@@ -0,0 +1,32 @@
+package dto
+
+import "time"
+
+// UpdateProfileRequest 修改公开主页资料
+type UpdateProfileRequest struct {
+	DisplayName   string `json:"display_name" binding:"max=50"`
+	Bio           string `json:"bio" binding:"max=500"`
+	AvatarImageID *uint  `json:"avatar_image_id"` // 自己上传的图片，为空时清除头像
+	Public        bool   `json:"public"`          // 开启后主页和公开日记的作者信息对外可见
+}
+
+// ProfileResponse 公开主页
+type ProfileResponse struct {
+	Username      string    `json:"username"`
+	DisplayName   string    `json:"display_name,omitempty"`
+	Bio           string    `json:"bio,omitempty"`
+	AvatarImageID *uint     `json:"avatar_image_id,omitempty"`
+	AvatarURL     string    `json:"avatar_url,omitempty"` // 限时签名地址
+	Public        bool      `json:"public"`
+	CreatedAt     time.Time `json:"created_at"`
+
+	FollowerCount  int64 `json:"follower_count"`
+	FollowingCount int64 `json:"following_count"`
+}
+
+// AuthorResponse 公开日记的作者
+type AuthorResponse struct {
+	Username    string `json:"username"`
+	DisplayName string `json:"display_name,omitempty"`
+	AvatarURL   string `json:"avatar_url,omitempty"`
+}
@@ -0,0 +1,22 @@
+package dto
+
+import "time"
+
+type CreateShareRequest struct {
+	ExpiresAt *time.Time `json:"expires_at"`                // 为空时长期有效
+	Password  string     `json:"password" binding:"max=72"` // 为空时不需要密码
+}
+
+// ShareLinkResponse 分享链接，URL 只在创建时返回
+type ShareLinkResponse struct {
+	ID           uint       `json:"id"`
+	DiaryID      uint       `json:"diary_id"`
+	URL          string     `json:"url,omitempty"`
+	HasPassword  bool       `json:"has_password"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	Active       bool       `json:"active"` // 未过期且未撤销
+	ViewCount    int64      `json:"view_count"`
+	LastViewedAt *time.Time `json:"last_viewed_at,omitempty"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
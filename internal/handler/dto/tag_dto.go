@@ -17,8 +17,9 @@ type TagResponse struct {
 }
 
 type TagListResponse struct {
-	Tags     []TagResponse `json:"tags"`
-	Total    int64         `json:"total"`
-	Page     int           `json:"page"`
-	PageSize int           `json:"page_size"`
+	Tags       []TagResponse `json:"tags"`
+	Total      int64         `json:"total"`
+	Page       int           `json:"page"`
+	PageSize   int           `json:"page_size"`
+	NextCursor string        `json:"next_cursor,omitempty"`
 }
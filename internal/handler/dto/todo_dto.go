@@ -6,32 +6,97 @@ type CreateTodoRequest struct {
 	Title       string     `json:"title" binding:"required,min=1,max=255"`
 	Description string     `json:"description"`
 	DueDate     *time.Time `json:"due_date"`
+	Priority    int        `json:"priority" binding:"min=0,max=3"`
+	ProjectID   *uint      `json:"project_id"`
+	ParentID    *uint      `json:"parent_id"`
+	RRule       string     `json:"rrule" binding:"max=255"`   // iCalendar RRULE，例如 FREQ=WEEKLY;BYDAY=MO,WE
+	Timezone    string     `json:"timezone" binding:"max=64"` // IANA 时区，例如 Asia/Shanghai
 }
 
 type UpdateTodoRequest struct {
 	Title       string     `json:"title" binding:"required,min=1,max=255"`
 	Description string     `json:"description"`
 	DueDate     *time.Time `json:"due_date"`
+	Priority    int        `json:"priority" binding:"min=0,max=3"`
+	ProjectID   *uint      `json:"project_id"`
+	RRule       string     `json:"rrule" binding:"max=255"`
+	Timezone    string     `json:"timezone" binding:"max=64"`
+}
+
+// PreviewRecurrenceRequest 预览重复规则
+type PreviewRecurrenceRequest struct {
+	RRule    string    `json:"rrule" binding:"required,max=255"`
+	Timezone string    `json:"timezone" binding:"max=64"`
+	Start    time.Time `json:"start" binding:"required"`
+	Count    int       `json:"count" binding:"min=0,max=50"`
+}
+
+// OccurrencesResponse 重复待办的计划时间
+type OccurrencesResponse struct {
+	Occurrences []time.Time `json:"occurrences"`
+}
+
+// ReorderTodosRequest 按数组顺序设置手动排序
+type ReorderTodosRequest struct {
+	IDs []uint `json:"ids" binding:"required,max=1000,dive,required"`
 }
 
 type TodoResponse struct {
-	ID          uint       `json:"id"`
-	Title       string     `json:"title"`
-	Description string     `json:"description"`
-	Done        bool       `json:"done"`
-	DueDate     *time.Time `json:"due_date,omitempty"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
+	ID           uint           `json:"id"`
+	Title        string         `json:"title"`
+	Description  string         `json:"description"`
+	Done         bool           `json:"done"`
+	DueDate      *time.Time     `json:"due_date,omitempty"`
+	Priority     int            `json:"priority"`
+	ProjectID    *uint          `json:"project_id,omitempty"`
+	ParentID     *uint          `json:"parent_id,omitempty"`
+	SortOrder    int            `json:"sort_order"`
+	RRule        string         `json:"rrule,omitempty"`
+	Timezone     string         `json:"timezone,omitempty"`
+	SeriesID     *uint          `json:"series_id,omitempty"`
+	OccurrenceAt *time.Time     `json:"occurrence_at,omitempty"`
+	Subtasks     []TodoResponse `json:"subtasks,omitempty"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
 }
 
 type TodoListResponse struct {
-	Todos    []TodoResponse `json:"todos"`
-	Total    int64          `json:"total"`
-	Page     int            `json:"page"`
-	PageSize int            `json:"page_size"`
+	Todos      []TodoResponse `json:"todos"`
+	Total      int64          `json:"total"`
+	Page       int            `json:"page"`
+	PageSize   int            `json:"page_size"`
+	NextCursor string         `json:"next_cursor,omitempty"`
 }
 
 type TodoStatsResponse struct {
-	Total   int64 `json:"total"`
-	Pending int64 `json:"pending"`
+	Total    int64                      `json:"total"`
+	Pending  int64                      `json:"pending"`
+	Projects []TodoProjectStatsResponse `json:"projects"`
+}
+
+// TodoProjectStatsResponse 单个清单的统计，project_id 为 0 表示未归入清单
+type TodoProjectStatsResponse struct {
+	ProjectID uint   `json:"project_id"`
+	Name      string `json:"name"`
+	Total     int64  `json:"total"`
+	Pending   int64  `json:"pending"`
+}
+
+type CreateProjectRequest struct {
+	Name  string `json:"name" binding:"required,min=1,max=100"`
+	Color string `json:"color" binding:"max=16"`
+}
+
+type UpdateProjectRequest struct {
+	Name      string `json:"name" binding:"required,min=1,max=100"`
+	Color     string `json:"color" binding:"max=16"`
+	SortOrder int    `json:"sort_order"`
+}
+
+type ProjectResponse struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Color     string    `json:"color"`
+	SortOrder int       `json:"sort_order"`
+	CreatedAt time.Time `json:"created_at"`
 }
@@ -27,10 +27,14 @@ type UpdatePasswordRequest struct {
 
 // UserResponse 用户响应
 type UserResponse struct {
-	ID        uint      `json:"id"`
-	Username  string    `json:"username"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID            uint      `json:"id"`
+	Username      string    `json:"username"`
+	DisplayName   string    `json:"display_name,omitempty"`
+	Bio           string    `json:"bio,omitempty"`
+	AvatarImageID *uint     `json:"avatar_image_id,omitempty"`
+	ProfilePublic bool      `json:"profile_public"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
 }
 
 // LoginResponse 登录响应
@@ -56,8 +60,19 @@ type Response struct {
 
 // ErrorResponse 错误响应
 type ErrorResponse struct {
-	Code    int    `json:"code"`
+	Code      int          `json:"code"`
+	ErrorCode string       `json:"error_code"`           // 稳定的错误码，例如 DIARY_NOT_FOUND
+	Message   string       `json:"message"`              // 按 Accept-Language 翻译的提示
+	Error     string       `json:"error,omitempty"`      // 补充说明，不包含内部错误
+	Details   []FieldError `json:"details,omitempty"`    // 参数校验失败的字段
+	RequestID string       `json:"request_id,omitempty"` // 服务器内部错误时返回，用于排查日志
+}
+
+// FieldError 单个字段的校验错误，field 为 json 字段路径，例如 tags[2]
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`            // required、max、oneof 等
+	Param   string `json:"param,omitempty"` // 规则参数，例如 max=255 中的 255
 	Message string `json:"message"`
-	Error   string `json:"error,omitempty"`
 }
 
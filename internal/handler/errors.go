@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"diary/internal/service"
+	"diary/pkg/ical"
+	"diary/pkg/importer"
+
+	chimw "github.com/go-chi/chi/v5/middleware"
+)
+
+// 错误码，客户端应根据 error_code 判断错误类型，message 随 Accept-Language 变化，只用于展示
+const (
+	codeBadRequest       = "BAD_REQUEST"
+	codeInvalidID        = "INVALID_ID"
+	codeNotFound         = "NOT_FOUND"
+	codeInvalidJSON      = "INVALID_JSON"
+	codeValidationFailed = "VALIDATION_FAILED"
+	codeBodyTooLarge     = "BODY_TOO_LARGE"
+	codeUnauthorized     = "UNAUTHORIZED"
+	codeForbidden        = "FORBIDDEN"
+	codeUnsupportedMedia = "UNSUPPORTED_MEDIA_TYPE"
+	codeInternal         = "INTERNAL_ERROR"
+	codeDeliveryFailed   = "DELIVERY_FAILED"
+)
+
+// errInvalidCredentials 登录失败时不区分用户不存在和密码错误
+var errInvalidCredentials = errors.New("用户名或密码错误")
+
+// errorMapping 服务层错误对应的状态码和错误码，响应消息使用错误本身的文字
+type errorMapping struct {
+	err    error
+	status int
+	code   string
+}
+
+var errorMappings = []errorMapping{
+	// 用户
+	{errInvalidCredentials, http.StatusUnauthorized, "INVALID_CREDENTIALS"},
+	{service.ErrUserNotFound, http.StatusNotFound, "USER_NOT_FOUND"},
+	{service.ErrUserAlreadyExists, http.StatusConflict, "USER_ALREADY_EXISTS"},
+	{service.ErrInvalidPassword, http.StatusBadRequest, "WRONG_PASSWORD"},
+	{service.ErrPasswordTooShort, http.StatusBadRequest, "PASSWORD_TOO_SHORT"},
+	{service.ErrInvalidUsername, http.StatusBadRequest, "INVALID_USERNAME"},
+	{service.ErrUnableResgister, http.StatusForbidden, "REGISTRATION_DISABLED"},
+	{service.ErrProfileNotFound, http.StatusNotFound, "PROFILE_NOT_FOUND"},
+	{service.ErrInvalidAvatar, http.StatusBadRequest, "INVALID_AVATAR"},
+	{service.ErrFollowSelf, http.StatusBadRequest, "FOLLOW_SELF"},
+	{service.ErrBlockSelf, http.StatusBadRequest, "BLOCK_SELF"},
+	{service.ErrInvalidCursor, http.StatusBadRequest, "INVALID_CURSOR"},
+
+	// 日记、标签
+	{service.ErrDiaryNotFound, http.StatusNotFound, "DIARY_NOT_FOUND"},
+	{service.ErrDiaryForbidden, http.StatusForbidden, "DIARY_FORBIDDEN"},
+	{service.ErrTooManyPinned, http.StatusConflict, "TOO_MANY_PINNED"},
+	{service.ErrTagNotFound, http.StatusNotFound, "TAG_NOT_FOUND"},
+	{service.ErrTagAlreadyExists, http.StatusConflict, "TAG_ALREADY_EXISTS"},
+
+	// 共享日记本
+	{service.ErrJournalNotFound, http.StatusNotFound, "JOURNAL_NOT_FOUND"},
+	{service.ErrJournalForbidden, http.StatusForbidden, "JOURNAL_FORBIDDEN"},
+	{service.ErrInvalidJournalRole, http.StatusBadRequest, "INVALID_JOURNAL_ROLE"},
+	{service.ErrJournalMemberExists, http.StatusConflict, "JOURNAL_MEMBER_EXISTS"},
+	{service.ErrJournalMemberNotFound, http.StatusNotFound, "JOURNAL_MEMBER_NOT_FOUND"},
+	{service.ErrJournalOwnerLeave, http.StatusBadRequest, "JOURNAL_OWNER_LEAVE"},
+	{service.ErrJournalKeyCorrupted, http.StatusInternalServerError, "JOURNAL_KEY_CORRUPTED"},
+
+	// 分享链接
+	{service.ErrShareNotFound, http.StatusNotFound, "SHARE_NOT_FOUND"},
+	{service.ErrShareExpired, http.StatusGone, "SHARE_EXPIRED"},
+	{service.ErrShareRevoked, http.StatusGone, "SHARE_REVOKED"},
+	{service.ErrSharePasswordRequired, http.StatusUnauthorized, "SHARE_PASSWORD_REQUIRED"},
+	{service.ErrSharePasswordInvalid, http.StatusForbidden, "SHARE_PASSWORD_INVALID"},
+	{service.ErrInvalidShareConf, http.StatusBadRequest, "INVALID_SHARE_SETTING"},
+
+	// 评论、表情回应
+	{service.ErrCommentNotFound, http.StatusNotFound, "COMMENT_NOT_FOUND"},
+	{service.ErrCommentForbidden, http.StatusForbidden, "COMMENT_FORBIDDEN"},
+	{service.ErrCommentsDisabled, http.StatusForbidden, "COMMENTS_DISABLED"},
+	{service.ErrInvalidEmoji, http.StatusBadRequest, "INVALID_EMOJI"},
+
+	// 待办
+	{service.ErrTodoNotFound, http.StatusNotFound, "TODO_NOT_FOUND"},
+	{service.ErrProjectNotFound, http.StatusNotFound, "PROJECT_NOT_FOUND"},
+	{service.ErrInvalidPriority, http.StatusBadRequest, "INVALID_PRIORITY"},
+	{service.ErrInvalidTodoParent, http.StatusBadRequest, "INVALID_TODO_PARENT"},
+	{service.ErrInvalidRRule, http.StatusBadRequest, "INVALID_RRULE"},
+	{service.ErrInvalidTimezone, http.StatusBadRequest, "INVALID_TIMEZONE"},
+	{service.ErrRecurrenceNeedsDueDate, http.StatusBadRequest, "RECURRENCE_NEEDS_DUE_DATE"},
+	{service.ErrRecurringSubtask, http.StatusBadRequest, "RECURRING_SUBTASK"},
+	{service.ErrNotRecurring, http.StatusBadRequest, "NOT_RECURRING"},
+
+	// 图片和附件
+	{service.ErrImageNotFound, http.StatusNotFound, "IMAGE_NOT_FOUND"},
+	{service.ErrInvalidSignature, http.StatusForbidden, "INVALID_SIGNATURE"},
+	{service.ErrUnsupportedMedia, http.StatusUnsupportedMediaType, "UNSUPPORTED_MEDIA_TYPE"},
+	{service.ErrFileTooLarge, http.StatusRequestEntityTooLarge, "FILE_TOO_LARGE"},
+
+	// 断点续传
+	{service.ErrUploadNotFound, http.StatusNotFound, "UPLOAD_NOT_FOUND"},
+	{service.ErrUploadExpired, http.StatusGone, "UPLOAD_EXPIRED"},
+	{service.ErrUploadOffsetMismatch, http.StatusConflict, "UPLOAD_OFFSET_MISMATCH"},
+	{service.ErrUploadCompleted, http.StatusConflict, "UPLOAD_COMPLETED"},
+	{service.ErrUploadChecksumMismatch, statusChecksumMismatch, "UPLOAD_CHECKSUM_MISMATCH"},
+	{service.ErrUploadTooLarge, http.StatusRequestEntityTooLarge, "UPLOAD_TOO_LARGE"},
+	{service.ErrUnsupportedChecksum, http.StatusBadRequest, "UNSUPPORTED_CHECKSUM"},
+
+	// 导出、导入
+	{service.ErrInvalidExportRequest, http.StatusBadRequest, "INVALID_EXPORT_REQUEST"},
+	{service.ErrNothingToExport, http.StatusBadRequest, "NOTHING_TO_EXPORT"},
+	{service.ErrPDFUnavailable, http.StatusNotImplemented, "PDF_UNAVAILABLE"},
+	{service.ErrExportJobNotFound, http.StatusNotFound, "EXPORT_JOB_NOT_FOUND"},
+	{service.ErrExportNotReady, http.StatusConflict, "EXPORT_NOT_READY"},
+	{service.ErrTooManyExportJobs, http.StatusTooManyRequests, "TOO_MANY_EXPORT_JOBS"},
+	{service.ErrInvalidArchive, http.StatusBadRequest, "INVALID_ARCHIVE"},
+	{service.ErrUnsupportedArchive, http.StatusBadRequest, "UNSUPPORTED_ARCHIVE"},
+	{importer.ErrUnknownFormat, http.StatusBadRequest, "UNKNOWN_IMPORT_FORMAT"},
+	{importer.ErrNoEntries, http.StatusBadRequest, "NO_IMPORT_ENTRIES"},
+
+	// 通知、日历
+	{service.ErrNotificationNotFound, http.StatusNotFound, "NOTIFICATION_NOT_FOUND"},
+	{service.ErrInvalidNotificationConf, http.StatusBadRequest, "INVALID_NOTIFICATION_SETTING"},
+	{service.ErrNotificationFailed, http.StatusBadGateway, codeDeliveryFailed},
+	{service.ErrCalendarFeedNotFound, http.StatusNotFound, "CALENDAR_FEED_NOT_FOUND"},
+	{service.ErrInvalidCalendarConf, http.StatusBadRequest, "INVALID_CALENDAR_SETTING"},
+	{ical.ErrInvalidCalendar, http.StatusBadRequest, "INVALID_ICALENDAR"},
+}
+
+// respondServiceError 按 errorMappings 返回服务层错误。
+// 包装错误（"%w: 详情"）的详情放在 error 字段；未知错误只记录日志，返回 INTERNAL_ERROR 和请求ID
+func respondServiceError(w http.ResponseWriter, r *http.Request, err error) {
+	for _, m := range errorMappings {
+		if !errors.Is(err, m.err) {
+			continue
+		}
+		resp := newErrorResponse(r, m.status, m.code, m.err.Error())
+		if detail, ok := strings.CutPrefix(err.Error(), m.err.Error()+": "); ok {
+			resp.Error = detail
+		}
+		writeError(w, resp)
+		return
+	}
+
+	reqID := chimw.GetReqID(r.Context())
+	log.Printf("[%s] %s %s: %v", reqID, r.Method, r.URL.Path, err)
+	resp := newErrorResponse(r, http.StatusInternalServerError, codeInternal, "服务器内部错误")
+	resp.RequestID = reqID
+	writeError(w, resp)
+}
@@ -1,30 +1,24 @@
 package handler
 
 import (
-	"archive/zip"
-	"bytes"
-	"encoding/csv"
-	"fmt"
+	"io"
+	"log"
 	"net/http"
 	"strconv"
-	"strings"
-	"time"
 
 	"diary/internal/domain"
+	"diary/internal/handler/dto"
 	"diary/internal/service"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/render"
 )
 
 type ExportHandler struct {
-	diaryService service.DiaryService
+	exportService service.ExportService
 }
 
-func NewExportHandler(diaryService service.DiaryService) *ExportHandler {
-	return &ExportHandler{
-		diaryService: diaryService,
-	}
+func NewExportHandler(exportService service.ExportService) *ExportHandler {
+	return &ExportHandler{exportService: exportService}
 }
 
 // ExportSingle 导出单个日记
@@ -32,172 +26,117 @@ func (h *ExportHandler) ExportSingle(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		respondError(w, http.StatusBadRequest, "无效的ID", err.Error())
+		respondError(w, r, http.StatusBadRequest, codeInvalidID, "无效的ID")
 		return
 	}
 
-	format := r.URL.Query().Get("format")
-	if format == "" {
-		format = "txt"
-	}
+	userID := r.Context().Value("user_id").(uint)
 
-	diary, err := h.diaryService.GetByID(r.Context(), uint(id))
+	format := r.URL.Query().Get("format")
+	filename, content, err := h.exportService.Single(r.Context(), userID, uint(id), format)
 	if err != nil {
-		respondError(w, http.StatusNotFound, "日记未找到", err.Error())
+		respondServiceError(w, r, err)
 		return
 	}
 
-	filename := fmt.Sprintf("diary_%s_%d.%s", diary.Date.Format("20060102"), diary.ID, format)
-	content := h.formatDiary(diary, format)
-
-	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
-	w.Header().Set("Content-Type", "application/octet-stream")
+	setAttachment(w, filename)
+	if format == "pdf" {
+		w.Header().Set("Content-Type", "application/pdf")
+	} else {
+		w.Header().Set("Content-Type", "application/octet-stream")
+	}
 	w.Write(content)
 }
 
-// ExportBatchRequest 批量导出请求
-type ExportBatchRequest struct {
-	Type      string `json:"type"`       // "all", "selected", "date_range"
-	IDs       []uint `json:"ids"`        // 当 type="selected" 时使用
-	StartDate string `json:"start_date"` // 当 type="date_range" 或 "all" 时使用
-	EndDate   string `json:"end_date"`   // 当 type="date_range" 或 "all" 时使用
-	Format    string `json:"format"`     // "md", "txt", "csv"
-}
-
-// ExportBatch 批量导出日记
+// ExportBatch 批量导出日记，边查询边写入响应
 func (h *ExportHandler) ExportBatch(w http.ResponseWriter, r *http.Request) {
-	var req ExportBatchRequest
-	if err := render.DecodeJSON(r.Body, &req); err != nil {
-		respondError(w, http.StatusBadRequest, "无效的请求参数", err.Error())
+	var req dto.ExportRequest
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
 	userID := r.Context().Value("user_id").(uint)
-	var diaries []domain.Diary
-	var err error
-
-	switch req.Type {
-	case "selected":
-		if len(req.IDs) == 0 {
-			respondError(w, http.StatusBadRequest, "未选择任何日记", "")
-			return
-		}
-		diaries, err = h.diaryService.GetByIDs(r.Context(), userID, req.IDs)
-	case "date_range", "all":
-		var start, end time.Time
-		if req.StartDate != "" {
-			start, _ = time.Parse("2006-01-02", req.StartDate)
-		} else {
-			start = time.Date(1970, 1, 1, 0, 0, 0, 0, time.Local)
-		}
-		if req.EndDate != "" {
-			end, _ = time.Parse("2006-01-02", req.EndDate)
-			end = end.Add(24 * time.Hour) // 包含结束当天
-		} else {
-			end = time.Now().Add(24 * time.Hour)
-		}
-		diaries, err = h.diaryService.GetByDateRange(r.Context(), userID, start, end)
-	default:
-		respondError(w, http.StatusBadRequest, "未知的导出类型", "")
+
+	started := false
+	err := h.exportService.Batch(r.Context(), userID, req.Type, req.IDs, req.StartDate, req.EndDate, req.Format, func(filename, contentType string) (io.Writer, error) {
+		started = true
+		setAttachment(w, filename)
+		w.Header().Set("Content-Type", contentType)
+		return w, nil
+	})
+	if err == nil {
 		return
 	}
-
-	if err != nil {
-		respondError(w, http.StatusInternalServerError, "获取日记失败", err.Error())
+	if started {
+		// 响应已经开始写入，只能记录日志
+		log.Printf("export diaries for user %d failed: %v", userID, err)
 		return
 	}
 
-	if len(diaries) == 0 {
-		respondError(w, http.StatusBadRequest, "没有可导出的日记", "")
+	respondServiceError(w, r, err)
+}
+
+// CreateJob 创建异步导出任务，适合日记很多的账户
+func (h *ExportHandler) CreateJob(w http.ResponseWriter, r *http.Request) {
+	var req dto.ExportRequest
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
-	// 如果是 CSV 格式，返回单个 CSV 文件
-	if req.Format == "csv" {
-		filename := fmt.Sprintf("diaries_export_%s.csv", time.Now().Format("20060102150405"))
-		content := h.generateCSV(diaries)
-		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
-		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
-		w.Write(content)
+	userID := r.Context().Value("user_id").(uint)
+
+	job, err := h.exportService.CreateJob(r.Context(), userID, req.Type, req.IDs, req.StartDate, req.EndDate, req.Format)
+	if err != nil {
+		respondServiceError(w, r, err)
 		return
 	}
 
-	// 否则返回 ZIP 包
-	filename := fmt.Sprintf("diaries_export_%s.zip", time.Now().Format("20060102150405"))
-	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
-	w.Header().Set("Content-Type", "application/zip")
-
-	zipWriter := zip.NewWriter(w)
-	defer zipWriter.Close()
-
-	for _, diary := range diaries {
-		fileExt := req.Format
-		if fileExt == "" {
-			fileExt = "txt"
-		}
-		entryName := fmt.Sprintf("%s_%s.%s", diary.Date.Format("20060102"), strings.ReplaceAll(diary.Title, " ", "_"), fileExt)
-
-		f, err := zipWriter.Create(entryName)
-		if err != nil {
-			continue
-		}
-		f.Write(h.formatDiary(&diary, req.Format))
+	w.Header().Set("Location", "/api/exports/"+job.ID)
+	respondSuccess(w, r, http.StatusAccepted, "导出任务已创建", toExportJobResponse(job))
+}
+
+// GetJob 查询导出任务状态
+func (h *ExportHandler) GetJob(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uint)
+
+	job, err := h.exportService.GetJob(r.Context(), userID, chi.URLParam(r, "id"))
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
 	}
+
+	respondSuccess(w, r, http.StatusOK, "获取成功", toExportJobResponse(job))
 }
 
-func (h *ExportHandler) formatDiary(diary *domain.Diary, format string) []byte {
-	var sb strings.Builder
-
-	if format == "md" {
-		sb.WriteString(fmt.Sprintf("# %s\n\n", diary.Title))
-		sb.WriteString(fmt.Sprintf("> 日期：%s  |  天气：%s  |  心情：%s\n\n",
-			diary.Date.Format("2006-01-02 15:04"), diary.Weather, diary.Mood))
-		if diary.Location != "" {
-			sb.WriteString(fmt.Sprintf("> 地点：%s\n\n", diary.Location))
-		}
-		if diary.Music != "" {
-			sb.WriteString(fmt.Sprintf("> 音乐：%s\n\n", diary.Music))
-		}
-		sb.WriteString("---\n\n")
-		sb.WriteString(diary.PlainContent)
-	} else {
-		// TXT format
-		sb.WriteString(fmt.Sprintf("标题：%s\n", diary.Title))
-		sb.WriteString(fmt.Sprintf("日期：%s\n", diary.Date.Format("2006-01-02 15:04")))
-		sb.WriteString(fmt.Sprintf("天气：%s\n", diary.Weather))
-		sb.WriteString(fmt.Sprintf("心情：%s\n", diary.Mood))
-		if diary.Location != "" {
-			sb.WriteString(fmt.Sprintf("地点：%s\n", diary.Location))
-		}
-		sb.WriteString("\n----------------------------------------\n\n")
-		sb.WriteString(diary.PlainContent)
+// DownloadJob 下载导出文件
+func (h *ExportHandler) DownloadJob(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uint)
+
+	job, file, err := h.exportService.OpenJob(r.Context(), userID, chi.URLParam(r, "id"))
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
 	}
+	defer file.Close()
 
-	return []byte(sb.String())
+	setAttachment(w, job.FileName)
+	http.ServeContent(w, r, job.FileName, job.UpdatedAt, file)
 }
 
-func (h *ExportHandler) generateCSV(diaries []domain.Diary) []byte {
-	buf := &bytes.Buffer{}
-	// 写入 UTF-8 BOM，防止 Excel 打开乱码
-	buf.Write([]byte{0xEF, 0xBB, 0xBF})
-
-	w := csv.NewWriter(buf)
-
-	// Header
-	w.Write([]string{"ID", "日期", "标题", "内容", "天气", "心情", "地点", "音乐"})
-
-	for _, d := range diaries {
-		w.Write([]string{
-			fmt.Sprintf("%d", d.ID),
-			d.Date.Format("2006-01-02 15:04:05"),
-			d.Title,
-			d.PlainContent,
-			d.Weather,
-			d.Mood,
-			d.Location,
-			d.Music,
-		})
+func toExportJobResponse(job *domain.ExportJob) dto.ExportJobResponse {
+	resp := dto.ExportJobResponse{
+		ID:         job.ID,
+		Status:     job.Status,
+		Format:     job.Format,
+		FileName:   job.FileName,
+		Size:       job.Size,
+		Error:      job.Error,
+		ExpiresAt:  job.ExpiresAt,
+		FinishedAt: job.FinishedAt,
+		CreatedAt:  job.CreatedAt,
+	}
+	if job.Status == domain.ExportJobDone {
+		resp.DownloadURL = "/api/exports/" + job.ID + "/download"
 	}
-	w.Flush()
-	return buf.Bytes()
+	return resp
 }
@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"diary/internal/handler/dto"
+	"diary/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type FollowHandler struct {
+	followService  service.FollowService
+	profileService service.ProfileService
+	diaryService   service.DiaryService
+}
+
+func NewFollowHandler(followService service.FollowService, profileService service.ProfileService, diaryService service.DiaryService) *FollowHandler {
+	return &FollowHandler{followService: followService, profileService: profileService, diaryService: diaryService}
+}
+
+func (h *FollowHandler) Follow(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uint)
+
+	if err := h.followService.Follow(r.Context(), userID, chi.URLParam(r, "username")); err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondSuccess(w, r, http.StatusOK, "已关注", nil)
+}
+
+func (h *FollowHandler) Unfollow(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uint)
+
+	if err := h.followService.Unfollow(r.Context(), userID, chi.URLParam(r, "username")); err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondSuccess(w, r, http.StatusOK, "已取消关注", nil)
+}
+
+// Block 屏蔽用户，对方不能再查看、评论和回应自己的日记，双方的关注同时解除
+func (h *FollowHandler) Block(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uint)
+
+	if err := h.followService.Block(r.Context(), userID, chi.URLParam(r, "username")); err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondSuccess(w, r, http.StatusOK, "已屏蔽", nil)
+}
+
+func (h *FollowHandler) Unblock(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uint)
+
+	if err := h.followService.Unblock(r.Context(), userID, chi.URLParam(r, "username")); err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondSuccess(w, r, http.StatusOK, "已取消屏蔽", nil)
+}
+
+func (h *FollowHandler) ListBlocks(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uint)
+
+	blocks, err := h.followService.ListBlocks(r.Context(), userID)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	resp := make([]dto.BlockResponse, len(blocks))
+	for i, b := range blocks {
+		resp[i] = dto.BlockResponse{Username: b.Blocked.Username, BlockedAt: b.CreatedAt}
+	}
+	respondSuccess(w, r, http.StatusOK, "获取成功", resp)
+}
+
+// Feed 关注的人的公开日记，按日期降序，用 ?cursor= 传入上一页的 next_cursor 翻页
+func (h *FollowHandler) Feed(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uint)
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	diaries, next, err := h.followService.Feed(r.Context(), userID, r.URL.Query().Get("cursor"), limit)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	userIDs := make([]uint, 0, len(diaries))
+	for _, d := range diaries {
+		userIDs = append(userIDs, d.UserID)
+	}
+	authors, err := h.profileService.PublicAuthors(r.Context(), userIDs)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	renderHTML := wantsHTML(r)
+	diaryResponses := []dto.DiaryResponse{}
+	for _, d := range diaries {
+		if renderHTML {
+			if err := h.diaryService.RenderHTML(r.Context(), &d); err != nil {
+				respondServiceError(w, r, err)
+				return
+			}
+		}
+		resp := toDiaryResponse(&d, false)
+		if author, ok := authors[d.UserID]; ok {
+			resp.Author = toAuthorResponse(author)
+		}
+		diaryResponses = append(diaryResponses, resp)
+	}
+
+	respondSuccess(w, r, http.StatusOK, "获取成功", dto.FeedResponse{
+		Diaries:    diaryResponses,
+		NextCursor: next,
+	})
+}
@@ -1,8 +1,8 @@
 package handler
 
 import (
-	"encoding/json"
 	"net/http"
+	"path/filepath"
 	"strconv"
 
 	"diary/internal/domain"
@@ -13,11 +13,29 @@ import (
 )
 
 type ImageHandler struct {
-	imageService service.ImageService
+	imageService   service.ImageService
+	diaryService   service.DiaryService
+	journalService service.JournalService
+	maxUploadMB    int
 }
 
-func NewImageHandler(imageService service.ImageService) *ImageHandler {
-	return &ImageHandler{imageService: imageService}
+func NewImageHandler(imageService service.ImageService, diaryService service.DiaryService, journalService service.JournalService, maxUploadMB int) *ImageHandler {
+	return &ImageHandler{imageService: imageService, diaryService: diaryService, journalService: journalService, maxUploadMB: maxUploadMB}
+}
+
+// canAttach 检查用户能否向日记添加图片和附件，不能时写入 404/403 响应
+func (h *ImageHandler) canAttach(w http.ResponseWriter, r *http.Request, userID, diaryID uint) bool {
+	diary, err := h.diaryService.GetByID(r.Context(), diaryID)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return false
+	}
+	// 作者，或者日记本的所有者和编辑
+	if !h.journalService.CanWrite(r.Context(), userID, diary) {
+		respondError(w, r, http.StatusForbidden, codeForbidden, "无权修改此日记")
+		return false
+	}
+	return true
 }
 
 func (h *ImageHandler) Upload(w http.ResponseWriter, r *http.Request) {
@@ -26,7 +44,7 @@ func (h *ImageHandler) Upload(w http.ResponseWriter, r *http.Request) {
 
 	file, header, err := r.FormFile("image")
 	if err != nil {
-		respondError(w, http.StatusBadRequest, "无法获取上传文件", err.Error())
+		respondError(w, r, http.StatusBadRequest, codeBadRequest, "无法获取上传文件")
 		return
 	}
 	defer file.Close()
@@ -43,26 +61,32 @@ func (h *ImageHandler) Upload(w http.ResponseWriter, r *http.Request) {
 			diaryID = &uid
 		}
 	}
+	if diaryID != nil && !h.canAttach(w, r, userID, *diaryID) {
+		return
+	}
 
 	image, err := h.imageService.Upload(r.Context(), userID, file, header.Filename)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, "图片上传失败", err.Error())
+		respondServiceError(w, r, err)
 		return
 	}
 
 	// 如果提供了 diary_id，尝试关联
 	if diaryID != nil {
-		_ = h.imageService.AttachToDiary(r.Context(), image.ID, *diaryID)
+		if err := h.imageService.AttachToDiary(r.Context(), image.ID, *diaryID); err != nil {
+			respondServiceError(w, r, err)
+			return
+		}
 		image.DiaryID = diaryID
 	}
 
-	respondSuccess(w, http.StatusCreated, "上传成功", h.toImageResponse(image))
+	respondSuccess(w, r, http.StatusCreated, "上传成功", h.toImageResponse(image))
 }
 
 func (h *ImageHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
 	if err != nil {
-		respondError(w, http.StatusBadRequest, "无效的ID", err.Error())
+		respondError(w, r, http.StatusBadRequest, codeInvalidID, "无效的ID")
 		return
 	}
 
@@ -71,27 +95,27 @@ func (h *ImageHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	// 先检查是否存在且属于当前用户
 	existing, err := h.imageService.GetByID(r.Context(), uint(id))
 	if err != nil {
-		respondError(w, http.StatusNotFound, "图片不存在", err.Error())
+		respondServiceError(w, r, err)
 		return
 	}
 
 	if existing.UserID != userID {
-		respondError(w, http.StatusForbidden, "无权删除此图片", "")
+		respondError(w, r, http.StatusForbidden, codeForbidden, "无权删除此图片")
 		return
 	}
 
 	if err := h.imageService.Delete(r.Context(), uint(id)); err != nil {
-		respondError(w, http.StatusInternalServerError, "删除图片失败", err.Error())
+		respondServiceError(w, r, err)
 		return
 	}
 
-	respondSuccess(w, http.StatusOK, "删除成功", nil)
+	respondSuccess(w, r, http.StatusOK, "删除成功", nil)
 }
 
 func (h *ImageHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
 	if err != nil {
-		respondError(w, http.StatusBadRequest, "无效的ID", err.Error())
+		respondError(w, r, http.StatusBadRequest, codeInvalidID, "无效的ID")
 		return
 	}
 
@@ -99,16 +123,16 @@ func (h *ImageHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 
 	image, err := h.imageService.GetByID(r.Context(), uint(id))
 	if err != nil {
-		respondError(w, http.StatusNotFound, "图片不存在", err.Error())
+		respondServiceError(w, r, err)
 		return
 	}
 
 	if image.UserID != userID {
-		respondError(w, http.StatusForbidden, "无权查看此图片", "")
+		respondError(w, r, http.StatusForbidden, codeForbidden, "无权查看此图片")
 		return
 	}
 
-	respondSuccess(w, http.StatusOK, "获取成功", h.toImageResponse(image))
+	respondSuccess(w, r, http.StatusOK, "获取成功", h.toImageResponse(image))
 }
 
 func (h *ImageHandler) List(w http.ResponseWriter, r *http.Request) {
@@ -133,9 +157,19 @@ func (h *ImageHandler) List(w http.ResponseWriter, r *http.Request) {
 	// 	}
 	// }
 
-	images, total, err := h.imageService.ListByUserID(r.Context(), userID, page, pageSize)
+	// 附件与图片共用一张表，这里只返回图片以兼容旧客户端
+	var images []domain.Image
+	var total int64
+	var next string
+	var err error
+	if cursor, limit, ok := cursorQuery(r); ok {
+		page, pageSize = 0, 0
+		images, next, err = h.imageService.ListByKindAfter(r.Context(), userID, domain.AttachmentKindImage, cursor, limit)
+	} else {
+		images, total, err = h.imageService.ListByKind(r.Context(), userID, domain.AttachmentKindImage, page, pageSize)
+	}
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, "获取列表失败", err.Error())
+		respondServiceError(w, r, err)
 		return
 	}
 
@@ -144,24 +178,24 @@ func (h *ImageHandler) List(w http.ResponseWriter, r *http.Request) {
 		imageResponses = append(imageResponses, h.toImageResponse(&img))
 	}
 
-	respondSuccess(w, http.StatusOK, "获取成功", dto.ImageListResponse{
-		Images:   imageResponses,
-		Total:    total,
-		Page:     page,
-		PageSize: pageSize,
+	respondSuccess(w, r, http.StatusOK, "获取成功", dto.ImageListResponse{
+		Images:     imageResponses,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		NextCursor: next,
 	})
 }
 
 func (h *ImageHandler) AttachToDiary(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
 	if err != nil {
-		respondError(w, http.StatusBadRequest, "无效的图片ID", err.Error())
+		respondError(w, r, http.StatusBadRequest, codeInvalidID, "无效的图片ID")
 		return
 	}
 
 	var req dto.AttachImageRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondError(w, http.StatusBadRequest, "请求格式错误", err.Error())
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
@@ -170,27 +204,167 @@ func (h *ImageHandler) AttachToDiary(w http.ResponseWriter, r *http.Request) {
 	// 检查图片权限
 	existing, err := h.imageService.GetByID(r.Context(), uint(id))
 	if err != nil {
-		respondError(w, http.StatusNotFound, "图片不存在", err.Error())
+		respondServiceError(w, r, err)
 		return
 	}
 	if existing.UserID != userID {
-		respondError(w, http.StatusForbidden, "无权操作此图片", "")
+		respondError(w, r, http.StatusForbidden, codeForbidden, "无权操作此图片")
+		return
+	}
+	if !h.canAttach(w, r, userID, req.DiaryID) {
 		return
 	}
 
 	if err := h.imageService.AttachToDiary(r.Context(), uint(id), req.DiaryID); err != nil {
-		respondError(w, http.StatusInternalServerError, "关联日记失败", err.Error())
+		respondServiceError(w, r, err)
 		return
 	}
 
-	respondSuccess(w, http.StatusOK, "关联成功", nil)
+	respondSuccess(w, r, http.StatusOK, "关联成功", nil)
+}
+
+// ServeSigned 通过签名链接访问图片，无需登录
+func (h *ImageHandler) ServeSigned(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, codeInvalidID, "无效的ID")
+		return
+	}
+	expires, _ := strconv.ParseInt(r.URL.Query().Get("expires"), 10, 64)
+	sig := r.URL.Query().Get("sig")
+
+	image, file, err := h.imageService.OpenSigned(r.Context(), uint(id), expires, sig)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "private, max-age=3600")
+	if image.MimeType != "" {
+		w.Header().Set("Content-Type", image.MimeType)
+	}
+	// ServeContent 支持 Range 请求，音视频可以拖动播放
+	http.ServeContent(w, r, filepath.Base(image.Path), info.ModTime(), file)
 }
 
 func (h *ImageHandler) toImageResponse(image *domain.Image) dto.ImageResponse {
+	return toImageResponse(image)
+}
+
+// UploadAttachment 上传附件（图片、音频、视频）
+func (h *ImageHandler) UploadAttachment(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, int64(h.maxUploadMB+1)<<20)
+	r.ParseMultipartForm(10 << 20)
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, codeBadRequest, "无法获取上传文件")
+		return
+	}
+	defer file.Close()
+
+	userID := r.Context().Value("user_id").(uint)
+
+	// 先检查日记权限，避免留下无法关联的附件
+	var diaryID *uint
+	if diaryIDStr := r.FormValue("diary_id"); diaryIDStr != "" {
+		id, err := strconv.ParseUint(diaryIDStr, 10, 32)
+		if err != nil {
+			respondError(w, r, http.StatusBadRequest, codeInvalidID, "无效的日记ID")
+			return
+		}
+		uid := uint(id)
+		if !h.canAttach(w, r, userID, uid) {
+			return
+		}
+		diaryID = &uid
+	}
+
+	attachment, err := h.imageService.UploadAttachment(r.Context(), userID, file, header.Filename)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	if diaryID != nil {
+		if err := h.imageService.AttachToDiary(r.Context(), attachment.ID, *diaryID); err != nil {
+			respondServiceError(w, r, err)
+			return
+		}
+		attachment.DiaryID = diaryID
+	}
+
+	respondSuccess(w, r, http.StatusCreated, "上传成功", h.toImageResponse(attachment))
+}
+
+// ListAttachments 获取附件列表，可通过 ?kind=image|audio|video 过滤
+func (h *ImageHandler) ListAttachments(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uint)
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	kind := r.URL.Query().Get("kind")
+	switch kind {
+	case "", domain.AttachmentKindImage, domain.AttachmentKindAudio, domain.AttachmentKindVideo:
+	default:
+		respondError(w, r, http.StatusBadRequest, codeBadRequest, "未知的附件类型")
+		return
+	}
+
+	var attachments []domain.Image
+	var total int64
+	var next string
+	var err error
+	if cursor, limit, ok := cursorQuery(r); ok {
+		page, pageSize = 0, 0
+		attachments, next, err = h.imageService.ListByKindAfter(r.Context(), userID, kind, cursor, limit)
+	} else {
+		attachments, total, err = h.imageService.ListByKind(r.Context(), userID, kind, page, pageSize)
+	}
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	responses := make([]dto.ImageResponse, len(attachments))
+	for i := range attachments {
+		responses[i] = h.toImageResponse(&attachments[i])
+	}
+
+	respondSuccess(w, r, http.StatusOK, "获取成功", dto.AttachmentListResponse{
+		Attachments: responses,
+		Total:       total,
+		Page:        page,
+		PageSize:    pageSize,
+		NextCursor:  next,
+	})
+}
+
+func toImageResponse(image *domain.Image) dto.ImageResponse {
 	return dto.ImageResponse{
-		ID:        image.ID,
-		Path:      image.Path,
-		DiaryID:   image.DiaryID,
-		CreatedAt: image.CreatedAt,
+		ID:           image.ID,
+		Path:         image.Path,
+		DiaryID:      image.DiaryID,
+		Kind:         image.Kind,
+		MimeType:     image.MimeType,
+		Size:         image.Size,
+		Duration:     image.Duration.Seconds(),
+		Width:        image.Width,
+		Height:       image.Height,
+		OriginalName: image.OriginalName,
+		CreatedAt:    image.CreatedAt,
 	}
 }
@@ -0,0 +1,312 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"diary/internal/domain"
+	"diary/internal/handler/dto"
+	"diary/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type JournalHandler struct {
+	journalService service.JournalService
+	profileService service.ProfileService
+	diaryService   service.DiaryService
+}
+
+func NewJournalHandler(journalService service.JournalService, profileService service.ProfileService, diaryService service.DiaryService) *JournalHandler {
+	return &JournalHandler{journalService: journalService, profileService: profileService, diaryService: diaryService}
+}
+
+func (h *JournalHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateJournalRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	userID := r.Context().Value("user_id").(uint)
+
+	journal, err := h.journalService.Create(r.Context(), userID, req.Name, req.Description)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondSuccess(w, r, http.StatusCreated, "创建成功", toJournalResponse(journal))
+}
+
+func (h *JournalHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uint)
+
+	journals, err := h.journalService.List(r.Context(), userID)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	resp := make([]dto.JournalResponse, len(journals))
+	for i := range journals {
+		resp[i] = toJournalResponse(&journals[i])
+	}
+	respondSuccess(w, r, http.StatusOK, "获取成功", resp)
+}
+
+func (h *JournalHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, codeInvalidID, "无效的ID")
+		return
+	}
+
+	userID := r.Context().Value("user_id").(uint)
+
+	journal, err := h.journalService.Get(r.Context(), userID, uint(id))
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondSuccess(w, r, http.StatusOK, "获取成功", toJournalResponse(journal))
+}
+
+func (h *JournalHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, codeInvalidID, "无效的ID")
+		return
+	}
+
+	var req dto.UpdateJournalRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	userID := r.Context().Value("user_id").(uint)
+
+	journal, err := h.journalService.Update(r.Context(), userID, uint(id), req.Name, req.Description)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondSuccess(w, r, http.StatusOK, "更新成功", toJournalResponse(journal))
+}
+
+// Delete 删除日记本，其中所有成员写的日记一并删除
+func (h *JournalHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, codeInvalidID, "无效的ID")
+		return
+	}
+
+	userID := r.Context().Value("user_id").(uint)
+
+	if err := h.journalService.Delete(r.Context(), userID, uint(id)); err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondSuccess(w, r, http.StatusOK, "删除成功", nil)
+}
+
+func (h *JournalHandler) ListMembers(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, codeInvalidID, "无效的ID")
+		return
+	}
+
+	userID := r.Context().Value("user_id").(uint)
+
+	members, err := h.journalService.ListMembers(r.Context(), userID, uint(id))
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	resp := make([]dto.JournalMemberResponse, len(members))
+	for i := range members {
+		resp[i] = toJournalMemberResponse(&members[i])
+	}
+	respondSuccess(w, r, http.StatusOK, "获取成功", resp)
+}
+
+func (h *JournalHandler) AddMember(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, codeInvalidID, "无效的ID")
+		return
+	}
+
+	var req dto.AddJournalMemberRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	userID := r.Context().Value("user_id").(uint)
+
+	member, err := h.journalService.AddMember(r.Context(), userID, uint(id), req.Username, req.Role)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondSuccess(w, r, http.StatusCreated, "成员已添加", toJournalMemberResponse(member))
+}
+
+func (h *JournalHandler) UpdateMember(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, codeInvalidID, "无效的ID")
+		return
+	}
+
+	var req dto.UpdateJournalMemberRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	userID := r.Context().Value("user_id").(uint)
+
+	if err := h.journalService.UpdateMemberRole(r.Context(), userID, uint(id), chi.URLParam(r, "username"), req.Role); err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondSuccess(w, r, http.StatusOK, "更新成功", nil)
+}
+
+// RemoveMember 所有者移除成员，成员用自己的用户名调用即为退出
+func (h *JournalHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, codeInvalidID, "无效的ID")
+		return
+	}
+
+	userID := r.Context().Value("user_id").(uint)
+
+	if err := h.journalService.RemoveMember(r.Context(), userID, uint(id), chi.URLParam(r, "username")); err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondSuccess(w, r, http.StatusOK, "成员已移除", nil)
+}
+
+// ListDiaries 日记本内所有成员的日记，返回每篇的作者
+func (h *JournalHandler) ListDiaries(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, codeInvalidID, "无效的ID")
+		return
+	}
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	userID := r.Context().Value("user_id").(uint)
+
+	diaries, total, err := h.journalService.ListDiaries(r.Context(), userID, uint(id), page, pageSize)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	h.respondDiaries(w, r, "获取成功", diaries, total, page, pageSize)
+}
+
+func (h *JournalHandler) SearchDiaries(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, codeInvalidID, "无效的ID")
+		return
+	}
+	keyword := r.URL.Query().Get("q")
+	if keyword == "" {
+		respondError(w, r, http.StatusBadRequest, codeBadRequest, "搜索关键词不能为空")
+		return
+	}
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	userID := r.Context().Value("user_id").(uint)
+
+	diaries, total, err := h.journalService.SearchDiaries(r.Context(), userID, uint(id), keyword, page, pageSize)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	h.respondDiaries(w, r, "搜索成功", diaries, total, page, pageSize)
+}
+
+// respondDiaries 返回日记列表，作者一次查出
+func (h *JournalHandler) respondDiaries(w http.ResponseWriter, r *http.Request, msg string, diaries []domain.Diary, total int64, page, pageSize int) {
+	userIDs := make([]uint, 0, len(diaries))
+	for _, d := range diaries {
+		userIDs = append(userIDs, d.UserID)
+	}
+	authors, err := h.profileService.Authors(r.Context(), userIDs)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	renderHTML := wantsHTML(r)
+	diaryResponses := []dto.DiaryResponse{}
+	for _, d := range diaries {
+		if renderHTML {
+			if err := h.diaryService.RenderHTML(r.Context(), &d); err != nil {
+				respondServiceError(w, r, err)
+				return
+			}
+		}
+		resp := toDiaryResponse(&d, false)
+		if author, ok := authors[d.UserID]; ok {
+			resp.Author = toAuthorResponse(author)
+		}
+		diaryResponses = append(diaryResponses, resp)
+	}
+
+	respondSuccess(w, r, http.StatusOK, msg, dto.DiaryListResponse{
+		Diaries:  diaryResponses,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	})
+}
+
+func toJournalResponse(journal *domain.Journal) dto.JournalResponse {
+	return dto.JournalResponse{
+		ID:          journal.ID,
+		Name:        journal.Name,
+		Description: journal.Description,
+		Role:        journal.Role,
+		CreatedAt:   journal.CreatedAt,
+		UpdatedAt:   journal.UpdatedAt,
+	}
+}
+
+func toJournalMemberResponse(member *domain.JournalMember) dto.JournalMemberResponse {
+	resp := dto.JournalMemberResponse{Role: member.Role, JoinedAt: member.CreatedAt}
+	if member.User != nil {
+		resp.Username = member.User.Username
+	}
+	return resp
+}
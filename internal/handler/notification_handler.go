@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"diary/internal/domain"
+	"diary/internal/handler/dto"
+	"diary/internal/service"
+	"diary/pkg/notify"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type NotificationHandler struct {
+	notificationService service.NotificationService
+}
+
+func NewNotificationHandler(notificationService service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{notificationService: notificationService}
+}
+
+// List 站内通知列表，unread=true 时只返回未读
+func (h *NotificationHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uint)
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+	if pageSize < 1 {
+		pageSize = 20
+	}
+	unreadOnly := r.URL.Query().Get("unread") == "true"
+
+	notifications, total, err := h.notificationService.List(r.Context(), userID, unreadOnly, page, pageSize)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+	unread, err := h.notificationService.CountUnread(r.Context(), userID)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	resp := dto.NotificationListResponse{
+		Notifications: []dto.NotificationResponse{},
+		Total:         total,
+		Unread:        unread,
+		Page:          page,
+		PageSize:      pageSize,
+	}
+	for _, n := range notifications {
+		resp.Notifications = append(resp.Notifications, dto.NotificationResponse{
+			ID:        n.ID,
+			Kind:      n.Kind,
+			Title:     n.Title,
+			Body:      n.Body,
+			Link:      n.Link,
+			ReadAt:    n.ReadAt,
+			CreatedAt: n.CreatedAt,
+		})
+	}
+	respondSuccess(w, r, http.StatusOK, "获取成功", resp)
+}
+
+func (h *NotificationHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, codeInvalidID, "无效的ID")
+		return
+	}
+
+	userID := r.Context().Value("user_id").(uint)
+
+	if err := h.notificationService.MarkRead(r.Context(), userID, uint(id)); err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondSuccess(w, r, http.StatusOK, "已标记为已读", nil)
+}
+
+func (h *NotificationHandler) MarkAllRead(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uint)
+
+	if err := h.notificationService.MarkAllRead(r.Context(), userID); err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondSuccess(w, r, http.StatusOK, "已全部标记为已读", nil)
+}
+
+func (h *NotificationHandler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uint)
+
+	setting, err := h.notificationService.GetSetting(r.Context(), userID)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondSuccess(w, r, http.StatusOK, "获取成功", toNotificationSettingResponse(setting))
+}
+
+func (h *NotificationHandler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	var req dto.NotificationSettingRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	userID := r.Context().Value("user_id").(uint)
+
+	setting := &domain.NotificationSetting{
+		UserID:        userID,
+		InApp:         req.InApp,
+		WebhookURL:    req.WebhookURL,
+		Email:         req.Email,
+		TodoReminders: req.TodoReminders,
+		DailyPrompt:   req.DailyPrompt,
+		PromptTime:    req.PromptTime,
+		Timezone:      req.Timezone,
+	}
+	if err := h.notificationService.UpdateSetting(r.Context(), setting); err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondSuccess(w, r, http.StatusOK, "保存成功", toNotificationSettingResponse(setting))
+}
+
+// SendTest 通过已开启的渠道发送一条测试通知，用于检查 Webhook 和邮件配置
+func (h *NotificationHandler) SendTest(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uint)
+
+	err := h.notificationService.Send(r.Context(), userID, notify.Message{
+		Kind:  "test",
+		Title: "测试通知",
+		Body:  "收到这条消息说明提醒渠道配置正确。",
+	})
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondSuccess(w, r, http.StatusOK, "已发送", nil)
+}
+
+func toNotificationSettingResponse(setting *domain.NotificationSetting) dto.NotificationSettingResponse {
+	return dto.NotificationSettingResponse{
+		InApp:         setting.InApp,
+		WebhookURL:    setting.WebhookURL,
+		Email:         setting.Email,
+		TodoReminders: setting.TodoReminders,
+		DailyPrompt:   setting.DailyPrompt,
+		PromptTime:    setting.PromptTime,
+		Timezone:      setting.Timezone,
+		UpdatedAt:     setting.UpdatedAt,
+	}
+}
@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"diary/internal/domain"
+	"diary/internal/handler/dto"
+	"diary/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type ProfileHandler struct {
+	profileService service.ProfileService
+	diaryService   service.DiaryService
+}
+
+func NewProfileHandler(profileService service.ProfileService, diaryService service.DiaryService) *ProfileHandler {
+	return &ProfileHandler{profileService: profileService, diaryService: diaryService}
+}
+
+// Update 修改自己的公开主页资料
+func (h *ProfileHandler) Update(w http.ResponseWriter, r *http.Request) {
+	var req dto.UpdateProfileRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	userID := r.Context().Value("user_id").(uint)
+
+	user, err := h.profileService.UpdateProfile(r.Context(), userID, req.DisplayName, req.Bio, req.AvatarImageID, req.Public)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondSuccess(w, r, http.StatusOK, "保存成功", toProfileResponse(user))
+}
+
+// Get 查看公开主页，不需要登录
+func (h *ProfileHandler) Get(w http.ResponseWriter, r *http.Request) {
+	user, err := h.profileService.GetPublic(r.Context(), chi.URLParam(r, "username"))
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondSuccess(w, r, http.StatusOK, "获取成功", toProfileResponse(user))
+}
+
+// ListDiaries 公开主页用户的公开日记，筛选参数和公开日记列表相同
+func (h *ProfileHandler) ListDiaries(w http.ResponseWriter, r *http.Request) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	filter, err := parsePublicDiaryFilter(r.URL.Query())
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, codeBadRequest, "无效的筛选参数")
+		return
+	}
+
+	var user *domain.User
+	var diaries []domain.Diary
+	var total int64
+	var next string
+	username := chi.URLParam(r, "username")
+	if cursor, limit, ok := cursorQuery(r); ok {
+		page, pageSize = 0, 0
+		user, diaries, next, err = h.profileService.ListDiariesAfter(r.Context(), username, filter, cursor, limit)
+	} else {
+		user, diaries, total, err = h.profileService.ListDiaries(r.Context(), username, filter, page, pageSize)
+	}
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	author := toAuthorResponse(user)
+	renderHTML := wantsHTML(r)
+	diaryResponses := []dto.DiaryResponse{}
+	for _, d := range diaries {
+		if renderHTML {
+			if err := h.diaryService.RenderHTML(r.Context(), &d); err != nil {
+				respondServiceError(w, r, err)
+				return
+			}
+		}
+		resp := toDiaryResponse(&d, false)
+		resp.Author = author
+		diaryResponses = append(diaryResponses, resp)
+	}
+
+	respondSuccess(w, r, http.StatusOK, "获取成功", dto.DiaryListResponse{
+		Diaries:    diaryResponses,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		NextCursor: next,
+	})
+}
+
+func toProfileResponse(user *domain.User) dto.ProfileResponse {
+	return dto.ProfileResponse{
+		Username:      user.Username,
+		DisplayName:   user.DisplayName,
+		Bio:           user.Bio,
+		AvatarImageID: user.AvatarImageID,
+		AvatarURL:     user.AvatarURL,
+		Public:        user.ProfilePublic,
+		CreatedAt:     user.CreatedAt,
+
+		FollowerCount:  user.FollowerCount,
+		FollowingCount: user.FollowingCount,
+	}
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"diary/internal/domain"
+	"diary/internal/handler/dto"
+	"diary/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type ProjectHandler struct {
+	projectService service.ProjectService
+}
+
+func NewProjectHandler(projectService service.ProjectService) *ProjectHandler {
+	return &ProjectHandler{projectService: projectService}
+}
+
+func (h *ProjectHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateProjectRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	userID := r.Context().Value("user_id").(uint)
+
+	project, err := h.projectService.Create(r.Context(), userID, req.Name, req.Color)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondSuccess(w, r, http.StatusCreated, "创建成功", toProjectResponse(project))
+}
+
+func (h *ProjectHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uint)
+
+	projects, err := h.projectService.List(r.Context(), userID)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	resp := []dto.ProjectResponse{}
+	for _, p := range projects {
+		resp = append(resp, toProjectResponse(&p))
+	}
+	respondSuccess(w, r, http.StatusOK, "获取成功", resp)
+}
+
+func (h *ProjectHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, codeInvalidID, "无效的ID")
+		return
+	}
+
+	var req dto.UpdateProjectRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	userID := r.Context().Value("user_id").(uint)
+
+	project, err := h.projectService.Update(r.Context(), userID, uint(id), req.Name, req.Color, req.SortOrder)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondSuccess(w, r, http.StatusOK, "更新成功", toProjectResponse(project))
+}
+
+func (h *ProjectHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, codeInvalidID, "无效的ID")
+		return
+	}
+
+	userID := r.Context().Value("user_id").(uint)
+
+	if err := h.projectService.Delete(r.Context(), userID, uint(id)); err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondSuccess(w, r, http.StatusOK, "删除成功", nil)
+}
+
+func toProjectResponse(project *domain.TodoProject) dto.ProjectResponse {
+	return dto.ProjectResponse{
+		ID:        project.ID,
+		Name:      project.Name,
+		Color:     project.Color,
+		SortOrder: project.SortOrder,
+		CreatedAt: project.CreatedAt,
+	}
+}
@@ -2,29 +2,104 @@ package handler
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"diary/internal/handler/dto"
+	"diary/internal/i18n"
 )
 
-// respondSuccess 返回成功响应
-func respondSuccess(w http.ResponseWriter, statusCode int, message string, data interface{}) {
+// respondSuccess 返回成功响应，message 按请求语言翻译
+func respondSuccess(w http.ResponseWriter, r *http.Request, statusCode int, message string, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
 	json.NewEncoder(w).Encode(dto.Response{
 		Code:    statusCode,
-		Message: message,
+		Message: i18n.T(i18n.FromRequest(r), message),
 		Data:    data,
 	})
 }
 
-// respondError 返回错误响应
-func respondError(w http.ResponseWriter, statusCode int, message string, err string) {
+// respondError 返回处理器自己发现的错误（参数、权限等），服务层错误使用 respondServiceError
+func respondError(w http.ResponseWriter, r *http.Request, statusCode int, code, message string) {
+	writeError(w, newErrorResponse(r, statusCode, code, message))
+}
+
+// respondValidationError 返回字段级的参数错误
+func respondValidationError(w http.ResponseWriter, r *http.Request, details []dto.FieldError) {
+	resp := newErrorResponse(r, http.StatusBadRequest, codeValidationFailed, "请求参数错误")
+	resp.Details = details
+	writeError(w, resp)
+}
+
+func newErrorResponse(r *http.Request, statusCode int, code, message string) dto.ErrorResponse {
+	return dto.ErrorResponse{
+		Code:      statusCode,
+		ErrorCode: code,
+		Message:   i18n.T(i18n.FromRequest(r), message),
+	}
+}
+
+func writeError(w http.ResponseWriter, resp dto.ErrorResponse) {
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	json.NewEncoder(w).Encode(dto.ErrorResponse{
-		Code:    statusCode,
-		Message: message,
-		Error:   err,
-	})
+	w.WriteHeader(resp.Code)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// notModified 条件请求是否可以返回 304：有 If-None-Match 时只比较 ETag（弱比较），否则比较 If-Modified-Since
+func notModified(r *http.Request, etag string, modTime time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, tag := range strings.Split(inm, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	return err == nil && !modTime.IsZero() && !modTime.Truncate(time.Second).After(since)
+}
+
+// externalBaseURL 生成给外部访问的链接前缀，未配置 PUBLIC_BASE_URL 时按请求的地址生成
+func externalBaseURL(r *http.Request, configured string) string {
+	if configured != "" {
+		return configured
+	}
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+// setAttachment 设置下载文件名，filename 提供 ASCII 兼容名，filename* 按 RFC 5987 编码 UTF-8 原名
+func setAttachment(w http.ResponseWriter, filename string) {
+	var fallback, encoded strings.Builder
+	for _, r := range filename {
+		if r < 0x20 || r > 0x7e || r == '"' || r == '\\' {
+			fallback.WriteByte('_')
+		} else {
+			fallback.WriteRune(r)
+		}
+	}
+	for _, b := range []byte(filename) {
+		if isAttrChar(b) {
+			encoded.WriteByte(b)
+		} else {
+			fmt.Fprintf(&encoded, "%%%02X", b)
+		}
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"; filename*=UTF-8''%s`, fallback.String(), encoded.String()))
+}
+
+// isAttrChar RFC 5987 中无需编码的字符
+func isAttrChar(b byte) bool {
+	switch {
+	case 'a' <= b && b <= 'z', 'A' <= b && b <= 'Z', '0' <= b && b <= '9':
+		return true
+	}
+	return strings.IndexByte("!#$&+-.^_`|~", b) >= 0
 }
@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"diary/internal/domain"
+	"diary/internal/handler/dto"
+	"diary/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// sharePasswordHeader 访问带密码的分享链接时携带密码的请求头
+const sharePasswordHeader = "X-Share-Password"
+
+type ShareHandler struct {
+	shareService service.ShareService
+	imageService service.ImageService
+	baseURL      string
+}
+
+func NewShareHandler(shareService service.ShareService, imageService service.ImageService, baseURL string) *ShareHandler {
+	return &ShareHandler{shareService: shareService, imageService: imageService, baseURL: baseURL}
+}
+
+// Create 为日记创建分享链接，链接只在这里返回一次
+func (h *ShareHandler) Create(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, codeInvalidID, "无效的ID")
+		return
+	}
+
+	var req dto.CreateShareRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	userID := r.Context().Value("user_id").(uint)
+
+	token, link, err := h.shareService.Create(r.Context(), userID, uint(id), req.ExpiresAt, req.Password)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	resp := toShareLinkResponse(link)
+	resp.URL = externalBaseURL(r, h.baseURL) + "/s/" + token
+	respondSuccess(w, r, http.StatusCreated, "分享链接已生成", resp)
+}
+
+func (h *ShareHandler) List(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, codeInvalidID, "无效的ID")
+		return
+	}
+
+	userID := r.Context().Value("user_id").(uint)
+
+	links, err := h.shareService.List(r.Context(), userID, uint(id))
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	resp := make([]dto.ShareLinkResponse, len(links))
+	for i := range links {
+		resp[i] = toShareLinkResponse(&links[i])
+	}
+	respondSuccess(w, r, http.StatusOK, "获取成功", resp)
+}
+
+func (h *ShareHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, codeInvalidID, "无效的ID")
+		return
+	}
+
+	userID := r.Context().Value("user_id").(uint)
+
+	if err := h.shareService.Revoke(r.Context(), userID, uint(id)); err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondSuccess(w, r, http.StatusOK, "分享链接已撤销", nil)
+}
+
+// View 通过分享链接查看日记，不需要登录；带密码的链接通过 X-Share-Password 传入密码。
+// 正文总是渲染为 HTML，附件返回签名地址
+func (h *ShareHandler) View(w http.ResponseWriter, r *http.Request) {
+	// 链接本身就是凭证，不缓存、不索引，也不通过 Referer 带给图片等外部资源
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Robots-Tag", "noindex, nofollow")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+
+	diary, err := h.shareService.View(r.Context(), chi.URLParam(r, "token"), r.Header.Get(sharePasswordHeader))
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	resp := toDiaryResponse(diary, true)
+	h.signAttachments(resp.Images)
+	if resp.Attachments != nil {
+		h.signAttachments(resp.Attachments.Images)
+		h.signAttachments(resp.Attachments.Audio)
+		h.signAttachments(resp.Attachments.Video)
+	}
+	respondSuccess(w, r, http.StatusOK, "获取成功", resp)
+}
+
+func (h *ShareHandler) signAttachments(items []dto.ImageResponse) {
+	for i := range items {
+		items[i].URL = h.imageService.SignedURL(items[i].ID)
+	}
+}
+
+func toShareLinkResponse(link *domain.ShareLink) dto.ShareLinkResponse {
+	return dto.ShareLinkResponse{
+		ID:           link.ID,
+		DiaryID:      link.DiaryID,
+		HasPassword:  link.HasPassword(),
+		ExpiresAt:    link.ExpiresAt,
+		Active:       link.RevokedAt == nil && !link.Expired(time.Now()),
+		ViewCount:    link.ViewCount,
+		LastViewedAt: link.LastViewedAt,
+		RevokedAt:    link.RevokedAt,
+		CreatedAt:    link.CreatedAt,
+	}
+}
@@ -25,20 +25,20 @@ func (h *StatsHandler) GetDashboardStats(w http.ResponseWriter, r *http.Request)
 	// 1. Diary Count
 	diaryCount, err := h.diaryRepo.CountByUserID(r.Context(), userID)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, "获取日记统计失败", err.Error())
+		respondServiceError(w, r, err)
 		return
 	}
 
 	// 2. Todo Stats
 	todoCount, err := h.todoRepo.CountByUserID(r.Context(), userID)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, "获取待办统计失败", err.Error())
+		respondServiceError(w, r, err)
 		return
 	}
 
 	pendingCount, err := h.todoRepo.CountPending(r.Context(), userID)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, "获取待办详情统计失败", err.Error())
+		respondServiceError(w, r, err)
 		return
 	}
 
@@ -50,7 +50,7 @@ func (h *StatsHandler) GetDashboardStats(w http.ResponseWriter, r *http.Request)
 	// 3. Monthly Trend
 	monthlyTrend, err := h.diaryRepo.GetMonthlyTrend(r.Context(), userID)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, "获取月度趋势失败", err.Error())
+		respondServiceError(w, r, err)
 		return
 	}
 
@@ -70,7 +70,7 @@ func (h *StatsHandler) GetDashboardStats(w http.ResponseWriter, r *http.Request)
 	// 4. Top Tags
 	topTags, err := h.diaryRepo.GetTopTags(r.Context(), userID, 6)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, "获取热门标签失败", err.Error())
+		respondServiceError(w, r, err)
 		return
 	}
 
@@ -94,5 +94,5 @@ func (h *StatsHandler) GetDashboardStats(w http.ResponseWriter, r *http.Request)
 		TopTags:           dtoTopTags,
 	}
 
-	respondSuccess(w, http.StatusOK, "获取统计成功", response)
+	respondSuccess(w, r, http.StatusOK, "获取统计成功", response)
 }
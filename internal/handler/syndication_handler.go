@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"diary/internal/service"
+	"diary/pkg/feed"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// feedFormat 一种订阅源格式
+type feedFormat struct {
+	contentType string
+	write       func(io.Writer, *feed.Feed) error
+}
+
+var feedFormats = map[string]feedFormat{
+	".atom": {"application/atom+xml; charset=utf-8", feed.WriteAtom},
+	".rss":  {"application/rss+xml; charset=utf-8", feed.WriteRSS},
+	".json": {"application/feed+json; charset=utf-8", feed.WriteJSON},
+}
+
+type SyndicationHandler struct {
+	syndicationService service.SyndicationService
+	baseURL            string
+}
+
+func NewSyndicationHandler(syndicationService service.SyndicationService, baseURL string) *SyndicationHandler {
+	return &SyndicationHandler{syndicationService: syndicationService, baseURL: baseURL}
+}
+
+// UserFeed 用户公开日记的订阅源，不需要登录。路径为 /feeds/{username}.atom、.rss 或 .json，
+// 用户名可能含有点号，所以整段作为一个参数再按扩展名拆分
+func (h *SyndicationHandler) UserFeed(w http.ResponseWriter, r *http.Request) {
+	file := chi.URLParam(r, "file")
+	ext := path.Ext(file)
+	format, ok := feedFormats[ext]
+	username := strings.TrimSuffix(file, ext)
+	if !ok || username == "" {
+		respondError(w, r, http.StatusNotFound, codeNotFound, "不支持的订阅格式")
+		return
+	}
+
+	base := externalBaseURL(r, h.baseURL)
+	f, err := h.syndicationService.UserFeed(r.Context(), username, base)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+	f.FeedURL = base + "/feeds/" + url.PathEscape(username) + ext
+
+	// 正文中的图片是签名地址，每次生成都不同，所以 ETag 按日记的 ID 和修改时间计算，是弱 ETag
+	etag := feedETag(ext, f)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Last-Modified", f.Updated.UTC().Format(http.TimeFormat))
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	if notModified(r, etag, f.Updated) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", format.contentType)
+	format.write(w, f)
+}
+
+func feedETag(ext string, f *feed.Feed) string {
+	h := sha1.New()
+	fmt.Fprintf(h, "%s\n%s\n%s\n%d\n", ext, f.Title, f.Description, f.Updated.UnixNano())
+	for _, item := range f.Items {
+		fmt.Fprintf(h, "%s %d\n", item.ID, item.Updated.UnixNano())
+	}
+	return `W/"` + hex.EncodeToString(h.Sum(nil)[:12]) + `"`
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"diary/internal/service"
+	"diary/pkg/feed"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type stubSyndicationService struct {
+	feed feed.Feed
+}
+
+func (s *stubSyndicationService) UserFeed(ctx context.Context, username, baseURL string) (*feed.Feed, error) {
+	if username != "alice" {
+		return nil, service.ErrProfileNotFound
+	}
+	// 每次返回新的副本，正文中的签名地址每次都不同
+	f := s.feed
+	f.Items = append([]feed.Item(nil), s.feed.Items...)
+	for i := range f.Items {
+		f.Items[i].ContentHTML = time.Now().String()
+	}
+	return &f, nil
+}
+
+func testFeed() feed.Feed {
+	updated := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	return feed.Feed{
+		Title:   "alice",
+		Updated: updated,
+		Items: []feed.Item{
+			{ID: "https://diary.example/api/diaries/1", Title: "一", Updated: updated},
+			{ID: "https://diary.example/api/diaries/2", Title: "二", Updated: updated.Add(-time.Hour)},
+		},
+	}
+}
+
+func TestFeedETag(t *testing.T) {
+	base := testFeed()
+	etag := feedETag(".atom", &base)
+	if etag[:2] != `W/` {
+		t.Fatalf("ETag %s 应为弱 ETag", etag)
+	}
+
+	same := testFeed()
+	same.Items[0].ContentHTML = "签名地址不同"
+	if got := feedETag(".atom", &same); got != etag {
+		t.Errorf("只有正文中的签名地址不同时 ETag 应相同")
+	}
+
+	edited := testFeed()
+	edited.Items[1].Updated = edited.Items[1].Updated.Add(time.Second)
+	removed := testFeed()
+	removed.Items = removed.Items[:1]
+	retitled := testFeed()
+	retitled.Title = "Alice"
+
+	for name, f := range map[string]*feed.Feed{"日记被修改": &edited, "日记被移除": &removed, "标题变化": &retitled} {
+		if feedETag(".atom", f) == etag {
+			t.Errorf("%s后 ETag 应变化", name)
+		}
+	}
+	if feedETag(".rss", &base) == etag {
+		t.Error("不同格式的 ETag 应不同")
+	}
+}
+
+func TestUserFeedNotModified(t *testing.T) {
+	h := NewSyndicationHandler(&stubSyndicationService{feed: testFeed()}, "https://diary.example")
+	get := func(file string, header http.Header) *httptest.ResponseRecorder {
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("file", file)
+		req := httptest.NewRequest(http.MethodGet, "/feeds/"+file, nil)
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		rec := httptest.NewRecorder()
+		h.UserFeed(rec, req)
+		return rec
+	}
+
+	first := get("alice.atom", nil)
+	if first.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", first.Code)
+	}
+	etag := first.Header().Get("ETag")
+	lastModified := first.Header().Get("Last-Modified")
+
+	tests := []struct {
+		name   string
+		file   string
+		header http.Header
+		want   int
+	}{
+		{"ETag 匹配", "alice.atom", http.Header{"If-None-Match": {etag}}, http.StatusNotModified},
+		{"ETag 去掉弱前缀也匹配", "alice.atom", http.Header{"If-None-Match": {etag[2:]}}, http.StatusNotModified},
+		{"多个 ETag 之一匹配", "alice.atom", http.Header{"If-None-Match": {`"other", ` + etag}}, http.StatusNotModified},
+		{"ETag 不匹配时忽略 If-Modified-Since", "alice.atom", http.Header{"If-None-Match": {`W/"stale"`}, "If-Modified-Since": {lastModified}}, http.StatusOK},
+		{"If-Modified-Since 等于修改时间", "alice.atom", http.Header{"If-Modified-Since": {lastModified}}, http.StatusNotModified},
+		{"If-Modified-Since 早于修改时间", "alice.atom", http.Header{"If-Modified-Since": {"Sat, 28 Feb 2026 09:00:00 GMT"}}, http.StatusOK},
+		{"其他格式的 ETag 不匹配", "alice.rss", http.Header{"If-None-Match": {etag}}, http.StatusOK},
+		{"不支持的格式", "alice.txt", nil, http.StatusNotFound},
+		{"用户不存在", "bob.atom", nil, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := get(tt.file, tt.header)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if rec.Code == http.StatusNotModified && rec.Body.Len() > 0 {
+				t.Fatalf("304 不应有响应体，got %d 字节", rec.Body.Len())
+			}
+		})
+	}
+}
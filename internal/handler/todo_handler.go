@@ -1,9 +1,10 @@
 package handler
 
 import (
-	"encoding/json"
 	"net/http"
+	"net/url"
 	"strconv"
+	"time"
 
 	"diary/internal/domain"
 	"diary/internal/handler/dto"
@@ -22,32 +23,30 @@ func NewTodoHandler(todoService service.TodoService) *TodoHandler {
 
 func (h *TodoHandler) Create(w http.ResponseWriter, r *http.Request) {
 	var req dto.CreateTodoRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondError(w, http.StatusBadRequest, "请求格式错误", err.Error())
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
 	userID := r.Context().Value("user_id").(uint)
 
-	todo, err := h.todoService.Create(r.Context(), userID, req.Title, req.Description, req.DueDate)
+	todo, err := h.todoService.Create(r.Context(), userID, req.Title, req.Description, req.DueDate, req.Priority, req.ProjectID, req.ParentID, req.RRule, req.Timezone)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, "创建待办事项失败", err.Error())
+		respondServiceError(w, r, err)
 		return
 	}
 
-	respondSuccess(w, http.StatusCreated, "创建成功", h.toTodoResponse(todo))
+	respondSuccess(w, r, http.StatusCreated, "创建成功", h.toTodoResponse(todo))
 }
 
 func (h *TodoHandler) Update(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
 	if err != nil {
-		respondError(w, http.StatusBadRequest, "无效的ID", err.Error())
+		respondError(w, r, http.StatusBadRequest, codeInvalidID, "无效的ID")
 		return
 	}
 
 	var req dto.UpdateTodoRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondError(w, http.StatusBadRequest, "请求格式错误", err.Error())
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
@@ -56,18 +55,18 @@ func (h *TodoHandler) Update(w http.ResponseWriter, r *http.Request) {
 	// 先检查是否存在且属于当前用户
 	existing, err := h.todoService.GetByID(r.Context(), uint(id))
 	if err != nil {
-		respondError(w, http.StatusNotFound, "待办事项不存在", err.Error())
+		respondServiceError(w, r, err)
 		return
 	}
 
 	if existing.UserID != userID {
-		respondError(w, http.StatusForbidden, "无权修改此待办事项", "")
+		respondError(w, r, http.StatusForbidden, codeForbidden, "无权修改此待办事项")
 		return
 	}
 
-	err = h.todoService.Update(r.Context(), uint(id), req.Title, req.Description, req.DueDate)
+	err = h.todoService.Update(r.Context(), uint(id), req.Title, req.Description, req.DueDate, req.Priority, req.ProjectID, req.RRule, req.Timezone)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, "更新待办事项失败", err.Error())
+		respondServiceError(w, r, err)
 		return
 	}
 
@@ -75,17 +74,17 @@ func (h *TodoHandler) Update(w http.ResponseWriter, r *http.Request) {
 	updated, err := h.todoService.GetByID(r.Context(), uint(id))
 	if err != nil {
 		// 更新成功但获取失败，返回成功响应但不带数据
-		respondSuccess(w, http.StatusOK, "更新成功", nil)
+		respondSuccess(w, r, http.StatusOK, "更新成功", nil)
 		return
 	}
 
-	respondSuccess(w, http.StatusOK, "更新成功", h.toTodoResponse(updated))
+	respondSuccess(w, r, http.StatusOK, "更新成功", h.toTodoResponse(updated))
 }
 
 func (h *TodoHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
 	if err != nil {
-		respondError(w, http.StatusBadRequest, "无效的ID", err.Error())
+		respondError(w, r, http.StatusBadRequest, codeInvalidID, "无效的ID")
 		return
 	}
 
@@ -94,27 +93,27 @@ func (h *TodoHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	// 先检查是否存在且属于当前用户
 	existing, err := h.todoService.GetByID(r.Context(), uint(id))
 	if err != nil {
-		respondError(w, http.StatusNotFound, "待办事项不存在", err.Error())
+		respondServiceError(w, r, err)
 		return
 	}
 
 	if existing.UserID != userID {
-		respondError(w, http.StatusForbidden, "无权删除此待办事项", "")
+		respondError(w, r, http.StatusForbidden, codeForbidden, "无权删除此待办事项")
 		return
 	}
 
 	if err := h.todoService.Delete(r.Context(), uint(id)); err != nil {
-		respondError(w, http.StatusInternalServerError, "删除待办事项失败", err.Error())
+		respondServiceError(w, r, err)
 		return
 	}
 
-	respondSuccess(w, http.StatusOK, "删除成功", nil)
+	respondSuccess(w, r, http.StatusOK, "删除成功", nil)
 }
 
 func (h *TodoHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
 	if err != nil {
-		respondError(w, http.StatusBadRequest, "无效的ID", err.Error())
+		respondError(w, r, http.StatusBadRequest, codeInvalidID, "无效的ID")
 		return
 	}
 
@@ -122,60 +121,165 @@ func (h *TodoHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 
 	todo, err := h.todoService.GetByID(r.Context(), uint(id))
 	if err != nil {
-		respondError(w, http.StatusNotFound, "待办事项不存在", err.Error())
+		respondServiceError(w, r, err)
 		return
 	}
 
 	if todo.UserID != userID {
-		respondError(w, http.StatusForbidden, "无权查看此待办事项", "")
+		respondError(w, r, http.StatusForbidden, codeForbidden, "无权查看此待办事项")
 		return
 	}
 
-	respondSuccess(w, http.StatusOK, "获取成功", h.toTodoResponse(todo))
+	respondSuccess(w, r, http.StatusOK, "获取成功", h.toTodoResponse(todo))
 }
 
+// List 获取待办列表，支持的查询参数：
+//   - done: true/false
+//   - priority: 0-3
+//   - project_id: 清单ID，none 表示未归入清单
+//   - parent_id: 父任务ID；不传时只返回顶层任务，子任务放在 subtasks 中
+//   - due_from、due_to: 截止日期范围（YYYY-MM-DD，包含两端）
+//   - sort: created_at（默认）、due_date、priority、order、title；order: asc/desc
 func (h *TodoHandler) List(w http.ResponseWriter, r *http.Request) {
 	userID := r.Context().Value("user_id").(uint)
-	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	query := r.URL.Query()
+	page, _ := strconv.Atoi(query.Get("page"))
 	if page < 1 {
 		page = 1
 	}
-	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+	pageSize, _ := strconv.Atoi(query.Get("page_size"))
 	if pageSize < 1 {
 		pageSize = 10
 	}
-	
-	// 可选的过滤参数
+
+	filter, err := parseTodoFilter(query)
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, codeBadRequest, "无效的筛选参数")
+		return
+	}
+
 	var todos []domain.Todo
 	var total int64
-	var err error
-
-	doneStr := r.URL.Query().Get("done")
-	if doneStr != "" {
-		done := doneStr == "true"
-		todos, total, err = h.todoService.ListByStatus(r.Context(), userID, done, page, pageSize)
+	var next string
+	if cursor, limit, ok := cursorQuery(r); ok {
+		page, pageSize = 0, 0
+		todos, next, err = h.todoService.ListAfter(r.Context(), userID, filter, cursor, limit)
 	} else {
-		todos, total, err = h.todoService.ListByUserID(r.Context(), userID, page, pageSize)
+		todos, total, err = h.todoService.List(r.Context(), userID, filter, page, pageSize)
 	}
-
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, "获取列表失败", err.Error())
+		respondServiceError(w, r, err)
 		return
 	}
 
-	var todoResponses []dto.TodoResponse
+	// 顶层任务的子任务一次查出，避免逐条查询
+	var subtasks map[uint][]domain.Todo
+	if *filter.ParentID == 0 && len(todos) > 0 {
+		ids := make([]uint, len(todos))
+		for i, t := range todos {
+			ids[i] = t.ID
+		}
+		if subtasks, err = h.todoService.ListSubtasks(r.Context(), ids); err != nil {
+			respondServiceError(w, r, err)
+			return
+		}
+	}
+
+	todoResponses := []dto.TodoResponse{}
 	for _, t := range todos {
-		todoResponses = append(todoResponses, h.toTodoResponse(&t))
+		resp := h.toTodoResponse(&t)
+		for _, sub := range subtasks[t.ID] {
+			resp.Subtasks = append(resp.Subtasks, h.toTodoResponse(&sub))
+		}
+		todoResponses = append(todoResponses, resp)
 	}
 
-	respondSuccess(w, http.StatusOK, "获取成功", dto.TodoListResponse{
-		Todos:    todoResponses,
-		Total:    total,
-		Page:     page,
-		PageSize: pageSize,
+	respondSuccess(w, r, http.StatusOK, "获取成功", dto.TodoListResponse{
+		Todos:      todoResponses,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		NextCursor: next,
 	})
 }
 
+func parseTodoFilter(query url.Values) (domain.TodoFilter, error) {
+	var filter domain.TodoFilter
+	if v := query.Get("done"); v != "" {
+		done := v == "true"
+		filter.Done = &done
+	}
+	if v := query.Get("priority"); v != "" {
+		priority, err := strconv.Atoi(v)
+		if err != nil || priority < domain.TodoPriorityNone || priority > domain.TodoPriorityHigh {
+			return filter, service.ErrInvalidPriority
+		}
+		filter.Priority = &priority
+	}
+	if v := query.Get("project_id"); v != "" {
+		var projectID uint
+		if v != "none" {
+			id, err := strconv.ParseUint(v, 10, 32)
+			if err != nil {
+				return filter, err
+			}
+			projectID = uint(id)
+		}
+		filter.ProjectID = &projectID
+	}
+	var parentID uint
+	if v := query.Get("parent_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			return filter, err
+		}
+		parentID = uint(id)
+	}
+	filter.ParentID = &parentID
+	if v := query.Get("due_from"); v != "" {
+		t, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			return filter, err
+		}
+		filter.DueFrom = &t
+	}
+	if v := query.Get("due_to"); v != "" {
+		t, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			return filter, err
+		}
+		t = t.AddDate(0, 0, 1) // 包含结束当天
+		filter.DueTo = &t
+	}
+	filter.Sort = query.Get("sort")
+	switch query.Get("order") {
+	case "asc":
+	case "desc":
+		filter.Desc = true
+	default:
+		// 创建时间和优先级默认从新到旧、从高到低，其余默认升序
+		filter.Desc = filter.Sort == "" || filter.Sort == "created_at" || filter.Sort == "priority"
+	}
+	return filter, nil
+}
+
+// Reorder 手动排序，请求体为按新顺序排列的待办ID
+func (h *TodoHandler) Reorder(w http.ResponseWriter, r *http.Request) {
+	var req dto.ReorderTodosRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	userID := r.Context().Value("user_id").(uint)
+
+	if err := h.todoService.Reorder(r.Context(), userID, req.IDs); err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondSuccess(w, r, http.StatusOK, "排序成功", nil)
+}
+
 func (h *TodoHandler) MarkAsDone(w http.ResponseWriter, r *http.Request) {
 	h.updateStatus(w, r, true)
 }
@@ -187,7 +291,7 @@ func (h *TodoHandler) MarkAsUndone(w http.ResponseWriter, r *http.Request) {
 func (h *TodoHandler) updateStatus(w http.ResponseWriter, r *http.Request, done bool) {
 	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
 	if err != nil {
-		respondError(w, http.StatusBadRequest, "无效的ID", err.Error())
+		respondError(w, r, http.StatusBadRequest, codeInvalidID, "无效的ID")
 		return
 	}
 
@@ -196,12 +300,12 @@ func (h *TodoHandler) updateStatus(w http.ResponseWriter, r *http.Request, done
 	// 先检查是否存在且属于当前用户
 	existing, err := h.todoService.GetByID(r.Context(), uint(id))
 	if err != nil {
-		respondError(w, http.StatusNotFound, "待办事项不存在", err.Error())
+		respondServiceError(w, r, err)
 		return
 	}
 
 	if existing.UserID != userID {
-		respondError(w, http.StatusForbidden, "无权修改此待办事项", "")
+		respondError(w, r, http.StatusForbidden, codeForbidden, "无权修改此待办事项")
 		return
 	}
 
@@ -212,36 +316,130 @@ func (h *TodoHandler) updateStatus(w http.ResponseWriter, r *http.Request, done
 	}
 
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, "更新状态失败", err.Error())
+		respondServiceError(w, r, err)
 		return
 	}
 
-	respondSuccess(w, http.StatusOK, "更新状态成功", nil)
+	respondSuccess(w, r, http.StatusOK, "更新状态成功", nil)
+}
+
+// Skip 跳过重复待办的本次
+func (h *TodoHandler) Skip(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, codeInvalidID, "无效的ID")
+		return
+	}
+
+	userID := r.Context().Value("user_id").(uint)
+
+	existing, err := h.todoService.GetByID(r.Context(), uint(id))
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	if existing.UserID != userID {
+		respondError(w, r, http.StatusForbidden, codeForbidden, "无权修改此待办事项")
+		return
+	}
+
+	if err := h.todoService.Skip(r.Context(), uint(id)); err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondSuccess(w, r, http.StatusOK, "已跳过", nil)
+}
+
+// Occurrences 重复待办从本次开始的计划时间，count 默认 10，最多 50
+func (h *TodoHandler) Occurrences(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, codeInvalidID, "无效的ID")
+		return
+	}
+
+	userID := r.Context().Value("user_id").(uint)
+
+	existing, err := h.todoService.GetByID(r.Context(), uint(id))
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	if existing.UserID != userID {
+		respondError(w, r, http.StatusForbidden, codeForbidden, "无权查看此待办事项")
+		return
+	}
+
+	count, _ := strconv.Atoi(r.URL.Query().Get("count"))
+	times, err := h.todoService.Occurrences(r.Context(), uint(id), count)
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	respondSuccess(w, r, http.StatusOK, "获取成功", dto.OccurrencesResponse{Occurrences: times})
+}
+
+// PreviewRecurrence 创建前预览重复规则
+func (h *TodoHandler) PreviewRecurrence(w http.ResponseWriter, r *http.Request) {
+	var req dto.PreviewRecurrenceRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	times, err := h.todoService.PreviewRecurrence(req.RRule, req.Timezone, req.Start, req.Count)
+	if err != nil {
+		respondError(w, r, http.StatusBadRequest, codeBadRequest, "无效的重复规则")
+		return
+	}
+
+	respondSuccess(w, r, http.StatusOK, "获取成功", dto.OccurrencesResponse{Occurrences: times})
 }
 
 func (h *TodoHandler) GetStats(w http.ResponseWriter, r *http.Request) {
 	userID := r.Context().Value("user_id").(uint)
 
-	total, pending, err := h.todoService.GetStats(r.Context(), userID)
+	stats, err := h.todoService.GetStats(r.Context(), userID)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, "获取统计信息失败", err.Error())
+		respondServiceError(w, r, err)
 		return
 	}
 
-	respondSuccess(w, http.StatusOK, "获取成功", dto.TodoStatsResponse{
-		Total:   total,
-		Pending: pending,
-	})
+	resp := dto.TodoStatsResponse{
+		Total:    stats.Total,
+		Pending:  stats.Pending,
+		Projects: []dto.TodoProjectStatsResponse{},
+	}
+	for _, p := range stats.Projects {
+		resp.Projects = append(resp.Projects, dto.TodoProjectStatsResponse{
+			ProjectID: p.ProjectID,
+			Name:      p.Name,
+			Total:     p.Total,
+			Pending:   p.Pending,
+		})
+	}
+	respondSuccess(w, r, http.StatusOK, "获取成功", resp)
 }
 
 func (h *TodoHandler) toTodoResponse(todo *domain.Todo) dto.TodoResponse {
 	return dto.TodoResponse{
-		ID:          todo.ID,
-		Title:       todo.Title,
-		Description: todo.Description,
-		Done:        todo.Done,
-		DueDate:     todo.DueDate,
-		CreatedAt:   todo.CreatedAt,
-		UpdatedAt:   todo.UpdatedAt,
+		ID:           todo.ID,
+		Title:        todo.Title,
+		Description:  todo.Description,
+		Done:         todo.Done,
+		DueDate:      todo.DueDate,
+		Priority:     todo.Priority,
+		ProjectID:    todo.ProjectID,
+		ParentID:     todo.ParentID,
+		SortOrder:    todo.SortOrder,
+		RRule:        todo.RRule,
+		Timezone:     todo.Timezone,
+		SeriesID:     todo.SeriesID,
+		OccurrenceAt: todo.OccurrenceAt,
+		CreatedAt:    todo.CreatedAt,
+		UpdatedAt:    todo.UpdatedAt,
 	}
 }
@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"diary/internal/domain"
+	"diary/internal/handler/dto"
+	"diary/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// 断点续传协议参考 tus 1.0（creation、checksum、expiration、termination 扩展）
+const (
+	tusVersion         = "1.0.0"
+	tusOffsetMediaType = "application/offset+octet-stream"
+	// tus 约定的校验失败状态码
+	statusChecksumMismatch = 460
+)
+
+type UploadHandler struct {
+	uploadService service.UploadService
+}
+
+func NewUploadHandler(uploadService service.UploadService) *UploadHandler {
+	return &UploadHandler{uploadService: uploadService}
+}
+
+// Create 创建上传会话
+// 请求头：Upload-Length（必填）、Upload-Metadata（filename、checksum，值为 base64）
+func (h *UploadHandler) Create(w http.ResponseWriter, r *http.Request) {
+	size, err := strconv.ParseInt(r.Header.Get("Upload-Length"), 10, 64)
+	if err != nil || size <= 0 {
+		respondError(w, r, http.StatusBadRequest, codeBadRequest, "缺少或无效的 Upload-Length")
+		return
+	}
+	meta := parseUploadMetadata(r.Header.Get("Upload-Metadata"))
+	if meta["filename"] == "" {
+		respondError(w, r, http.StatusBadRequest, codeBadRequest, "缺少文件名")
+		return
+	}
+
+	userID := r.Context().Value("user_id").(uint)
+
+	upload, err := h.uploadService.Create(r.Context(), userID, meta["filename"], size, meta["checksum"])
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Tus-Resumable", tusVersion)
+	w.Header().Set("Location", "/api/uploads/"+upload.ID)
+	w.Header().Set("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	respondSuccess(w, r, http.StatusCreated, "创建成功", h.toUploadResponse(upload))
+}
+
+// Head 查询上传进度
+func (h *UploadHandler) Head(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uint)
+
+	upload, err := h.uploadService.Status(r.Context(), userID, chi.URLParam(r, "id"))
+	if err != nil {
+		w.Header().Set("Tus-Resumable", tusVersion)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	h.writeUploadHeaders(w, upload)
+	w.Header().Set("Upload-Length", strconv.FormatInt(upload.Size, 10))
+	w.Header().Set("Cache-Control", "no-store")
+	if upload.ImageID != nil {
+		w.Header().Set("Upload-Image-Id", strconv.FormatUint(uint64(*upload.ImageID), 10))
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// Patch 追加分片
+// 请求头：Upload-Offset（必填）、Upload-Checksum（可选，格式为 "sha256 <base64>"）
+func (h *UploadHandler) Patch(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Content-Type") != tusOffsetMediaType {
+		resp := newErrorResponse(r, http.StatusUnsupportedMediaType, codeUnsupportedMedia, "不支持的 Content-Type")
+		resp.Error = "Content-Type: " + tusOffsetMediaType
+		writeError(w, resp)
+		return
+	}
+	offset, err := strconv.ParseInt(r.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		respondError(w, r, http.StatusBadRequest, codeBadRequest, "缺少或无效的 Upload-Offset")
+		return
+	}
+
+	var checksum *service.ChunkChecksum
+	if v := r.Header.Get("Upload-Checksum"); v != "" {
+		parts := strings.SplitN(v, " ", 2)
+		if len(parts) != 2 {
+			respondError(w, r, http.StatusBadRequest, codeBadRequest, "无效的 Upload-Checksum")
+			return
+		}
+		sum, err := base64.StdEncoding.DecodeString(parts[1])
+		if err != nil {
+			respondError(w, r, http.StatusBadRequest, codeBadRequest, "无效的 Upload-Checksum")
+			return
+		}
+		checksum = &service.ChunkChecksum{Algorithm: parts[0], Sum: sum}
+	}
+
+	userID := r.Context().Value("user_id").(uint)
+
+	upload, image, err := h.uploadService.Append(r.Context(), userID, chi.URLParam(r, "id"), offset, r.Body, checksum)
+	if upload != nil {
+		h.writeUploadHeaders(w, upload)
+	}
+	if err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	if image == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	// 最后一个分片：返回生成的图片
+	respondSuccess(w, r, http.StatusOK, "上传成功", toImageResponse(image))
+}
+
+// Delete 取消上传
+func (h *UploadHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("user_id").(uint)
+
+	if err := h.uploadService.Terminate(r.Context(), userID, chi.URLParam(r, "id")); err != nil {
+		respondServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Tus-Resumable", tusVersion)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *UploadHandler) writeUploadHeaders(w http.ResponseWriter, upload *domain.Upload) {
+	w.Header().Set("Tus-Resumable", tusVersion)
+	w.Header().Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	w.Header().Set("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+}
+
+func (h *UploadHandler) toUploadResponse(upload *domain.Upload) dto.UploadResponse {
+	return dto.UploadResponse{
+		ID:        upload.ID,
+		Filename:  upload.Filename,
+		Size:      upload.Size,
+		Offset:    upload.Offset,
+		ImageID:   upload.ImageID,
+		ExpiresAt: upload.ExpiresAt,
+	}
+}
+
+// parseUploadMetadata 解析 tus 的 Upload-Metadata 头："key base64value,key2 base64value2"
+func parseUploadMetadata(header string) map[string]string {
+	meta := make(map[string]string)
+	for _, pair := range strings.Split(header, ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), " ", 2)
+		if parts[0] == "" {
+			continue
+		}
+		if len(parts) == 1 {
+			meta[parts[0]] = ""
+			continue
+		}
+		value, err := base64.StdEncoding.DecodeString(parts[1])
+		if err != nil {
+			continue
+		}
+		meta[parts[0]] = string(value)
+	}
+	return meta
+}
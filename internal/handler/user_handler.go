@@ -1,7 +1,7 @@
 package handler
 
 import (
-	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -25,30 +25,20 @@ func NewUserHandler(userService service.UserService) *UserHandler {
 // Register 用户注册
 func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
 	var req dto.RegisterRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondError(w, http.StatusBadRequest, "请求格式错误", err.Error())
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
 	user, err := h.userService.Register(r.Context(), req.Username, req.Password)
 	if err != nil {
-		switch err {
-		case service.ErrUserAlreadyExists:
-			respondError(w, http.StatusConflict, "用户已存在", err.Error())
-		case service.ErrInvalidUsername:
-			respondError(w, http.StatusBadRequest, "用户名格式不正确", err.Error())
-		case service.ErrUnableResgister:
-			respondError(w, http.StatusBadRequest, "暂未开放注册", err.Error())
-		default:
-			respondError(w, http.StatusInternalServerError, "注册失败", err.Error())
-		}
+		respondServiceError(w, r, err)
 		return
 	}
 
 	// 注册成功后自动登录
 	_, token, err := h.userService.Login(r.Context(), req.Username, req.Password)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, "注册成功但登录失败", err.Error())
+		respondServiceError(w, r, err)
 		return
 	}
 
@@ -57,25 +47,22 @@ func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
 		Token: token,
 	}
 
-	respondSuccess(w, http.StatusCreated, "注册成功", response)
+	respondSuccess(w, r, http.StatusCreated, "注册成功", response)
 }
 
 // Login 用户登录
 func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 	var req dto.LoginRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondError(w, http.StatusBadRequest, "请求格式错误", err.Error())
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
 	user, token, err := h.userService.Login(r.Context(), req.Username, req.Password)
+	if errors.Is(err, service.ErrUserNotFound) || errors.Is(err, service.ErrInvalidPassword) {
+		err = errInvalidCredentials
+	}
 	if err != nil {
-		switch err {
-		case service.ErrUserNotFound, service.ErrInvalidPassword:
-			respondError(w, http.StatusUnauthorized, "用户名或密码错误", err.Error())
-		default:
-			respondError(w, http.StatusInternalServerError, "登录失败", err.Error())
-		}
+		respondServiceError(w, r, err)
 		return
 	}
 
@@ -84,24 +71,24 @@ func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 		Token: token,
 	}
 
-	respondSuccess(w, http.StatusOK, "登录成功", response)
+	respondSuccess(w, r, http.StatusOK, "登录成功", response)
 }
 
 // GetProfile 获取当前用户信息
 func (h *UserHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
 	userID := h.getUserIDFromContext(r)
 	if userID == 0 {
-		respondError(w, http.StatusUnauthorized, "未授权", "")
+		respondError(w, r, http.StatusUnauthorized, codeUnauthorized, "未授权")
 		return
 	}
 
 	user, err := h.userService.GetByID(r.Context(), userID)
 	if err != nil {
-		respondError(w, http.StatusNotFound, "用户不存在", err.Error())
+		respondServiceError(w, r, err)
 		return
 	}
 
-	respondSuccess(w, http.StatusOK, "获取成功", h.toUserResponse(user))
+	respondSuccess(w, r, http.StatusOK, "获取成功", h.toUserResponse(user))
 }
 
 // GetUserByID 根据ID获取用户
@@ -109,92 +96,78 @@ func (h *UserHandler) GetUserByID(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		respondError(w, http.StatusBadRequest, "无效的用户ID", err.Error())
+		respondError(w, r, http.StatusBadRequest, codeInvalidID, "无效的用户ID")
 		return
 	}
 
 	user, err := h.userService.GetByID(r.Context(), uint(id))
 	if err != nil {
-		respondError(w, http.StatusNotFound, "用户不存在", err.Error())
+		respondServiceError(w, r, err)
 		return
 	}
 
-	respondSuccess(w, http.StatusOK, "获取成功", h.toUserResponse(user))
+	respondSuccess(w, r, http.StatusOK, "获取成功", h.toUserResponse(user))
 }
 
 // UpdateUsername 更新用户名
 func (h *UserHandler) UpdateUsername(w http.ResponseWriter, r *http.Request) {
 	userID := h.getUserIDFromContext(r)
 	if userID == 0 {
-		respondError(w, http.StatusUnauthorized, "未授权", "")
+		respondError(w, r, http.StatusUnauthorized, codeUnauthorized, "未授权")
 		return
 	}
 
 	var req dto.UpdateUsernameRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondError(w, http.StatusBadRequest, "请求格式错误", err.Error())
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
 	err := h.userService.UpdateUsername(r.Context(), userID, req.NewUsername)
 	if err != nil {
-		switch err {
-		case service.ErrUserAlreadyExists:
-			respondError(w, http.StatusConflict, "用户名已被占用", err.Error())
-		case service.ErrInvalidUsername:
-			respondError(w, http.StatusBadRequest, "用户名格式不正确", err.Error())
-		default:
-			respondError(w, http.StatusInternalServerError, "更新失败", err.Error())
-		}
+		respondServiceError(w, r, err)
 		return
 	}
 
-	respondSuccess(w, http.StatusOK, "更新成功", nil)
+	respondSuccess(w, r, http.StatusOK, "更新成功", nil)
 }
 
 // UpdatePassword 更新密码
 func (h *UserHandler) UpdatePassword(w http.ResponseWriter, r *http.Request) {
 	userID := h.getUserIDFromContext(r)
 	if userID == 0 {
-		respondError(w, http.StatusUnauthorized, "未授权", "")
+		respondError(w, r, http.StatusUnauthorized, codeUnauthorized, "未授权")
 		return
 	}
 
 	var req dto.UpdatePasswordRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondError(w, http.StatusBadRequest, "请求格式错误", err.Error())
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
 	err := h.userService.UpdatePassword(r.Context(), userID, req.OldPassword, req.NewPassword)
 	if err != nil {
-		switch err {
-		case service.ErrInvalidPassword:
-			respondError(w, http.StatusUnauthorized, "旧密码错误", err.Error())
-		default:
-			respondError(w, http.StatusInternalServerError, "更新失败", err.Error())
-		}
+		respondServiceError(w, r, err)
 		return
 	}
 
-	respondSuccess(w, http.StatusOK, "密码更新成功", nil)
+	respondSuccess(w, r, http.StatusOK, "密码更新成功", nil)
 }
 
 // DeleteUser 删除用户
 func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 	userID := h.getUserIDFromContext(r)
 	if userID == 0 {
-		respondError(w, http.StatusUnauthorized, "未授权", "")
+		respondError(w, r, http.StatusUnauthorized, codeUnauthorized, "未授权")
 		return
 	}
 
 	err := h.userService.Delete(r.Context(), userID)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, "删除失败", err.Error())
+		respondServiceError(w, r, err)
 		return
 	}
 
-	respondSuccess(w, http.StatusOK, "删除成功", nil)
+	respondSuccess(w, r, http.StatusOK, "删除成功", nil)
 }
 
 // ListUsers 获取用户列表（管理员功能）
@@ -211,7 +184,7 @@ func (h *UserHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
 
 	users, total, err := h.userService.List(r.Context(), page, pageSize)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, "获取用户列表失败", err.Error())
+		respondServiceError(w, r, err)
 		return
 	}
 
@@ -227,16 +200,20 @@ func (h *UserHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
 		PageSize: pageSize,
 	}
 
-	respondSuccess(w, http.StatusOK, "获取成功", response)
+	respondSuccess(w, r, http.StatusOK, "获取成功", response)
 }
 
 // 辅助方法：将领域模型转换为响应DTO
 func (h *UserHandler) toUserResponse(user *domain.User) *dto.UserResponse {
 	return &dto.UserResponse{
-		ID:        user.ID,
-		Username:  user.Username,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
+		ID:            user.ID,
+		Username:      user.Username,
+		DisplayName:   user.DisplayName,
+		Bio:           user.Bio,
+		AvatarImageID: user.AvatarImageID,
+		ProfilePublic: user.ProfilePublic,
+		CreatedAt:     user.CreatedAt,
+		UpdatedAt:     user.UpdatedAt,
 	}
 }
 
@@ -0,0 +1,198 @@
+package i18n
+
+// en 英文译文，键为中文原文
+var en = map[string]string{
+	// 通用
+	"获取成功":        "OK",
+	"创建成功":        "Created",
+	"更新成功":        "Updated",
+	"删除成功":        "Deleted",
+	"保存成功":        "Saved",
+	"搜索成功":        "OK",
+	"排序成功":        "Reordered",
+	"关联成功":        "Linked",
+	"更新状态成功":      "Status updated",
+	"获取统计成功":      "OK",
+	"服务器内部错误":     "Internal server error",
+	"无效的ID":       "Invalid ID",
+	"无效的筛选参数":     "Invalid filter parameters",
+	"搜索关键词不能为空":   "Search keyword is required",
+	"未授权":         "Unauthorized",
+	"未登录":         "Not logged in",
+	"无效的认证头":      "Invalid Authorization header",
+	"登录已失效，请重新登录": "Session expired, please log in again",
+
+	// 请求体和参数校验
+	"请求格式错误":            "Malformed request",
+	"请求参数错误":            "Invalid request parameters",
+	"请求体过大":             "Request body too large",
+	"请求体不能为空":           "Request body is required",
+	"请求体只能包含一个 JSON 对象": "Request body must contain a single JSON object",
+	"不能超过 %d 字节":        "must not exceed %d bytes",
+	"不支持的字段":            "unknown field",
+	"类型应为 %s":           "must be a %s",
+	"字符串":               "string",
+	"布尔值":               "boolean",
+	"数字":                "number",
+	"数组":                "array",
+	"对象":                "object",
+	"不能为空":              "is required",
+	"长度不能少于 %s 个字符":     "must be at least %s characters",
+	"长度不能超过 %s 个字符":     "must be at most %s characters",
+	"长度必须为 %s":          "must be exactly %s characters",
+	"至少 %s 项":           "must contain at least %s items",
+	"最多 %s 项":           "must contain at most %s items",
+	"必须为 %s 项":          "must contain exactly %s items",
+	"不能小于 %s":           "must be at least %s",
+	"不能大于 %s":           "must be at most %s",
+	"必须是以下之一：%s":        "must be one of: %s",
+	"、":                 ", ",
+	"邮箱格式错误":            "must be a valid email address",
+	"URL 格式错误":          "must be a valid URL",
+	"时间格式应为 %s":         "must match the time format %s",
+	"无效的时区":             "invalid time zone",
+	"不满足规则 %s":          "failed the %s rule",
+
+	// 用户
+	"注册成功":         "Registered",
+	"登录成功":         "Logged in",
+	"密码更新成功":       "Password updated",
+	"无效的用户ID":      "Invalid user ID",
+	"用户名或密码错误":     "Invalid username or password",
+	"用户不存在":        "User not found",
+	"用户已存在":        "User already exists",
+	"密码错误":         "Incorrect password",
+	"密码至少需要6位":     "Password must be at least 6 characters",
+	"用户名格式不正确":     "Invalid username",
+	"不允许注册":        "Registration is disabled",
+	"用户主页不存在":      "Profile not found",
+	"头像必须是自己上传的图片": "Avatar must be an image you uploaded",
+
+	// 日记、标签
+	"日记不存在":      "Diary not found",
+	"无权查看此日记":    "You are not allowed to view this diary",
+	"无权修改此日记":    "You are not allowed to edit this diary",
+	"无权删除此日记":    "You are not allowed to delete this diary",
+	"无权操作此日记":    "You are not allowed to modify this diary",
+	"最多只能置顶3篇日记": "At most 3 diaries can be pinned",
+	"已置顶":        "Pinned",
+	"已取消置顶":      "Unpinned",
+	"标签不存在":      "Tag not found",
+	"标签已存在":      "Tag already exists",
+
+	// 待办
+	"待办事项不存在":         "Todo not found",
+	"无权查看此待办事项":       "You are not allowed to view this todo",
+	"无权修改此待办事项":       "You are not allowed to edit this todo",
+	"无权删除此待办事项":       "You are not allowed to delete this todo",
+	"清单不存在":           "Project not found",
+	"无效的优先级":          "Invalid priority",
+	"父任务不存在或不能再添加子任务": "Parent todo not found or cannot have subtasks",
+	"无效的重复规则":         "Invalid recurrence rule",
+	"重复待办需要设置截止时间":    "Recurring todos require a due date",
+	"子任务不能设置重复":       "Subtasks cannot recur",
+	"该待办不是重复待办":       "This todo is not recurring",
+	"已跳过":             "Skipped",
+
+	// 图片和附件
+	"上传成功":      "Uploaded",
+	"图片上传失败":    "Image upload failed",
+	"图片不存在":     "Image not found",
+	"无效的图片ID":   "Invalid image ID",
+	"无效的日记ID":   "Invalid diary ID",
+	"无权查看此图片":   "You are not allowed to view this image",
+	"无权删除此图片":   "You are not allowed to delete this image",
+	"无权操作此图片":   "You are not allowed to modify this image",
+	"无法获取上传文件":  "No file uploaded",
+	"文件过大或格式错误": "File too large or malformed form data",
+	"文件过大":      "File too large",
+	"不支持的文件类型":  "Unsupported file type",
+	"未知的附件类型":   "Unknown attachment type",
+	"链接无效或已过期":  "Link is invalid or expired",
+
+	// 断点续传
+	"上传会话不存在":              "Upload not found",
+	"上传会话已过期":              "Upload expired",
+	"上传偏移量不匹配":             "Upload offset mismatch",
+	"上传已完成":                "Upload already completed",
+	"校验和不匹配":               "Checksum mismatch",
+	"不支持的校验算法":             "Unsupported checksum algorithm",
+	"不支持的 Content-Type":    "Unsupported Content-Type",
+	"缺少或无效的 Upload-Length": "Missing or invalid Upload-Length",
+	"缺少或无效的 Upload-Offset": "Missing or invalid Upload-Offset",
+	"无效的 Upload-Checksum":  "Invalid Upload-Checksum",
+	"缺少文件名":                "Missing filename",
+
+	// 导出、导入
+	"导出任务已创建":                   "Export job created",
+	"导出任务不存在":                   "Export job not found",
+	"导出尚未完成":                    "Export is not ready yet",
+	"无效的导出参数":                   "Invalid export parameters",
+	"没有可导出的日记":                  "No diaries to export",
+	"服务器未配置 PDF 字体，暂不支持 PDF 导出": "PDF export is unavailable: no PDF font configured on the server",
+	"未完成的导出任务过多，请等待已有任务完成":      "Too many unfinished export jobs, please wait for them to finish",
+	"导入完成":                      "Import completed",
+	"预览成功":                      "Preview ready",
+	"不支持的导入格式":                  "Unsupported import format",
+	"无效的导出包":                    "Invalid export archive",
+	"不支持的导出包版本":                 "Unsupported export archive version",
+	"无法识别的导入格式":                 "Unrecognized import format",
+	"没有找到可导入的日记":                "No importable diaries found",
+
+	// 分享链接
+	"分享链接已生成": "Share link created",
+	"分享链接已撤销": "Share link revoked",
+	"分享链接不存在": "Share link not found",
+	"分享链接已过期": "Share link has expired",
+	"分享链接已失效": "Share link has been revoked",
+	"需要访问密码":  "A password is required to view this diary",
+	"访问密码错误":  "Wrong password",
+	"无效的分享设置": "Invalid share settings",
+
+	// 评论、表情回应
+	"评论成功":    "Comment posted",
+	"设置已更新":   "Settings updated",
+	"回应成功":    "Reaction added",
+	"已取消回应":   "Reaction removed",
+	"缺少表情参数":  "The emoji parameter is required",
+	"评论不存在":   "Comment not found",
+	"无权操作此评论": "You are not allowed to modify this comment",
+	"作者已关闭评论": "Comments are disabled on this diary",
+	"无效的表情":   "Invalid emoji",
+
+	// 关注、屏蔽
+	"已关注":    "Followed",
+	"已取消关注":  "Unfollowed",
+	"已屏蔽":    "Blocked",
+	"已取消屏蔽":  "Unblocked",
+	"不能关注自己": "You cannot follow yourself",
+	"不能屏蔽自己": "You cannot block yourself",
+	"无效的游标":  "Invalid cursor",
+
+	// 订阅源
+	"不支持的订阅格式": "Unsupported feed format",
+
+	// 共享日记本
+	"成员已添加":      "Member added",
+	"成员已移除":      "Member removed",
+	"日记本不存在":     "Journal not found",
+	"无权操作此日记本":   "You are not allowed to modify this journal",
+	"无效的成员角色":    "Invalid member role",
+	"该用户已经是成员":   "The user is already a member",
+	"成员不存在":      "Member not found",
+	"所有者不能退出日记本": "The owner cannot leave the journal",
+	"日记本密钥已损坏":   "The journal encryption key is corrupted",
+
+	// 通知、日历
+	"已发送":              "Sent",
+	"已标记为已读":           "Marked as read",
+	"已全部标记为已读":         "All marked as read",
+	"通知不存在":            "Notification not found",
+	"无效的提醒设置":          "Invalid notification settings",
+	"通知发送失败":           "Notification delivery failed",
+	"订阅链接已生成":          "Subscription link generated",
+	"已关闭日历订阅":          "Calendar subscription disabled",
+	"日历订阅不存在":          "Calendar subscription not found",
+	"无效的日历订阅设置":        "Invalid calendar subscription settings",
+	"无效的 iCalendar 文件": "Invalid iCalendar file",
+}
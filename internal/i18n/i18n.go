@@ -0,0 +1,64 @@
+// Package i18n 接口消息的多语言支持。
+// 消息以中文原文作为键（可以是 fmt 格式串），其他语言查表翻译，没有译文时返回中文
+package i18n
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// 支持的语言，默认中文
+const (
+	Chinese = "zh"
+	English = "en"
+)
+
+var catalogs = map[string]map[string]string{
+	English: en,
+}
+
+// FromRequest 根据 Accept-Language 选择语言，取 q 值最高的已支持语言
+func FromRequest(r *http.Request) string {
+	header := r.Header.Get("Accept-Language")
+	if header == "" {
+		return Chinese
+	}
+
+	lang, best := Chinese, 0.0
+	for _, part := range strings.Split(header, ",") {
+		tag, q := parseLanguage(part)
+		if q <= best {
+			continue
+		}
+		switch tag {
+		case Chinese, English:
+			lang, best = tag, q
+		}
+	}
+	return lang
+}
+
+// parseLanguage 解析 "en-US;q=0.8"，返回主语言和 q 值
+func parseLanguage(part string) (string, float64) {
+	tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+	tag, _, _ = strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+
+	q := 1.0
+	if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return tag, 0
+		}
+		q = f
+	}
+	return tag, q
+}
+
+// T 翻译消息
+func T(lang, msg string) string {
+	if s, ok := catalogs[lang][msg]; ok {
+		return s
+	}
+	return msg
+}
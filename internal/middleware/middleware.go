@@ -3,12 +3,17 @@ package middleware
 import (
 	"context"
 	"diary/config"
+	"diary/internal/handler/dto"
+	"diary/internal/i18n"
 	"diary/internal/models"
 	"diary/pkg/utils"
+	"encoding/json"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
+	chimw "github.com/go-chi/chi/v5/middleware"
 	"github.com/golang-jwt/jwt/v5"
 	"gorm.io/gorm"
 )
@@ -22,23 +27,23 @@ func AuthMiddleware(cfg *config.Config, db *gorm.DB) func(http.Handler) http.Han
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			auth := r.Header.Get("Authorization")
 			if auth == "" {
-				next.ServeHTTP(w, r)
+				unauthorized(w, r, "未登录")
 				return
 			}
 			parts := strings.SplitN(auth, " ", 2)
 			if len(parts) != 2 || parts[0] != "Bearer" {
-				http.Error(w, "invalid authorization header", http.StatusUnauthorized)
+				unauthorized(w, r, "无效的认证头")
 				return
 			}
 			tokenStr := parts[1]
 			token, err := utils.ParseJWTToken(tokenStr, cfg)
 			if err != nil || !token.Valid {
-				http.Error(w, "invalid token", http.StatusUnauthorized)
+				unauthorized(w, r, "登录已失效，请重新登录")
 				return
 			}
 			claims, ok := token.Claims.(jwt.MapClaims)
 			if !ok {
-				http.Error(w, "invalid token claims", http.StatusUnauthorized)
+				unauthorized(w, r, "登录已失效，请重新登录")
 				return
 			}
 			sub := claims["sub"]
@@ -49,12 +54,12 @@ func AuthMiddleware(cfg *config.Config, db *gorm.DB) func(http.Handler) http.Han
 			case string:
 				uid, _ = strconv.ParseUint(v, 10, 64)
 			default:
-				http.Error(w, "invalid sub claim", http.StatusUnauthorized)
+				unauthorized(w, r, "登录已失效，请重新登录")
 				return
 			}
 			var u models.User
 			if err := db.First(&u, uid).Error; err != nil {
-				http.Error(w, "user not found", http.StatusUnauthorized)
+				unauthorized(w, r, "用户不存在")
 				return
 			}
 			ctx := context.WithValue(r.Context(), userCtxKey, &u)
@@ -64,6 +69,17 @@ func AuthMiddleware(cfg *config.Config, db *gorm.DB) func(http.Handler) http.Han
 	}
 }
 
+// unauthorized 返回与处理器一致的 JSON 错误
+func unauthorized(w http.ResponseWriter, r *http.Request, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnauthorized)
+	json.NewEncoder(w).Encode(dto.ErrorResponse{
+		Code:      http.StatusUnauthorized,
+		ErrorCode: "UNAUTHORIZED",
+		Message:   i18n.T(i18n.FromRequest(r), message),
+	})
+}
+
 func UserFromContext(r *http.Request) *models.User {
 	u, _ := r.Context().Value(userCtxKey).(*models.User)
 	return u
@@ -72,8 +88,10 @@ func UserFromContext(r *http.Request) *models.User {
 func CORSMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*") // 允许所有来源，生产环境建议指定具体域名
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS, PATCH")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS, PATCH, HEAD")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With, "+
+			"Tus-Resumable, Upload-Length, Upload-Offset, Upload-Metadata, Upload-Checksum, X-Share-Token, X-Share-Password")
+		w.Header().Set("Access-Control-Expose-Headers", "Location, Tus-Resumable, Upload-Offset, Upload-Length, Upload-Expires, Upload-Image-Id")
 		w.Header().Set("Access-Control-Allow-Credentials", "true")
 
 		if r.Method == "OPTIONS" {
@@ -84,3 +102,30 @@ func CORSMiddleware(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// UntrustedContent 用于直接提供用户上传文件的路由：禁止浏览器嗅探类型，
+// 并以沙箱方式打开，即使旧文件的扩展名被识别为 HTML 也不会在本站执行脚本
+func UntrustedContent(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Content-Security-Policy", "sandbox; default-src 'none'")
+		next.ServeHTTP(w, r)
+	})
+}
+
+// TimeoutExcept 与 chi 的 Timeout 相同，但跳过以 prefixes 开头的路径
+// 用于导出、导入等需要长时间流式读写的接口
+func TimeoutExcept(timeout time.Duration, prefixes ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		withTimeout := chimw.Timeout(timeout)(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, prefix := range prefixes {
+				if strings.HasPrefix(r.URL.Path, prefix) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			withTimeout.ServeHTTP(w, r)
+		})
+	}
+}
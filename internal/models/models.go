@@ -16,29 +16,38 @@ type User struct {
 	Todos      []Todo    `json:"-"`
 	IsDeleted  bool      `gorm:"default:false" json:"is_deleted"`
 	DeleteTime time.Time `json:"delete_time,omitempty"`
+	// 公开主页资料，ProfilePublic 开启后才对外展示
+	DisplayName   string `gorm:"size:50" json:"display_name"`
+	Bio           string `gorm:"size:500" json:"bio"`
+	AvatarImageID *uint  `gorm:"null" json:"avatar_image_id,omitempty"`
+	ProfilePublic bool   `gorm:"default:false" json:"profile_public"`
+	// 账号导出包的ID，首次导出时随机生成
+	ArchiveID string `gorm:"size:32" json:"-"`
 }
 
 type Diary struct {
-	ID         uint   `gorm:"primaryKey" json:"id"`
-	UserID     uint   `gorm:"index" json:"user_id"`
-	Title      string `gorm:"size:255;index" json:"title"`
-	Weather    string `gorm:"size:255" json:"weather"`
-	Location   string `gorm:"size:255" json:"location"`
-	Date       time.Time
-	IsPublic   bool                   `gorm:"default:false" json:"is_public"`
-	IsDeleted  bool                   `gorm:"default:false" json:"is_deleted"`
-	DeleteTime time.Time              `json:"delete_time,omitempty"`
-	Mood       string                 `gorm:"size:255" json:"mood"`
-	Music      string                 `gorm:"type:text" json:"music"`
-	IsPinned   bool                   `gorm:"default:false" json:"is_pinned"`
-	ContentEnc []byte                 `gorm:"type:blob" json:"-"`
-	IV         []byte                 `gorm:"type:blob" json:"-"`
-	Summary    string                 `gorm:"size:512;index" json:"summary,omitempty"`     // 明文短摘用于搜索/列表（可为空）
-	Properties map[string]interface{} `gorm:"serializer:json" json:"properties,omitempty"` // 扩展字段 (JSON)
-	CreatedAt  time.Time              `json:"created_at"`
-	UpdatedAt  time.Time              `json:"updated_at"`
-	Images     []Image                `json:"images,omitempty"`
-	Tags       []Tag                  `gorm:"many2many:diaries_tags" json:"tags,omitempty"`
+	ID               uint   `gorm:"primaryKey" json:"id"`
+	UserID           uint   `gorm:"index" json:"user_id"`
+	Title            string `gorm:"size:255;index" json:"title"`
+	Weather          string `gorm:"size:255" json:"weather"`
+	Location         string `gorm:"size:255" json:"location"`
+	Date             time.Time
+	IsPublic         bool                   `gorm:"default:false" json:"is_public"`
+	IsDeleted        bool                   `gorm:"default:false" json:"is_deleted"`
+	DeleteTime       time.Time              `json:"delete_time,omitempty"`
+	Mood             string                 `gorm:"size:255" json:"mood"`
+	Music            string                 `gorm:"type:text" json:"music"`
+	IsPinned         bool                   `gorm:"default:false" json:"is_pinned"`
+	CommentsDisabled bool                   `gorm:"default:false" json:"comments_disabled"`
+	JournalID        *uint                  `gorm:"index;null" json:"journal_id,omitempty"` // 所属的共享日记本
+	ContentEnc       []byte                 `gorm:"type:blob" json:"-"`
+	IV               []byte                 `gorm:"type:blob" json:"-"`
+	Summary          string                 `gorm:"size:512;index" json:"summary,omitempty"`     // 明文短摘用于搜索/列表（可为空）
+	Properties       map[string]interface{} `gorm:"serializer:json" json:"properties,omitempty"` // 扩展字段 (JSON)
+	CreatedAt        time.Time              `json:"created_at"`
+	UpdatedAt        time.Time              `json:"updated_at"`
+	Images           []Image                `json:"images,omitempty"`
+	Tags             []Tag                  `gorm:"many2many:diaries_tags" json:"tags,omitempty"`
 	// Not stored:
 	PlainContent string `gorm:"-" json:"content,omitempty"`      // 解密后的 Markdown 文本（仅 API 输出）
 	ContentHTML  string `gorm:"-" json:"content_html,omitempty"` // 服务端渲染的 HTML（仅 API 输出，可选）
@@ -59,22 +68,232 @@ type Todo struct {
 	Description string     `gorm:"type:text" json:"description"`
 	Done        bool       `json:"done"`
 	DueDate     *time.Time `json:"due_date,omitempty"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
-	IsDeleted   bool       `gorm:"default:false" json:"is_deleted"`
-	DeleteTime  time.Time  `json:"delete_time,omitempty"`
+	Priority    int        `gorm:"default:0;index" json:"priority"` // 0 无 1 低 2 中 3 高
+	ProjectID   *uint      `gorm:"index;null" json:"project_id,omitempty"`
+	ParentID    *uint      `gorm:"index;null" json:"parent_id,omitempty"` // 子任务所属的父任务
+	SortOrder   int        `gorm:"default:0" json:"sort_order"`
+	// 重复规则（iCalendar RRULE），按 Timezone 时区从 RecurStart 开始展开
+	RRule        string     `gorm:"size:255" json:"rrule,omitempty"`
+	Timezone     string     `gorm:"size:64" json:"timezone,omitempty"`
+	RecurStart   *time.Time `json:"recur_start,omitempty"`
+	SeriesID     *uint      `gorm:"index;null" json:"series_id,omitempty"` // 重复系列中第一个待办的ID，第一个待办本身为空
+	OccurrenceAt *time.Time `gorm:"index" json:"occurrence_at,omitempty"`  // 本次按规则计划的时间，修改截止日期不影响后续排期
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	IsDeleted    bool       `gorm:"default:false" json:"is_deleted"`
+	DeleteTime   time.Time  `json:"delete_time,omitempty"`
 }
 
-type Image struct {
+// TodoProject 待办清单，用于对待办事项分组
+type TodoProject struct {
 	ID         uint      `gorm:"primaryKey" json:"id"`
 	UserID     uint      `gorm:"index" json:"user_id"`
-	DiaryID    *uint     `gorm:"index;null" json:"diary_id,omitempty"`
-	Path       string    `gorm:"size:1024" json:"path"`
+	Name       string    `gorm:"size:100" json:"name"`
+	Color      string    `gorm:"size:16" json:"color"`
+	SortOrder  int       `gorm:"default:0" json:"sort_order"`
 	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
 	IsDeleted  bool      `gorm:"default:false" json:"is_deleted"`
 	DeleteTime time.Time `json:"delete_time,omitempty"`
 }
 
+// Image 日记附件，除图片外也保存音频和视频（沿用 images 表以兼容旧数据）
+type Image struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	UserID       uint      `gorm:"index" json:"user_id"`
+	DiaryID      *uint     `gorm:"index;null" json:"diary_id,omitempty"`
+	Path         string    `gorm:"size:1024" json:"path"`
+	Kind         string    `gorm:"size:16;default:image;index" json:"kind"` // image/audio/video
+	MimeType     string    `gorm:"size:100" json:"mime_type"`
+	Size         int64     `json:"size"`
+	DurationMs   int64     `json:"duration_ms,omitempty"`
+	Width        int       `json:"width,omitempty"`
+	Height       int       `json:"height,omitempty"`
+	OriginalName string    `gorm:"size:255" json:"original_name,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	IsDeleted    bool      `gorm:"default:false" json:"is_deleted"`
+	DeleteTime   time.Time `json:"delete_time,omitempty"`
+}
+
+// Upload 断点续传的上传会话，完成后生成 Image
+type Upload struct {
+	ID        string    `gorm:"primaryKey;size:36" json:"id"`
+	UserID    uint      `gorm:"index" json:"user_id"`
+	Filename  string    `gorm:"size:255" json:"filename"`
+	Size      int64     `json:"size"`
+	Offset    int64     `gorm:"column:upload_offset" json:"offset"`
+	Checksum  string    `gorm:"size:128" json:"checksum,omitempty"` // 完整文件的 sha256（hex），可选
+	ImageID   *uint     `gorm:"null" json:"image_id,omitempty"`
+	ExpiresAt time.Time `gorm:"index" json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ImportRecord 记录导入数据的来源ID与本地ID的映射，用于重复导入时去重
+type ImportRecord struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"uniqueIndex:idx_import_source" json:"user_id"`
+	Source    string    `gorm:"size:64;uniqueIndex:idx_import_source" json:"source"` // 来源标识，例如导出包的 archive_id
+	Kind      string    `gorm:"size:16;uniqueIndex:idx_import_source" json:"kind"`   // diary/todo/image
+	SourceID  string    `gorm:"size:64;uniqueIndex:idx_import_source" json:"source_id"`
+	TargetID  uint      `json:"target_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ExportJob 异步导出任务，完成后文件保存在导出目录中供下载
+type ExportJob struct {
+	ID         string     `gorm:"primaryKey;size:36" json:"id"`
+	UserID     uint       `gorm:"index" json:"user_id"`
+	Status     string     `gorm:"size:16;index" json:"status"` // pending/running/done/failed
+	Format     string     `gorm:"size:16" json:"format"`
+	Request    string     `gorm:"type:text" json:"request"` // 导出参数 (JSON)
+	FilePath   string     `gorm:"size:1024" json:"-"`
+	FileName   string     `gorm:"size:255" json:"file_name"`
+	Size       int64      `json:"size"`
+	Error      string     `gorm:"size:512" json:"error,omitempty"`
+	ExpiresAt  time.Time  `gorm:"index" json:"expires_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// ReminderJob 待发送的提醒，持久化以便服务重启后继续发送
+type ReminderJob struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"index" json:"user_id"`
+	Kind      string    `gorm:"size:16;uniqueIndex:idx_reminder_job" json:"kind"` // todo_due/daily_prompt
+	RefID     uint      `gorm:"uniqueIndex:idx_reminder_job" json:"ref_id"`       // todo_due 为待办ID，daily_prompt 为用户ID
+	FireAt    time.Time `gorm:"uniqueIndex:idx_reminder_job" json:"fire_at"`
+	Status    string    `gorm:"size:16;index" json:"status"` // pending/sent/failed/cancelled
+	NextTryAt time.Time `gorm:"index" json:"next_try_at"`    // 失败重试时推迟
+	Attempts  int       `json:"attempts"`
+	LastError string    `gorm:"size:512" json:"last_error,omitempty"`
+	Delivered string    `gorm:"size:64" json:"delivered,omitempty"` // 已发送成功的渠道，逗号分隔
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ReminderWatermark 提醒调度器已经规划到的时间，只有一行，重启后从这里继续规划
+type ReminderWatermark struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	PlannedAt time.Time `json:"planned_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Notification 站内通知
+type Notification struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"index" json:"user_id"`
+	Kind      string     `gorm:"size:32" json:"kind"`
+	Title     string     `gorm:"size:255" json:"title"`
+	Body      string     `gorm:"type:text" json:"body"`
+	Link      string     `gorm:"size:512" json:"link,omitempty"`
+	ReadAt    *time.Time `gorm:"index" json:"read_at,omitempty"`
+	CreatedAt time.Time  `gorm:"index" json:"created_at"`
+}
+
+// NotificationSetting 用户的提醒设置，没有记录时使用默认值
+type NotificationSetting struct {
+	UserID        uint      `gorm:"primaryKey" json:"user_id"`
+	InApp         bool      `json:"in_app"`
+	WebhookURL    string    `gorm:"size:512" json:"webhook_url"`
+	Email         string    `gorm:"size:255" json:"email"`
+	TodoReminders bool      `json:"todo_reminders"`
+	DailyPrompt   bool      `gorm:"index" json:"daily_prompt"`
+	PromptTime    string    `gorm:"size:5" json:"prompt_time"` // HH:MM
+	Timezone      string    `gorm:"size:64" json:"timezone"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// CalendarFeed 日历订阅，只保存令牌的哈希，令牌本身只在创建时返回一次
+type CalendarFeed struct {
+	UserID         uint      `gorm:"primaryKey" json:"user_id"`
+	TokenHash      string    `gorm:"size:64;uniqueIndex" json:"-"`
+	IncludeDiaries bool      `json:"include_diaries"`
+	TodoComponent  string    `gorm:"size:8" json:"todo_component"` // VEVENT/VTODO
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// ShareLink 单篇日记的分享链接，只保存令牌的哈希，令牌本身只在创建时返回一次
+type ShareLink struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	UserID       uint       `gorm:"index" json:"user_id"`
+	DiaryID      uint       `gorm:"index" json:"diary_id"`
+	TokenHash    string     `gorm:"size:64;uniqueIndex" json:"-"`
+	PasswordHash string     `gorm:"size:255" json:"-"` // bcrypt，为空表示不需要密码
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	ViewCount    int64      `gorm:"default:0" json:"view_count"`
+	LastViewedAt *time.Time `json:"last_viewed_at,omitempty"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// Comment 日记评论，ParentID 为回复的评论，RootID 为所在楼层的顶层评论（顶层评论为空）
+type Comment struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	DiaryID    uint       `gorm:"index" json:"diary_id"`
+	UserID     uint       `gorm:"index" json:"user_id"`
+	ParentID   *uint      `gorm:"null" json:"parent_id,omitempty"`
+	RootID     *uint      `gorm:"index;null" json:"root_id,omitempty"`
+	Content    string     `gorm:"type:text" json:"content"` // Markdown
+	EditedAt   *time.Time `json:"edited_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	IsDeleted  bool       `gorm:"default:false" json:"is_deleted"`
+	DeleteTime time.Time  `json:"delete_time,omitempty"`
+}
+
+// Reaction 日记的表情回应
+type Reaction struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	DiaryID   uint      `gorm:"uniqueIndex:idx_reaction" json:"diary_id"`
+	UserID    uint      `gorm:"uniqueIndex:idx_reaction;index" json:"user_id"`
+	Emoji     string    `gorm:"size:32;uniqueIndex:idx_reaction" json:"emoji"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Follow 关注关系
+type Follow struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	FollowerID uint      `gorm:"uniqueIndex:idx_follow" json:"follower_id"`
+	FolloweeID uint      `gorm:"uniqueIndex:idx_follow;index" json:"followee_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Block 屏蔽关系，BlockerID 屏蔽了 BlockedID
+type Block struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	BlockerID uint      `gorm:"uniqueIndex:idx_block" json:"blocker_id"`
+	BlockedID uint      `gorm:"uniqueIndex:idx_block;index" json:"blocked_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Journal 共享日记本。日记本内的日记用日记本自己的数据密钥加密，
+// 数据密钥由服务端主密钥加密后保存在 KeyEnc，所有成员共用
+type Journal struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	OwnerID     uint      `gorm:"index" json:"owner_id"`
+	Name        string    `gorm:"size:100" json:"name"`
+	Description string    `gorm:"size:500" json:"description"`
+	KeyEnc      string    `gorm:"size:255" json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	IsDeleted   bool      `gorm:"default:false" json:"is_deleted"`
+	DeleteTime  time.Time `json:"delete_time,omitempty"`
+}
+
+// JournalMember 日记本成员，Role 为 owner、editor 或 viewer
+type JournalMember struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	JournalID uint      `gorm:"uniqueIndex:idx_journal_member" json:"journal_id"`
+	UserID    uint      `gorm:"uniqueIndex:idx_journal_member;index" json:"user_id"`
+	Role      string    `gorm:"size:16" json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
 func AutoMigrate(db *gorm.DB) error {
-	return db.AutoMigrate(&User{}, &Diary{}, &Tag{}, &Todo{}, &Image{})
+	return db.AutoMigrate(&User{}, &Diary{}, &Tag{}, &Todo{}, &TodoProject{}, &Image{}, &Upload{}, &ImportRecord{}, &ExportJob{},
+		&ReminderJob{}, &ReminderWatermark{}, &Notification{}, &NotificationSetting{}, &CalendarFeed{}, &ShareLink{},
+		&Comment{}, &Reaction{}, &Follow{}, &Block{}, &Journal{}, &JournalMember{})
 }
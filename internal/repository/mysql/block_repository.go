@@ -0,0 +1,67 @@
+package mysql
+
+import (
+	"context"
+
+	"diary/internal/domain"
+	"diary/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type blockRepository struct {
+	db *gorm.DB
+}
+
+func NewBlockRepository(db *gorm.DB) domain.BlockRepository {
+	return &blockRepository{db: db}
+}
+
+func (r *blockRepository) Create(ctx context.Context, block *domain.Block) error {
+	dbBlock := &models.Block{
+		BlockerID: block.BlockerID,
+		BlockedID: block.BlockedID,
+	}
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(dbBlock).Error
+	if err != nil {
+		return err
+	}
+	block.ID = dbBlock.ID
+	block.CreatedAt = dbBlock.CreatedAt
+	return nil
+}
+
+func (r *blockRepository) Delete(ctx context.Context, blockerID, blockedID uint) error {
+	return r.db.WithContext(ctx).
+		Where("blocker_id = ? AND blocked_id = ?", blockerID, blockedID).
+		Delete(&models.Block{}).Error
+}
+
+func (r *blockRepository) Exists(ctx context.Context, blockerID, blockedID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.Block{}).
+		Where("blocker_id = ? AND blocked_id = ?", blockerID, blockedID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *blockRepository) ListByBlockerID(ctx context.Context, blockerID uint) ([]domain.Block, error) {
+	var dbBlocks []models.Block
+	err := r.db.WithContext(ctx).
+		Where("blocker_id = ?", blockerID).
+		Order("created_at DESC, id DESC").
+		Find(&dbBlocks).Error
+	if err != nil {
+		return nil, err
+	}
+
+	blocks := make([]domain.Block, len(dbBlocks))
+	for i, b := range dbBlocks {
+		blocks[i] = domain.Block{ID: b.ID, BlockerID: b.BlockerID, BlockedID: b.BlockedID, CreatedAt: b.CreatedAt}
+	}
+	return blocks, nil
+}
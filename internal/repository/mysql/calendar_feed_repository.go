@@ -0,0 +1,69 @@
+package mysql
+
+import (
+	"context"
+
+	"diary/internal/domain"
+	"diary/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type calendarFeedRepository struct {
+	db *gorm.DB
+}
+
+func NewCalendarFeedRepository(db *gorm.DB) domain.CalendarFeedRepository {
+	return &calendarFeedRepository{db: db}
+}
+
+func (r *calendarFeedRepository) GetByUserID(ctx context.Context, userID uint) (*domain.CalendarFeed, error) {
+	var dbFeed models.CalendarFeed
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&dbFeed).Error; err != nil {
+		return nil, err
+	}
+	return r.toDomain(&dbFeed), nil
+}
+
+func (r *calendarFeedRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*domain.CalendarFeed, error) {
+	var dbFeed models.CalendarFeed
+	if err := r.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&dbFeed).Error; err != nil {
+		return nil, err
+	}
+	return r.toDomain(&dbFeed), nil
+}
+
+func (r *calendarFeedRepository) Save(ctx context.Context, feed *domain.CalendarFeed) error {
+	dbFeed := &models.CalendarFeed{
+		UserID:         feed.UserID,
+		TokenHash:      feed.TokenHash,
+		IncludeDiaries: feed.IncludeDiaries,
+		TodoComponent:  feed.TodoComponent,
+		CreatedAt:      feed.CreatedAt,
+	}
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{UpdateAll: true}).
+		Create(dbFeed).Error
+	if err != nil {
+		return err
+	}
+	feed.CreatedAt = dbFeed.CreatedAt
+	feed.UpdatedAt = dbFeed.UpdatedAt
+	return nil
+}
+
+func (r *calendarFeedRepository) Delete(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&models.CalendarFeed{}).Error
+}
+
+func (r *calendarFeedRepository) toDomain(dbFeed *models.CalendarFeed) *domain.CalendarFeed {
+	return &domain.CalendarFeed{
+		UserID:         dbFeed.UserID,
+		TokenHash:      dbFeed.TokenHash,
+		IncludeDiaries: dbFeed.IncludeDiaries,
+		TodoComponent:  dbFeed.TodoComponent,
+		CreatedAt:      dbFeed.CreatedAt,
+		UpdatedAt:      dbFeed.UpdatedAt,
+	}
+}
@@ -0,0 +1,153 @@
+package mysql
+
+import (
+	"context"
+	"time"
+
+	"diary/internal/domain"
+	"diary/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type commentRepository struct {
+	db *gorm.DB
+}
+
+func NewCommentRepository(db *gorm.DB) domain.CommentRepository {
+	return &commentRepository{db: db}
+}
+
+func (r *commentRepository) Create(ctx context.Context, comment *domain.Comment) error {
+	dbComment := &models.Comment{
+		DiaryID:  comment.DiaryID,
+		UserID:   comment.UserID,
+		ParentID: comment.ParentID,
+		RootID:   comment.RootID,
+		Content:  comment.Content,
+	}
+	if err := r.db.WithContext(ctx).Create(dbComment).Error; err != nil {
+		return err
+	}
+	comment.ID = dbComment.ID
+	comment.CreatedAt = dbComment.CreatedAt
+	comment.UpdatedAt = dbComment.UpdatedAt
+	return nil
+}
+
+func (r *commentRepository) GetByID(ctx context.Context, id uint) (*domain.Comment, error) {
+	var dbComment models.Comment
+	if err := r.db.WithContext(ctx).First(&dbComment, id).Error; err != nil {
+		return nil, err
+	}
+	return r.toDomain(&dbComment), nil
+}
+
+func (r *commentRepository) UpdateContent(ctx context.Context, id uint, content string, editedAt time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&models.Comment{}).
+		Where("id = ? AND is_deleted = ?", id, false).
+		Updates(map[string]interface{}{
+			"content":   content,
+			"edited_at": editedAt,
+		}).Error
+}
+
+func (r *commentRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).
+		Model(&models.Comment{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"is_deleted":  true,
+			"delete_time": time.Now(),
+		}).Error
+}
+
+func (r *commentRepository) ListRoots(ctx context.Context, diaryID uint, offset, limit int) ([]domain.Comment, int64, error) {
+	liveReplies := r.db.Model(&models.Comment{}).
+		Select("root_id").
+		Where("diary_id = ? AND root_id IS NOT NULL AND is_deleted = ?", diaryID, false)
+	query := r.db.WithContext(ctx).
+		Model(&models.Comment{}).
+		Where("diary_id = ? AND root_id IS NULL", diaryID).
+		Where("is_deleted = ? OR id IN (?)", false, liveReplies)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var dbComments []models.Comment
+	err := query.
+		Order("created_at ASC, id ASC").
+		Offset(offset).
+		Limit(limit).
+		Find(&dbComments).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return r.toDomainList(dbComments), total, nil
+}
+
+func (r *commentRepository) ListByRootIDs(ctx context.Context, rootIDs []uint) ([]domain.Comment, error) {
+	if len(rootIDs) == 0 {
+		return nil, nil
+	}
+	var dbComments []models.Comment
+	err := r.db.WithContext(ctx).
+		Where("root_id IN ?", rootIDs).
+		Order("created_at ASC, id ASC").
+		Find(&dbComments).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.toDomainList(dbComments), nil
+}
+
+func (r *commentRepository) CountByDiaryIDs(ctx context.Context, diaryIDs []uint) (map[uint]int64, error) {
+	counts := make(map[uint]int64)
+	if len(diaryIDs) == 0 {
+		return counts, nil
+	}
+	var rows []struct {
+		DiaryID uint
+		Count   int64
+	}
+	err := r.db.WithContext(ctx).
+		Model(&models.Comment{}).
+		Select("diary_id, COUNT(*) AS count").
+		Where("diary_id IN ? AND is_deleted = ?", diaryIDs, false).
+		Group("diary_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		counts[row.DiaryID] = row.Count
+	}
+	return counts, nil
+}
+
+func (r *commentRepository) toDomainList(dbComments []models.Comment) []domain.Comment {
+	comments := make([]domain.Comment, len(dbComments))
+	for i := range dbComments {
+		comments[i] = *r.toDomain(&dbComments[i])
+	}
+	return comments
+}
+
+func (r *commentRepository) toDomain(dbComment *models.Comment) *domain.Comment {
+	return &domain.Comment{
+		ID:         dbComment.ID,
+		DiaryID:    dbComment.DiaryID,
+		UserID:     dbComment.UserID,
+		ParentID:   dbComment.ParentID,
+		RootID:     dbComment.RootID,
+		Content:    dbComment.Content,
+		EditedAt:   dbComment.EditedAt,
+		CreatedAt:  dbComment.CreatedAt,
+		UpdatedAt:  dbComment.UpdatedAt,
+		IsDeleted:  dbComment.IsDeleted,
+		DeleteTime: dbComment.DeleteTime,
+	}
+}
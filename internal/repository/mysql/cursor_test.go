@@ -0,0 +1,180 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"diary/internal/domain"
+
+	gmysql "gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// sqlRecorder 记录 DryRun 模式下生成的 SQL，不需要真实的数据库
+type sqlRecorder struct {
+	statements []string
+}
+
+func (r *sqlRecorder) LogMode(logger.LogLevel) logger.Interface      { return r }
+func (r *sqlRecorder) Info(context.Context, string, ...interface{})  {}
+func (r *sqlRecorder) Warn(context.Context, string, ...interface{})  {}
+func (r *sqlRecorder) Error(context.Context, string, ...interface{}) {}
+func (r *sqlRecorder) Trace(_ context.Context, _ time.Time, fc func() (string, int64), _ error) {
+	sql, _ := fc()
+	r.statements = append(r.statements, sql)
+}
+
+func dryRunDB(t *testing.T) (*gorm.DB, *sqlRecorder) {
+	t.Helper()
+	// sql.Open 不会建立连接
+	conn, err := sql.Open("mysql", "user:pass@tcp(127.0.0.1:1)/diary")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := &sqlRecorder{}
+	db, err := gorm.Open(gmysql.New(gmysql.Config{Conn: conn, SkipInitializeWithVersion: true}),
+		&gorm.Config{DryRun: true, DisableAutomaticPing: true, Logger: rec})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db, rec
+}
+
+// TestKeysetPredicates 游标条件必须与 ORDER BY 的列和方向一致，否则翻页时会重复或漏掉记录
+func TestKeysetPredicates(t *testing.T) {
+	ctx := context.Background()
+	at := time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC)
+	const ts = "'2026-01-02 09:00:00'"
+
+	tests := []struct {
+		name  string
+		run   func(db *gorm.DB)
+		where string
+		order string
+	}{
+		{
+			name: "日记列表按置顶、日期、ID",
+			run: func(db *gorm.DB) {
+				NewDiaryRepository(db).ListByUserIDAfter(ctx, 1, &domain.DiaryCursor{Pinned: true, Date: at, ID: 7}, 21)
+			},
+			where: "(is_pinned < true OR (is_pinned = true AND (date < " + ts + " OR (date = " + ts + " AND id < 7))))",
+			order: "ORDER BY is_pinned DESC, date DESC, id DESC LIMIT 21",
+		},
+		{
+			name: "日记列表第一页",
+			run: func(db *gorm.DB) {
+				NewDiaryRepository(db).ListByUserIDAfter(ctx, 1, nil, 21)
+			},
+			where: "WHERE user_id = 1 AND is_deleted = false ORDER BY",
+			order: "ORDER BY is_pinned DESC, date DESC, id DESC LIMIT 21",
+		},
+		{
+			name: "公开日记",
+			run: func(db *gorm.DB) {
+				NewDiaryRepository(db).ListPublicAfter(ctx, domain.PublicDiaryFilter{UserID: 3}, &domain.DiaryCursor{Date: at, ID: 7}, 21)
+			},
+			where: "(date < " + ts + " OR (date = " + ts + " AND id < 7))",
+			order: "ORDER BY date DESC, id DESC LIMIT 21",
+		},
+		{
+			name: "搜索",
+			run: func(db *gorm.DB) {
+				NewDiaryRepository(db).SearchByUserIDAfter(ctx, 1, "雨", &domain.DiaryCursor{Date: at, ID: 7}, 21)
+			},
+			where: "(date < " + ts + " OR (date = " + ts + " AND id < 7))",
+			order: "ORDER BY date DESC, id DESC LIMIT 21",
+		},
+		{
+			name: "标签",
+			run: func(db *gorm.DB) {
+				NewTagRepository(db).ListAfter(ctx, &domain.TimeCursor{Time: at, ID: 7}, 21)
+			},
+			where: "(created_at < " + ts + " OR (created_at = " + ts + " AND id < 7))",
+			order: "ORDER BY created_at DESC, id DESC LIMIT 21",
+		},
+		{
+			name: "附件",
+			run: func(db *gorm.DB) {
+				NewImageRepository(db).ListByUserIDAndKindAfter(ctx, 1, "audio", &domain.TimeCursor{Time: at, ID: 7}, 21)
+			},
+			where: "(created_at < " + ts + " OR (created_at = " + ts + " AND id < 7))",
+			order: "ORDER BY created_at DESC, id DESC LIMIT 21",
+		},
+		{
+			name: "待办默认按创建时间升序",
+			run: func(db *gorm.DB) {
+				NewTodoRepository(db).ListAfter(ctx, 1, domain.TodoFilter{}, &domain.TodoCursor{Time: &at, ID: 7}, 21)
+			},
+			where: "(created_at > " + ts + " OR (created_at = " + ts + " AND id > 7))",
+			order: "ORDER BY created_at ASC,id ASC LIMIT 21",
+		},
+		{
+			name: "待办按优先级倒序",
+			run: func(db *gorm.DB) {
+				NewTodoRepository(db).ListAfter(ctx, 1, domain.TodoFilter{Sort: "priority", Desc: true}, &domain.TodoCursor{Sort: "priority", Desc: true, Int: 2, ID: 7}, 21)
+			},
+			where: "(priority < 2 OR (priority = 2 AND id < 7))",
+			order: "ORDER BY priority DESC,id DESC LIMIT 21",
+		},
+		{
+			name: "待办按手动排序",
+			run: func(db *gorm.DB) {
+				NewTodoRepository(db).ListAfter(ctx, 1, domain.TodoFilter{Sort: "order"}, &domain.TodoCursor{Sort: "order", Int: 4, ID: 7}, 21)
+			},
+			where: "(sort_order > 4 OR (sort_order = 4 AND id > 7))",
+			order: "ORDER BY sort_order ASC,id ASC LIMIT 21",
+		},
+		{
+			name: "待办按标题",
+			run: func(db *gorm.DB) {
+				NewTodoRepository(db).ListAfter(ctx, 1, domain.TodoFilter{Sort: "title"}, &domain.TodoCursor{Sort: "title", Text: "买菜", ID: 7}, 21)
+			},
+			where: "(title > '买菜' OR (title = '买菜' AND id > 7))",
+			order: "ORDER BY title ASC,id ASC LIMIT 21",
+		},
+		{
+			name: "待办按截止时间，游标有截止时间时后面还有没有截止时间的",
+			run: func(db *gorm.DB) {
+				NewTodoRepository(db).ListAfter(ctx, 1, domain.TodoFilter{Sort: "due_date", Desc: true}, &domain.TodoCursor{Sort: "due_date", Desc: true, Time: &at, ID: 7}, 21)
+			},
+			where: "(due_date IS NULL OR due_date < " + ts + " OR (due_date = " + ts + " AND id < 7))",
+			order: "ORDER BY due_date IS NULL,due_date DESC,id DESC LIMIT 21",
+		},
+		{
+			name: "待办按截止时间，游标已进入没有截止时间的部分",
+			run: func(db *gorm.DB) {
+				NewTodoRepository(db).ListAfter(ctx, 1, domain.TodoFilter{Sort: "due_date"}, &domain.TodoCursor{Sort: "due_date", ID: 7}, 21)
+			},
+			where: "(due_date IS NULL AND id > 7)",
+			order: "ORDER BY due_date IS NULL,due_date ASC,id ASC LIMIT 21",
+		},
+		{
+			name: "到期提醒按截止时间和ID翻页",
+			run: func(db *gorm.DB) {
+				NewTodoRepository(db).ListDueBetween(ctx, at.Add(-time.Hour), at.Add(time.Hour), &domain.TimeCursor{Time: at, ID: 7}, 200)
+			},
+			where: "(due_date > " + ts + " OR (due_date = " + ts + " AND id > 7))",
+			order: "ORDER BY due_date ASC, id ASC LIMIT 200",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, rec := dryRunDB(t)
+			tt.run(db)
+			if len(rec.statements) == 0 {
+				t.Fatal("没有生成 SQL")
+			}
+			stmt := rec.statements[0]
+			if !strings.Contains(stmt, tt.where) {
+				t.Errorf("缺少条件 %s\nSQL: %s", tt.where, stmt)
+			}
+			if !strings.Contains(stmt, tt.order) {
+				t.Errorf("缺少排序 %s\nSQL: %s", tt.order, stmt)
+			}
+		})
+	}
+}
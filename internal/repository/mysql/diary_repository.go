@@ -34,6 +34,7 @@ func (r *diaryRepository) Create(ctx context.Context, diary *domain.Diary) error
 		IV:         diary.IV,
 		Summary:    diary.Summary,
 		Properties: diary.Properties,
+		JournalID:  diary.JournalID,
 		CreatedAt:  time.Now(),
 		UpdatedAt:  time.Now(),
 		IsDeleted:  false,
@@ -128,7 +129,7 @@ func (r *diaryRepository) ListByUserID(ctx context.Context, userID uint, offset,
 		Where("user_id = ? AND is_deleted = ?", userID, false).
 		Offset(offset).
 		Limit(limit).
-		Order("is_pinned DESC, date DESC, created_at DESC").
+		Order("is_pinned DESC, date DESC, id DESC").
 		Find(&dbDiaries).Error
 	if err != nil {
 		return nil, 0, err
@@ -142,22 +143,44 @@ func (r *diaryRepository) ListByUserID(ctx context.Context, userID uint, offset,
 	return diaries, total, nil
 }
 
-func (r *diaryRepository) ListPublic(ctx context.Context, offset, limit int) ([]domain.Diary, int64, error) {
+func (r *diaryRepository) ListByUserIDAfter(ctx context.Context, userID uint, after *domain.DiaryCursor, limit int) ([]domain.Diary, error) {
 	var dbDiaries []models.Diary
-	var total int64
+	query := r.db.WithContext(ctx).
+		Where("user_id = ? AND is_deleted = ?", userID, false)
+	if after != nil {
+		query = query.Where("(is_pinned < ? OR (is_pinned = ? AND (date < ? OR (date = ? AND id < ?))))",
+			after.Pinned, after.Pinned, after.Date, after.Date, after.ID)
+	}
+	err := query.
+		Order("is_pinned DESC, date DESC, id DESC").
+		Limit(limit).
+		Find(&dbDiaries).Error
+	if err != nil {
+		return nil, err
+	}
 
-	if err := r.db.WithContext(ctx).
-		Model(&models.Diary{}).
-		Where("is_public = ? AND is_deleted = ?", true, false).
-		Count(&total).Error; err != nil {
+	diaries := make([]domain.Diary, len(dbDiaries))
+	for i, dbDiary := range dbDiaries {
+		diaries[i] = *r.toDomain(&dbDiary)
+	}
+	return diaries, nil
+}
+
+func (r *diaryRepository) ListPublic(ctx context.Context, filter domain.PublicDiaryFilter, offset, limit int) ([]domain.Diary, int64, error) {
+	query := r.publicQuery(ctx, filter)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
-	err := r.db.WithContext(ctx).
-		Where("is_public = ? AND is_deleted = ?", true, false).
+	var dbDiaries []models.Diary
+	err := query.
+		Preload("Tags", "is_deleted = ?", false).
+		Preload("Images", "is_deleted = ?", false).
 		Offset(offset).
 		Limit(limit).
-		Order("date DESC, created_at DESC").
+		Order("date DESC, id DESC").
 		Find(&dbDiaries).Error
 	if err != nil {
 		return nil, 0, err
@@ -167,10 +190,57 @@ func (r *diaryRepository) ListPublic(ctx context.Context, offset, limit int) ([]
 	for i, dbDiary := range dbDiaries {
 		diaries[i] = *r.toDomain(&dbDiary)
 	}
-
 	return diaries, total, nil
 }
 
+func (r *diaryRepository) ListPublicAfter(ctx context.Context, filter domain.PublicDiaryFilter, after *domain.DiaryCursor, limit int) ([]domain.Diary, error) {
+	query := r.publicQuery(ctx, filter)
+	if after != nil {
+		query = query.Where("(date < ? OR (date = ? AND id < ?))", after.Date, after.Date, after.ID)
+	}
+
+	var dbDiaries []models.Diary
+	err := query.
+		Preload("Tags", "is_deleted = ?", false).
+		Preload("Images", "is_deleted = ?", false).
+		Order("date DESC, id DESC").
+		Limit(limit).
+		Find(&dbDiaries).Error
+	if err != nil {
+		return nil, err
+	}
+
+	diaries := make([]domain.Diary, len(dbDiaries))
+	for i, dbDiary := range dbDiaries {
+		diaries[i] = *r.toDomain(&dbDiary)
+	}
+	return diaries, nil
+}
+
+// publicQuery 公开日记按筛选条件过滤
+func (r *diaryRepository) publicQuery(ctx context.Context, filter domain.PublicDiaryFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).
+		Model(&models.Diary{}).
+		Where("is_public = ? AND is_deleted = ?", true, false)
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.Tag != "" {
+		tagged := r.db.Table("diaries_tags").
+			Select("diaries_tags.diary_id").
+			Joins("JOIN tags ON tags.id = diaries_tags.tag_id").
+			Where("tags.name = ? AND tags.is_deleted = ?", filter.Tag, false)
+		query = query.Where("id IN (?)", tagged)
+	}
+	if filter.DateFrom != nil {
+		query = query.Where("date >= ?", *filter.DateFrom)
+	}
+	if filter.DateTo != nil {
+		query = query.Where("date < ?", *filter.DateTo)
+	}
+	return query
+}
+
 func (r *diaryRepository) SearchByUserID(ctx context.Context, userID uint, keyword string, offset, limit int) ([]domain.Diary, int64, error) {
 	var dbDiaries []models.Diary
 	var total int64
@@ -188,7 +258,60 @@ func (r *diaryRepository) SearchByUserID(ctx context.Context, userID uint, keywo
 		Where("user_id = ? AND is_deleted = ? AND (title LIKE ? OR summary LIKE ?)", userID, false, query, query).
 		Offset(offset).
 		Limit(limit).
-		Order("date DESC").
+		Order("date DESC, id DESC").
+		Find(&dbDiaries).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	diaries := make([]domain.Diary, len(dbDiaries))
+	for i, dbDiary := range dbDiaries {
+		diaries[i] = *r.toDomain(&dbDiary)
+	}
+
+	return diaries, total, nil
+}
+
+func (r *diaryRepository) SearchByUserIDAfter(ctx context.Context, userID uint, keyword string, after *domain.DiaryCursor, limit int) ([]domain.Diary, error) {
+	pattern := "%" + keyword + "%"
+	query := r.db.WithContext(ctx).
+		Where("user_id = ? AND is_deleted = ? AND (title LIKE ? OR summary LIKE ?)", userID, false, pattern, pattern)
+	if after != nil {
+		query = query.Where("(date < ? OR (date = ? AND id < ?))", after.Date, after.Date, after.ID)
+	}
+
+	var dbDiaries []models.Diary
+	err := query.
+		Order("date DESC, id DESC").
+		Limit(limit).
+		Find(&dbDiaries).Error
+	if err != nil {
+		return nil, err
+	}
+
+	diaries := make([]domain.Diary, len(dbDiaries))
+	for i, dbDiary := range dbDiaries {
+		diaries[i] = *r.toDomain(&dbDiary)
+	}
+	return diaries, nil
+}
+
+func (r *diaryRepository) ListByJournalID(ctx context.Context, journalID uint, offset, limit int) ([]domain.Diary, int64, error) {
+	query := r.db.WithContext(ctx).
+		Model(&models.Diary{}).
+		Where("journal_id = ? AND is_deleted = ?", journalID, false)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var dbDiaries []models.Diary
+	err := query.
+		Preload("Tags", "is_deleted = ?", false).
+		Offset(offset).
+		Limit(limit).
+		Order("date DESC, created_at DESC").
 		Find(&dbDiaries).Error
 	if err != nil {
 		return nil, 0, err
@@ -198,13 +321,42 @@ func (r *diaryRepository) SearchByUserID(ctx context.Context, userID uint, keywo
 	for i, dbDiary := range dbDiaries {
 		diaries[i] = *r.toDomain(&dbDiary)
 	}
+	return diaries, total, nil
+}
 
+func (r *diaryRepository) SearchByJournalID(ctx context.Context, journalID uint, keyword string, offset, limit int) ([]domain.Diary, int64, error) {
+	like := "%" + keyword + "%"
+	query := r.db.WithContext(ctx).
+		Model(&models.Diary{}).
+		Where("journal_id = ? AND is_deleted = ? AND (title LIKE ? OR summary LIKE ?)", journalID, false, like, like)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var dbDiaries []models.Diary
+	err := query.
+		Offset(offset).
+		Limit(limit).
+		Order("date DESC").
+		Find(&dbDiaries).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	diaries := make([]domain.Diary, len(dbDiaries))
+	for i, dbDiary := range dbDiaries {
+		diaries[i] = *r.toDomain(&dbDiary)
+	}
 	return diaries, total, nil
 }
 
 func (r *diaryRepository) GetByDateRange(ctx context.Context, userID uint, startDate, endDate time.Time) ([]domain.Diary, error) {
 	var dbDiaries []models.Diary
 	err := r.db.WithContext(ctx).
+		Preload("Images", "is_deleted = ?", false).
+		Preload("Tags", "is_deleted = ?", false).
 		Where("user_id = ? AND is_deleted = ? AND date BETWEEN ? AND ?", userID, false, startDate, endDate).
 		Order("date ASC").
 		Find(&dbDiaries).Error
@@ -222,6 +374,8 @@ func (r *diaryRepository) GetByDateRange(ctx context.Context, userID uint, start
 func (r *diaryRepository) GetByIDs(ctx context.Context, userID uint, ids []uint) ([]domain.Diary, error) {
 	var dbDiaries []models.Diary
 	err := r.db.WithContext(ctx).
+		Preload("Images", "is_deleted = ?", false).
+		Preload("Tags", "is_deleted = ?", false).
 		Where("user_id = ? AND is_deleted = ? AND id IN ?", userID, false, ids).
 		Order("date ASC").
 		Find(&dbDiaries).Error
@@ -236,6 +390,56 @@ func (r *diaryRepository) GetByIDs(ctx context.Context, userID uint, ids []uint)
 	return diaries, nil
 }
 
+func (r *diaryRepository) ListByDateRangeAfter(ctx context.Context, userID uint, startDate, endDate time.Time, after *domain.DiaryCursor, limit int) ([]domain.Diary, error) {
+	var dbDiaries []models.Diary
+	query := r.db.WithContext(ctx).
+		Preload("Images", "is_deleted = ?", false).
+		Preload("Tags", "is_deleted = ?", false).
+		Where("user_id = ? AND is_deleted = ? AND date BETWEEN ? AND ?", userID, false, startDate, endDate)
+	if after != nil {
+		query = query.Where("(date > ? OR (date = ? AND id > ?))", after.Date, after.Date, after.ID)
+	}
+	err := query.
+		Order("date ASC, id ASC").
+		Limit(limit).
+		Find(&dbDiaries).Error
+	if err != nil {
+		return nil, err
+	}
+
+	diaries := make([]domain.Diary, len(dbDiaries))
+	for i, dbDiary := range dbDiaries {
+		diaries[i] = *r.toDomain(&dbDiary)
+	}
+	return diaries, nil
+}
+
+func (r *diaryRepository) ListFeed(ctx context.Context, followerID uint, before *domain.DiaryCursor, limit int) ([]domain.Diary, error) {
+	followees := r.db.Model(&models.Follow{}).
+		Select("followee_id").
+		Where("follower_id = ?", followerID)
+	var dbDiaries []models.Diary
+	query := r.db.WithContext(ctx).
+		Preload("Tags", "is_deleted = ?", false).
+		Where("is_public = ? AND is_deleted = ? AND user_id IN (?)", true, false, followees)
+	if before != nil {
+		query = query.Where("(date < ? OR (date = ? AND id < ?))", before.Date, before.Date, before.ID)
+	}
+	err := query.
+		Order("date DESC, id DESC").
+		Limit(limit).
+		Find(&dbDiaries).Error
+	if err != nil {
+		return nil, err
+	}
+
+	diaries := make([]domain.Diary, len(dbDiaries))
+	for i, dbDiary := range dbDiaries {
+		diaries[i] = *r.toDomain(&dbDiary)
+	}
+	return diaries, nil
+}
+
 func (r *diaryRepository) GetByTags(ctx context.Context, userID uint, tagIDs []uint, offset, limit int) ([]domain.Diary, int64, error) {
 	// 这是一个复杂查询，需要关联 diaries_tags 表
 	// SELECT d.* FROM diaries d
@@ -418,6 +622,13 @@ func (r *diaryRepository) UpdatePinStatus(ctx context.Context, id uint, isPinned
 		Update("is_pinned", isPinned).Error
 }
 
+func (r *diaryRepository) UpdateCommentsDisabled(ctx context.Context, id uint, disabled bool) error {
+	return r.db.WithContext(ctx).
+		Model(&models.Diary{}).
+		Where("id = ?", id).
+		Update("comments_disabled", disabled).Error
+}
+
 func (r *diaryRepository) CountPinned(ctx context.Context, userID uint) (int64, error) {
 	var count int64
 	err := r.db.WithContext(ctx).
@@ -455,37 +666,32 @@ func (r *diaryRepository) GetMoodStats(ctx context.Context, userID uint) (map[st
 
 func (r *diaryRepository) toDomain(dbDiary *models.Diary) *domain.Diary {
 	diary := &domain.Diary{
-		ID:         dbDiary.ID,
-		UserID:     dbDiary.UserID,
-		Title:      dbDiary.Title,
-		Weather:    dbDiary.Weather,
-		Location:   dbDiary.Location,
-		Date:       dbDiary.Date,
-		IsPublic:   dbDiary.IsPublic,
-		Mood:       dbDiary.Mood,
-		Music:      dbDiary.Music,
-		IsPinned:   dbDiary.IsPinned,
-		ContentEnc: dbDiary.ContentEnc,
-		IV:         dbDiary.IV,
-		Summary:    dbDiary.Summary,
-		Properties: dbDiary.Properties,
-		CreatedAt:  dbDiary.CreatedAt,
-		UpdatedAt:  dbDiary.UpdatedAt,
-		IsDeleted:  dbDiary.IsDeleted,
-		DeleteTime: dbDiary.DeleteTime,
+		ID:               dbDiary.ID,
+		UserID:           dbDiary.UserID,
+		Title:            dbDiary.Title,
+		Weather:          dbDiary.Weather,
+		Location:         dbDiary.Location,
+		Date:             dbDiary.Date,
+		IsPublic:         dbDiary.IsPublic,
+		Mood:             dbDiary.Mood,
+		Music:            dbDiary.Music,
+		IsPinned:         dbDiary.IsPinned,
+		ContentEnc:       dbDiary.ContentEnc,
+		IV:               dbDiary.IV,
+		Summary:          dbDiary.Summary,
+		Properties:       dbDiary.Properties,
+		CreatedAt:        dbDiary.CreatedAt,
+		UpdatedAt:        dbDiary.UpdatedAt,
+		IsDeleted:        dbDiary.IsDeleted,
+		DeleteTime:       dbDiary.DeleteTime,
+		CommentsDisabled: dbDiary.CommentsDisabled,
+		JournalID:        dbDiary.JournalID,
 	}
 
 	if len(dbDiary.Images) > 0 {
 		diary.Images = make([]domain.Image, len(dbDiary.Images))
 		for i, img := range dbDiary.Images {
-			diary.Images[i] = domain.Image{
-				ID:        img.ID,
-				UserID:    img.UserID,
-				DiaryID:   img.DiaryID,
-				Path:      img.Path,
-				CreatedAt: img.CreatedAt,
-				IsDeleted: img.IsDeleted,
-			}
+			diary.Images[i] = *imageToDomain(&img)
 		}
 	}
 
@@ -0,0 +1,123 @@
+package mysql
+
+import (
+	"context"
+	"time"
+
+	"diary/internal/domain"
+	"diary/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type exportJobRepository struct {
+	db *gorm.DB
+}
+
+func NewExportJobRepository(db *gorm.DB) domain.ExportJobRepository {
+	return &exportJobRepository{db: db}
+}
+
+func (r *exportJobRepository) Create(ctx context.Context, job *domain.ExportJob) error {
+	dbJob := &models.ExportJob{
+		ID:        job.ID,
+		UserID:    job.UserID,
+		Status:    job.Status,
+		Format:    job.Format,
+		Request:   job.Request,
+		ExpiresAt: job.ExpiresAt,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	if err := r.db.WithContext(ctx).Create(dbJob).Error; err != nil {
+		return err
+	}
+
+	job.CreatedAt = dbJob.CreatedAt
+	job.UpdatedAt = dbJob.UpdatedAt
+	return nil
+}
+
+func (r *exportJobRepository) GetByID(ctx context.Context, id string) (*domain.ExportJob, error) {
+	var dbJob models.ExportJob
+	err := r.db.WithContext(ctx).
+		Where("id = ?", id).
+		First(&dbJob).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.toDomain(&dbJob), nil
+}
+
+func (r *exportJobRepository) Update(ctx context.Context, job *domain.ExportJob) error {
+	job.UpdatedAt = time.Now()
+	return r.db.WithContext(ctx).
+		Model(&models.ExportJob{}).
+		Where("id = ?", job.ID).
+		Updates(map[string]interface{}{
+			"status":      job.Status,
+			"file_path":   job.FilePath,
+			"file_name":   job.FileName,
+			"size":        job.Size,
+			"error":       job.Error,
+			"expires_at":  job.ExpiresAt,
+			"finished_at": job.FinishedAt,
+			"updated_at":  job.UpdatedAt,
+		}).Error
+}
+
+func (r *exportJobRepository) FailUnfinished(ctx context.Context, reason string) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.ExportJob{}).
+		Where("status IN ?", []string{domain.ExportJobPending, domain.ExportJobRunning}).
+		Updates(map[string]interface{}{
+			"status":      domain.ExportJobFailed,
+			"error":       reason,
+			"finished_at": time.Now(),
+			"updated_at":  time.Now(),
+		})
+	return result.RowsAffected, result.Error
+}
+
+func (r *exportJobRepository) ListExpired(ctx context.Context, before time.Time, limit int) ([]domain.ExportJob, error) {
+	var dbJobs []models.ExportJob
+	err := r.db.WithContext(ctx).
+		Where("expires_at < ?", before).
+		Order("expires_at ASC").
+		Limit(limit).
+		Find(&dbJobs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]domain.ExportJob, len(dbJobs))
+	for i, dbJob := range dbJobs {
+		jobs[i] = *r.toDomain(&dbJob)
+	}
+	return jobs, nil
+}
+
+func (r *exportJobRepository) Delete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).
+		Where("id = ?", id).
+		Delete(&models.ExportJob{}).Error
+}
+
+func (r *exportJobRepository) toDomain(dbJob *models.ExportJob) *domain.ExportJob {
+	return &domain.ExportJob{
+		ID:         dbJob.ID,
+		UserID:     dbJob.UserID,
+		Status:     dbJob.Status,
+		Format:     dbJob.Format,
+		Request:    dbJob.Request,
+		FilePath:   dbJob.FilePath,
+		FileName:   dbJob.FileName,
+		Size:       dbJob.Size,
+		Error:      dbJob.Error,
+		ExpiresAt:  dbJob.ExpiresAt,
+		FinishedAt: dbJob.FinishedAt,
+		CreatedAt:  dbJob.CreatedAt,
+		UpdatedAt:  dbJob.UpdatedAt,
+	}
+}
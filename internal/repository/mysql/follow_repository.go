@@ -0,0 +1,71 @@
+package mysql
+
+import (
+	"context"
+
+	"diary/internal/domain"
+	"diary/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type followRepository struct {
+	db *gorm.DB
+}
+
+func NewFollowRepository(db *gorm.DB) domain.FollowRepository {
+	return &followRepository{db: db}
+}
+
+func (r *followRepository) Create(ctx context.Context, follow *domain.Follow) error {
+	dbFollow := &models.Follow{
+		FollowerID: follow.FollowerID,
+		FolloweeID: follow.FolloweeID,
+	}
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(dbFollow).Error
+	if err != nil {
+		return err
+	}
+	follow.ID = dbFollow.ID
+	follow.CreatedAt = dbFollow.CreatedAt
+	return nil
+}
+
+func (r *followRepository) Delete(ctx context.Context, followerID, followeeID uint) error {
+	return r.db.WithContext(ctx).
+		Where("follower_id = ? AND followee_id = ?", followerID, followeeID).
+		Delete(&models.Follow{}).Error
+}
+
+func (r *followRepository) Exists(ctx context.Context, followerID, followeeID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.Follow{}).
+		Where("follower_id = ? AND followee_id = ?", followerID, followeeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *followRepository) CountByUserID(ctx context.Context, userID uint) (int64, int64, error) {
+	var followers, following int64
+	err := r.db.WithContext(ctx).
+		Model(&models.Follow{}).
+		Joins("JOIN users ON users.id = follows.follower_id AND users.is_deleted = ?", false).
+		Where("follows.followee_id = ?", userID).
+		Count(&followers).Error
+	if err != nil {
+		return 0, 0, err
+	}
+	err = r.db.WithContext(ctx).
+		Model(&models.Follow{}).
+		Joins("JOIN users ON users.id = follows.followee_id AND users.is_deleted = ?", false).
+		Where("follows.follower_id = ?", userID).
+		Count(&following).Error
+	if err != nil {
+		return 0, 0, err
+	}
+	return followers, following, nil
+}
@@ -18,12 +18,23 @@ func NewImageRepository(db *gorm.DB) domain.ImageRepository {
 }
 
 func (r *imageRepository) Create(ctx context.Context, image *domain.Image) error {
+	kind := image.Kind
+	if kind == "" {
+		kind = domain.AttachmentKindImage
+	}
 	dbImage := &models.Image{
-		UserID:    image.UserID,
-		DiaryID:   image.DiaryID,
-		Path:      image.Path,
-		CreatedAt: time.Now(),
-		IsDeleted: false,
+		UserID:       image.UserID,
+		DiaryID:      image.DiaryID,
+		Path:         image.Path,
+		Kind:         kind,
+		MimeType:     image.MimeType,
+		Size:         image.Size,
+		DurationMs:   image.Duration.Milliseconds(),
+		Width:        image.Width,
+		Height:       image.Height,
+		OriginalName: image.OriginalName,
+		CreatedAt:    time.Now(),
+		IsDeleted:    false,
 	}
 
 	if err := r.db.WithContext(ctx).Create(dbImage).Error; err != nil {
@@ -31,6 +42,7 @@ func (r *imageRepository) Create(ctx context.Context, image *domain.Image) error
 	}
 
 	image.ID = dbImage.ID
+	image.Kind = dbImage.Kind
 	image.CreatedAt = dbImage.CreatedAt
 	return nil
 }
@@ -46,6 +58,22 @@ func (r *imageRepository) GetByID(ctx context.Context, id uint) (*domain.Image,
 	return r.toDomain(&dbImage), nil
 }
 
+func (r *imageRepository) GetByIDs(ctx context.Context, ids []uint) ([]domain.Image, error) {
+	var dbImages []models.Image
+	err := r.db.WithContext(ctx).
+		Where("id IN ? AND is_deleted = ?", ids, false).
+		Find(&dbImages).Error
+	if err != nil {
+		return nil, err
+	}
+
+	images := make([]domain.Image, len(dbImages))
+	for i, dbImage := range dbImages {
+		images[i] = *r.toDomain(&dbImage)
+	}
+	return images, nil
+}
+
 func (r *imageRepository) Update(ctx context.Context, image *domain.Image) error {
 	return r.db.WithContext(ctx).
 		Model(&models.Image{}).
@@ -95,6 +123,64 @@ func (r *imageRepository) ListByUserID(ctx context.Context, userID uint, offset,
 	return images, total, nil
 }
 
+func (r *imageRepository) ListByUserIDAndKind(ctx context.Context, userID uint, kind string, offset, limit int) ([]domain.Image, int64, error) {
+	var dbImages []models.Image
+	var total int64
+
+	query := r.db.WithContext(ctx).
+		Model(&models.Image{}).
+		Where("user_id = ? AND is_deleted = ?", userID, false)
+	if kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Offset(offset).
+		Limit(limit).
+		Order("created_at DESC, id DESC").
+		Find(&dbImages).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	images := make([]domain.Image, len(dbImages))
+	for i, dbImage := range dbImages {
+		images[i] = *r.toDomain(&dbImage)
+	}
+
+	return images, total, nil
+}
+
+func (r *imageRepository) ListByUserIDAndKindAfter(ctx context.Context, userID uint, kind string, after *domain.TimeCursor, limit int) ([]domain.Image, error) {
+	query := r.db.WithContext(ctx).
+		Where("user_id = ? AND is_deleted = ?", userID, false)
+	if kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+	if after != nil {
+		query = query.Where("(created_at < ? OR (created_at = ? AND id < ?))", after.Time, after.Time, after.ID)
+	}
+
+	var dbImages []models.Image
+	err := query.
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Find(&dbImages).Error
+	if err != nil {
+		return nil, err
+	}
+
+	images := make([]domain.Image, len(dbImages))
+	for i, dbImage := range dbImages {
+		images[i] = *r.toDomain(&dbImage)
+	}
+	return images, nil
+}
+
 func (r *imageRepository) ListByDiaryID(ctx context.Context, diaryID uint) ([]domain.Image, error) {
 	var dbImages []models.Image
 	err := r.db.WithContext(ctx).
@@ -173,14 +259,99 @@ func (r *imageRepository) DeleteByPath(ctx context.Context, path string) error {
 		}).Error
 }
 
+func (r *imageRepository) ListExpiredUnattached(ctx context.Context, before time.Time, afterID uint, limit int) ([]domain.Image, error) {
+	var dbImages []models.Image
+	err := r.db.WithContext(ctx).
+		Where("id > ? AND diary_id IS NULL AND is_deleted = ? AND created_at < ?", afterID, false, before).
+		Where("id NOT IN (?)", r.db.Model(&models.User{}).Select("avatar_image_id").Where("avatar_image_id IS NOT NULL")).
+		Order("id ASC").
+		Limit(limit).
+		Find(&dbImages).Error
+	if err != nil {
+		return nil, err
+	}
+
+	images := make([]domain.Image, len(dbImages))
+	for i, dbImage := range dbImages {
+		images[i] = *r.toDomain(&dbImage)
+	}
+	return images, nil
+}
+
+func (r *imageRepository) ListDeletedBefore(ctx context.Context, before time.Time, afterID uint, limit int) ([]domain.Image, error) {
+	var dbImages []models.Image
+	err := r.db.WithContext(ctx).
+		Where("id > ? AND is_deleted = ? AND delete_time < ?", afterID, true, before).
+		Order("id ASC").
+		Limit(limit).
+		Find(&dbImages).Error
+	if err != nil {
+		return nil, err
+	}
+
+	images := make([]domain.Image, len(dbImages))
+	for i, dbImage := range dbImages {
+		images[i] = *r.toDomain(&dbImage)
+	}
+	return images, nil
+}
+
+func (r *imageRepository) ListActiveAfterID(ctx context.Context, afterID uint, limit int) ([]domain.Image, error) {
+	var dbImages []models.Image
+	err := r.db.WithContext(ctx).
+		Where("id > ? AND is_deleted = ?", afterID, false).
+		Order("id ASC").
+		Limit(limit).
+		Find(&dbImages).Error
+	if err != nil {
+		return nil, err
+	}
+
+	images := make([]domain.Image, len(dbImages))
+	for i, dbImage := range dbImages {
+		images[i] = *r.toDomain(&dbImage)
+	}
+	return images, nil
+}
+
+func (r *imageRepository) ListAllPaths(ctx context.Context) ([]string, error) {
+	var paths []string
+	err := r.db.WithContext(ctx).
+		Model(&models.Image{}).
+		Pluck("path", &paths).Error
+	return paths, err
+}
+
+func (r *imageRepository) HardDelete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).
+		Where("id = ?", id).
+		Delete(&models.Image{}).Error
+}
+
 func (r *imageRepository) toDomain(dbImage *models.Image) *domain.Image {
+	return imageToDomain(dbImage)
+}
+
+// imageToDomain 供其他仓储（如预加载日记附件）复用
+func imageToDomain(dbImage *models.Image) *domain.Image {
+	kind := dbImage.Kind
+	if kind == "" {
+		kind = domain.AttachmentKindImage
+	}
 	return &domain.Image{
-		ID:         dbImage.ID,
-		UserID:     dbImage.UserID,
-		DiaryID:    dbImage.DiaryID,
-		Path:       dbImage.Path,
-		CreatedAt:  dbImage.CreatedAt,
-		IsDeleted:  dbImage.IsDeleted,
-		DeleteTime: dbImage.DeleteTime,
+		ID:           dbImage.ID,
+		UserID:       dbImage.UserID,
+		DiaryID:      dbImage.DiaryID,
+		Path:         dbImage.Path,
+		Kind:         kind,
+		MimeType:     dbImage.MimeType,
+		Size:         dbImage.Size,
+		Duration:     time.Duration(dbImage.DurationMs) * time.Millisecond,
+		Width:        dbImage.Width,
+		Height:       dbImage.Height,
+		OriginalName: dbImage.OriginalName,
+		CreatedAt:    dbImage.CreatedAt,
+		IsDeleted:    dbImage.IsDeleted,
+		DeleteTime:   dbImage.DeleteTime,
 	}
 }
@@ -0,0 +1,55 @@
+package mysql
+
+import (
+	"context"
+	"time"
+
+	"diary/internal/domain"
+	"diary/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type importRecordRepository struct {
+	db *gorm.DB
+}
+
+func NewImportRecordRepository(db *gorm.DB) domain.ImportRecordRepository {
+	return &importRecordRepository{db: db}
+}
+
+func (r *importRecordRepository) Get(ctx context.Context, userID uint, source, kind, sourceID string) (*domain.ImportRecord, error) {
+	var dbRecord models.ImportRecord
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND source = ? AND kind = ? AND source_id = ?", userID, source, kind, sourceID).
+		First(&dbRecord).Error
+	if err != nil {
+		return nil, err
+	}
+	return &domain.ImportRecord{
+		ID:        dbRecord.ID,
+		UserID:    dbRecord.UserID,
+		Source:    dbRecord.Source,
+		Kind:      dbRecord.Kind,
+		SourceID:  dbRecord.SourceID,
+		TargetID:  dbRecord.TargetID,
+		CreatedAt: dbRecord.CreatedAt,
+	}, nil
+}
+
+func (r *importRecordRepository) Create(ctx context.Context, record *domain.ImportRecord) error {
+	dbRecord := &models.ImportRecord{
+		UserID:    record.UserID,
+		Source:    record.Source,
+		Kind:      record.Kind,
+		SourceID:  record.SourceID,
+		TargetID:  record.TargetID,
+		CreatedAt: time.Now(),
+	}
+	if err := r.db.WithContext(ctx).Create(dbRecord).Error; err != nil {
+		return err
+	}
+	record.ID = dbRecord.ID
+	record.CreatedAt = dbRecord.CreatedAt
+	return nil
+}
@@ -0,0 +1,199 @@
+package mysql
+
+import (
+	"context"
+	"time"
+
+	"diary/internal/domain"
+	"diary/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type journalRepository struct {
+	db *gorm.DB
+}
+
+func NewJournalRepository(db *gorm.DB) domain.JournalRepository {
+	return &journalRepository{db: db}
+}
+
+func (r *journalRepository) Create(ctx context.Context, journal *domain.Journal) error {
+	dbJournal := &models.Journal{
+		OwnerID:     journal.OwnerID,
+		Name:        journal.Name,
+		Description: journal.Description,
+		KeyEnc:      journal.KeyEnc,
+	}
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(dbJournal).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.JournalMember{
+			JournalID: dbJournal.ID,
+			UserID:    journal.OwnerID,
+			Role:      domain.JournalRoleOwner,
+		}).Error
+	})
+	if err != nil {
+		return err
+	}
+	journal.ID = dbJournal.ID
+	journal.CreatedAt = dbJournal.CreatedAt
+	journal.UpdatedAt = dbJournal.UpdatedAt
+	journal.Role = domain.JournalRoleOwner
+	return nil
+}
+
+func (r *journalRepository) GetByID(ctx context.Context, id uint) (*domain.Journal, error) {
+	var dbJournal models.Journal
+	err := r.db.WithContext(ctx).
+		Where("id = ? AND is_deleted = ?", id, false).
+		First(&dbJournal).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.toDomain(&dbJournal), nil
+}
+
+func (r *journalRepository) GetKeyEnc(ctx context.Context, id uint) (string, error) {
+	var dbJournal models.Journal
+	err := r.db.WithContext(ctx).
+		Select("key_enc").
+		First(&dbJournal, id).Error
+	return dbJournal.KeyEnc, err
+}
+
+func (r *journalRepository) Update(ctx context.Context, journal *domain.Journal) error {
+	return r.db.WithContext(ctx).
+		Model(&models.Journal{}).
+		Where("id = ? AND is_deleted = ?", journal.ID, false).
+		Updates(map[string]interface{}{
+			"name":        journal.Name,
+			"description": journal.Description,
+		}).Error
+}
+
+func (r *journalRepository) Delete(ctx context.Context, id uint) error {
+	now := time.Now()
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.Diary{}).
+			Where("journal_id = ? AND is_deleted = ?", id, false).
+			Updates(map[string]interface{}{
+				"is_deleted":  true,
+				"delete_time": now,
+			}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&models.Journal{}).
+			Where("id = ?", id).
+			Updates(map[string]interface{}{
+				"is_deleted":  true,
+				"delete_time": now,
+			}).Error
+	})
+}
+
+func (r *journalRepository) ListByUserID(ctx context.Context, userID uint) ([]domain.Journal, error) {
+	var rows []struct {
+		models.Journal
+		Role string
+	}
+	err := r.db.WithContext(ctx).
+		Model(&models.Journal{}).
+		Select("journals.*, journal_members.role").
+		Joins("JOIN journal_members ON journal_members.journal_id = journals.id").
+		Where("journal_members.user_id = ? AND journals.is_deleted = ?", userID, false).
+		Order("journals.created_at DESC, journals.id DESC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	journals := make([]domain.Journal, len(rows))
+	for i := range rows {
+		journals[i] = *r.toDomain(&rows[i].Journal)
+		journals[i].Role = rows[i].Role
+	}
+	return journals, nil
+}
+
+func (r *journalRepository) GetMember(ctx context.Context, journalID, userID uint) (*domain.JournalMember, error) {
+	var dbMember models.JournalMember
+	err := r.db.WithContext(ctx).
+		Where("journal_id = ? AND user_id = ?", journalID, userID).
+		First(&dbMember).Error
+	if err != nil {
+		return nil, err
+	}
+	return memberToDomain(&dbMember), nil
+}
+
+func (r *journalRepository) ListMembers(ctx context.Context, journalID uint) ([]domain.JournalMember, error) {
+	var dbMembers []models.JournalMember
+	err := r.db.WithContext(ctx).
+		Where("journal_id = ?", journalID).
+		Order("created_at ASC, id ASC").
+		Find(&dbMembers).Error
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]domain.JournalMember, len(dbMembers))
+	for i := range dbMembers {
+		members[i] = *memberToDomain(&dbMembers[i])
+	}
+	return members, nil
+}
+
+func (r *journalRepository) AddMember(ctx context.Context, member *domain.JournalMember) error {
+	dbMember := &models.JournalMember{
+		JournalID: member.JournalID,
+		UserID:    member.UserID,
+		Role:      member.Role,
+	}
+	if err := r.db.WithContext(ctx).Create(dbMember).Error; err != nil {
+		return err
+	}
+	member.ID = dbMember.ID
+	member.CreatedAt = dbMember.CreatedAt
+	return nil
+}
+
+func (r *journalRepository) UpdateMemberRole(ctx context.Context, journalID, userID uint, role string) error {
+	return r.db.WithContext(ctx).
+		Model(&models.JournalMember{}).
+		Where("journal_id = ? AND user_id = ?", journalID, userID).
+		Update("role", role).Error
+}
+
+func (r *journalRepository) RemoveMember(ctx context.Context, journalID, userID uint) error {
+	return r.db.WithContext(ctx).
+		Where("journal_id = ? AND user_id = ?", journalID, userID).
+		Delete(&models.JournalMember{}).Error
+}
+
+func (r *journalRepository) toDomain(dbJournal *models.Journal) *domain.Journal {
+	return &domain.Journal{
+		ID:          dbJournal.ID,
+		OwnerID:     dbJournal.OwnerID,
+		Name:        dbJournal.Name,
+		Description: dbJournal.Description,
+		KeyEnc:      dbJournal.KeyEnc,
+		CreatedAt:   dbJournal.CreatedAt,
+		UpdatedAt:   dbJournal.UpdatedAt,
+		IsDeleted:   dbJournal.IsDeleted,
+		DeleteTime:  dbJournal.DeleteTime,
+	}
+}
+
+func memberToDomain(dbMember *models.JournalMember) *domain.JournalMember {
+	return &domain.JournalMember{
+		ID:        dbMember.ID,
+		JournalID: dbMember.JournalID,
+		UserID:    dbMember.UserID,
+		Role:      dbMember.Role,
+		CreatedAt: dbMember.CreatedAt,
+	}
+}
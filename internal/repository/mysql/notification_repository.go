@@ -0,0 +1,273 @@
+package mysql
+
+import (
+	"context"
+	"time"
+
+	"diary/internal/domain"
+	"diary/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type reminderJobRepository struct {
+	db *gorm.DB
+}
+
+func NewReminderJobRepository(db *gorm.DB) domain.ReminderJobRepository {
+	return &reminderJobRepository{db: db}
+}
+
+func (r *reminderJobRepository) Create(ctx context.Context, job *domain.ReminderJob) error {
+	dbJob := &models.ReminderJob{
+		UserID:    job.UserID,
+		Kind:      job.Kind,
+		RefID:     job.RefID,
+		FireAt:    job.FireAt,
+		Status:    job.Status,
+		NextTryAt: job.NextTryAt,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	// 规划会重复执行，依靠唯一索引去重
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(dbJob).Error
+	if err != nil {
+		return err
+	}
+
+	job.ID = dbJob.ID
+	job.CreatedAt = dbJob.CreatedAt
+	job.UpdatedAt = dbJob.UpdatedAt
+	return nil
+}
+
+func (r *reminderJobRepository) ListDue(ctx context.Context, now time.Time, limit int) ([]domain.ReminderJob, error) {
+	var dbJobs []models.ReminderJob
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND next_try_at <= ?", domain.ReminderPending, now).
+		Order("next_try_at ASC").
+		Limit(limit).
+		Find(&dbJobs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]domain.ReminderJob, len(dbJobs))
+	for i, dbJob := range dbJobs {
+		jobs[i] = domain.ReminderJob{
+			ID:        dbJob.ID,
+			UserID:    dbJob.UserID,
+			Kind:      dbJob.Kind,
+			RefID:     dbJob.RefID,
+			FireAt:    dbJob.FireAt,
+			Status:    dbJob.Status,
+			NextTryAt: dbJob.NextTryAt,
+			Attempts:  dbJob.Attempts,
+			LastError: dbJob.LastError,
+			Delivered: dbJob.Delivered,
+			CreatedAt: dbJob.CreatedAt,
+			UpdatedAt: dbJob.UpdatedAt,
+		}
+	}
+	return jobs, nil
+}
+
+func (r *reminderJobRepository) Update(ctx context.Context, job *domain.ReminderJob) error {
+	job.UpdatedAt = time.Now()
+	return r.db.WithContext(ctx).
+		Model(&models.ReminderJob{}).
+		Where("id = ?", job.ID).
+		Updates(map[string]interface{}{
+			"status":      job.Status,
+			"next_try_at": job.NextTryAt,
+			"attempts":    job.Attempts,
+			"last_error":  job.LastError,
+			"delivered":   job.Delivered,
+			"updated_at":  job.UpdatedAt,
+		}).Error
+}
+
+// reminderWatermarkID 水位只有一行
+const reminderWatermarkID = 1
+
+func (r *reminderJobRepository) Watermark(ctx context.Context) (time.Time, error) {
+	var dbMark models.ReminderWatermark
+	err := r.db.WithContext(ctx).Where("id = ?", reminderWatermarkID).Limit(1).Find(&dbMark).Error
+	return dbMark.PlannedAt, err
+}
+
+func (r *reminderJobRepository) SaveWatermark(ctx context.Context, at time.Time) error {
+	dbMark := &models.ReminderWatermark{ID: reminderWatermarkID, PlannedAt: at, UpdatedAt: time.Now()}
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoUpdates: clause.AssignmentColumns([]string{"planned_at", "updated_at"})}).
+		Create(dbMark).Error
+}
+
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) domain.NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+func (r *notificationRepository) Create(ctx context.Context, n *domain.Notification) error {
+	dbNotification := &models.Notification{
+		UserID:    n.UserID,
+		Kind:      n.Kind,
+		Title:     n.Title,
+		Body:      n.Body,
+		Link:      n.Link,
+		CreatedAt: time.Now(),
+	}
+
+	if err := r.db.WithContext(ctx).Create(dbNotification).Error; err != nil {
+		return err
+	}
+
+	n.ID = dbNotification.ID
+	n.CreatedAt = dbNotification.CreatedAt
+	return nil
+}
+
+func (r *notificationRepository) ListByUserID(ctx context.Context, userID uint, unreadOnly bool, offset, limit int) ([]domain.Notification, int64, error) {
+	query := r.db.WithContext(ctx).
+		Model(&models.Notification{}).
+		Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var dbNotifications []models.Notification
+	err := query.
+		Order("created_at DESC, id DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&dbNotifications).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	notifications := make([]domain.Notification, len(dbNotifications))
+	for i, n := range dbNotifications {
+		notifications[i] = domain.Notification{
+			ID:        n.ID,
+			UserID:    n.UserID,
+			Kind:      n.Kind,
+			Title:     n.Title,
+			Body:      n.Body,
+			Link:      n.Link,
+			ReadAt:    n.ReadAt,
+			CreatedAt: n.CreatedAt,
+		}
+	}
+	return notifications, total, nil
+}
+
+func (r *notificationRepository) CountUnread(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *notificationRepository) MarkRead(ctx context.Context, userID, id uint) error {
+	result := r.db.WithContext(ctx).
+		Model(&models.Notification{}).
+		Where("id = ? AND user_id = ?", id, userID).
+		Update("read_at", gorm.Expr("COALESCE(read_at, ?)", time.Now()))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *notificationRepository) MarkAllRead(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).
+		Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now()).Error
+}
+
+type notificationSettingRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationSettingRepository(db *gorm.DB) domain.NotificationSettingRepository {
+	return &notificationSettingRepository{db: db}
+}
+
+func (r *notificationSettingRepository) Get(ctx context.Context, userID uint) (*domain.NotificationSetting, error) {
+	var dbSetting models.NotificationSetting
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		First(&dbSetting).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.toDomain(&dbSetting), nil
+}
+
+func (r *notificationSettingRepository) Save(ctx context.Context, setting *domain.NotificationSetting) error {
+	setting.UpdatedAt = time.Now()
+	dbSetting := &models.NotificationSetting{
+		UserID:        setting.UserID,
+		InApp:         setting.InApp,
+		WebhookURL:    setting.WebhookURL,
+		Email:         setting.Email,
+		TodoReminders: setting.TodoReminders,
+		DailyPrompt:   setting.DailyPrompt,
+		PromptTime:    setting.PromptTime,
+		Timezone:      setting.Timezone,
+		UpdatedAt:     setting.UpdatedAt,
+	}
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{UpdateAll: true}).
+		Create(dbSetting).Error
+}
+
+func (r *notificationSettingRepository) ListDailyPrompt(ctx context.Context, afterUserID uint, limit int) ([]domain.NotificationSetting, error) {
+	var dbSettings []models.NotificationSetting
+	err := r.db.WithContext(ctx).
+		Where("daily_prompt = ? AND user_id > ?", true, afterUserID).
+		Order("user_id ASC").
+		Limit(limit).
+		Find(&dbSettings).Error
+	if err != nil {
+		return nil, err
+	}
+
+	settings := make([]domain.NotificationSetting, len(dbSettings))
+	for i, s := range dbSettings {
+		settings[i] = *r.toDomain(&s)
+	}
+	return settings, nil
+}
+
+func (r *notificationSettingRepository) toDomain(s *models.NotificationSetting) *domain.NotificationSetting {
+	return &domain.NotificationSetting{
+		UserID:        s.UserID,
+		InApp:         s.InApp,
+		WebhookURL:    s.WebhookURL,
+		Email:         s.Email,
+		TodoReminders: s.TodoReminders,
+		DailyPrompt:   s.DailyPrompt,
+		PromptTime:    s.PromptTime,
+		Timezone:      s.Timezone,
+		UpdatedAt:     s.UpdatedAt,
+	}
+}
@@ -0,0 +1,77 @@
+package mysql
+
+import (
+	"context"
+
+	"diary/internal/domain"
+	"diary/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type reactionRepository struct {
+	db *gorm.DB
+}
+
+func NewReactionRepository(db *gorm.DB) domain.ReactionRepository {
+	return &reactionRepository{db: db}
+}
+
+func (r *reactionRepository) Add(ctx context.Context, reaction *domain.Reaction) error {
+	dbReaction := &models.Reaction{
+		DiaryID: reaction.DiaryID,
+		UserID:  reaction.UserID,
+		Emoji:   reaction.Emoji,
+	}
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(dbReaction).Error
+	if err != nil {
+		return err
+	}
+	reaction.ID = dbReaction.ID
+	reaction.CreatedAt = dbReaction.CreatedAt
+	return nil
+}
+
+func (r *reactionRepository) Remove(ctx context.Context, diaryID, userID uint, emoji string) error {
+	return r.db.WithContext(ctx).
+		Where("diary_id = ? AND user_id = ? AND emoji = ?", diaryID, userID, emoji).
+		Delete(&models.Reaction{}).Error
+}
+
+func (r *reactionRepository) CountByDiaryIDs(ctx context.Context, diaryIDs []uint) (map[uint][]domain.ReactionCount, error) {
+	counts := make(map[uint][]domain.ReactionCount)
+	if len(diaryIDs) == 0 {
+		return counts, nil
+	}
+	var rows []struct {
+		DiaryID uint
+		Emoji   string
+		Count   int64
+	}
+	err := r.db.WithContext(ctx).
+		Model(&models.Reaction{}).
+		Select("diary_id, emoji, COUNT(*) AS count, MIN(id) AS first_id").
+		Where("diary_id IN ?", diaryIDs).
+		Group("diary_id, emoji").
+		Order("count DESC, first_id ASC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		counts[row.DiaryID] = append(counts[row.DiaryID], domain.ReactionCount{Emoji: row.Emoji, Count: row.Count})
+	}
+	return counts, nil
+}
+
+func (r *reactionRepository) ListEmojis(ctx context.Context, diaryID, userID uint) ([]string, error) {
+	var emojis []string
+	err := r.db.WithContext(ctx).
+		Model(&models.Reaction{}).
+		Where("diary_id = ? AND user_id = ?", diaryID, userID).
+		Pluck("emoji", &emojis).Error
+	return emojis, err
+}
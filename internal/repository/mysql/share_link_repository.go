@@ -0,0 +1,97 @@
+package mysql
+
+import (
+	"context"
+	"time"
+
+	"diary/internal/domain"
+	"diary/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type shareLinkRepository struct {
+	db *gorm.DB
+}
+
+func NewShareLinkRepository(db *gorm.DB) domain.ShareLinkRepository {
+	return &shareLinkRepository{db: db}
+}
+
+func (r *shareLinkRepository) Create(ctx context.Context, link *domain.ShareLink) error {
+	dbLink := &models.ShareLink{
+		UserID:       link.UserID,
+		DiaryID:      link.DiaryID,
+		TokenHash:    link.TokenHash,
+		PasswordHash: link.PasswordHash,
+		ExpiresAt:    link.ExpiresAt,
+	}
+	if err := r.db.WithContext(ctx).Create(dbLink).Error; err != nil {
+		return err
+	}
+	link.ID = dbLink.ID
+	link.CreatedAt = dbLink.CreatedAt
+	return nil
+}
+
+func (r *shareLinkRepository) GetByID(ctx context.Context, id uint) (*domain.ShareLink, error) {
+	var dbLink models.ShareLink
+	if err := r.db.WithContext(ctx).First(&dbLink, id).Error; err != nil {
+		return nil, err
+	}
+	return r.toDomain(&dbLink), nil
+}
+
+func (r *shareLinkRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*domain.ShareLink, error) {
+	var dbLink models.ShareLink
+	if err := r.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&dbLink).Error; err != nil {
+		return nil, err
+	}
+	return r.toDomain(&dbLink), nil
+}
+
+func (r *shareLinkRepository) ListByDiaryID(ctx context.Context, diaryID uint) ([]domain.ShareLink, error) {
+	var dbLinks []models.ShareLink
+	err := r.db.WithContext(ctx).
+		Where("diary_id = ?", diaryID).
+		Order("created_at DESC, id DESC").
+		Find(&dbLinks).Error
+	if err != nil {
+		return nil, err
+	}
+	links := make([]domain.ShareLink, len(dbLinks))
+	for i := range dbLinks {
+		links[i] = *r.toDomain(&dbLinks[i])
+	}
+	return links, nil
+}
+
+func (r *shareLinkRepository) Revoke(ctx context.Context, id uint, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.ShareLink{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at).Error
+}
+
+func (r *shareLinkRepository) RecordView(ctx context.Context, id uint, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.ShareLink{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"view_count":     gorm.Expr("view_count + 1"),
+			"last_viewed_at": at,
+		}).Error
+}
+
+func (r *shareLinkRepository) toDomain(dbLink *models.ShareLink) *domain.ShareLink {
+	return &domain.ShareLink{
+		ID:           dbLink.ID,
+		UserID:       dbLink.UserID,
+		DiaryID:      dbLink.DiaryID,
+		TokenHash:    dbLink.TokenHash,
+		PasswordHash: dbLink.PasswordHash,
+		ExpiresAt:    dbLink.ExpiresAt,
+		ViewCount:    dbLink.ViewCount,
+		LastViewedAt: dbLink.LastViewedAt,
+		RevokedAt:    dbLink.RevokedAt,
+		CreatedAt:    dbLink.CreatedAt,
+	}
+}
@@ -87,7 +87,7 @@ func (r *tagRepository) List(ctx context.Context, offset, limit int) ([]domain.T
 		Where("is_deleted = ?", false).
 		Offset(offset).
 		Limit(limit).
-		Order("created_at DESC").
+		Order("created_at DESC, id DESC").
 		Find(&dbTags).Error
 	if err != nil {
 		return nil, 0, err
@@ -101,6 +101,29 @@ func (r *tagRepository) List(ctx context.Context, offset, limit int) ([]domain.T
 	return tags, total, nil
 }
 
+func (r *tagRepository) ListAfter(ctx context.Context, after *domain.TimeCursor, limit int) ([]domain.Tag, error) {
+	query := r.db.WithContext(ctx).
+		Where("is_deleted = ?", false)
+	if after != nil {
+		query = query.Where("(created_at < ? OR (created_at = ? AND id < ?))", after.Time, after.Time, after.ID)
+	}
+
+	var dbTags []models.Tag
+	err := query.
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Find(&dbTags).Error
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make([]domain.Tag, len(dbTags))
+	for i, dbTag := range dbTags {
+		tags[i] = *r.toDomain(&dbTag)
+	}
+	return tags, nil
+}
+
 func (r *tagRepository) GetByIDs(ctx context.Context, ids []uint) ([]domain.Tag, error) {
 	var dbTags []models.Tag
 	err := r.db.WithContext(ctx).
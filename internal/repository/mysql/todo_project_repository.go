@@ -0,0 +1,101 @@
+package mysql
+
+import (
+	"context"
+	"time"
+
+	"diary/internal/domain"
+	"diary/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type todoProjectRepository struct {
+	db *gorm.DB
+}
+
+func NewTodoProjectRepository(db *gorm.DB) domain.TodoProjectRepository {
+	return &todoProjectRepository{db: db}
+}
+
+func (r *todoProjectRepository) Create(ctx context.Context, project *domain.TodoProject) error {
+	dbProject := &models.TodoProject{
+		UserID:    project.UserID,
+		Name:      project.Name,
+		Color:     project.Color,
+		SortOrder: project.SortOrder,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	if err := r.db.WithContext(ctx).Create(dbProject).Error; err != nil {
+		return err
+	}
+
+	project.ID = dbProject.ID
+	project.CreatedAt = dbProject.CreatedAt
+	project.UpdatedAt = dbProject.UpdatedAt
+	return nil
+}
+
+func (r *todoProjectRepository) GetByID(ctx context.Context, id uint) (*domain.TodoProject, error) {
+	var dbProject models.TodoProject
+	err := r.db.WithContext(ctx).
+		Where("id = ? AND is_deleted = ?", id, false).
+		First(&dbProject).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.toDomain(&dbProject), nil
+}
+
+func (r *todoProjectRepository) Update(ctx context.Context, project *domain.TodoProject) error {
+	return r.db.WithContext(ctx).
+		Model(&models.TodoProject{}).
+		Where("id = ? AND is_deleted = ?", project.ID, false).
+		Updates(map[string]interface{}{
+			"name":       project.Name,
+			"color":      project.Color,
+			"sort_order": project.SortOrder,
+			"updated_at": time.Now(),
+		}).Error
+}
+
+func (r *todoProjectRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).
+		Model(&models.TodoProject{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"is_deleted":  true,
+			"delete_time": time.Now(),
+		}).Error
+}
+
+func (r *todoProjectRepository) ListByUserID(ctx context.Context, userID uint) ([]domain.TodoProject, error) {
+	var dbProjects []models.TodoProject
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND is_deleted = ?", userID, false).
+		Order("sort_order ASC, id ASC").
+		Find(&dbProjects).Error
+	if err != nil {
+		return nil, err
+	}
+
+	projects := make([]domain.TodoProject, len(dbProjects))
+	for i, dbProject := range dbProjects {
+		projects[i] = *r.toDomain(&dbProject)
+	}
+	return projects, nil
+}
+
+func (r *todoProjectRepository) toDomain(dbProject *models.TodoProject) *domain.TodoProject {
+	return &domain.TodoProject{
+		ID:        dbProject.ID,
+		UserID:    dbProject.UserID,
+		Name:      dbProject.Name,
+		Color:     dbProject.Color,
+		SortOrder: dbProject.SortOrder,
+		CreatedAt: dbProject.CreatedAt,
+		UpdatedAt: dbProject.UpdatedAt,
+	}
+}
@@ -19,14 +19,23 @@ func NewTodoRepository(db *gorm.DB) domain.TodoRepository {
 
 func (r *todoRepository) Create(ctx context.Context, todo *domain.Todo) error {
 	dbTodo := &models.Todo{
-		UserID:      todo.UserID,
-		Title:       todo.Title,
-		Description: todo.Description,
-		Done:        todo.Done,
-		DueDate:     todo.DueDate,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
-		IsDeleted:   false,
+		UserID:       todo.UserID,
+		Title:        todo.Title,
+		Description:  todo.Description,
+		Done:         todo.Done,
+		DueDate:      todo.DueDate,
+		Priority:     todo.Priority,
+		ProjectID:    todo.ProjectID,
+		ParentID:     todo.ParentID,
+		SortOrder:    todo.SortOrder,
+		RRule:        todo.RRule,
+		Timezone:     todo.Timezone,
+		RecurStart:   todo.RecurStart,
+		SeriesID:     todo.SeriesID,
+		OccurrenceAt: todo.OccurrenceAt,
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+		IsDeleted:    false,
 	}
 
 	if err := r.db.WithContext(ctx).Create(dbTodo).Error; err != nil {
@@ -52,11 +61,17 @@ func (r *todoRepository) GetByID(ctx context.Context, id uint) (*domain.Todo, er
 
 func (r *todoRepository) Update(ctx context.Context, todo *domain.Todo) error {
 	updates := map[string]interface{}{
-		"title":       todo.Title,
-		"description": todo.Description,
-		"done":        todo.Done,
-		"due_date":    todo.DueDate,
-		"updated_at":  time.Now(),
+		"title":         todo.Title,
+		"description":   todo.Description,
+		"done":          todo.Done,
+		"due_date":      todo.DueDate,
+		"priority":      todo.Priority,
+		"project_id":    todo.ProjectID,
+		"rrule":         todo.RRule,
+		"timezone":      todo.Timezone,
+		"recur_start":   todo.RecurStart,
+		"occurrence_at": todo.OccurrenceAt,
+		"updated_at":    time.Now(),
 	}
 
 	return r.db.WithContext(ctx).
@@ -188,17 +203,312 @@ func (r *todoRepository) CountPending(ctx context.Context, userID uint) (int64,
 	return count, err
 }
 
+// todoSortColumns 允许的排序字段，避免拼接任意 SQL
+var todoSortColumns = map[string]string{
+	"created_at": "created_at",
+	"due_date":   "due_date",
+	"priority":   "priority",
+	"order":      "sort_order",
+	"title":      "title",
+}
+
+func (r *todoRepository) List(ctx context.Context, userID uint, filter domain.TodoFilter, offset, limit int) ([]domain.Todo, int64, error) {
+	query := r.filterQuery(ctx, userID, filter)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var dbTodos []models.Todo
+	err := r.orderQuery(query, filter).
+		Offset(offset).
+		Limit(limit).
+		Find(&dbTodos).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	todos := make([]domain.Todo, len(dbTodos))
+	for i, dbTodo := range dbTodos {
+		todos[i] = *r.toDomain(&dbTodo)
+	}
+	return todos, total, nil
+}
+
+func (r *todoRepository) ListAfter(ctx context.Context, userID uint, filter domain.TodoFilter, after *domain.TodoCursor, limit int) ([]domain.Todo, error) {
+	query := r.filterQuery(ctx, userID, filter)
+	if after != nil {
+		column, desc := todoSortOf(filter)
+		cmp := ">"
+		if desc {
+			cmp = "<"
+		}
+		var value interface{}
+		switch column {
+		case "created_at", "due_date":
+			value = after.Time
+		case "priority", "sort_order":
+			value = after.Int
+		default:
+			value = after.Text
+		}
+		switch {
+		case column != "due_date":
+			query = query.Where("("+column+" "+cmp+" ? OR ("+column+" = ? AND id "+cmp+" ?))", value, value, after.ID)
+		case after.Time == nil:
+			// 没有截止日期的排在最后，游标已经进入这一段
+			query = query.Where("due_date IS NULL AND id "+cmp+" ?", after.ID)
+		default:
+			query = query.Where("(due_date IS NULL OR due_date "+cmp+" ? OR (due_date = ? AND id "+cmp+" ?))", *after.Time, *after.Time, after.ID)
+		}
+	}
+
+	var dbTodos []models.Todo
+	err := r.orderQuery(query, filter).
+		Limit(limit).
+		Find(&dbTodos).Error
+	if err != nil {
+		return nil, err
+	}
+
+	todos := make([]domain.Todo, len(dbTodos))
+	for i, dbTodo := range dbTodos {
+		todos[i] = *r.toDomain(&dbTodo)
+	}
+	return todos, nil
+}
+
+// filterQuery 按筛选条件过滤用户的待办
+func (r *todoRepository) filterQuery(ctx context.Context, userID uint, filter domain.TodoFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).
+		Model(&models.Todo{}).
+		Where("user_id = ? AND is_deleted = ?", userID, false)
+	if filter.Done != nil {
+		query = query.Where("done = ?", *filter.Done)
+	}
+	if filter.Priority != nil {
+		query = query.Where("priority = ?", *filter.Priority)
+	}
+	if filter.ProjectID != nil {
+		if *filter.ProjectID == 0 {
+			query = query.Where("project_id IS NULL")
+		} else {
+			query = query.Where("project_id = ?", *filter.ProjectID)
+		}
+	}
+	if filter.ParentID != nil {
+		if *filter.ParentID == 0 {
+			query = query.Where("parent_id IS NULL")
+		} else {
+			query = query.Where("parent_id = ?", *filter.ParentID)
+		}
+	}
+	if filter.DueFrom != nil {
+		query = query.Where("due_date >= ?", *filter.DueFrom)
+	}
+	if filter.DueTo != nil {
+		query = query.Where("due_date < ?", *filter.DueTo)
+	}
+	return query
+}
+
+// orderQuery 按 filter 的排序字段排序，相同时按 id，保证游标分页的顺序稳定
+func (r *todoRepository) orderQuery(query *gorm.DB, filter domain.TodoFilter) *gorm.DB {
+	column, desc := todoSortOf(filter)
+	dir := "ASC"
+	if desc {
+		dir = "DESC"
+	}
+	if column == "due_date" {
+		// 没有截止日期的排在最后
+		query = query.Order("due_date IS NULL")
+	}
+	return query.
+		Order(column + " " + dir).
+		Order("id " + dir)
+}
+
+// todoSortOf 排序字段对应的列，未知字段按创建时间
+func todoSortOf(filter domain.TodoFilter) (string, bool) {
+	column, ok := todoSortColumns[filter.Sort]
+	if !ok {
+		column = "created_at"
+	}
+	return column, filter.Desc
+}
+
+func (r *todoRepository) ListByParentIDs(ctx context.Context, parentIDs []uint) ([]domain.Todo, error) {
+	if len(parentIDs) == 0 {
+		return nil, nil
+	}
+	var dbTodos []models.Todo
+	err := r.db.WithContext(ctx).
+		Where("parent_id IN ? AND is_deleted = ?", parentIDs, false).
+		Order("sort_order ASC, id ASC").
+		Find(&dbTodos).Error
+	if err != nil {
+		return nil, err
+	}
+
+	todos := make([]domain.Todo, len(dbTodos))
+	for i, dbTodo := range dbTodos {
+		todos[i] = *r.toDomain(&dbTodo)
+	}
+	return todos, nil
+}
+
+func (r *todoRepository) SetDoneByParentID(ctx context.Context, parentID uint, done bool) error {
+	return r.db.WithContext(ctx).
+		Model(&models.Todo{}).
+		Where("parent_id = ? AND is_deleted = ?", parentID, false).
+		Updates(map[string]interface{}{
+			"done":       done,
+			"updated_at": time.Now(),
+		}).Error
+}
+
+func (r *todoRepository) SetProjectByParentID(ctx context.Context, parentID uint, projectID *uint) error {
+	return r.db.WithContext(ctx).
+		Model(&models.Todo{}).
+		Where("parent_id = ?", parentID).
+		Updates(map[string]interface{}{
+			"project_id": projectID,
+			"updated_at": time.Now(),
+		}).Error
+}
+
+func (r *todoRepository) DeleteByParentID(ctx context.Context, parentID uint) error {
+	return r.db.WithContext(ctx).
+		Model(&models.Todo{}).
+		Where("parent_id = ? AND is_deleted = ?", parentID, false).
+		Updates(map[string]interface{}{
+			"is_deleted":  true,
+			"delete_time": time.Now(),
+		}).Error
+}
+
+func (r *todoRepository) ClearProject(ctx context.Context, projectID uint) error {
+	return r.db.WithContext(ctx).
+		Model(&models.Todo{}).
+		Where("project_id = ?", projectID).
+		Update("project_id", nil).Error
+}
+
+func (r *todoRepository) MaxSortOrder(ctx context.Context, userID uint, parentID *uint) (int, error) {
+	query := r.db.WithContext(ctx).
+		Model(&models.Todo{}).
+		Where("user_id = ? AND is_deleted = ?", userID, false)
+	if parentID == nil {
+		query = query.Where("parent_id IS NULL")
+	} else {
+		query = query.Where("parent_id = ?", *parentID)
+	}
+
+	var max *int
+	if err := query.Select("MAX(sort_order)").Scan(&max).Error; err != nil {
+		return 0, err
+	}
+	if max == nil {
+		return 0, nil
+	}
+	return *max, nil
+}
+
+func (r *todoRepository) UpdateSortOrder(ctx context.Context, userID uint, ids []uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i, id := range ids {
+			err := tx.Model(&models.Todo{}).
+				Where("id = ? AND user_id = ? AND is_deleted = ?", id, userID, false).
+				Update("sort_order", i+1).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *todoRepository) CountByProject(ctx context.Context, userID uint) ([]domain.TodoProjectStats, error) {
+	var rows []struct {
+		ProjectID *uint
+		Total     int64
+		Pending   int64
+	}
+	err := r.db.WithContext(ctx).
+		Model(&models.Todo{}).
+		Select("project_id, COUNT(*) AS total, SUM(CASE WHEN done = ? THEN 1 ELSE 0 END) AS pending", false).
+		Where("user_id = ? AND is_deleted = ?", userID, false).
+		Group("project_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make([]domain.TodoProjectStats, len(rows))
+	for i, row := range rows {
+		stats[i] = domain.TodoProjectStats{Total: row.Total, Pending: row.Pending}
+		if row.ProjectID != nil {
+			stats[i].ProjectID = *row.ProjectID
+		}
+	}
+	return stats, nil
+}
+
+func (r *todoRepository) GetByOccurrence(ctx context.Context, seriesID uint, at time.Time) (*domain.Todo, error) {
+	var dbTodo models.Todo
+	err := r.db.WithContext(ctx).
+		Where("(series_id = ? OR id = ?) AND occurrence_at = ?", seriesID, seriesID, at).
+		First(&dbTodo).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.toDomain(&dbTodo), nil
+}
+
+func (r *todoRepository) ListDueBetween(ctx context.Context, start, end time.Time, after *domain.TimeCursor, limit int) ([]domain.Todo, error) {
+	query := r.db.WithContext(ctx).
+		Where("due_date > ? AND due_date <= ? AND done = ? AND is_deleted = ?", start, end, false, false)
+	if after != nil {
+		// 同一时间截止的待办可能超过一批，按 (due_date, id) 翻页
+		query = query.Where("(due_date > ? OR (due_date = ? AND id > ?))", after.Time, after.Time, after.ID)
+	}
+	var dbTodos []models.Todo
+	err := query.
+		Order("due_date ASC, id ASC").
+		Limit(limit).
+		Find(&dbTodos).Error
+	if err != nil {
+		return nil, err
+	}
+
+	todos := make([]domain.Todo, len(dbTodos))
+	for i, dbTodo := range dbTodos {
+		todos[i] = *r.toDomain(&dbTodo)
+	}
+	return todos, nil
+}
+
 func (r *todoRepository) toDomain(dbTodo *models.Todo) *domain.Todo {
 	return &domain.Todo{
-		ID:          dbTodo.ID,
-		UserID:      dbTodo.UserID,
-		Title:       dbTodo.Title,
-		Description: dbTodo.Description,
-		Done:        dbTodo.Done,
-		DueDate:     dbTodo.DueDate,
-		CreatedAt:   dbTodo.CreatedAt,
-		UpdatedAt:   dbTodo.UpdatedAt,
-		IsDeleted:   dbTodo.IsDeleted,
-		DeleteTime:  dbTodo.DeleteTime,
+		ID:           dbTodo.ID,
+		UserID:       dbTodo.UserID,
+		Title:        dbTodo.Title,
+		Description:  dbTodo.Description,
+		Done:         dbTodo.Done,
+		DueDate:      dbTodo.DueDate,
+		Priority:     dbTodo.Priority,
+		ProjectID:    dbTodo.ProjectID,
+		ParentID:     dbTodo.ParentID,
+		SortOrder:    dbTodo.SortOrder,
+		RRule:        dbTodo.RRule,
+		Timezone:     dbTodo.Timezone,
+		RecurStart:   dbTodo.RecurStart,
+		SeriesID:     dbTodo.SeriesID,
+		OccurrenceAt: dbTodo.OccurrenceAt,
+		CreatedAt:    dbTodo.CreatedAt,
+		UpdatedAt:    dbTodo.UpdatedAt,
+		IsDeleted:    dbTodo.IsDeleted,
+		DeleteTime:   dbTodo.DeleteTime,
 	}
 }
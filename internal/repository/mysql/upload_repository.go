@@ -0,0 +1,115 @@
+package mysql
+
+import (
+	"context"
+	"time"
+
+	"diary/internal/domain"
+	"diary/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type uploadRepository struct {
+	db *gorm.DB
+}
+
+func NewUploadRepository(db *gorm.DB) domain.UploadRepository {
+	return &uploadRepository{db: db}
+}
+
+func (r *uploadRepository) Create(ctx context.Context, upload *domain.Upload) error {
+	dbUpload := &models.Upload{
+		ID:        upload.ID,
+		UserID:    upload.UserID,
+		Filename:  upload.Filename,
+		Size:      upload.Size,
+		Offset:    upload.Offset,
+		Checksum:  upload.Checksum,
+		ExpiresAt: upload.ExpiresAt,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	if err := r.db.WithContext(ctx).Create(dbUpload).Error; err != nil {
+		return err
+	}
+
+	upload.CreatedAt = dbUpload.CreatedAt
+	upload.UpdatedAt = dbUpload.UpdatedAt
+	return nil
+}
+
+func (r *uploadRepository) GetByID(ctx context.Context, id string) (*domain.Upload, error) {
+	var dbUpload models.Upload
+	err := r.db.WithContext(ctx).
+		Where("id = ?", id).
+		First(&dbUpload).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.toDomain(&dbUpload), nil
+}
+
+func (r *uploadRepository) UpdateOffset(ctx context.Context, id string, from, to int64) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.Upload{}).
+		Where("id = ? AND upload_offset = ?", id, from).
+		Updates(map[string]interface{}{
+			"upload_offset": to,
+			"updated_at":    time.Now(),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *uploadRepository) MarkCompleted(ctx context.Context, id string, imageID uint) error {
+	return r.db.WithContext(ctx).
+		Model(&models.Upload{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"image_id":   imageID,
+			"updated_at": time.Now(),
+		}).Error
+}
+
+func (r *uploadRepository) ListExpired(ctx context.Context, before time.Time, limit int) ([]domain.Upload, error) {
+	var dbUploads []models.Upload
+	err := r.db.WithContext(ctx).
+		Where("expires_at < ?", before).
+		Order("expires_at ASC").
+		Limit(limit).
+		Find(&dbUploads).Error
+	if err != nil {
+		return nil, err
+	}
+
+	uploads := make([]domain.Upload, len(dbUploads))
+	for i, dbUpload := range dbUploads {
+		uploads[i] = *r.toDomain(&dbUpload)
+	}
+	return uploads, nil
+}
+
+func (r *uploadRepository) Delete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).
+		Where("id = ?", id).
+		Delete(&models.Upload{}).Error
+}
+
+func (r *uploadRepository) toDomain(dbUpload *models.Upload) *domain.Upload {
+	return &domain.Upload{
+		ID:        dbUpload.ID,
+		UserID:    dbUpload.UserID,
+		Filename:  dbUpload.Filename,
+		Size:      dbUpload.Size,
+		Offset:    dbUpload.Offset,
+		Checksum:  dbUpload.Checksum,
+		ImageID:   dbUpload.ImageID,
+		ExpiresAt: dbUpload.ExpiresAt,
+		CreatedAt: dbUpload.CreatedAt,
+		UpdatedAt: dbUpload.UpdatedAt,
+	}
+}
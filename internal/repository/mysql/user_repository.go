@@ -160,15 +160,66 @@ func (r *userRepository) List(ctx context.Context, offset, limit int) ([]domain.
 	return users, total, nil
 }
 
+// GetByIDs 根据ID列表批量获取用户
+func (r *userRepository) GetByIDs(ctx context.Context, ids []uint) ([]domain.User, error) {
+	var dbUsers []models.User
+	err := r.db.WithContext(ctx).
+		Where("id IN ? AND is_deleted = ?", ids, false).
+		Find(&dbUsers).Error
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]domain.User, len(dbUsers))
+	for i := range dbUsers {
+		users[i] = *r.toDomain(&dbUsers[i])
+	}
+	return users, nil
+}
+
+// UpdateProfile 更新公开主页资料
+func (r *userRepository) UpdateProfile(ctx context.Context, user *domain.User) error {
+	return r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Where("id = ? AND is_deleted = ?", user.ID, false).
+		Updates(map[string]interface{}{
+			"display_name":    user.DisplayName,
+			"bio":             user.Bio,
+			"avatar_image_id": user.AvatarImageID,
+			"profile_public":  user.ProfilePublic,
+			"updated_at":      time.Now(),
+		}).Error
+}
+
+func (r *userRepository) EnsureArchiveID(ctx context.Context, id uint, candidate string) (string, error) {
+	// 只在为空时写入，并发导出时以先写入的为准
+	err := r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Where("id = ? AND (archive_id IS NULL OR archive_id = '')", id).
+		Update("archive_id", candidate).Error
+	if err != nil {
+		return "", err
+	}
+	var dbUser models.User
+	if err := r.db.WithContext(ctx).Select("archive_id").First(&dbUser, id).Error; err != nil {
+		return "", err
+	}
+	return dbUser.ArchiveID, nil
+}
+
 // toDomain 将数据库模型转换为领域模型
 func (r *userRepository) toDomain(dbUser *models.User) *domain.User {
 	return &domain.User{
-		ID:         dbUser.ID,
-		Username:   dbUser.Username,
-		CreatedAt:  dbUser.CreatedAt,
-		UpdatedAt:  dbUser.UpdatedAt,
-		IsDeleted:  dbUser.IsDeleted,
-		DeleteTime: dbUser.DeleteTime,
+		ID:            dbUser.ID,
+		Username:      dbUser.Username,
+		CreatedAt:     dbUser.CreatedAt,
+		UpdatedAt:     dbUser.UpdatedAt,
+		IsDeleted:     dbUser.IsDeleted,
+		DeleteTime:    dbUser.DeleteTime,
+		DisplayName:   dbUser.DisplayName,
+		Bio:           dbUser.Bio,
+		AvatarImageID: dbUser.AvatarImageID,
+		ProfilePublic: dbUser.ProfilePublic,
 	}
 }
 
@@ -0,0 +1,559 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"diary/config"
+	"diary/internal/domain"
+)
+
+// ArchiveVersion 当前导出包格式版本，格式有不兼容变更时递增
+const ArchiveVersion = 1
+
+const (
+	archiveManifestFile = "manifest.json"
+	archiveDiariesFile  = "diaries.json"
+	archiveTodosFile    = "todos.json"
+	archiveImagesFile   = "images.json"
+	archiveProjectsFile = "projects.json"
+)
+
+var (
+	ErrInvalidArchive     = errors.New("无效的导出包")
+	ErrUnsupportedArchive = errors.New("不支持的导出包版本")
+)
+
+// ArchiveManifest 导出包清单
+type ArchiveManifest struct {
+	Version    int       `json:"version"`
+	ArchiveID  string    `json:"archive_id"` // 同一实例上同一账号的导出包相同，导入时据此去重
+	ExportedAt time.Time `json:"exported_at"`
+	Diaries    int       `json:"diaries"`
+	Todos      int       `json:"todos"`
+	Images     int       `json:"images"`
+	Projects   int       `json:"projects"`
+}
+
+// ArchiveDiary 导出包中的日记，正文保留 image:ID 引用
+type ArchiveDiary struct {
+	ID         uint                   `json:"id"`
+	Title      string                 `json:"title"`
+	Content    string                 `json:"content"`
+	Weather    string                 `json:"weather,omitempty"`
+	Mood       string                 `json:"mood,omitempty"`
+	Location   string                 `json:"location,omitempty"`
+	Music      string                 `json:"music,omitempty"`
+	Date       time.Time              `json:"date"`
+	IsPublic   bool                   `json:"is_public"`
+	IsPinned   bool                   `json:"is_pinned"`
+	Tags       []string               `json:"tags,omitempty"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+	ImageIDs   []uint                 `json:"image_ids,omitempty"`
+	CreatedAt  time.Time              `json:"created_at"`
+	UpdatedAt  time.Time              `json:"updated_at"`
+}
+
+// ArchiveTodo 导出包中的待办事项
+type ArchiveTodo struct {
+	ID          uint       `json:"id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description,omitempty"`
+	Done        bool       `json:"done"`
+	DueDate     *time.Time `json:"due_date,omitempty"`
+	Priority    int        `json:"priority,omitempty"`
+	ProjectID   *uint      `json:"project_id,omitempty"`
+	ParentID    *uint      `json:"parent_id,omitempty"`
+	SortOrder   int        `json:"sort_order,omitempty"`
+	RRule       string     `json:"rrule,omitempty"`
+	Timezone    string     `json:"timezone,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// ArchiveProject 导出包中的待办清单
+type ArchiveProject struct {
+	ID        uint   `json:"id"`
+	Name      string `json:"name"`
+	Color     string `json:"color,omitempty"`
+	SortOrder int    `json:"sort_order"`
+}
+
+// ArchiveImage 导出包中的附件，File 为包内路径
+type ArchiveImage struct {
+	ID           uint      `json:"id"`
+	File         string    `json:"file"`
+	Kind         string    `json:"kind"`
+	MimeType     string    `json:"mime_type,omitempty"`
+	OriginalName string    `json:"original_name,omitempty"`
+	DiaryID      *uint     `json:"diary_id,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type ArchiveService interface {
+	// Export 将用户的日记、待办和附件打包写入 w
+	Export(ctx context.Context, userID uint, w io.Writer) error
+	// Import 从导出包恢复数据，重复导入同一个包时跳过已导入的对象；dryRun 时只统计不写入
+	Import(ctx context.Context, userID uint, r io.ReaderAt, size int64, dryRun bool) (*ImportReport, error)
+}
+
+type archiveService struct {
+	diaryService   DiaryService
+	todoService    TodoService
+	projectService ProjectService
+	imageService   ImageService
+	importRepo     domain.ImportRecordRepository
+	userRepo       domain.UserRepository
+	cfg            *config.Config
+}
+
+func NewArchiveService(diaryService DiaryService, todoService TodoService, projectService ProjectService, imageService ImageService, importRepo domain.ImportRecordRepository, userRepo domain.UserRepository, cfg *config.Config) ArchiveService {
+	return &archiveService{
+		diaryService:   diaryService,
+		todoService:    todoService,
+		projectService: projectService,
+		imageService:   imageService,
+		importRepo:     importRepo,
+		userRepo:       userRepo,
+		cfg:            cfg,
+	}
+}
+
+func (s *archiveService) Export(ctx context.Context, userID uint, w io.Writer) error {
+	archiveID, err := s.archiveID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+	diaryCount, err := s.writeDiaries(ctx, zw, userID)
+	if err != nil {
+		return err
+	}
+
+	archiveTodos := []ArchiveTodo{}
+	for page := 1; ; page++ {
+		todos, _, err := s.todoService.ListByUserID(ctx, userID, page, 100)
+		if err != nil {
+			return err
+		}
+		for _, t := range todos {
+			archiveTodos = append(archiveTodos, ArchiveTodo{
+				ID:          t.ID,
+				Title:       t.Title,
+				Description: t.Description,
+				Done:        t.Done,
+				DueDate:     t.DueDate,
+				Priority:    t.Priority,
+				ProjectID:   t.ProjectID,
+				ParentID:    t.ParentID,
+				SortOrder:   t.SortOrder,
+				RRule:       t.RRule,
+				Timezone:    t.Timezone,
+				CreatedAt:   t.CreatedAt,
+			})
+		}
+		if len(todos) < 100 {
+			break
+		}
+	}
+
+	projects, err := s.projectService.List(ctx, userID)
+	if err != nil {
+		return err
+	}
+	archiveProjects := make([]ArchiveProject, 0, len(projects))
+	for _, p := range projects {
+		archiveProjects = append(archiveProjects, ArchiveProject{ID: p.ID, Name: p.Name, Color: p.Color, SortOrder: p.SortOrder})
+	}
+
+	var images []domain.Image
+	for page := 1; ; page++ {
+		batch, _, err := s.imageService.ListByKind(ctx, userID, "", page, 100)
+		if err != nil {
+			return err
+		}
+		images = append(images, batch...)
+		if len(batch) < 100 {
+			break
+		}
+	}
+
+	archiveImages := make([]ArchiveImage, 0, len(images))
+	for _, img := range images {
+		name := fmt.Sprintf("images/%d%s", img.ID, filepath.Ext(img.Path))
+		if err := s.copyImage(ctx, zw, img.ID, name); err != nil {
+			// 文件丢失的附件不写入清单，导入时对应引用会被替换为占位文字
+			if errors.Is(err, ErrImageNotFound) {
+				continue
+			}
+			// 写入失败时已经输出了不完整的条目，只能中止
+			return err
+		}
+		archiveImages = append(archiveImages, ArchiveImage{
+			ID:           img.ID,
+			File:         name,
+			Kind:         img.Kind,
+			MimeType:     img.MimeType,
+			OriginalName: img.OriginalName,
+			DiaryID:      img.DiaryID,
+			CreatedAt:    img.CreatedAt,
+		})
+	}
+
+	manifest := ArchiveManifest{
+		Version:    ArchiveVersion,
+		ArchiveID:  archiveID,
+		ExportedAt: time.Now(),
+		Diaries:    diaryCount,
+		Todos:      len(archiveTodos),
+		Images:     len(archiveImages),
+		Projects:   len(archiveProjects),
+	}
+
+	if err := writeZipJSON(zw, archiveManifestFile, manifest); err != nil {
+		return err
+	}
+	if err := writeZipJSON(zw, archiveTodosFile, archiveTodos); err != nil {
+		return err
+	}
+	if err := writeZipJSON(zw, archiveProjectsFile, archiveProjects); err != nil {
+		return err
+	}
+	if err := writeZipJSON(zw, archiveImagesFile, archiveImages); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// writeDiaries 逐篇写出 diaries.json，不把全部日记读入内存，返回日记数
+func (s *archiveService) writeDiaries(ctx context.Context, zw *zip.Writer, userID uint) (int, error) {
+	f, err := zw.Create(archiveDiariesFile)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := io.WriteString(f, "[\n"); err != nil {
+		return 0, err
+	}
+
+	// MySQL DATETIME 的取值范围，覆盖全部日记
+	start := time.Date(1000, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+	count := 0
+	err = s.diaryService.EachByDateRange(ctx, userID, start, end, func(d *domain.Diary) error {
+		item := ArchiveDiary{
+			ID:         d.ID,
+			Title:      d.Title,
+			Content:    d.PlainContent,
+			Weather:    d.Weather,
+			Mood:       d.Mood,
+			Location:   d.Location,
+			Music:      d.Music,
+			Date:       d.Date,
+			IsPublic:   d.IsPublic,
+			IsPinned:   d.IsPinned,
+			Properties: d.Properties,
+			CreatedAt:  d.CreatedAt,
+			UpdatedAt:  d.UpdatedAt,
+		}
+		for _, t := range d.Tags {
+			item.Tags = append(item.Tags, t.Name)
+		}
+		for _, img := range d.Images {
+			item.ImageIDs = append(item.ImageIDs, img.ID)
+		}
+		raw, err := json.MarshalIndent(item, "  ", "  ")
+		if err != nil {
+			return err
+		}
+		sep := "  "
+		if count > 0 {
+			sep = ",\n  "
+		}
+		count++
+		if _, err := io.WriteString(f, sep); err != nil {
+			return err
+		}
+		_, err = f.Write(raw)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	_, err = io.WriteString(f, "\n]\n")
+	return count, err
+}
+
+// archiveID 首次导出时随机生成并保存，同一账号多次导出得到相同的ID，
+// 重复导入较新的导出包时只会补充新增的对象；不依赖实例密钥，轮换密钥后仍然不变
+func (s *archiveService) archiveID(ctx context.Context, userID uint) (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return s.userRepo.EnsureArchiveID(ctx, userID, hex.EncodeToString(buf))
+}
+
+func (s *archiveService) copyImage(ctx context.Context, zw *zip.Writer, id uint, name string) error {
+	_, file, err := s.imageService.Open(ctx, id)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// 图片和音视频本身已压缩，不再重复压缩
+	f, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store, Modified: time.Now()})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(f, file)
+	return err
+}
+
+func (s *archiveService) Import(ctx context.Context, userID uint, r io.ReaderAt, size int64, dryRun bool) (*ImportReport, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, ErrInvalidArchive
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	var manifest ArchiveManifest
+	if err := readZipJSON(files, archiveManifestFile, &manifest); err != nil || manifest.ArchiveID == "" {
+		return nil, ErrInvalidArchive
+	}
+	if manifest.Version < 1 || manifest.Version > ArchiveVersion {
+		return nil, ErrUnsupportedArchive
+	}
+
+	var diaries []ArchiveDiary
+	var todos []ArchiveTodo
+	var images []ArchiveImage
+	if err := readZipJSON(files, archiveDiariesFile, &diaries); err != nil {
+		return nil, ErrInvalidArchive
+	}
+	if err := readZipJSON(files, archiveTodosFile, &todos); err != nil {
+		return nil, ErrInvalidArchive
+	}
+	if err := readZipJSON(files, archiveImagesFile, &images); err != nil {
+		return nil, ErrInvalidArchive
+	}
+	// 较早的导出包没有清单
+	var projects []ArchiveProject
+	if _, ok := files[archiveProjectsFile]; ok {
+		if err := readZipJSON(files, archiveProjectsFile, &projects); err != nil {
+			return nil, ErrInvalidArchive
+		}
+	}
+
+	source := "archive:" + manifest.ArchiveID
+	report := &ImportReport{Source: source, DryRun: dryRun}
+
+	// 先导入附件，得到旧ID到新ID的映射
+	imageIDs := make(map[uint]uint, len(images))
+	for _, img := range images {
+		newID, created, err := s.importOnce(ctx, userID, source, domain.ImportKindImage, img.ID, dryRun, func() (uint, error) {
+			f, ok := files[img.File]
+			if !ok {
+				return 0, ErrInvalidArchive
+			}
+			rc, err := f.Open()
+			if err != nil {
+				return 0, err
+			}
+			defer rc.Close()
+
+			name := img.OriginalName
+			if name == "" {
+				name = filepath.Base(img.File)
+			}
+			image, err := s.imageService.UploadAttachment(ctx, userID, rc, name)
+			if err != nil {
+				return 0, err
+			}
+			return image.ID, nil
+		})
+		if err != nil {
+			report.Images.Failed++
+			report.warn("附件 %d 导入失败：%v", img.ID, err)
+			continue
+		}
+		countImport(&report.Images, created)
+		imageIDs[img.ID] = newID
+	}
+
+	for _, d := range diaries {
+		_, created, err := s.importOnce(ctx, userID, source, domain.ImportKindDiary, d.ID, dryRun, func() (uint, error) {
+			return s.importDiary(ctx, userID, d, imageIDs, report)
+		})
+		if err != nil {
+			report.Diaries.Failed++
+			report.warn("日记 %d 导入失败：%v", d.ID, err)
+			continue
+		}
+		countImport(&report.Diaries, created)
+		if dryRun {
+			report.Entries = append(report.Entries, ImportPreview{
+				SourceID: strconv.FormatUint(uint64(d.ID), 10),
+				Title:    d.Title,
+				Date:     d.Date,
+				Tags:     d.Tags,
+				Media:    len(d.ImageIDs),
+				Exists:   !created,
+			})
+		}
+	}
+
+	// 按原顺序创建清单，新清单的排序与原来一致
+	sort.SliceStable(projects, func(i, j int) bool {
+		return projects[i].SortOrder < projects[j].SortOrder
+	})
+	projectIDs := make(map[uint]uint, len(projects))
+	for _, p := range projects {
+		newID, created, err := s.importOnce(ctx, userID, source, domain.ImportKindProject, p.ID, dryRun, func() (uint, error) {
+			project, err := s.projectService.Create(ctx, userID, p.Name, p.Color)
+			if err != nil {
+				return 0, err
+			}
+			return project.ID, nil
+		})
+		if err != nil {
+			report.Projects.Failed++
+			report.warn("清单 %d 导入失败：%v", p.ID, err)
+			continue
+		}
+		if newID != 0 {
+			projectIDs[p.ID] = newID
+		}
+		countImport(&report.Projects, created)
+	}
+
+	// 先导入父任务，子任务再按新ID关联；同一层级内按手动排序创建
+	sort.SliceStable(todos, func(i, j int) bool {
+		pi, pj := todos[i].ParentID == nil, todos[j].ParentID == nil
+		if pi != pj {
+			return pi
+		}
+		return todos[i].SortOrder < todos[j].SortOrder
+	})
+	todoIDs := make(map[uint]uint)
+	var ordered []uint
+	for _, t := range todos {
+		var parentID, projectID *uint
+		if t.ParentID != nil {
+			if id, ok := todoIDs[*t.ParentID]; ok {
+				parentID = &id
+			}
+		}
+		if t.ProjectID != nil {
+			if id, ok := projectIDs[*t.ProjectID]; ok {
+				projectID = &id
+			}
+		}
+		// 已完成的重复待办不再带规则，否则完成时会生成与包中重复的下一次
+		rule := t.RRule
+		if t.Done {
+			rule = ""
+		}
+		newID, created, err := s.importOnce(ctx, userID, source, domain.ImportKindTodo, t.ID, dryRun, func() (uint, error) {
+			todo, err := s.todoService.Create(ctx, userID, t.Title, t.Description, t.DueDate, t.Priority, projectID, parentID, rule, t.Timezone)
+			if err != nil {
+				return 0, err
+			}
+			if t.Done {
+				if err := s.todoService.MarkAsDone(ctx, todo.ID); err != nil {
+					return 0, err
+				}
+			}
+			return todo.ID, nil
+		})
+		if err != nil {
+			report.Todos.Failed++
+			report.warn("待办 %d 导入失败：%v", t.ID, err)
+			continue
+		}
+		if newID != 0 {
+			todoIDs[t.ID] = newID
+		}
+		if created && !dryRun {
+			ordered = append(ordered, newID)
+		}
+		countImport(&report.Todos, created)
+	}
+
+	// 恢复手动排序，ordered 已按层级和原顺序排列
+	if len(ordered) > 0 {
+		if err := s.todoService.Reorder(ctx, userID, ordered); err != nil {
+			report.warn("待办排序恢复失败：%v", err)
+		}
+	}
+
+	return report, nil
+}
+
+func (s *archiveService) importDiary(ctx context.Context, userID uint, d ArchiveDiary, imageIDs map[uint]uint, report *ImportReport) (uint, error) {
+	// 正文中的图片引用改写为新ID，找不到的附件替换为占位文字
+	content := RewriteImageRefs(d.Content, func(id uint, alt string) (string, bool) {
+		if newID, ok := imageIDs[id]; ok {
+			return fmt.Sprintf("![%s](image:%d)", alt, newID), true
+		}
+		return fmt.Sprintf("[图片：%s]", alt), true
+	})
+
+	var attach []uint
+	for _, id := range d.ImageIDs {
+		if newID, ok := imageIDs[id]; ok {
+			attach = append(attach, newID)
+		}
+	}
+
+	diary, err := s.diaryService.Create(ctx, userID, nil, d.Title, content, d.Weather, d.Mood, d.Location, d.Date, d.IsPublic, d.Tags, attach, d.Properties, d.Music)
+	if err != nil {
+		return 0, err
+	}
+	if d.IsPinned {
+		if _, err := s.diaryService.TogglePin(ctx, userID, diary.ID); err != nil {
+			report.warn("日记 %d 置顶失败：%v", d.ID, err)
+		}
+	}
+	return diary.ID, nil
+}
+
+// importOnce 如果对象已从同一来源导入过则直接返回之前的ID，否则执行 create 并记录映射
+func (s *archiveService) importOnce(ctx context.Context, userID uint, source, kind string, sourceID uint, dryRun bool, create func() (uint, error)) (uint, bool, error) {
+	return importOnce(ctx, s.importRepo, userID, source, kind, strconv.FormatUint(uint64(sourceID), 10), dryRun, create)
+}
+
+func writeZipJSON(zw *zip.Writer, name string, v interface{}) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func readZipJSON(files map[string]*zip.File, name string, v interface{}) error {
+	f, ok := files[name]
+	if !ok {
+		return ErrInvalidArchive
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return json.NewDecoder(rc).Decode(v)
+}
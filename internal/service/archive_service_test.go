@@ -0,0 +1,387 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"diary/config"
+	"diary/internal/domain"
+)
+
+// stubDiaryService 只保存导出导入用到的字段，正文不加密
+type stubDiaryService struct {
+	DiaryService
+	diaries []domain.Diary
+}
+
+func (s *stubDiaryService) Create(ctx context.Context, userID uint, journalID *uint, title, content, weather, mood, location string, date time.Time, isPublic bool, tagNames []string, imageIDs []uint, properties map[string]interface{}, music string) (*domain.Diary, error) {
+	d := domain.Diary{
+		ID: uint(len(s.diaries) + 1), UserID: userID, Title: title, PlainContent: content,
+		Weather: weather, Mood: mood, Location: location, Date: date, IsPublic: isPublic, Properties: properties, Music: music,
+	}
+	for _, name := range tagNames {
+		d.Tags = append(d.Tags, domain.Tag{Name: name})
+	}
+	for _, id := range imageIDs {
+		d.Images = append(d.Images, domain.Image{ID: id})
+	}
+	s.diaries = append(s.diaries, d)
+	return &d, nil
+}
+
+func (s *stubDiaryService) TogglePin(ctx context.Context, userID, diaryID uint) (bool, error) {
+	d := &s.diaries[diaryID-1]
+	d.IsPinned = !d.IsPinned
+	return d.IsPinned, nil
+}
+
+func (s *stubDiaryService) EachByDateRange(ctx context.Context, userID uint, startDate, endDate time.Time, fn func(diary *domain.Diary) error) error {
+	for i := range s.diaries {
+		if d := s.diaries[i]; d.UserID == userID {
+			if err := fn(&d); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *stubDiaryService) byUser(userID uint) []domain.Diary {
+	var out []domain.Diary
+	for _, d := range s.diaries {
+		if d.UserID == userID {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+type memImportRepo struct {
+	domain.ImportRecordRepository
+	records map[string]*domain.ImportRecord
+}
+
+func importKey(userID uint, source, kind, sourceID string) string {
+	return fmt.Sprintf("%d|%s|%s|%s", userID, source, kind, sourceID)
+}
+
+func (r *memImportRepo) Get(ctx context.Context, userID uint, source, kind, sourceID string) (*domain.ImportRecord, error) {
+	rec, ok := r.records[importKey(userID, source, kind, sourceID)]
+	if !ok {
+		return nil, errFakeNotFound
+	}
+	return rec, nil
+}
+
+func (r *memImportRepo) Create(ctx context.Context, record *domain.ImportRecord) error {
+	r.records[importKey(record.UserID, record.Source, record.Kind, record.SourceID)] = record
+	return nil
+}
+
+type archiveFixture struct {
+	service   ArchiveService
+	diaries   *stubDiaryService
+	todos     *memTodoRepo
+	projects  *memProjectRepo
+	images    *memImageRepo
+	imageSvc  ImageService
+	todoSvc   TodoService
+	users     *memUserRepo
+	uploadDir string
+}
+
+func newArchiveFixture(t *testing.T) *archiveFixture {
+	t.Helper()
+	cfg := &config.Config{UploadDir: t.TempDir(), MaxImageMB: 1, JWTSecret: "secret"}
+	f := &archiveFixture{
+		users:     &memUserRepo{},
+		uploadDir: cfg.UploadDir,
+		diaries:   &stubDiaryService{},
+		todos:     newMemTodoRepo(),
+		projects:  newMemProjectRepo(),
+		images:    newMemImageRepo(),
+	}
+	f.imageSvc = NewImageService(f.images, cfg)
+	f.todoSvc = NewTodoService(f.todos, f.projects)
+	projectSvc := NewProjectService(f.projects, f.todos)
+	f.service = NewArchiveService(f.diaries, f.todoSvc, projectSvc, f.imageSvc, &memImportRepo{records: map[string]*domain.ImportRecord{}}, f.users, cfg)
+	return f
+}
+
+func readManifest(t *testing.T, archive []byte) ArchiveManifest {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]*zip.File{}
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+	var m ArchiveManifest
+	if err := readZipJSON(files, archiveManifestFile, &m); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestArchiveRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	f := newArchiveFixture(t)
+
+	// 用户 1 的数据：两个清单，清单中的待办带子任务，一张图片和引用它的置顶日记
+	f.projects.Create(ctx, &domain.TodoProject{UserID: 1, Name: "工作", Color: "#f00", SortOrder: 2})
+	f.projects.Create(ctx, &domain.TodoProject{UserID: 1, Name: "生活", SortOrder: 1})
+	work := uint(1)
+	parent, err := f.todoSvc.Create(ctx, 1, "发布", "", nil, 2, &work, nil, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.todoSvc.Create(ctx, 1, "写说明", "", nil, 0, nil, &parent.ID, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	done, err := f.todoSvc.Create(ctx, 1, "买菜", "", nil, 0, nil, nil, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.todoSvc.MarkAsDone(ctx, done.ID); err != nil {
+		t.Fatal(err)
+	}
+	// 手动把“买菜”排到“发布”前面
+	if err := f.todoSvc.Reorder(ctx, 1, []uint{done.ID, parent.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := f.imageSvc.Upload(ctx, 1, bytes.NewReader(testPNG(t)), "a.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	date := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	content := fmt.Sprintf("今天\n\n![截图](image:%d)", img.ID)
+	if _, err := f.diaries.Create(ctx, 1, nil, "标题", content, "晴", "", "", date, true, []string{"工作"}, []uint{img.ID}, nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	f.diaries.TogglePin(ctx, 1, 1)
+
+	var archive bytes.Buffer
+	if err := f.service.Export(ctx, 1, &archive); err != nil {
+		t.Fatal(err)
+	}
+	manifest := readManifest(t, archive.Bytes())
+	if manifest.Diaries != 1 || manifest.Todos != 3 || manifest.Images != 1 || manifest.Projects != 2 {
+		t.Fatalf("manifest = %+v", manifest)
+	}
+
+	// 导入到用户 2
+	report, err := f.service.Import(ctx, 2, bytes.NewReader(archive.Bytes()), int64(archive.Len()), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ImportReport{
+		Diaries:  ImportCount{Created: 1},
+		Todos:    ImportCount{Created: 3},
+		Images:   ImportCount{Created: 1},
+		Projects: ImportCount{Created: 2},
+	}
+	if report.Diaries != want.Diaries || report.Todos != want.Todos || report.Images != want.Images || report.Projects != want.Projects {
+		t.Fatalf("report = %+v, warnings %v", report, report.Warnings)
+	}
+
+	// 清单按原顺序创建
+	projects, _ := f.projects.ListByUserID(ctx, 2)
+	if len(projects) != 2 || projects[0].Name != "生活" || projects[1].Name != "工作" || projects[1].Color != "#f00" {
+		t.Fatalf("projects = %+v", projects)
+	}
+	newWork := projects[1].ID
+
+	// 待办保留清单、子任务、完成状态和手动排序
+	byTitle := map[string]domain.Todo{}
+	for _, todo := range f.todos.live() {
+		if todo.UserID == 2 {
+			byTitle[todo.Title] = todo
+		}
+	}
+	p, c, d := byTitle["发布"], byTitle["写说明"], byTitle["买菜"]
+	if p.ProjectID == nil || *p.ProjectID != newWork {
+		t.Fatalf("发布 ProjectID = %v, want %d", p.ProjectID, newWork)
+	}
+	if c.ParentID == nil || *c.ParentID != p.ID || c.ProjectID == nil || *c.ProjectID != newWork {
+		t.Fatalf("写说明 = %+v", c)
+	}
+	if !d.Done || d.SortOrder >= p.SortOrder {
+		t.Fatalf("买菜 = %+v, 发布 = %+v", d, p)
+	}
+
+	// 日记正文和附件指向新图片
+	imported := f.diaries.byUser(2)
+	if len(imported) != 1 {
+		t.Fatalf("imported %d diaries", len(imported))
+	}
+	var newImage uint
+	for id, img := range f.images.images {
+		if img.UserID == 2 {
+			newImage = id
+		}
+	}
+	got := imported[0]
+	if got.PlainContent != fmt.Sprintf("今天\n\n![截图](image:%d)", newImage) {
+		t.Fatalf("content = %q", got.PlainContent)
+	}
+	if !got.IsPinned || !got.IsPublic || got.Weather != "晴" || len(got.Tags) != 1 || len(got.Images) != 1 || got.Images[0].ID != newImage {
+		t.Fatalf("diary = %+v", got)
+	}
+
+	// 再次导出得到相同的 ArchiveID，重复导入全部跳过
+	var again bytes.Buffer
+	if err := f.service.Export(ctx, 1, &again); err != nil {
+		t.Fatal(err)
+	}
+	if id := readManifest(t, again.Bytes()).ArchiveID; id != manifest.ArchiveID {
+		t.Fatalf("ArchiveID = %s, want %s", id, manifest.ArchiveID)
+	}
+	report, err = f.service.Import(ctx, 2, bytes.NewReader(again.Bytes()), int64(again.Len()), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Diaries.Skipped != 1 || report.Todos.Skipped != 3 || report.Images.Skipped != 1 || report.Projects.Skipped != 2 ||
+		report.Diaries.Created+report.Todos.Created+report.Images.Created+report.Projects.Created != 0 {
+		t.Fatalf("第二次导入 report = %+v", report)
+	}
+	if len(f.diaries.byUser(2)) != 1 {
+		t.Fatal("重复导入不应创建日记")
+	}
+
+	// 其他账号的导出包有不同的 ArchiveID
+	var other bytes.Buffer
+	if err := f.service.Export(ctx, 2, &other); err != nil {
+		t.Fatal(err)
+	}
+	if readManifest(t, other.Bytes()).ArchiveID == manifest.ArchiveID {
+		t.Fatal("不同账号的 ArchiveID 不应相同")
+	}
+}
+
+func TestArchiveImportRejectsInvalid(t *testing.T) {
+	ctx := context.Background()
+	f := newArchiveFixture(t)
+
+	build := func(manifest interface{}) []byte {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		if manifest != nil {
+			w, _ := zw.Create(archiveManifestFile)
+			json.NewEncoder(w).Encode(manifest)
+		}
+		for _, name := range []string{archiveDiariesFile, archiveTodosFile, archiveImagesFile} {
+			w, _ := zw.Create(name)
+			w.Write([]byte("[]"))
+		}
+		zw.Close()
+		return buf.Bytes()
+	}
+
+	tests := []struct {
+		name    string
+		archive []byte
+		wantErr error
+	}{
+		{"不是 zip", []byte("not a zip"), ErrInvalidArchive},
+		{"没有清单", build(nil), ErrInvalidArchive},
+		{"没有 ArchiveID", build(ArchiveManifest{Version: 1}), ErrInvalidArchive},
+		{"更新的版本", build(ArchiveManifest{Version: ArchiveVersion + 1, ArchiveID: "x"}), ErrUnsupportedArchive},
+		{"没有 projects.json 的旧导出包", build(ArchiveManifest{Version: 1, ArchiveID: "x"}), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := f.service.Import(ctx, 1, bytes.NewReader(tt.archive), int64(len(tt.archive)), false); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// limitWriter 写入超过 limit 字节后返回错误，模拟下载中途断开
+type limitWriter struct {
+	limit, written int
+}
+
+func (w *limitWriter) Write(p []byte) (int, error) {
+	if w.written+len(p) > w.limit {
+		return 0, errors.New("connection reset")
+	}
+	w.written += len(p)
+	return len(p), nil
+}
+
+func noisePNG(t *testing.T, size int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	rand.New(rand.NewSource(1)).Read(img.Pix)
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestArchiveExportImageErrors(t *testing.T) {
+	ctx := context.Background()
+	f := newArchiveFixture(t)
+
+	missing, err := f.imageSvc.Upload(ctx, 1, bytes.NewReader(testPNG(t)), "missing.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.imageSvc.Upload(ctx, 1, bytes.NewReader(noisePNG(t, 128)), "noise.png"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(f.uploadDir, f.images.images[missing.ID].Path)); err != nil {
+		t.Fatal(err)
+	}
+
+	// 文件丢失的附件跳过，导出仍然成功
+	var archive bytes.Buffer
+	if err := f.service.Export(ctx, 1, &archive); err != nil {
+		t.Fatal(err)
+	}
+	if manifest := readManifest(t, archive.Bytes()); manifest.Images != 1 {
+		t.Fatalf("manifest images = %d, want 1", manifest.Images)
+	}
+
+	// 写出附件时出错必须返回错误，不能继续生成看起来完整的导出包
+	if err := f.service.Export(ctx, 1, &limitWriter{limit: 16 << 10}); err == nil {
+		t.Fatal("写入失败时应返回错误")
+	}
+}
+
+func TestArchiveIDSurvivesSecretRotation(t *testing.T) {
+	ctx := context.Background()
+	f := newArchiveFixture(t)
+
+	var before bytes.Buffer
+	if err := f.service.Export(ctx, 1, &before); err != nil {
+		t.Fatal(err)
+	}
+
+	// 轮换实例密钥后，同一账号的导出包ID不变，重新导入旧导出包不会产生重复数据
+	rotated := NewArchiveService(f.diaries, f.todoSvc, NewProjectService(f.projects, f.todos), f.imageSvc,
+		&memImportRepo{records: map[string]*domain.ImportRecord{}}, f.users, &config.Config{UploadDir: f.uploadDir, JWTSecret: "rotated"})
+	var after bytes.Buffer
+	if err := rotated.Export(ctx, 1, &after); err != nil {
+		t.Fatal(err)
+	}
+	if a, b := readManifest(t, before.Bytes()).ArchiveID, readManifest(t, after.Bytes()).ArchiveID; a == "" || a != b {
+		t.Fatalf("ArchiveID %q != %q", a, b)
+	}
+}
@@ -0,0 +1,386 @@
+package service
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"diary/internal/domain"
+	"diary/pkg/ical"
+)
+
+var (
+	ErrCalendarFeedNotFound = errors.New("日历订阅不存在")
+	ErrInvalidCalendarConf  = errors.New("无效的日历订阅设置")
+)
+
+const (
+	calendarProdID = "-//diary//calendar//ZH"
+	// calendarImportSource 导入映射中 .ics 文件的来源标识，按 UID 去重
+	calendarImportSource = "ical"
+	// maxCalendarImportItems 单次导入最多处理的事件数
+	maxCalendarImportItems = 2000
+)
+
+type CalendarService interface {
+	// GetFeed 获取订阅设置，未开启时返回 ErrCalendarFeedNotFound
+	GetFeed(ctx context.Context, userID uint) (*domain.CalendarFeed, error)
+	// UpdateFeed 修改订阅内容，不改变令牌
+	UpdateFeed(ctx context.Context, userID uint, includeDiaries bool, todoComponent string) (*domain.CalendarFeed, error)
+	// RotateToken 开启订阅或更换令牌，旧链接立即失效；令牌明文只在这里返回
+	RotateToken(ctx context.Context, userID uint) (string, *domain.CalendarFeed, error)
+	// Revoke 关闭订阅
+	Revoke(ctx context.Context, userID uint) error
+	// Feed 根据令牌生成日历，令牌无效时返回 ErrCalendarFeedNotFound
+	Feed(ctx context.Context, token string) (*ical.Component, error)
+	// Import 将 .ics 中的 VTODO 和 VEVENT 导入为待办，没有时区的时间按 tz 解释
+	Import(ctx context.Context, userID uint, r io.Reader, tz string, dryRun bool) (*ImportReport, error)
+}
+
+type calendarService struct {
+	feedRepo     domain.CalendarFeedRepository
+	todoService  TodoService
+	diaryService DiaryService
+	importRepo   domain.ImportRecordRepository
+}
+
+func NewCalendarService(feedRepo domain.CalendarFeedRepository, todoService TodoService, diaryService DiaryService, importRepo domain.ImportRecordRepository) CalendarService {
+	return &calendarService{
+		feedRepo:     feedRepo,
+		todoService:  todoService,
+		diaryService: diaryService,
+		importRepo:   importRepo,
+	}
+}
+
+func (s *calendarService) GetFeed(ctx context.Context, userID uint) (*domain.CalendarFeed, error) {
+	feed, err := s.feedRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, ErrCalendarFeedNotFound
+	}
+	return feed, nil
+}
+
+func (s *calendarService) UpdateFeed(ctx context.Context, userID uint, includeDiaries bool, todoComponent string) (*domain.CalendarFeed, error) {
+	feed, err := s.GetFeed(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	switch todoComponent = strings.ToUpper(todoComponent); todoComponent {
+	case "":
+		todoComponent = domain.CalendarTodoAsEvent
+	case domain.CalendarTodoAsEvent, domain.CalendarTodoAsTodo:
+	default:
+		return nil, fmt.Errorf("%w: todo_component 只能是 VEVENT 或 VTODO", ErrInvalidCalendarConf)
+	}
+
+	feed.IncludeDiaries = includeDiaries
+	feed.TodoComponent = todoComponent
+	if err := s.feedRepo.Save(ctx, feed); err != nil {
+		return nil, err
+	}
+	return feed, nil
+}
+
+func (s *calendarService) RotateToken(ctx context.Context, userID uint) (string, *domain.CalendarFeed, error) {
+	token, err := newAccessToken()
+	if err != nil {
+		return "", nil, err
+	}
+
+	feed, err := s.feedRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		feed = &domain.CalendarFeed{UserID: userID, TodoComponent: domain.CalendarTodoAsEvent}
+	}
+	feed.TokenHash = hashAccessToken(token)
+	if err := s.feedRepo.Save(ctx, feed); err != nil {
+		return "", nil, err
+	}
+	return token, feed, nil
+}
+
+func (s *calendarService) Revoke(ctx context.Context, userID uint) error {
+	return s.feedRepo.Delete(ctx, userID)
+}
+
+func (s *calendarService) Feed(ctx context.Context, token string) (*ical.Component, error) {
+	if token == "" {
+		return nil, ErrCalendarFeedNotFound
+	}
+	feed, err := s.feedRepo.GetByTokenHash(ctx, hashAccessToken(token))
+	if err != nil {
+		return nil, ErrCalendarFeedNotFound
+	}
+
+	cal := ical.NewCalendar(calendarProdID, "日记")
+
+	// 只输出已有的待办；重复待办的后续次数在完成本次后才生成，不写 RRULE 以免与已完成的记录重复
+	due := time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
+	filter := domain.TodoFilter{DueFrom: &due, Sort: "due_date"}
+	for page := 1; ; page++ {
+		todos, total, err := s.todoService.List(ctx, feed.UserID, filter, page, 100)
+		if err != nil {
+			return nil, err
+		}
+		for i := range todos {
+			cal.Children = append(cal.Children, todoComponent(&todos[i], feed.TodoComponent))
+		}
+		if len(todos) == 0 || int64(page*100) >= total {
+			break
+		}
+	}
+
+	if feed.IncludeDiaries {
+		start := time.Date(1970, 1, 1, 0, 0, 0, 0, time.Local)
+		end := time.Now().AddDate(1, 0, 0)
+		err := s.diaryService.EachByDateRange(ctx, feed.UserID, start, end, func(diary *domain.Diary) error {
+			cal.Children = append(cal.Children, diaryComponent(diary))
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return cal, nil
+}
+
+// todoComponent 待办写成 VTODO，或写成 VEVENT 以便不支持任务的日历应用显示，已完成的标题前加 ✓
+func todoComponent(todo *domain.Todo, as string) *ical.Component {
+	if as != domain.CalendarTodoAsTodo {
+		as = domain.CalendarTodoAsEvent
+	}
+	c := &ical.Component{Name: as}
+	c.Add("UID", fmt.Sprintf("todo-%d@diary", todo.ID))
+	c.AddTime("DTSTAMP", todo.UpdatedAt)
+	c.AddTime("LAST-MODIFIED", todo.UpdatedAt)
+
+	if as == domain.CalendarTodoAsTodo {
+		c.AddText("SUMMARY", todo.Title)
+		c.AddTime("DUE", *todo.DueDate)
+		if todo.Done {
+			c.Add("STATUS", "COMPLETED")
+			c.AddTime("COMPLETED", todo.UpdatedAt)
+		} else {
+			c.Add("STATUS", "NEEDS-ACTION")
+		}
+	} else {
+		title := todo.Title
+		if todo.Done {
+			title = "✓ " + title
+		}
+		c.AddText("SUMMARY", title)
+		c.AddTime("DTSTART", *todo.DueDate)
+		c.Add("TRANSP", "TRANSPARENT")
+	}
+	c.AddText("DESCRIPTION", todo.Description)
+	if p := icalPriority(todo.Priority); p > 0 {
+		c.Add("PRIORITY", fmt.Sprint(p))
+	}
+	return c
+}
+
+// diaryComponent 日记写成全天事件
+func diaryComponent(diary *domain.Diary) *ical.Component {
+	title := diary.Title
+	if title == "" {
+		title = "日记"
+	}
+	var meta []string
+	for _, v := range []string{diary.Weather, diary.Mood, diary.Location} {
+		if v != "" {
+			meta = append(meta, v)
+		}
+	}
+
+	c := &ical.Component{Name: "VEVENT"}
+	c.Add("UID", fmt.Sprintf("diary-%d@diary", diary.ID))
+	c.AddTime("DTSTAMP", diary.UpdatedAt)
+	c.AddTime("LAST-MODIFIED", diary.UpdatedAt)
+	c.AddText("SUMMARY", title)
+	c.AddDate("DTSTART", diary.Date)
+	c.AddDate("DTEND", diary.Date.AddDate(0, 0, 1))
+	c.AddText("DESCRIPTION", strings.Join(meta, " · "))
+	c.Add("TRANSP", "TRANSPARENT")
+	return c
+}
+
+// icalPriority 优先级映射：高 1、中 5、低 9，iCalendar 中 0 表示未设置
+func icalPriority(priority int) int {
+	switch priority {
+	case domain.TodoPriorityHigh:
+		return 1
+	case domain.TodoPriorityMedium:
+		return 5
+	case domain.TodoPriorityLow:
+		return 9
+	}
+	return 0
+}
+
+func todoPriority(p int) int {
+	switch {
+	case p >= 1 && p <= 4:
+		return domain.TodoPriorityHigh
+	case p == 5:
+		return domain.TodoPriorityMedium
+	case p >= 6 && p <= 9:
+		return domain.TodoPriorityLow
+	}
+	return domain.TodoPriorityNone
+}
+
+// calendarItem 从 VTODO/VEVENT 中取出的待办
+type calendarItem struct {
+	uid         string
+	title       string
+	description string
+	due         *time.Time
+	priority    int
+	done        bool
+	rule        string
+	tz          string
+}
+
+func (s *calendarService) Import(ctx context.Context, userID uint, r io.Reader, tz string, dryRun bool) (*ImportReport, error) {
+	loc, err := loadTimezone(tz)
+	if err != nil {
+		return nil, err
+	}
+	cal, err := ical.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ImportReport{Source: calendarImportSource, DryRun: dryRun}
+	n := 0
+	for _, c := range cal.Children {
+		if c.Name != "VTODO" && c.Name != "VEVENT" {
+			continue
+		}
+		if n++; n > maxCalendarImportItems {
+			report.warn("事件超过 %d 个，其余未导入", maxCalendarImportItems)
+			break
+		}
+
+		item, ok := parseCalendarItem(c, loc, report)
+		if !ok {
+			continue
+		}
+		_, created, err := importOnce(ctx, s.importRepo, userID, calendarImportSource, domain.ImportKindTodo, item.uid, dryRun, func() (uint, error) {
+			return s.createTodo(ctx, userID, item, report)
+		})
+		if err != nil {
+			return nil, err
+		}
+		countImport(&report.Todos, created)
+		if dryRun {
+			preview := ImportPreview{SourceID: item.uid, Title: item.title, Exists: !created}
+			if item.due != nil {
+				preview.Date = *item.due
+			}
+			report.Entries = append(report.Entries, preview)
+		}
+	}
+	return report, nil
+}
+
+// parseCalendarItem 读取标题、截止时间、优先级、完成状态和重复规则；返回 false 表示跳过
+func parseCalendarItem(c *ical.Component, loc *time.Location, report *ImportReport) (*calendarItem, bool) {
+	item := &calendarItem{
+		title:       strings.TrimSpace(c.Text("SUMMARY")),
+		description: c.Text("DESCRIPTION"),
+	}
+	if item.title == "" {
+		item.title = "(无标题)"
+	}
+	item.title = truncate(item.title, 255)
+
+	// 重复事件中单独修改过的某一次，只导入原始事件
+	if c.Get("RECURRENCE-ID") != nil {
+		report.warn("%s：跳过重复事件中单独修改的一次", item.title)
+		return nil, false
+	}
+	status := strings.ToUpper(c.Text("STATUS"))
+	if status == "CANCELLED" {
+		report.warn("%s：已取消，跳过", item.title)
+		return nil, false
+	}
+	item.done = status == "COMPLETED" || c.Get("COMPLETED") != nil
+
+	// 待办使用 DUE，没有时使用 DTSTART；全天事件为当天 0 点
+	var dueProp *ical.Property
+	if c.Name == "VTODO" {
+		dueProp = c.Get("DUE")
+	}
+	if dueProp == nil {
+		dueProp = c.Get("DTSTART")
+	}
+	if dueProp != nil {
+		due, _, err := dueProp.Time(loc)
+		if err != nil {
+			report.warn("%s：%v，忽略截止时间", item.title, err)
+		} else {
+			item.due = &due
+			item.tz = dueProp.TZID()
+		}
+	}
+
+	if p := c.Get("PRIORITY"); p != nil {
+		var v int
+		fmt.Sscan(p.Value, &v)
+		item.priority = todoPriority(v)
+	}
+
+	if rule := c.Get("RRULE"); rule != nil && !item.done {
+		switch {
+		case item.due == nil:
+			report.warn("%s：没有时间，忽略重复规则", item.title)
+		default:
+			tz := item.tz
+			if _, err := loadTimezone(tz); err != nil {
+				tz = ""
+			}
+			if tz == "" && loc != time.Local {
+				tz = loc.String()
+			}
+			if _, err := parseRecurrence(rule.Value, tz, *item.due); err != nil {
+				report.warn("%s：不支持的重复规则 %s，按单次导入", item.title, rule.Value)
+			} else {
+				item.rule, item.tz = rule.Value, tz
+			}
+		}
+	}
+
+	item.uid = c.Text("UID")
+	if item.uid == "" {
+		var start string
+		if item.due != nil {
+			start = item.due.UTC().Format(time.RFC3339)
+		}
+		item.uid = item.title + "|" + start
+	}
+	if len(item.uid) > 64 {
+		sum := sha1.Sum([]byte(item.uid))
+		item.uid = hex.EncodeToString(sum[:])
+	}
+	return item, true
+}
+
+// createTodo 已完成的待办不带重复规则导入，避免标记完成时生成下一次
+func (s *calendarService) createTodo(ctx context.Context, userID uint, item *calendarItem, report *ImportReport) (uint, error) {
+	todo, err := s.todoService.Create(ctx, userID, item.title, item.description, item.due, item.priority, nil, nil, item.rule, item.tz)
+	if err != nil {
+		return 0, err
+	}
+	if item.done {
+		if err := s.todoService.MarkAsDone(ctx, todo.ID); err != nil {
+			report.warn("%s：标记完成失败：%v", item.title, err)
+		}
+	}
+	return todo.ID, nil
+}
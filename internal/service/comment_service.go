@@ -0,0 +1,338 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"diary/config"
+	"diary/internal/domain"
+	"diary/pkg/markdown"
+)
+
+var (
+	ErrCommentNotFound  = errors.New("评论不存在")
+	ErrCommentForbidden = errors.New("无权操作此评论")
+	ErrCommentsDisabled = errors.New("作者已关闭评论")
+	ErrInvalidEmoji     = errors.New("无效的表情")
+)
+
+// Viewer 访问日记的用户，通过分享链接访问时带上令牌和密码
+type Viewer struct {
+	UserID        uint
+	ShareToken    string
+	SharePassword string
+}
+
+type CommentService interface {
+	// List 按楼层分页获取评论，每个顶层评论带上完整的回复树
+	List(ctx context.Context, viewer Viewer, diaryID uint, page, pageSize int) ([]domain.Comment, int64, error)
+	// Create 发表评论，parentID 不为空时回复该评论
+	Create(ctx context.Context, viewer Viewer, diaryID uint, parentID *uint, content string) (*domain.Comment, error)
+	// Update 修改自己的评论
+	Update(ctx context.Context, viewer Viewer, id uint, content string) (*domain.Comment, error)
+	// Delete 删除评论，评论者和日记作者都可以删除；有回复的评论保留位置
+	Delete(ctx context.Context, userID, id uint) error
+	// SetCommentsDisabled 作者开启或关闭日记的评论
+	SetCommentsDisabled(ctx context.Context, userID, diaryID uint, disabled bool) error
+	// React 对日记回应表情，重复回应不报错
+	React(ctx context.Context, viewer Viewer, diaryID uint, emoji string) ([]domain.ReactionCount, error)
+	// Unreact 取消表情回应
+	Unreact(ctx context.Context, viewer Viewer, diaryID uint, emoji string) ([]domain.ReactionCount, error)
+	// Reactions 获取日记的表情统计，标记当前用户回应过的表情
+	Reactions(ctx context.Context, viewer Viewer, diaryID uint) ([]domain.ReactionCount, error)
+}
+
+type commentService struct {
+	commentRepo    domain.CommentRepository
+	reactionRepo   domain.ReactionRepository
+	diaryRepo      domain.DiaryRepository
+	blockRepo      domain.BlockRepository
+	shareService   ShareService
+	profileService ProfileService
+	renderer       *markdown.Renderer
+}
+
+func NewCommentService(commentRepo domain.CommentRepository, reactionRepo domain.ReactionRepository, diaryRepo domain.DiaryRepository,
+	blockRepo domain.BlockRepository, shareService ShareService, profileService ProfileService, cfg *config.Config) CommentService {
+	return &commentService{
+		commentRepo:    commentRepo,
+		reactionRepo:   reactionRepo,
+		diaryRepo:      diaryRepo,
+		blockRepo:      blockRepo,
+		shareService:   shareService,
+		profileService: profileService,
+		renderer:       markdown.NewRenderer(cfg.MarkdownCacheSize),
+	}
+}
+
+func (s *commentService) List(ctx context.Context, viewer Viewer, diaryID uint, page, pageSize int) ([]domain.Comment, int64, error) {
+	if _, err := s.authorize(ctx, viewer, diaryID); err != nil {
+		return nil, 0, err
+	}
+	if page < 1 {
+		page = 1
+	}
+	// 每个根评论会带出全部回复，限制单页数量
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	roots, total, err := s.commentRepo.ListRoots(ctx, diaryID, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	rootIDs := make([]uint, len(roots))
+	for i := range roots {
+		rootIDs[i] = roots[i].ID
+	}
+	replies, err := s.commentRepo.ListByRootIDs(ctx, rootIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	all := append(roots, replies...)
+	if err := s.fill(ctx, all); err != nil {
+		return nil, 0, err
+	}
+	return buildCommentTree(all[:len(roots)], all[len(roots):]), total, nil
+}
+
+func (s *commentService) Create(ctx context.Context, viewer Viewer, diaryID uint, parentID *uint, content string) (*domain.Comment, error) {
+	diary, err := s.authorize(ctx, viewer, diaryID)
+	if err != nil {
+		return nil, err
+	}
+	if diary.CommentsDisabled {
+		return nil, ErrCommentsDisabled
+	}
+
+	comment := &domain.Comment{DiaryID: diaryID, UserID: viewer.UserID, Content: content}
+	if parentID != nil {
+		parent, err := s.commentRepo.GetByID(ctx, *parentID)
+		if err != nil || parent.DiaryID != diaryID || parent.IsDeleted {
+			return nil, ErrCommentNotFound
+		}
+		comment.ParentID = &parent.ID
+		comment.RootID = parent.RootID
+		if comment.RootID == nil {
+			comment.RootID = &parent.ID
+		}
+	}
+
+	if err := s.commentRepo.Create(ctx, comment); err != nil {
+		return nil, err
+	}
+	return s.fillOne(ctx, comment)
+}
+
+func (s *commentService) Update(ctx context.Context, viewer Viewer, id uint, content string) (*domain.Comment, error) {
+	comment, err := s.commentRepo.GetByID(ctx, id)
+	if err != nil || comment.IsDeleted {
+		return nil, ErrCommentNotFound
+	}
+	if comment.UserID != viewer.UserID {
+		return nil, ErrCommentForbidden
+	}
+	diary, err := s.authorize(ctx, viewer, comment.DiaryID)
+	if err != nil {
+		return nil, err
+	}
+	if diary.CommentsDisabled {
+		return nil, ErrCommentsDisabled
+	}
+
+	now := time.Now()
+	if err := s.commentRepo.UpdateContent(ctx, id, content, now); err != nil {
+		return nil, err
+	}
+	comment.Content = content
+	comment.EditedAt = &now
+	comment.UpdatedAt = now
+	return s.fillOne(ctx, comment)
+}
+
+func (s *commentService) Delete(ctx context.Context, userID, id uint) error {
+	comment, err := s.commentRepo.GetByID(ctx, id)
+	if err != nil || comment.IsDeleted {
+		return ErrCommentNotFound
+	}
+	if comment.UserID != userID {
+		diary, err := s.diaryRepo.GetByID(ctx, comment.DiaryID)
+		if err != nil || diary.UserID != userID {
+			return ErrCommentForbidden
+		}
+	}
+	return s.commentRepo.Delete(ctx, id)
+}
+
+func (s *commentService) SetCommentsDisabled(ctx context.Context, userID, diaryID uint, disabled bool) error {
+	diary, err := s.diaryRepo.GetByID(ctx, diaryID)
+	if err != nil {
+		return ErrDiaryNotFound
+	}
+	if diary.UserID != userID {
+		return ErrDiaryForbidden
+	}
+	return s.diaryRepo.UpdateCommentsDisabled(ctx, diaryID, disabled)
+}
+
+func (s *commentService) React(ctx context.Context, viewer Viewer, diaryID uint, emoji string) ([]domain.ReactionCount, error) {
+	if !validEmoji(emoji) {
+		return nil, ErrInvalidEmoji
+	}
+	if _, err := s.authorize(ctx, viewer, diaryID); err != nil {
+		return nil, err
+	}
+	reaction := &domain.Reaction{DiaryID: diaryID, UserID: viewer.UserID, Emoji: emoji}
+	if err := s.reactionRepo.Add(ctx, reaction); err != nil {
+		return nil, err
+	}
+	return s.reactions(ctx, viewer.UserID, diaryID)
+}
+
+func (s *commentService) Unreact(ctx context.Context, viewer Viewer, diaryID uint, emoji string) ([]domain.ReactionCount, error) {
+	if _, err := s.authorize(ctx, viewer, diaryID); err != nil {
+		return nil, err
+	}
+	if err := s.reactionRepo.Remove(ctx, diaryID, viewer.UserID, emoji); err != nil {
+		return nil, err
+	}
+	return s.reactions(ctx, viewer.UserID, diaryID)
+}
+
+func (s *commentService) Reactions(ctx context.Context, viewer Viewer, diaryID uint) ([]domain.ReactionCount, error) {
+	if _, err := s.authorize(ctx, viewer, diaryID); err != nil {
+		return nil, err
+	}
+	return s.reactions(ctx, viewer.UserID, diaryID)
+}
+
+func (s *commentService) reactions(ctx context.Context, userID, diaryID uint) ([]domain.ReactionCount, error) {
+	counts, err := s.reactionRepo.CountByDiaryIDs(ctx, []uint{diaryID})
+	if err != nil {
+		return nil, err
+	}
+	mine, err := s.reactionRepo.ListEmojis(ctx, diaryID, userID)
+	if err != nil {
+		return nil, err
+	}
+	reacted := make(map[string]bool, len(mine))
+	for _, e := range mine {
+		reacted[e] = true
+	}
+	result := counts[diaryID]
+	for i := range result {
+		result[i].Reacted = reacted[result[i].Emoji]
+	}
+	return result, nil
+}
+
+// authorize 日记作者、公开日记或持有该日记有效分享链接的登录用户可以查看和参与评论，
+// 被作者屏蔽的用户除外
+func (s *commentService) authorize(ctx context.Context, viewer Viewer, diaryID uint) (*domain.Diary, error) {
+	diary, err := s.diaryRepo.GetByID(ctx, diaryID)
+	if err != nil {
+		return nil, ErrDiaryNotFound
+	}
+	if diary.UserID == viewer.UserID {
+		return diary, nil
+	}
+	blocked, err := s.blockRepo.Exists(ctx, diary.UserID, viewer.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if blocked {
+		return nil, ErrDiaryForbidden
+	}
+	if diary.IsPublic {
+		return diary, nil
+	}
+	if viewer.ShareToken == "" {
+		return nil, ErrDiaryForbidden
+	}
+	if err := s.shareService.Authorize(ctx, viewer.ShareToken, viewer.SharePassword, diaryID); err != nil {
+		return nil, err
+	}
+	return diary, nil
+}
+
+func (s *commentService) fillOne(ctx context.Context, comment *domain.Comment) (*domain.Comment, error) {
+	list := []domain.Comment{*comment}
+	if err := s.fill(ctx, list); err != nil {
+		return nil, err
+	}
+	return &list[0], nil
+}
+
+// fill 渲染正文并一次查出全部评论者；已删除的评论清空内容和作者
+func (s *commentService) fill(ctx context.Context, comments []domain.Comment) error {
+	userIDs := make([]uint, 0, len(comments))
+	for i := range comments {
+		if !comments[i].IsDeleted {
+			userIDs = append(userIDs, comments[i].UserID)
+		}
+	}
+	authors, err := s.profileService.Authors(ctx, userIDs)
+	if err != nil {
+		return err
+	}
+
+	for i := range comments {
+		c := &comments[i]
+		if c.IsDeleted {
+			c.Content = ""
+			continue
+		}
+		c.Author = authors[c.UserID]
+		html, err := s.renderer.Render(c.Content)
+		if err != nil {
+			return err
+		}
+		c.ContentHTML = html
+	}
+	return nil
+}
+
+// buildCommentTree 按 ParentID 把回复挂到父评论下，去掉没有未删除回复的已删除评论
+func buildCommentTree(roots, replies []domain.Comment) []domain.Comment {
+	children := make(map[uint][]domain.Comment)
+	for _, c := range replies {
+		if c.ParentID != nil {
+			children[*c.ParentID] = append(children[*c.ParentID], c)
+		}
+	}
+
+	var attach func(list []domain.Comment) []domain.Comment
+	attach = func(list []domain.Comment) []domain.Comment {
+		result := make([]domain.Comment, 0, len(list))
+		for _, c := range list {
+			c.Replies = attach(children[c.ID])
+			if c.IsDeleted && len(c.Replies) == 0 {
+				continue
+			}
+			result = append(result, c)
+		}
+		return result
+	}
+	return attach(roots)
+}
+
+// validEmoji 只接受表情符号，包括肤色修饰和 ZWJ 组合
+func validEmoji(emoji string) bool {
+	if emoji == "" || len(emoji) > 32 || !utf8.ValidString(emoji) {
+		return false
+	}
+	for _, r := range emoji {
+		switch {
+		case unicode.Is(unicode.So, r):
+		case r == '\u200d', r == '\ufe0f': // ZWJ、变体选择符
+		case r >= 0x1f3fb && r <= 0x1f3ff:
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"diary/config"
+	"diary/internal/domain"
+)
+
+type memCommentRepo struct {
+	domain.CommentRepository
+	comments map[uint]*domain.Comment
+	nextID   uint
+}
+
+func (r *memCommentRepo) Create(ctx context.Context, comment *domain.Comment) error {
+	r.nextID++
+	comment.ID = r.nextID
+	copied := *comment
+	r.comments[comment.ID] = &copied
+	return nil
+}
+
+func (r *memCommentRepo) GetByID(ctx context.Context, id uint) (*domain.Comment, error) {
+	c, ok := r.comments[id]
+	if !ok {
+		return nil, errFakeNotFound
+	}
+	copied := *c
+	return &copied, nil
+}
+
+func (r *memCommentRepo) UpdateContent(ctx context.Context, id uint, content string, editedAt time.Time) error {
+	r.comments[id].Content = content
+	r.comments[id].EditedAt = &editedAt
+	return nil
+}
+
+func (r *memCommentRepo) Delete(ctx context.Context, id uint) error {
+	r.comments[id].IsDeleted = true
+	return nil
+}
+
+// memBlockRepo 键为 [屏蔽者, 被屏蔽者]
+type memBlockRepo struct {
+	domain.BlockRepository
+	blocked map[[2]uint]bool
+}
+
+func (r *memBlockRepo) Exists(ctx context.Context, blockerID, blockedID uint) (bool, error) {
+	return r.blocked[[2]uint{blockerID, blockedID}], nil
+}
+
+type stubProfileService struct {
+	ProfileService
+}
+
+func (stubProfileService) Authors(ctx context.Context, userIDs []uint) (map[uint]*domain.User, error) {
+	return map[uint]*domain.User{}, nil
+}
+
+// 用户 1 是作者，用户 3 被作者屏蔽
+func newTestCommentService(t *testing.T) (CommentService, *memCommentRepo, string) {
+	t.Helper()
+	diaries := newMemDiaryRepo(
+		domain.Diary{ID: 1, UserID: 1},
+		domain.Diary{ID: 2, UserID: 1, IsPublic: true},
+		domain.Diary{ID: 3, UserID: 1, IsPublic: true, CommentsDisabled: true},
+		domain.Diary{ID: 4, UserID: 1},
+	)
+	shares := NewShareService(&memShareRepo{links: map[uint]*domain.ShareLink{}}, diaries, nil)
+	token, _, err := shares.Create(context.Background(), 1, 1, nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	comments := &memCommentRepo{comments: map[uint]*domain.Comment{}}
+	blocks := &memBlockRepo{blocked: map[[2]uint]bool{{1, 3}: true}}
+	s := NewCommentService(comments, nil, diaries, blocks, shares, stubProfileService{}, &config.Config{})
+	return s, comments, token
+}
+
+func TestCommentAuthorize(t *testing.T) {
+	ctx := context.Background()
+	s, _, token := newTestCommentService(t)
+
+	tests := []struct {
+		name    string
+		viewer  Viewer
+		diaryID uint
+		wantErr error
+	}{
+		{"作者评论私密日记", Viewer{UserID: 1}, 1, nil},
+		{"其他用户评论私密日记", Viewer{UserID: 2}, 1, ErrDiaryForbidden},
+		{"持有分享链接", Viewer{UserID: 2, ShareToken: token}, 1, nil},
+		{"分享链接只对应一篇日记", Viewer{UserID: 2, ShareToken: token}, 4, ErrShareNotFound},
+		{"被屏蔽的用户持有分享链接", Viewer{UserID: 3, ShareToken: token}, 1, ErrDiaryForbidden},
+		{"公开日记", Viewer{UserID: 2}, 2, nil},
+		{"被屏蔽的用户评论公开日记", Viewer{UserID: 3}, 2, ErrDiaryForbidden},
+		{"作者关闭了评论", Viewer{UserID: 2}, 3, ErrCommentsDisabled},
+		{"日记不存在", Viewer{UserID: 2}, 99, ErrDiaryNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.Create(ctx, tt.viewer, tt.diaryID, nil, "你好"); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// 被屏蔽的用户同样不能查看公开日记的评论和回应
+func TestCommentBlockedViewer(t *testing.T) {
+	ctx := context.Background()
+	s, _, _ := newTestCommentService(t)
+	blocked := Viewer{UserID: 3}
+
+	if _, _, err := s.List(ctx, blocked, 2, 1, 20); !errors.Is(err, ErrDiaryForbidden) {
+		t.Errorf("查看评论 err = %v, want %v", err, ErrDiaryForbidden)
+	}
+	if _, err := s.Reactions(ctx, blocked, 2); !errors.Is(err, ErrDiaryForbidden) {
+		t.Errorf("查看回应 err = %v, want %v", err, ErrDiaryForbidden)
+	}
+	if _, err := s.React(ctx, blocked, 2, "👍"); !errors.Is(err, ErrDiaryForbidden) {
+		t.Errorf("回应 err = %v, want %v", err, ErrDiaryForbidden)
+	}
+}
+
+func TestCommentReplyMustBeOnSameDiary(t *testing.T) {
+	ctx := context.Background()
+	s, _, _ := newTestCommentService(t)
+
+	root, err := s.Create(ctx, Viewer{UserID: 2}, 2, nil, "一楼")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reply, err := s.Create(ctx, Viewer{UserID: 1}, 2, &root.ID, "回复")
+	if err != nil {
+		t.Fatal(err)
+	}
+	nested, err := s.Create(ctx, Viewer{UserID: 2}, 2, &reply.ID, "再回复")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nested.RootID == nil || *nested.RootID != root.ID {
+		t.Fatalf("RootID = %v, want %d", nested.RootID, root.ID)
+	}
+
+	if _, err := s.Create(ctx, Viewer{UserID: 1}, 1, &root.ID, "串楼"); !errors.Is(err, ErrCommentNotFound) {
+		t.Fatalf("回复其他日记的评论 err = %v, want %v", err, ErrCommentNotFound)
+	}
+}
+
+func TestCommentEditAndDelete(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		userID     uint
+		wantEdit   error
+		wantDelete error
+	}{
+		{"评论者", 2, nil, nil},
+		{"日记作者只能删除", 1, ErrCommentForbidden, nil},
+		{"其他用户", 4, ErrCommentForbidden, ErrCommentForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, repo, _ := newTestCommentService(t)
+			c, err := s.Create(ctx, Viewer{UserID: 2}, 2, nil, "原文")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := s.Update(ctx, Viewer{UserID: tt.userID}, c.ID, "改过"); !errors.Is(err, tt.wantEdit) {
+				t.Fatalf("Update err = %v, want %v", err, tt.wantEdit)
+			}
+			if err := s.Delete(ctx, tt.userID, c.ID); !errors.Is(err, tt.wantDelete) {
+				t.Fatalf("Delete err = %v, want %v", err, tt.wantDelete)
+			}
+			if deleted := repo.comments[c.ID].IsDeleted; deleted != (tt.wantDelete == nil) {
+				t.Fatalf("IsDeleted = %v", deleted)
+			}
+		})
+	}
+}
+
+func TestSetCommentsDisabledOwnerOnly(t *testing.T) {
+	ctx := context.Background()
+	s, _, _ := newTestCommentService(t)
+
+	if err := s.SetCommentsDisabled(ctx, 2, 2, true); !errors.Is(err, ErrDiaryForbidden) {
+		t.Fatalf("err = %v, want %v", err, ErrDiaryForbidden)
+	}
+	if err := s.SetCommentsDisabled(ctx, 1, 2, true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Create(ctx, Viewer{UserID: 2}, 2, nil, "还能评论吗"); !errors.Is(err, ErrCommentsDisabled) {
+		t.Fatalf("err = %v, want %v", err, ErrCommentsDisabled)
+	}
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	"diary/internal/domain"
+)
+
+var ErrInvalidCursor = errors.New("无效的游标")
+
+// 游标分页的每页数量
+const (
+	defaultCursorLimit = 20
+	maxCursorLimit     = 100
+)
+
+// encodeCursor 把游标编码为客户端原样回传的不透明字符串
+func encodeCursor(c interface{}) string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodeCursor 解析 encodeCursor 生成的游标，空字符串返回 false
+func decodeCursor(s string, c interface{}) (bool, error) {
+	if s == "" {
+		return false, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || json.Unmarshal(raw, c) != nil {
+		return false, ErrInvalidCursor
+	}
+	return true, nil
+}
+
+func encodeDiaryCursor(c domain.DiaryCursor) string {
+	return encodeCursor(c)
+}
+
+// decodeDiaryCursor 空字符串返回 nil
+func decodeDiaryCursor(s string) (*domain.DiaryCursor, error) {
+	var c domain.DiaryCursor
+	if ok, err := decodeCursor(s, &c); !ok {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func decodeTimeCursor(s string) (*domain.TimeCursor, error) {
+	var c domain.TimeCursor
+	if ok, err := decodeCursor(s, &c); !ok {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// cursorLimit 每页数量，默认 20，最多 100
+func cursorLimit(limit int) int {
+	if limit < 1 {
+		return defaultCursorLimit
+	}
+	if limit > maxCursorLimit {
+		return maxCursorLimit
+	}
+	return limit
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"diary/internal/domain"
+)
+
+func TestDecodeCursor(t *testing.T) {
+	date := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	valid := encodeDiaryCursor(domain.DiaryCursor{Pinned: true, Date: date, ID: 9})
+
+	tests := []struct {
+		name    string
+		cursor  string
+		want    *domain.DiaryCursor
+		wantErr error
+	}{
+		{"空游标从头开始", "", nil, nil},
+		{"原样回传", valid, &domain.DiaryCursor{Pinned: true, Date: date, ID: 9}, nil},
+		{"不是 base64", "!!!", nil, ErrInvalidCursor},
+		{"不是 JSON", base64.RawURLEncoding.EncodeToString([]byte("page=2")), nil, ErrInvalidCursor},
+		{"标准 base64 的填充", valid + "==", nil, ErrInvalidCursor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeDiaryCursor(tt.cursor)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if (got == nil) != (tt.want == nil) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+			if got != nil && (got.Pinned != tt.want.Pinned || !got.Date.Equal(tt.want.Date) || got.ID != tt.want.ID) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCursorLimit(t *testing.T) {
+	for _, tt := range []struct{ in, want int }{
+		{0, defaultCursorLimit}, {-5, defaultCursorLimit}, {1, 1}, {50, 50}, {maxCursorLimit, maxCursorLimit}, {1000, maxCursorLimit},
+	} {
+		if got := cursorLimit(tt.in); got != tt.want {
+			t.Errorf("cursorLimit(%d) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNextDiaryPage(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 1, d, 0, 0, 0, 0, time.UTC) }
+	rows := []domain.Diary{
+		{ID: 5, Date: day(3), IsPinned: true},
+		{ID: 4, Date: day(2)},
+		{ID: 3, Date: day(2)},
+	}
+
+	tests := []struct {
+		name       string
+		limit      int
+		withPinned bool
+		wantLen    int
+		want       *domain.DiaryCursor
+	}{
+		{"多取的一条说明还有下一页", 2, true, 2, &domain.DiaryCursor{Date: day(2), ID: 4}},
+		{"游标记录最后一条的置顶状态", 1, true, 1, &domain.DiaryCursor{Pinned: true, Date: day(3), ID: 5}},
+		{"不区分置顶的列表忽略置顶", 1, false, 1, &domain.DiaryCursor{Date: day(3), ID: 5}},
+		{"刚好取完没有下一页", 3, true, 3, nil},
+		{"不足一页", 10, true, 3, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, next := nextDiaryPage(rows, tt.limit, tt.withPinned)
+			if len(page) != tt.wantLen {
+				t.Fatalf("len = %d, want %d", len(page), tt.wantLen)
+			}
+			if tt.want == nil {
+				if next != "" {
+					t.Fatalf("next = %q, want 空", next)
+				}
+				return
+			}
+			got, err := decodeDiaryCursor(next)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *got != *tt.want {
+				t.Fatalf("next = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// pagedTodoRepo 返回固定的结果，并记录传入的游标
+type pagedTodoRepo struct {
+	domain.TodoRepository
+	rows  []domain.Todo
+	after *domain.TodoCursor
+	limit int
+}
+
+func (r *pagedTodoRepo) ListAfter(ctx context.Context, userID uint, filter domain.TodoFilter, after *domain.TodoCursor, limit int) ([]domain.Todo, error) {
+	r.after, r.limit = after, limit
+	return r.rows[:min(limit, len(r.rows))], nil
+}
+
+func TestTodoListAfter(t *testing.T) {
+	ctx := context.Background()
+	created := time.Date(2026, 1, 1, 8, 0, 0, 0, time.UTC)
+	due := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	rows := []domain.Todo{
+		{ID: 1, Title: "a", Priority: 3, SortOrder: 1, DueDate: &due, CreatedAt: created},
+		{ID: 2, Title: "b", Priority: 2, SortOrder: 2, CreatedAt: created.Add(time.Hour)},
+		{ID: 3, Title: "c", Priority: 1, SortOrder: 3, CreatedAt: created.Add(2 * time.Hour)},
+	}
+
+	tests := []struct {
+		name   string
+		filter domain.TodoFilter
+		want   domain.TodoCursor
+	}{
+		{"默认按创建时间", domain.TodoFilter{}, domain.TodoCursor{Sort: "created_at", Time: ptrTime(created.Add(time.Hour)), ID: 2}},
+		{"未知排序按创建时间", domain.TodoFilter{Sort: "bogus"}, domain.TodoCursor{Sort: "created_at", Time: ptrTime(created.Add(time.Hour)), ID: 2}},
+		{"优先级倒序", domain.TodoFilter{Sort: "priority", Desc: true}, domain.TodoCursor{Sort: "priority", Desc: true, Int: 2, ID: 2}},
+		{"手动排序", domain.TodoFilter{Sort: "order"}, domain.TodoCursor{Sort: "order", Int: 2, ID: 2}},
+		{"标题", domain.TodoFilter{Sort: "title"}, domain.TodoCursor{Sort: "title", Text: "b", ID: 2}},
+		{"截止时间为空", domain.TodoFilter{Sort: "due_date"}, domain.TodoCursor{Sort: "due_date", ID: 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &pagedTodoRepo{rows: rows}
+			s := NewTodoService(repo, nil)
+			page, next, err := s.ListAfter(ctx, 1, tt.filter, "", 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if repo.limit != 3 || len(page) != 2 {
+				t.Fatalf("limit = %d, len = %d, want 多取一条并返回 2 条", repo.limit, len(page))
+			}
+
+			// 下一页原样传给仓储
+			if _, _, err := s.ListAfter(ctx, 1, tt.filter, next, 2); err != nil {
+				t.Fatal(err)
+			}
+			got := repo.after
+			if got == nil || got.Sort != tt.want.Sort || got.Desc != tt.want.Desc || got.ID != tt.want.ID ||
+				got.Int != tt.want.Int || got.Text != tt.want.Text || (got.Time == nil) != (tt.want.Time == nil) ||
+				(got.Time != nil && !got.Time.Equal(*tt.want.Time)) {
+				t.Fatalf("after = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTodoListAfterRejectsOtherSort(t *testing.T) {
+	ctx := context.Background()
+	due := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	repo := &pagedTodoRepo{rows: []domain.Todo{{ID: 1, DueDate: &due}, {ID: 2}}}
+	s := NewTodoService(repo, nil)
+
+	_, next, err := s.ListAfter(ctx, 1, domain.TodoFilter{Sort: "due_date"}, "", 1)
+	if err != nil || next == "" {
+		t.Fatalf("next = %q, err = %v", next, err)
+	}
+	for _, filter := range []domain.TodoFilter{
+		{Sort: "title"},
+		{Sort: "due_date", Desc: true},
+	} {
+		if _, _, err := s.ListAfter(ctx, 1, filter, next, 1); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("filter %+v: err = %v, want %v", filter, err, ErrInvalidCursor)
+		}
+	}
+}
@@ -3,52 +3,84 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"html/template"
+	"strconv"
 	"strings"
 	"time"
 
 	"diary/config"
 	"diary/internal/domain"
+	"diary/pkg/markdown"
 	"diary/pkg/utils"
 )
 
 var (
-	ErrDiaryNotFound = errors.New("日记不存在")
+	ErrDiaryNotFound  = errors.New("日记不存在")
+	ErrDiaryForbidden = errors.New("无权操作此日记")
+	ErrTooManyPinned  = errors.New("最多只能置顶3篇日记")
 )
 
+// diaryBatchSize 批量遍历日记时每次查询的条数
+const diaryBatchSize = 100
+
 type DiaryService interface {
-	Create(ctx context.Context, userID uint, title, content, weather, mood, location string, date time.Time, isPublic bool, tagNames []string, imageIDs []uint, properties map[string]interface{}, music string) (*domain.Diary, error)
+	// Create 创建日记，journalID 不为空时写入该日记本并使用日记本的密钥加密，调用方负责检查权限
+	Create(ctx context.Context, userID uint, journalID *uint, title, content, weather, mood, location string, date time.Time, isPublic bool, tagNames []string, imageIDs []uint, properties map[string]interface{}, music string) (*domain.Diary, error)
 	GetByID(ctx context.Context, id uint) (*domain.Diary, error)
-	Update(ctx context.Context, id uint, title, content, weather, mood, location string, date time.Time, isPublic bool, tagNames []string, properties map[string]interface{}, music string) error
+	// Update 修改日记，userID 为操作者，调用方负责检查权限。只有作者可以修改是否公开，日记本的其他成员修改时保持原值
+	Update(ctx context.Context, userID, id uint, title, content, weather, mood, location string, date time.Time, isPublic bool, tagNames []string, properties map[string]interface{}, music string) error
 	Delete(ctx context.Context, id uint) error
 	ListByUserID(ctx context.Context, userID uint, page, pageSize int) ([]domain.Diary, int64, error)
-	ListPublic(ctx context.Context, page, pageSize int) ([]domain.Diary, int64, error)
+	// ListByUserIDAfter 按游标获取日记（置顶在前），返回下一页的游标，为空表示没有更多
+	ListByUserIDAfter(ctx context.Context, userID uint, cursor string, limit int) ([]domain.Diary, string, error)
+	// ListPublic 按条件获取公开日记
+	ListPublic(ctx context.Context, filter domain.PublicDiaryFilter, page, pageSize int) ([]domain.Diary, int64, error)
+	// ListPublicAfter 按游标获取公开日记
+	ListPublicAfter(ctx context.Context, filter domain.PublicDiaryFilter, cursor string, limit int) ([]domain.Diary, string, error)
 	Search(ctx context.Context, userID uint, keyword string, page, pageSize int) ([]domain.Diary, int64, error)
+	// SearchAfter 按游标搜索日记
+	SearchAfter(ctx context.Context, userID uint, keyword string, cursor string, limit int) ([]domain.Diary, string, error)
+	// ListByJournalID 获取日记本内的日记，调用方负责检查成员身份
+	ListByJournalID(ctx context.Context, journalID uint, page, pageSize int) ([]domain.Diary, int64, error)
+	// SearchJournal 搜索日记本内的日记，调用方负责检查成员身份
+	SearchJournal(ctx context.Context, journalID uint, keyword string, page, pageSize int) ([]domain.Diary, int64, error)
 	GetByDateRange(ctx context.Context, userID uint, startDate, endDate time.Time) ([]domain.Diary, error)
 	GetByIDs(ctx context.Context, userID uint, ids []uint) ([]domain.Diary, error)
+	// EachByDateRange 按日期升序分批读取日记并逐篇回调，正文保留 image:ID 引用；fn 返回错误时停止
+	EachByDateRange(ctx context.Context, userID uint, startDate, endDate time.Time, fn func(diary *domain.Diary) error) error
 	TogglePin(ctx context.Context, userID, diaryID uint) (bool, error)
+	// ListFeed 按日期降序获取关注的人在游标之前的公开日记
+	ListFeed(ctx context.Context, followerID uint, before *domain.DiaryCursor, limit int) ([]domain.Diary, error)
+	// RenderHTML 将正文渲染为 HTML 并填充 ContentHTML
+	RenderHTML(ctx context.Context, diary *domain.Diary) error
 }
 
 type diaryService struct {
-	diaryRepo domain.DiaryRepository
-	tagRepo   domain.TagRepository
-	imageRepo domain.ImageRepository
-	cfg       *config.Config
+	diaryRepo    domain.DiaryRepository
+	tagRepo      domain.TagRepository
+	imageRepo    domain.ImageRepository
+	commentRepo  domain.CommentRepository
+	reactionRepo domain.ReactionRepository
+	journalRepo  domain.JournalRepository
+	cfg          *config.Config
+	renderer     *markdown.Renderer
 }
 
-func (s *diaryService) tryEncrypt(text string) (string, error) {
-	if text == "" || len(s.cfg.AESKey) != 32 {
+func (s *diaryService) tryEncrypt(key []byte, text string) (string, error) {
+	if text == "" || len(key) != 32 {
 		return text, nil
 	}
-	return utils.EncryptToString(s.cfg.AESKey, text)
+	return utils.EncryptToString(key, text)
 }
 
-func (s *diaryService) tryDecrypt(text string) string {
-	if text == "" || len(s.cfg.AESKey) != 32 {
+func (s *diaryService) tryDecrypt(key []byte, text string) string {
+	if text == "" || len(key) != 32 {
 		return text
 	}
 	// 去除可能的空白字符
 	text = strings.TrimSpace(text)
-	decrypted, err := utils.DecryptFromString(s.cfg.AESKey, text)
+	decrypted, err := utils.DecryptFromString(key, text)
 	if err != nil {
 		// 解密失败，可能是旧数据（明文），直接返回原文本
 		return text
@@ -56,17 +88,23 @@ func (s *diaryService) tryDecrypt(text string) string {
 	return decrypted
 }
 
-func (s *diaryService) decryptDiaries(diaries []domain.Diary) {
+// decryptDiaries 解密列表中的日记，日记本密钥无法获取时返回错误，不会把密文当作明文返回
+func (s *diaryService) decryptDiaries(ctx context.Context, diaries []domain.Diary) error {
+	keys := make(map[uint][]byte)
 	for i := range diaries {
-		diaries[i].Title = s.tryDecrypt(diaries[i].Title)
-		diaries[i].Weather = s.tryDecrypt(diaries[i].Weather)
-		diaries[i].Mood = s.tryDecrypt(diaries[i].Mood)
-		diaries[i].Location = s.tryDecrypt(diaries[i].Location)
-		diaries[i].Music = s.tryDecrypt(diaries[i].Music)
+		key, err := s.keyFor(ctx, diaries[i].JournalID, keys)
+		if err != nil {
+			return err
+		}
+		diaries[i].Title = s.tryDecrypt(key, diaries[i].Title)
+		diaries[i].Weather = s.tryDecrypt(key, diaries[i].Weather)
+		diaries[i].Mood = s.tryDecrypt(key, diaries[i].Mood)
+		diaries[i].Location = s.tryDecrypt(key, diaries[i].Location)
+		diaries[i].Music = s.tryDecrypt(key, diaries[i].Music)
 
 		// 解密内容并生成动态摘要
-		if len(diaries[i].ContentEnc) > 0 && len(diaries[i].IV) > 0 && len(s.cfg.AESKey) == 32 {
-			plaintext, err := utils.Decrypt(s.cfg.AESKey, diaries[i].ContentEnc, diaries[i].IV)
+		if len(diaries[i].ContentEnc) > 0 && len(diaries[i].IV) > 0 && len(key) == 32 {
+			plaintext, err := utils.Decrypt(key, diaries[i].ContentEnc, diaries[i].IV)
 			if err == nil {
 				content := string(plaintext)
 				diaries[i].PlainContent = content
@@ -74,18 +112,29 @@ func (s *diaryService) decryptDiaries(diaries []domain.Diary) {
 			}
 		}
 	}
+	return nil
 }
 
-func NewDiaryService(diaryRepo domain.DiaryRepository, tagRepo domain.TagRepository, imageRepo domain.ImageRepository, cfg *config.Config) DiaryService {
+func NewDiaryService(diaryRepo domain.DiaryRepository, tagRepo domain.TagRepository, imageRepo domain.ImageRepository, commentRepo domain.CommentRepository,
+	reactionRepo domain.ReactionRepository, journalRepo domain.JournalRepository, cfg *config.Config) DiaryService {
 	return &diaryService{
-		diaryRepo: diaryRepo,
-		tagRepo:   tagRepo,
-		imageRepo: imageRepo,
-		cfg:       cfg,
+		diaryRepo:    diaryRepo,
+		tagRepo:      tagRepo,
+		imageRepo:    imageRepo,
+		commentRepo:  commentRepo,
+		reactionRepo: reactionRepo,
+		journalRepo:  journalRepo,
+		cfg:          cfg,
+		renderer:     markdown.NewRenderer(cfg.MarkdownCacheSize),
 	}
 }
 
-func (s *diaryService) Create(ctx context.Context, userID uint, title, content, weather, mood, location string, date time.Time, isPublic bool, tagNames []string, imageIDs []uint, properties map[string]interface{}, music string) (*domain.Diary, error) {
+func (s *diaryService) Create(ctx context.Context, userID uint, journalID *uint, title, content, weather, mood, location string, date time.Time, isPublic bool, tagNames []string, imageIDs []uint, properties map[string]interface{}, music string) (*domain.Diary, error) {
+	key, err := s.keyFor(ctx, journalID, nil)
+	if err != nil {
+		return nil, err
+	}
+
 	// 处理标签
 	var tags []domain.Tag
 	for _, name := range tagNames {
@@ -96,16 +145,20 @@ func (s *diaryService) Create(ctx context.Context, userID uint, title, content,
 		tags = append(tags, *tag)
 	}
 
+	// 客户端可能回传读取时生成的签名链接，统一还原为 image:ID
+	content = normalizeImageRefs(content)
+
 	// 加密敏感字段
-	encTitle, _ := s.tryEncrypt(title)
-	encWeather, _ := s.tryEncrypt(weather)
-	encMood, _ := s.tryEncrypt(mood)
-	encLocation, _ := s.tryEncrypt(location)
-	encMusic, _ := s.tryEncrypt(music)
+	encTitle, _ := s.tryEncrypt(key, title)
+	encWeather, _ := s.tryEncrypt(key, weather)
+	encMood, _ := s.tryEncrypt(key, mood)
+	encLocation, _ := s.tryEncrypt(key, location)
+	encMusic, _ := s.tryEncrypt(key, music)
 
 	// 创建日记对象
 	diary := &domain.Diary{
 		UserID:     userID,
+		JournalID:  journalID,
 		Title:      encTitle,
 		Weather:    encWeather,
 		Mood:       encMood,
@@ -119,8 +172,8 @@ func (s *diaryService) Create(ctx context.Context, userID uint, title, content,
 	}
 
 	// 加密内容
-	if content != "" && len(s.cfg.AESKey) == 32 {
-		encrypted, nonce, err := utils.Encrypt(s.cfg.AESKey, []byte(content))
+	if content != "" && len(key) == 32 {
+		encrypted, nonce, err := utils.Encrypt(key, []byte(content))
 		if err != nil {
 			return nil, err
 		}
@@ -132,7 +185,7 @@ func (s *diaryService) Create(ctx context.Context, userID uint, title, content,
 		// 或者：我们可以要求必须有密钥。
 		// 为了健壮性，如果没有密钥，就不加密，但是我们的模型只有ContentEnc。
 		// 我们可以把明文直接存入ContentEnc（不推荐）或者报错。
-		if content != "" && len(s.cfg.AESKey) != 32 {
+		if content != "" && len(key) != 32 {
 			// Log warning
 		}
 	}
@@ -151,9 +204,11 @@ func (s *diaryService) Create(ctx context.Context, userID uint, title, content,
 			}
 		}
 	}
+	// 关联正文中引用的图片
+	s.syncImageRefs(ctx, userID, diary.ID, "", content)
 
 	// 填充 PlainContent 用于返回
-	diary.PlainContent = content
+	diary.PlainContent = s.signImageRefs(ctx, userID, diary.ID, content)
 
 	return diary, nil
 }
@@ -165,51 +220,66 @@ func (s *diaryService) GetByID(ctx context.Context, id uint) (*domain.Diary, err
 		return nil, ErrDiaryNotFound
 	}
 
-	// 解密内容
-	if len(diary.ContentEnc) > 0 && len(diary.IV) > 0 && len(s.cfg.AESKey) == 32 {
-		plaintext, err := utils.Decrypt(s.cfg.AESKey, diary.ContentEnc, diary.IV)
-		if err == nil {
-			diary.PlainContent = string(plaintext)
-		}
+	key, err := s.keyFor(ctx, diary.JournalID, nil)
+	if err != nil {
+		return nil, err
 	}
 
+	// 解密内容
+	diary.PlainContent = s.decryptContent(key, diary)
+
 	// 解密其他字段
-	diary.Title = s.tryDecrypt(diary.Title)
-	diary.Weather = s.tryDecrypt(diary.Weather)
-	diary.Mood = s.tryDecrypt(diary.Mood)
-	diary.Location = s.tryDecrypt(diary.Location)
-	diary.Music = s.tryDecrypt(diary.Music)
+	diary.Title = s.tryDecrypt(key, diary.Title)
+	diary.Weather = s.tryDecrypt(key, diary.Weather)
+	diary.Mood = s.tryDecrypt(key, diary.Mood)
+	diary.Location = s.tryDecrypt(key, diary.Location)
+	diary.Music = s.tryDecrypt(key, diary.Music)
 
 	diary.Summary = makeSummary(diary.PlainContent)
+	diary.PlainContent = s.signImageRefs(ctx, diary.UserID, diary.ID, diary.PlainContent)
+
+	one := []domain.Diary{*diary}
+	s.fillEngagement(ctx, one)
+	diary.CommentCount, diary.Reactions = one[0].CommentCount, one[0].Reactions
 
 	return diary, nil
 }
 
-func (s *diaryService) Update(ctx context.Context, id uint, title, content, weather, mood, location string, date time.Time, isPublic bool, tagNames []string, properties map[string]interface{}, music string) error {
+func (s *diaryService) Update(ctx context.Context, userID, id uint, title, content, weather, mood, location string, date time.Time, isPublic bool, tagNames []string, properties map[string]interface{}, music string) error {
 	diary, err := s.diaryRepo.GetByID(ctx, id)
 	if err != nil {
 		return ErrDiaryNotFound
 	}
 
-	encTitle, _ := s.tryEncrypt(title)
-	encWeather, _ := s.tryEncrypt(weather)
-	encMood, _ := s.tryEncrypt(mood)
-	encLocation, _ := s.tryEncrypt(location)
-	encMusic, _ := s.tryEncrypt(music)
+	key, err := s.keyFor(ctx, diary.JournalID, nil)
+	if err != nil {
+		return err
+	}
+
+	content = normalizeImageRefs(content)
+	oldContent := s.decryptContent(key, diary)
+
+	encTitle, _ := s.tryEncrypt(key, title)
+	encWeather, _ := s.tryEncrypt(key, weather)
+	encMood, _ := s.tryEncrypt(key, mood)
+	encLocation, _ := s.tryEncrypt(key, location)
+	encMusic, _ := s.tryEncrypt(key, music)
 
 	diary.Title = encTitle
 	diary.Weather = encWeather
 	diary.Mood = encMood
 	diary.Location = encLocation
 	diary.Date = date
-	diary.IsPublic = isPublic
+	if userID == diary.UserID {
+		diary.IsPublic = isPublic
+	}
 	diary.Summary = "" // 暂时不写入摘要，保护隐私
 	diary.Properties = properties
 	diary.Music = encMusic
 
 	// 更新内容
-	if content != "" && len(s.cfg.AESKey) == 32 {
-		encrypted, nonce, err := utils.Encrypt(s.cfg.AESKey, []byte(content))
+	if content != "" && len(key) == 32 {
+		encrypted, nonce, err := utils.Encrypt(key, []byte(content))
 		if err != nil {
 			return err
 		}
@@ -222,6 +292,10 @@ func (s *diaryService) Update(ctx context.Context, id uint, title, content, weat
 		return err
 	}
 
+	if content != "" {
+		s.syncImageRefs(ctx, userID, id, oldContent, content)
+	}
+
 	var newTagIDs []uint
 	for _, name := range tagNames {
 		tag, err := s.tagRepo.GetOrCreate(ctx, name)
@@ -280,12 +354,16 @@ func (s *diaryService) ListByUserID(ctx context.Context, userID uint, page, page
 		return nil, 0, err
 	}
 
-	s.decryptDiaries(diaries)
+	if err := s.decryptDiaries(ctx, diaries); err != nil {
+		return nil, 0, err
+	}
+	s.signDiaryImageRefs(ctx, diaries)
+	s.fillEngagement(ctx, diaries)
 
 	return diaries, total, nil
 }
 
-func (s *diaryService) ListPublic(ctx context.Context, page, pageSize int) ([]domain.Diary, int64, error) {
+func (s *diaryService) ListPublic(ctx context.Context, filter domain.PublicDiaryFilter, page, pageSize int) ([]domain.Diary, int64, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -293,11 +371,16 @@ func (s *diaryService) ListPublic(ctx context.Context, page, pageSize int) ([]do
 		pageSize = 20
 	}
 	offset := (page - 1) * pageSize
-	diaries, total, err := s.diaryRepo.ListPublic(ctx, offset, pageSize)
+	diaries, total, err := s.diaryRepo.ListPublic(ctx, filter, offset, pageSize)
 	if err == nil {
-		s.decryptDiaries(diaries)
+		err = s.decryptDiaries(ctx, diaries)
+	}
+	if err != nil {
+		return nil, 0, err
 	}
-	return diaries, total, err
+	s.signDiaryImageRefs(ctx, diaries)
+	s.fillEngagement(ctx, diaries)
+	return diaries, total, nil
 }
 
 func (s *diaryService) Search(ctx context.Context, userID uint, keyword string, page, pageSize int) ([]domain.Diary, int64, error) {
@@ -310,9 +393,138 @@ func (s *diaryService) Search(ctx context.Context, userID uint, keyword string,
 	offset := (page - 1) * pageSize
 	diaries, total, err := s.diaryRepo.SearchByUserID(ctx, userID, keyword, offset, pageSize)
 	if err == nil {
-		s.decryptDiaries(diaries)
+		err = s.decryptDiaries(ctx, diaries)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	s.signDiaryImageRefs(ctx, diaries)
+	s.fillEngagement(ctx, diaries)
+	return diaries, total, nil
+}
+
+func (s *diaryService) ListByUserIDAfter(ctx context.Context, userID uint, cursor string, limit int) ([]domain.Diary, string, error) {
+	after, err := decodeDiaryCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	limit = cursorLimit(limit)
+	diaries, err := s.diaryRepo.ListByUserIDAfter(ctx, userID, after, limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+	diaries, next := nextDiaryPage(diaries, limit, true)
+	if err := s.decryptDiaries(ctx, diaries); err != nil {
+		return nil, "", err
+	}
+	s.signDiaryImageRefs(ctx, diaries)
+	s.fillEngagement(ctx, diaries)
+	return diaries, next, nil
+}
+
+func (s *diaryService) ListPublicAfter(ctx context.Context, filter domain.PublicDiaryFilter, cursor string, limit int) ([]domain.Diary, string, error) {
+	after, err := decodeDiaryCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	limit = cursorLimit(limit)
+	diaries, err := s.diaryRepo.ListPublicAfter(ctx, filter, after, limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+	diaries, next := nextDiaryPage(diaries, limit, false)
+	if err := s.decryptDiaries(ctx, diaries); err != nil {
+		return nil, "", err
+	}
+	s.signDiaryImageRefs(ctx, diaries)
+	s.fillEngagement(ctx, diaries)
+	return diaries, next, nil
+}
+
+func (s *diaryService) SearchAfter(ctx context.Context, userID uint, keyword string, cursor string, limit int) ([]domain.Diary, string, error) {
+	after, err := decodeDiaryCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	limit = cursorLimit(limit)
+	diaries, err := s.diaryRepo.SearchByUserIDAfter(ctx, userID, keyword, after, limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+	diaries, next := nextDiaryPage(diaries, limit, false)
+	if err := s.decryptDiaries(ctx, diaries); err != nil {
+		return nil, "", err
+	}
+	s.signDiaryImageRefs(ctx, diaries)
+	s.fillEngagement(ctx, diaries)
+	return diaries, next, nil
+}
+
+// nextDiaryPage 查询时多取一条判断是否还有下一页，返回本页和下一页的游标；withPinned 表示列表按置顶排序
+func nextDiaryPage(diaries []domain.Diary, limit int, withPinned bool) ([]domain.Diary, string) {
+	if len(diaries) <= limit {
+		return diaries, ""
+	}
+	diaries = diaries[:limit]
+	last := diaries[limit-1]
+	c := domain.DiaryCursor{Date: last.Date, ID: last.ID}
+	if withPinned {
+		c.Pinned = last.IsPinned
+	}
+	return diaries, encodeDiaryCursor(c)
+}
+
+func (s *diaryService) ListFeed(ctx context.Context, followerID uint, before *domain.DiaryCursor, limit int) ([]domain.Diary, error) {
+	diaries, err := s.diaryRepo.ListFeed(ctx, followerID, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.decryptDiaries(ctx, diaries); err != nil {
+		return nil, err
+	}
+	s.signDiaryImageRefs(ctx, diaries)
+	s.fillEngagement(ctx, diaries)
+	return diaries, nil
+}
+
+func (s *diaryService) ListByJournalID(ctx context.Context, journalID uint, page, pageSize int) ([]domain.Diary, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 20
+	}
+	offset := (page - 1) * pageSize
+	diaries, total, err := s.diaryRepo.ListByJournalID(ctx, journalID, offset, pageSize)
+	if err == nil {
+		err = s.decryptDiaries(ctx, diaries)
+	}
+	if err != nil {
+		return nil, 0, err
 	}
-	return diaries, total, err
+	s.signDiaryImageRefs(ctx, diaries)
+	s.fillEngagement(ctx, diaries)
+	return diaries, total, nil
+}
+
+func (s *diaryService) SearchJournal(ctx context.Context, journalID uint, keyword string, page, pageSize int) ([]domain.Diary, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 20
+	}
+	offset := (page - 1) * pageSize
+	diaries, total, err := s.diaryRepo.SearchByJournalID(ctx, journalID, keyword, offset, pageSize)
+	if err == nil {
+		err = s.decryptDiaries(ctx, diaries)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	s.signDiaryImageRefs(ctx, diaries)
+	s.fillEngagement(ctx, diaries)
+	return diaries, total, nil
 }
 
 func (s *diaryService) GetByDateRange(ctx context.Context, userID uint, startDate, endDate time.Time) ([]domain.Diary, error) {
@@ -321,16 +533,8 @@ func (s *diaryService) GetByDateRange(ctx context.Context, userID uint, startDat
 		return nil, err
 	}
 
-	s.decryptDiaries(diaries)
-
-	// Decrypt content for export
-	for i := range diaries {
-		if len(diaries[i].ContentEnc) > 0 && len(diaries[i].IV) > 0 && len(s.cfg.AESKey) == 32 {
-			plaintext, err := utils.Decrypt(s.cfg.AESKey, diaries[i].ContentEnc, diaries[i].IV)
-			if err == nil {
-				diaries[i].PlainContent = string(plaintext)
-			}
-		}
+	if err := s.decryptDiaries(ctx, diaries); err != nil {
+		return nil, err
 	}
 	return diaries, nil
 }
@@ -341,27 +545,44 @@ func (s *diaryService) GetByIDs(ctx context.Context, userID uint, ids []uint) ([
 		return nil, err
 	}
 
-	s.decryptDiaries(diaries)
+	if err := s.decryptDiaries(ctx, diaries); err != nil {
+		return nil, err
+	}
+	return diaries, nil
+}
+
+func (s *diaryService) EachByDateRange(ctx context.Context, userID uint, startDate, endDate time.Time, fn func(diary *domain.Diary) error) error {
+	var cursor *domain.DiaryCursor
+	for {
+		diaries, err := s.diaryRepo.ListByDateRangeAfter(ctx, userID, startDate, endDate, cursor, diaryBatchSize)
+		if err != nil {
+			return err
+		}
 
-	// Decrypt content for export
-	for i := range diaries {
-		if len(diaries[i].ContentEnc) > 0 && len(diaries[i].IV) > 0 && len(s.cfg.AESKey) == 32 {
-			plaintext, err := utils.Decrypt(s.cfg.AESKey, diaries[i].ContentEnc, diaries[i].IV)
-			if err == nil {
-				diaries[i].PlainContent = string(plaintext)
+		if err := s.decryptDiaries(ctx, diaries); err != nil {
+			return err
+		}
+		for i := range diaries {
+			if err := fn(&diaries[i]); err != nil {
+				return err
 			}
 		}
+
+		if len(diaries) < diaryBatchSize {
+			return nil
+		}
+		last := diaries[len(diaries)-1]
+		cursor = &domain.DiaryCursor{Date: last.Date, ID: last.ID}
 	}
-	return diaries, nil
 }
 
 func (s *diaryService) TogglePin(ctx context.Context, userID, diaryID uint) (bool, error) {
 	diary, err := s.diaryRepo.GetByID(ctx, diaryID)
 	if err != nil {
-		return false, err
+		return false, ErrDiaryNotFound
 	}
 	if diary.UserID != userID {
-		return false, errors.New("无权操作此日记")
+		return false, ErrDiaryForbidden
 	}
 
 	newStatus := !diary.IsPinned
@@ -372,7 +593,7 @@ func (s *diaryService) TogglePin(ctx context.Context, userID, diaryID uint) (boo
 			return false, err
 		}
 		if count >= 3 {
-			return false, errors.New("最多只能置顶3篇日记")
+			return false, ErrTooManyPinned
 		}
 	}
 
@@ -380,6 +601,192 @@ func (s *diaryService) TogglePin(ctx context.Context, userID, diaryID uint) (boo
 	return newStatus, err
 }
 
+func (s *diaryService) RenderHTML(ctx context.Context, diary *domain.Diary) error {
+	// 先还原签名链接，保证相同内容的缓存键稳定
+	html, err := s.renderer.Render(normalizeImageRefs(diary.PlainContent))
+	if err != nil {
+		return err
+	}
+	diary.ContentHTML = s.signHTMLImageRefs(ctx, diary.UserID, html)
+	return nil
+}
+
+// signHTMLImageRefs 将渲染结果中 src="image:ID" 替换为签名地址
+func (s *diaryService) signHTMLImageRefs(ctx context.Context, ownerID uint, html string) string {
+	matches := htmlImageRefPattern.FindAllStringSubmatch(html, -1)
+	if len(matches) == 0 {
+		return html
+	}
+	var ids []uint
+	for _, m := range matches {
+		id, _ := strconv.ParseUint(m[1], 10, 32)
+		ids = append(ids, uint(id))
+	}
+	owned := make(map[string]bool)
+	if images, err := s.imageRepo.GetByIDs(ctx, ids); err == nil {
+		for _, img := range images {
+			if img.UserID == ownerID {
+				owned[strconv.FormatUint(uint64(img.ID), 10)] = true
+			}
+		}
+	}
+	return htmlImageRefPattern.ReplaceAllStringFunc(html, func(ref string) string {
+		m := htmlImageRefPattern.FindStringSubmatch(ref)
+		if !owned[m[1]] {
+			return `src=""`
+		}
+		id, _ := strconv.ParseUint(m[1], 10, 32)
+		return fmt.Sprintf(`src="%s"`, template.HTMLEscapeString(signImageURL(s.cfg, uint(id))))
+	})
+}
+
+// keyFor 日记使用的加密密钥：个人日记用服务端主密钥，日记本内的日记用日记本的数据密钥。
+// keys 缓存本次已解开的日记本密钥，可以为 nil；未配置主密钥时返回 nil，不加密
+func (s *diaryService) keyFor(ctx context.Context, journalID *uint, keys map[uint][]byte) ([]byte, error) {
+	if journalID == nil {
+		return s.cfg.AESKey, nil
+	}
+	if key, ok := keys[*journalID]; ok {
+		return key, nil
+	}
+	keyEnc, err := s.journalRepo.GetKeyEnc(ctx, *journalID)
+	if err != nil {
+		return nil, err
+	}
+	key, err := unwrapJournalKey(s.cfg, keyEnc)
+	if err != nil {
+		return nil, err
+	}
+	if keys != nil {
+		keys[*journalID] = key
+	}
+	return key, nil
+}
+
+// decryptContent 解密日记正文，失败时返回空字符串
+func (s *diaryService) decryptContent(key []byte, diary *domain.Diary) string {
+	if len(diary.ContentEnc) == 0 || len(diary.IV) == 0 || len(key) != 32 {
+		return ""
+	}
+	plaintext, err := utils.Decrypt(key, diary.ContentEnc, diary.IV)
+	if err != nil {
+		return ""
+	}
+	return string(plaintext)
+}
+
+// syncImageRefs 根据正文中的图片引用关联/取消关联图片
+// 只关联属于操作者 userID 且尚未关联其他日记的图片；只取消本日记的关联，不论图片属于谁
+func (s *diaryService) syncImageRefs(ctx context.Context, userID, diaryID uint, oldContent, newContent string) {
+	newIDs := ImageRefIDs(newContent)
+	newSet := make(map[uint]bool, len(newIDs))
+	for _, id := range newIDs {
+		newSet[id] = true
+	}
+
+	var removed []uint
+	for _, id := range ImageRefIDs(oldContent) {
+		if !newSet[id] {
+			removed = append(removed, id)
+		}
+	}
+
+	ids := append(newIDs, removed...)
+	if len(ids) == 0 {
+		return
+	}
+	images, err := s.imageRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		return
+	}
+
+	for _, img := range images {
+		if newSet[img.ID] {
+			if img.UserID == userID && img.DiaryID == nil {
+				s.imageRepo.AttachToDiary(ctx, img.ID, diaryID)
+			}
+		} else if img.DiaryID != nil && *img.DiaryID == diaryID {
+			s.imageRepo.DetachFromDiary(ctx, img.ID)
+		}
+	}
+}
+
+// signImageRefs 将正文中属于 ownerID 或已关联到本日记的图片引用替换为签名地址，
+// 日记本中其他成员插入的图片通过关联关系识别
+func (s *diaryService) signImageRefs(ctx context.Context, ownerID, diaryID uint, content string) string {
+	ids := ImageRefIDs(content)
+	if len(ids) == 0 {
+		return content
+	}
+	images, err := s.imageRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		return content
+	}
+	owned := make(map[uint]bool, len(images))
+	for _, img := range images {
+		if img.UserID == ownerID || (img.DiaryID != nil && *img.DiaryID == diaryID) {
+			owned[img.ID] = true
+		}
+	}
+	return RewriteImageRefs(content, func(id uint, alt string) (string, bool) {
+		if !owned[id] {
+			return "", false
+		}
+		return fmt.Sprintf("![%s](%s)", alt, signImageURL(s.cfg, id)), true
+	})
+}
+
+// signDiaryImageRefs 批量处理列表中的图片引用，只查询一次图片表
+func (s *diaryService) signDiaryImageRefs(ctx context.Context, diaries []domain.Diary) {
+	var ids []uint
+	for i := range diaries {
+		ids = append(ids, ImageRefIDs(diaries[i].PlainContent)...)
+	}
+	if len(ids) == 0 {
+		return
+	}
+	images, err := s.imageRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		return
+	}
+	byID := make(map[uint]domain.Image, len(images))
+	for _, img := range images {
+		byID[img.ID] = img
+	}
+
+	for i := range diaries {
+		d := &diaries[i]
+		d.PlainContent = RewriteImageRefs(d.PlainContent, func(id uint, alt string) (string, bool) {
+			img, ok := byID[id]
+			if !ok || (img.UserID != d.UserID && (img.DiaryID == nil || *img.DiaryID != d.ID)) {
+				return "", false
+			}
+			return fmt.Sprintf("![%s](%s)", alt, signImageURL(s.cfg, id)), true
+		})
+	}
+}
+
+// fillEngagement 批量填充评论数和表情统计，查询失败时保持为空，不影响列表本身
+func (s *diaryService) fillEngagement(ctx context.Context, diaries []domain.Diary) {
+	if len(diaries) == 0 {
+		return
+	}
+	ids := make([]uint, len(diaries))
+	for i := range diaries {
+		ids[i] = diaries[i].ID
+	}
+	if counts, err := s.commentRepo.CountByDiaryIDs(ctx, ids); err == nil {
+		for i := range diaries {
+			diaries[i].CommentCount = counts[diaries[i].ID]
+		}
+	}
+	if reactions, err := s.reactionRepo.CountByDiaryIDs(ctx, ids); err == nil {
+		for i := range diaries {
+			diaries[i].Reactions = reactions[diaries[i].ID]
+		}
+	}
+}
+
 func makeSummary(content string) string {
 	runes := []rune(content)
 	if len(runes) > 200 {
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"diary/config"
+	"diary/internal/domain"
+)
+
+type memTagRepo struct {
+	domain.TagRepository
+}
+
+func (memTagRepo) GetByDiaryID(ctx context.Context, diaryID uint) ([]domain.Tag, error) {
+	return nil, nil
+}
+
+// TestJournalEditorUpdate 编辑修改日记本中的日记：不能改变是否公开，自己插入的图片关联到日记并能正常显示
+func TestJournalEditorUpdate(t *testing.T) {
+	ctx := context.Background()
+	journalID := uint(1)
+	diaries := newMemDiaryRepo(domain.Diary{ID: 1, UserID: 10, JournalID: &journalID})
+	images := newMemImageRepo()
+	for _, owner := range []uint{11, 13} {
+		images.Create(ctx, &domain.Image{UserID: owner})
+	}
+	cfg := &config.Config{JWTSecret: "secret", SignedURLExpireMinutes: 10}
+	s := NewDiaryService(diaries, memTagRepo{}, images, nil, nil, newTestJournalRepo(), cfg).(*diaryService)
+
+	content := "![编辑的图](image:1) ![别人的图](image:2)"
+	date := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	if err := s.Update(ctx, 11, 1, "", content, "", "", "", date, true, nil, nil, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if diaries.diaries[1].IsPublic {
+		t.Fatal("编辑不能公开作者的日记")
+	}
+	if d := images.images[1].DiaryID; d == nil || *d != 1 {
+		t.Fatalf("编辑的图片 DiaryID = %v, want 1", d)
+	}
+	if images.images[2].DiaryID != nil {
+		t.Fatal("其他用户的图片不能被关联")
+	}
+
+	signed := s.signImageRefs(ctx, 10, 1, content)
+	if !strings.Contains(signed, "/media/images/1?") {
+		t.Fatalf("编辑插入的图片应被签名: %s", signed)
+	}
+	if !strings.Contains(signed, "(image:2)") {
+		t.Fatalf("其他用户的图片不应被签名: %s", signed)
+	}
+
+	if err := s.Update(ctx, 10, 1, "", content, "", "", "", date, true, nil, nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	if !diaries.diaries[1].IsPublic {
+		t.Fatal("作者应能公开自己的日记")
+	}
+}
+
+// TestCorruptedJournalKey 日记本密钥损坏时返回错误，不能把密文当作明文返回
+func TestCorruptedJournalKey(t *testing.T) {
+	ctx := context.Background()
+	journalID := uint(1)
+	diaries := newMemDiaryRepo(
+		domain.Diary{ID: 1, UserID: 10, JournalID: &journalID, Title: "密文"},
+		domain.Diary{ID: 2, UserID: 10, Title: "个人日记"},
+	)
+	journals := newTestJournalRepo()
+	journals.journals[1].KeyEnc = "corrupted"
+	cfg := &config.Config{AESKey: []byte("0123456789abcdef0123456789abcdef"), JWTSecret: "secret"}
+	s := NewDiaryService(diaries, memTagRepo{}, newMemImageRepo(), nil, nil, journals, cfg)
+
+	if _, err := s.GetByID(ctx, 1); !errors.Is(err, ErrJournalKeyCorrupted) {
+		t.Fatalf("GetByID err = %v, want %v", err, ErrJournalKeyCorrupted)
+	}
+	if _, err := s.GetByIDs(ctx, 10, []uint{2, 1}); !errors.Is(err, ErrJournalKeyCorrupted) {
+		t.Fatalf("GetByIDs err = %v, want %v", err, ErrJournalKeyCorrupted)
+	}
+	// 个人日记不受影响
+	if got, err := s.GetByIDs(ctx, 10, []uint{2}); err != nil || len(got) != 1 {
+		t.Fatalf("GetByIDs = %d 篇, err = %v", len(got), err)
+	}
+}
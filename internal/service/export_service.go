@@ -0,0 +1,909 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"diary/config"
+	"diary/internal/domain"
+	"diary/pkg/markdown"
+	"diary/pkg/pdfbook"
+	"diary/pkg/sitegen"
+
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrNothingToExport      = errors.New("没有可导出的日记")
+	ErrInvalidExportRequest = errors.New("无效的导出参数")
+	ErrExportJobNotFound    = errors.New("导出任务不存在")
+	ErrExportNotReady       = errors.New("导出尚未完成")
+	ErrPDFUnavailable       = errors.New("服务器未配置 PDF 字体，暂不支持 PDF 导出")
+	ErrTooManyExportJobs    = errors.New("未完成的导出任务过多，请等待已有任务完成")
+)
+
+// exportMaxPendingPerUser 每个用户同时排队或执行中的导出任务数上限
+const exportMaxPendingPerUser = 3
+
+// exportParams 批量导出参数，异步任务以 JSON 形式保存在 ExportJob.Request 中
+type exportParams struct {
+	Type      string `json:"type"` // "all", "selected", "date_range"
+	IDs       []uint `json:"ids"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+	Format    string `json:"format"` // "md", "txt", "csv", "pdf", "html"
+}
+
+type ExportService interface {
+	// Single 导出单篇日记，返回文件名和内容
+	Single(ctx context.Context, userID, id uint, format string) (string, []byte, error)
+	// Batch 边读取边写出，确认有数据后才调用 open 获取输出；没有数据时返回 ErrNothingToExport
+	// exportType 为 all/selected/date_range，ids 只在 selected 时使用，日期格式为 2006-01-02
+	Batch(ctx context.Context, userID uint, exportType string, ids []uint, startDate, endDate, format string, open func(filename, contentType string) (io.Writer, error)) error
+	// CreateJob 创建异步导出任务，任务在后台执行，参数同 Batch
+	CreateJob(ctx context.Context, userID uint, exportType string, ids []uint, startDate, endDate, format string) (*domain.ExportJob, error)
+	// GetJob 查询导出任务
+	GetJob(ctx context.Context, userID uint, id string) (*domain.ExportJob, error)
+	// OpenJob 打开已完成任务的导出文件
+	OpenJob(ctx context.Context, userID uint, id string) (*domain.ExportJob, *os.File, error)
+	// Start 将上次未完成的任务标记为失败，并定期清理过期的导出文件，ctx 取消时退出
+	Start(ctx context.Context)
+}
+
+type exportService struct {
+	diaryService DiaryService
+	imageService ImageService
+	jobRepo      domain.ExportJobRepository
+	settingRepo  domain.NotificationSettingRepository
+	cfg          *config.Config
+	renderer     *markdown.Renderer
+
+	// 限制同时执行的导出任务数
+	workers chan struct{}
+	// 每个用户未完成的任务数，避免单个用户堆积大量等待中的 goroutine
+	mu      sync.Mutex
+	pending map[uint]int
+}
+
+func NewExportService(diaryService DiaryService, imageService ImageService, jobRepo domain.ExportJobRepository, settingRepo domain.NotificationSettingRepository, cfg *config.Config) ExportService {
+	if err := os.MkdirAll(cfg.ExportDir, 0755); err != nil {
+		log.Printf("create export dir failed: %v", err)
+	}
+	workers := cfg.ExportWorkers
+	if workers < 1 {
+		workers = 1
+	}
+
+	return &exportService{
+		diaryService: diaryService,
+		imageService: imageService,
+		jobRepo:      jobRepo,
+		settingRepo:  settingRepo,
+		cfg:          cfg,
+		renderer:     markdown.NewRenderer(0),
+		workers:      make(chan struct{}, workers),
+		pending:      make(map[uint]int),
+	}
+}
+
+func (s *exportService) Single(ctx context.Context, userID, id uint, format string) (string, []byte, error) {
+	if format == "" {
+		format = "txt"
+	}
+
+	// GetByIDs 会校验归属，并保留正文中的 image:ID 引用
+	diaries, err := s.diaryService.GetByIDs(ctx, userID, []uint{id})
+	if err != nil || len(diaries) == 0 {
+		return "", nil, ErrDiaryNotFound
+	}
+	diary := &diaries[0]
+	filename := exportFileName(diary, format)
+
+	if format == "pdf" {
+		var buf bytes.Buffer
+		if err := s.writePDF(ctx, userID, []domain.Diary{*diary}, &buf); err != nil {
+			return "", nil, err
+		}
+		return filename, buf.Bytes(), nil
+	}
+
+	// 单文件导出时图片以 data URI 内嵌
+	diary.PlainContent = s.rewriteImages(ctx, userID, diary.PlainContent, func(img *domain.Image, alt string) string {
+		if format != "md" {
+			return fmt.Sprintf("[图片：%s]", alt)
+		}
+		dataURI, err := s.imageDataURI(ctx, img.ID)
+		if err != nil {
+			return fmt.Sprintf("[图片：%s]", alt)
+		}
+		return fmt.Sprintf("![%s](%s)", alt, dataURI)
+	})
+
+	return filename, formatDiary(diary, format), nil
+}
+
+func (s *exportService) Batch(ctx context.Context, userID uint, exportType string, ids []uint, startDate, endDate, format string, open func(filename, contentType string) (io.Writer, error)) error {
+	return s.batch(ctx, userID, exportParams{Type: exportType, IDs: ids, StartDate: startDate, EndDate: endDate, Format: format}, open)
+}
+
+func (s *exportService) batch(ctx context.Context, userID uint, req exportParams, open func(filename, contentType string) (io.Writer, error)) error {
+	if err := validateExportParams(&req, s.userLocation(ctx, userID)); err != nil {
+		return err
+	}
+	switch req.Format {
+	case "csv":
+		return s.writeCSV(ctx, userID, req, open)
+	case "pdf":
+		return s.writeBook(ctx, userID, req, open)
+	case "html":
+		return s.writeSite(ctx, userID, req, open)
+	}
+	return s.writeZip(ctx, userID, req, open)
+}
+
+// writeBook 导出为 PDF 书籍，目录需要先知道全部日记，因此先读取全部正文，图片在排版时才读取
+func (s *exportService) writeBook(ctx context.Context, userID uint, req exportParams, open func(filename, contentType string) (io.Writer, error)) error {
+	if s.cfg.PDFFontPath == "" {
+		return ErrPDFUnavailable
+	}
+
+	var diaries []domain.Diary
+	err := s.eachDiary(ctx, userID, req, func(diary *domain.Diary) error {
+		diaries = append(diaries, *diary)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(diaries) == 0 {
+		return ErrNothingToExport
+	}
+
+	out, err := open(fmt.Sprintf("diaries_export_%s.pdf", time.Now().Format("20060102150405")), "application/pdf")
+	if err != nil {
+		return err
+	}
+	return s.writePDF(ctx, userID, diaries, out)
+}
+
+func (s *exportService) writePDF(ctx context.Context, userID uint, diaries []domain.Diary, w io.Writer) error {
+	if s.cfg.PDFFontPath == "" {
+		return ErrPDFUnavailable
+	}
+	// 选中导出时按ID分批查询，整体重新按日期排序
+	sort.SliceStable(diaries, func(i, j int) bool {
+		return diaries[i].Date.Before(diaries[j].Date)
+	})
+
+	book := &pdfbook.Book{Title: "我的日记"}
+	first, last := diaries[0].Date, diaries[len(diaries)-1].Date
+	if first.Format("20060102") == last.Format("20060102") {
+		book.Subtitle = first.Format("2006-01-02")
+	} else {
+		book.Subtitle = fmt.Sprintf("%s — %s", first.Format("2006-01-02"), last.Format("2006-01-02"))
+	}
+	book.Subtitle += fmt.Sprintf("  ·  共 %d 篇", len(diaries))
+
+	for _, d := range diaries {
+		entry := pdfbook.Entry{Title: d.Title, Date: d.Date}
+		for _, meta := range []string{d.Weather, d.Mood, d.Location} {
+			if meta != "" {
+				entry.Meta = append(entry.Meta, meta)
+			}
+		}
+		for _, t := range d.Tags {
+			entry.Tags = append(entry.Tags, t.Name)
+		}
+		entry.Blocks = s.bookBlocks(ctx, userID, &d)
+		book.Entries = append(book.Entries, entry)
+	}
+
+	return pdfbook.Write(w, s.cfg.PDFFontPath, book)
+}
+
+// bookBlocks 将正文按图片引用拆分为文本和图片片段，未在正文中引用的图片附件追加在最后
+func (s *exportService) bookBlocks(ctx context.Context, userID uint, diary *domain.Diary) []pdfbook.Block {
+	var blocks []pdfbook.Block
+	inline := make(map[uint]bool)
+	content := diary.PlainContent
+	pos := 0
+	for _, m := range imageRefPattern.FindAllStringSubmatchIndex(content, -1) {
+		id, err := strconv.ParseUint(content[m[4]:m[5]], 10, 32)
+		if err != nil {
+			continue
+		}
+		img, err := s.imageService.GetByID(ctx, uint(id))
+		if err != nil || img.UserID != userID {
+			continue
+		}
+		if text := content[pos:m[0]]; strings.TrimSpace(text) != "" {
+			blocks = append(blocks, pdfbook.Block{Text: text})
+		}
+		blocks = append(blocks, pdfbook.Block{Image: s.bookImage(ctx, img.ID, content[m[2]:m[3]])})
+		inline[img.ID] = true
+		pos = m[1]
+	}
+	if text := content[pos:]; strings.TrimSpace(text) != "" {
+		blocks = append(blocks, pdfbook.Block{Text: text})
+	}
+
+	for _, img := range diary.Images {
+		if img.Kind == domain.AttachmentKindImage && !inline[img.ID] {
+			blocks = append(blocks, pdfbook.Block{Image: s.bookImage(ctx, img.ID, img.OriginalName)})
+		}
+	}
+	return blocks
+}
+
+func (s *exportService) bookImage(ctx context.Context, id uint, alt string) *pdfbook.Image {
+	return &pdfbook.Image{
+		Alt: alt,
+		Load: func() ([]byte, error) {
+			_, file, err := s.imageService.Open(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			defer file.Close()
+			return io.ReadAll(file)
+		},
+	}
+}
+
+// writeCSV 导出为单个 CSV 文件
+func (s *exportService) writeCSV(ctx context.Context, userID uint, req exportParams, open func(filename, contentType string) (io.Writer, error)) error {
+	var w *csv.Writer
+	err := s.eachDiary(ctx, userID, req, func(diary *domain.Diary) error {
+		if w == nil {
+			out, err := open(fmt.Sprintf("diaries_export_%s.csv", time.Now().Format("20060102150405")), "text/csv; charset=utf-8")
+			if err != nil {
+				return err
+			}
+			// 写入 UTF-8 BOM，防止 Excel 打开乱码
+			if _, err := out.Write([]byte{0xEF, 0xBB, 0xBF}); err != nil {
+				return err
+			}
+			w = csv.NewWriter(out)
+			w.Write([]string{"ID", "日期", "标题", "内容", "天气", "心情", "地点", "音乐"})
+		}
+
+		content := s.rewriteImages(ctx, userID, diary.PlainContent, func(img *domain.Image, alt string) string {
+			return fmt.Sprintf("[图片：%s]", alt)
+		})
+		w.Write([]string{
+			fmt.Sprintf("%d", diary.ID),
+			diary.Date.Format("2006-01-02 15:04:05"),
+			diary.Title,
+			content,
+			diary.Weather,
+			diary.Mood,
+			diary.Location,
+			diary.Music,
+		})
+		return w.Error()
+	})
+	if err != nil {
+		return err
+	}
+	if w == nil {
+		return ErrNothingToExport
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// writeZip 每篇日记一个文件，正文引用的图片统一放在 images/ 目录下
+func (s *exportService) writeZip(ctx context.Context, userID uint, req exportParams, open func(filename, contentType string) (io.Writer, error)) error {
+	fileExt := req.Format
+	if fileExt == "" {
+		fileExt = "txt"
+	}
+
+	var zw *zip.Writer
+	written := make(map[uint]bool)
+	used := make(map[string]bool)
+	manifest := exportManifest{Format: fileExt, ExportedAt: time.Now()}
+	err := s.eachDiary(ctx, userID, req, func(diary *domain.Diary) error {
+		if zw == nil {
+			out, err := open(fmt.Sprintf("diaries_export_%s.zip", manifest.ExportedAt.Format("20060102150405")), "application/zip")
+			if err != nil {
+				return err
+			}
+			zw = zip.NewWriter(out)
+		}
+
+		var images []*domain.Image
+		var refs []string
+		diary.PlainContent = s.rewriteImages(ctx, userID, diary.PlainContent, func(img *domain.Image, alt string) string {
+			name := fmt.Sprintf("images/%d%s", img.ID, filepath.Ext(img.Path))
+			refs = append(refs, name)
+			if !written[img.ID] {
+				written[img.ID] = true
+				images = append(images, img)
+			}
+			if req.Format == "md" {
+				return fmt.Sprintf("![%s](%s)", alt, name)
+			}
+			return fmt.Sprintf("[图片：%s]", name)
+		})
+
+		entryName := uniqueFileName(used, diary, fileExt)
+		f, err := zw.Create(entryName)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(formatDiary(diary, req.Format)); err != nil {
+			return err
+		}
+
+		item := exportManifestEntry{ID: diary.ID, Title: diary.Title, Date: diary.Date, File: entryName, Images: refs}
+		for _, t := range diary.Tags {
+			item.Tags = append(item.Tags, t.Name)
+		}
+		manifest.Entries = append(manifest.Entries, item)
+
+		// 图片紧跟在引用它的日记之后写出，不在内存中累积
+		for _, img := range images {
+			if err := s.copyImage(ctx, zw, img); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if zw == nil {
+		return ErrNothingToExport
+	}
+
+	manifest.Count = len(manifest.Entries)
+	f, err := zw.Create("manifest.json")
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(manifest); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// exportManifest 批量导出包中的 manifest.json，记录每篇日记对应的文件
+type exportManifest struct {
+	Format     string                `json:"format"`
+	ExportedAt time.Time             `json:"exported_at"`
+	Count      int                   `json:"count"`
+	Entries    []exportManifestEntry `json:"entries"`
+}
+
+type exportManifestEntry struct {
+	ID     uint      `json:"id"`
+	Title  string    `json:"title"`
+	Date   time.Time `json:"date"`
+	File   string    `json:"file"`
+	Tags   []string  `json:"tags,omitempty"`
+	Images []string  `json:"images,omitempty"`
+}
+
+// exportFileName 由日期和标题生成文件名，去掉路径分隔符等不安全的字符
+func exportFileName(diary *domain.Diary, ext string) string {
+	return fmt.Sprintf("%s_%s.%s", diary.Date.Format("20060102"), sanitizeFileName(diary.Title), ext)
+}
+
+// uniqueFileName 同名时追加日记ID
+func uniqueFileName(used map[string]bool, diary *domain.Diary, ext string) string {
+	name := exportFileName(diary, ext)
+	base := strings.TrimSuffix(name, "."+ext)
+	for i := 0; used[name]; i++ {
+		if i == 0 {
+			name = fmt.Sprintf("%s_%d.%s", base, diary.ID, ext)
+		} else {
+			name = fmt.Sprintf("%s_%d_%d.%s", base, diary.ID, i, ext)
+		}
+	}
+	used[name] = true
+	return name
+}
+
+const maxFileNameRunes = 60
+
+func sanitizeFileName(title string) string {
+	var sb strings.Builder
+	n := 0
+	for _, r := range title {
+		if n >= maxFileNameRunes {
+			break
+		}
+		if strings.ContainsRune(`/\:*?"<>|`, r) || unicode.IsControl(r) || unicode.IsSpace(r) {
+			r = '_'
+		}
+		sb.WriteRune(r)
+		n++
+	}
+	// 去掉首尾的点，避免 "." ".." 和隐藏文件
+	name := strings.Trim(sb.String(), "._")
+	if name == "" {
+		return "untitled"
+	}
+	return name
+}
+
+// writeSite 导出为静态网站，日记页面和图片边读取边写出，目录、标签页和搜索索引最后生成
+func (s *exportService) writeSite(ctx context.Context, userID uint, req exportParams, open func(filename, contentType string) (io.Writer, error)) error {
+	var zw *zip.Writer
+	var site *sitegen.Writer
+	written := make(map[uint]bool)
+	err := s.eachDiary(ctx, userID, req, func(diary *domain.Diary) error {
+		if zw == nil {
+			out, err := open(fmt.Sprintf("diaries_site_%s.zip", time.Now().Format("20060102150405")), "application/zip")
+			if err != nil {
+				return err
+			}
+			zw = zip.NewWriter(out)
+			site = sitegen.NewWriter(zw, "我的日记")
+		}
+
+		var images []*domain.Image
+		content := s.rewriteImages(ctx, userID, diary.PlainContent, func(img *domain.Image, alt string) string {
+			if !written[img.ID] {
+				written[img.ID] = true
+				images = append(images, img)
+			}
+			return fmt.Sprintf("![%s](../images/%d%s)", alt, img.ID, filepath.Ext(img.Path))
+		})
+		html, err := s.renderer.Render(content)
+		if err != nil {
+			return err
+		}
+
+		entry := sitegen.Entry{
+			ID:    diary.ID,
+			Title: diary.Title,
+			Date:  diary.Date,
+			HTML:  template.HTML(html), // 已经过 bluemonday 过滤
+			Text: s.rewriteImages(ctx, userID, diary.PlainContent, func(img *domain.Image, alt string) string {
+				return alt
+			}),
+		}
+		for _, meta := range []string{diary.Weather, diary.Mood, diary.Location, diary.Music} {
+			if meta != "" {
+				entry.Meta = append(entry.Meta, meta)
+			}
+		}
+		for _, t := range diary.Tags {
+			entry.Tags = append(entry.Tags, t.Name)
+		}
+		if err := site.AddEntry(entry); err != nil {
+			return err
+		}
+
+		for _, img := range images {
+			if err := s.copyImage(ctx, zw, img); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if zw == nil {
+		return ErrNothingToExport
+	}
+	if err := site.Close(); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func (s *exportService) copyImage(ctx context.Context, zw *zip.Writer, img *domain.Image) error {
+	_, file, err := s.imageService.Open(ctx, img.ID)
+	if err != nil {
+		// 文件丢失时跳过，不影响其他内容
+		return nil
+	}
+	defer file.Close()
+
+	f, err := zw.Create(fmt.Sprintf("images/%d%s", img.ID, filepath.Ext(img.Path)))
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(f, file)
+	return err
+}
+
+// eachDiary 按导出类型逐篇读取日记，不会一次性加载全部数据
+func (s *exportService) eachDiary(ctx context.Context, userID uint, req exportParams, fn func(diary *domain.Diary) error) error {
+	if req.Type == "selected" {
+		for start := 0; start < len(req.IDs); start += diaryBatchSize {
+			end := min(start+diaryBatchSize, len(req.IDs))
+			diaries, err := s.diaryService.GetByIDs(ctx, userID, req.IDs[start:end])
+			if err != nil {
+				return err
+			}
+			for i := range diaries {
+				if err := fn(&diaries[i]); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	start, end, err := exportDateRange(req, s.userLocation(ctx, userID))
+	if err != nil {
+		return err
+	}
+	return s.diaryService.EachByDateRange(ctx, userID, start, end, fn)
+}
+
+// userLocation 用户在提醒设置中配置的时区，没有设置时使用服务器本地时区
+func (s *exportService) userLocation(ctx context.Context, userID uint) *time.Location {
+	setting, err := s.settingRepo.Get(ctx, userID)
+	if err != nil {
+		return time.Local
+	}
+	loc, err := loadTimezone(setting.Timezone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// exportDateRange 按 loc 时区的自然日解析导出的日期范围，结束日期包含当天；日期格式错误时返回 ErrInvalidExportRequest
+func exportDateRange(req exportParams, loc *time.Location) (time.Time, time.Time, error) {
+	start := time.Date(1970, 1, 1, 0, 0, 0, 0, loc)
+	end := time.Now().Add(24 * time.Hour)
+	if req.StartDate != "" {
+		t, err := time.ParseInLocation("2006-01-02", req.StartDate, loc)
+		if err != nil {
+			return start, end, ErrInvalidExportRequest
+		}
+		start = t
+	}
+	if req.EndDate != "" {
+		t, err := time.ParseInLocation("2006-01-02", req.EndDate, loc)
+		if err != nil {
+			return start, end, ErrInvalidExportRequest
+		}
+		end = t.AddDate(0, 0, 1) // 包含结束当天
+	}
+	if !end.After(start) {
+		return start, end, ErrInvalidExportRequest
+	}
+	return start, end, nil
+}
+
+func validateExportParams(req *exportParams, loc *time.Location) error {
+	switch req.Type {
+	case "selected":
+		if len(req.IDs) == 0 {
+			return ErrInvalidExportRequest
+		}
+	case "date_range", "all":
+	default:
+		return ErrInvalidExportRequest
+	}
+	switch req.Format {
+	case "", "md", "txt", "csv", "pdf", "html":
+	default:
+		return ErrInvalidExportRequest
+	}
+	if req.Type != "selected" {
+		if _, _, err := exportDateRange(*req, loc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *exportService) CreateJob(ctx context.Context, userID uint, exportType string, ids []uint, startDate, endDate, format string) (*domain.ExportJob, error) {
+	req := exportParams{Type: exportType, IDs: ids, StartDate: startDate, EndDate: endDate, Format: format}
+	if err := validateExportParams(&req, s.userLocation(ctx, userID)); err != nil {
+		return nil, err
+	}
+	params, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if !s.reserve(userID) {
+		return nil, ErrTooManyExportJobs
+	}
+	job := &domain.ExportJob{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Status:    domain.ExportJobPending,
+		Format:    req.Format,
+		Request:   string(params),
+		ExpiresAt: time.Now().Add(time.Duration(s.cfg.ExportJobExpireHours) * time.Hour),
+	}
+	if err := s.jobRepo.Create(ctx, job); err != nil {
+		s.release(userID)
+		return nil, err
+	}
+
+	// 任务不随请求结束而取消
+	go func() {
+		defer s.release(userID)
+		s.run(context.Background(), *job, req)
+	}()
+	return job, nil
+}
+
+// reserve 占用用户的一个任务名额，已达上限时返回 false
+func (s *exportService) reserve(userID uint) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.pending[userID] >= exportMaxPendingPerUser {
+		return false
+	}
+	s.pending[userID]++
+	return true
+}
+
+func (s *exportService) release(userID uint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.pending[userID]--; s.pending[userID] <= 0 {
+		delete(s.pending, userID)
+	}
+}
+
+// run 执行导出任务，先写入临时文件，完成后再改名，避免下载到不完整的文件
+func (s *exportService) run(ctx context.Context, job domain.ExportJob, req exportParams) {
+	s.workers <- struct{}{}
+	defer func() { <-s.workers }()
+
+	job.Status = domain.ExportJobRunning
+	if err := s.jobRepo.Update(ctx, &job); err != nil {
+		log.Printf("update export job %s failed: %v", job.ID, err)
+	}
+
+	tmpPath := filepath.Join(s.cfg.ExportDir, job.ID+".part")
+	var file *os.File
+	err := s.batch(ctx, job.UserID, req, func(filename, contentType string) (io.Writer, error) {
+		job.FileName = filename
+		f, err := os.Create(tmpPath)
+		file = f
+		return f, err
+	})
+	if file != nil {
+		if cerr := file.Close(); err == nil {
+			err = cerr
+		}
+	}
+
+	now := time.Now()
+	job.FinishedAt = &now
+	job.ExpiresAt = now.Add(time.Duration(s.cfg.ExportJobExpireHours) * time.Hour)
+	if err == nil {
+		job.FilePath = filepath.Join(s.cfg.ExportDir, job.ID+filepath.Ext(job.FileName))
+		err = os.Rename(tmpPath, job.FilePath)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		job.Status = domain.ExportJobFailed
+		job.FilePath = ""
+		job.Error = err.Error()
+	} else {
+		job.Status = domain.ExportJobDone
+		if info, err := os.Stat(job.FilePath); err == nil {
+			job.Size = info.Size()
+		}
+	}
+
+	if err := s.jobRepo.Update(ctx, &job); err != nil {
+		log.Printf("update export job %s failed: %v", job.ID, err)
+	}
+}
+
+func (s *exportService) GetJob(ctx context.Context, userID uint, id string) (*domain.ExportJob, error) {
+	job, err := s.jobRepo.GetByID(ctx, id)
+	if err != nil || job.UserID != userID {
+		return nil, ErrExportJobNotFound
+	}
+	return job, nil
+}
+
+func (s *exportService) OpenJob(ctx context.Context, userID uint, id string) (*domain.ExportJob, *os.File, error) {
+	job, err := s.GetJob(ctx, userID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if job.Status != domain.ExportJobDone {
+		return job, nil, ErrExportNotReady
+	}
+	file, err := os.Open(job.FilePath)
+	if err != nil {
+		return nil, nil, ErrExportJobNotFound
+	}
+	return job, file, nil
+}
+
+func (s *exportService) Start(ctx context.Context) {
+	// 进程重启后，之前未完成的任务不会再继续执行
+	if n, err := s.jobRepo.FailUnfinished(ctx, "服务重启，任务已中断"); err != nil {
+		log.Printf("fail unfinished export jobs failed: %v", err)
+	} else if n > 0 {
+		log.Printf("export jobs: marked %d interrupted jobs as failed", n)
+	}
+	// 中断的任务留下的临时文件不会再被改名或删除
+	if n, err := s.removePartFiles(); err != nil {
+		log.Printf("remove export part files failed: %v", err)
+	} else if n > 0 {
+		log.Printf("export jobs: removed %d leftover part files", n)
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if n, err := s.cleanupExpired(ctx); err != nil {
+					log.Printf("cleanup export jobs failed: %v", err)
+				} else if n > 0 {
+					log.Printf("cleanup export jobs: removed %d expired jobs", n)
+				}
+			}
+		}
+	}()
+}
+
+// removePartFiles 删除导出目录中的 .part 临时文件，只在启动时、还没有任务执行前调用
+func (s *exportService) removePartFiles() (int, error) {
+	paths, err := filepath.Glob(filepath.Join(s.cfg.ExportDir, "*.part"))
+	if err != nil {
+		return 0, err
+	}
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return 0, err
+		}
+	}
+	return len(paths), nil
+}
+
+func (s *exportService) cleanupExpired(ctx context.Context) (int, error) {
+	count := 0
+	for {
+		jobs, err := s.jobRepo.ListExpired(ctx, time.Now(), gcBatchSize)
+		if err != nil {
+			return count, err
+		}
+		for _, job := range jobs {
+			if job.FilePath != "" {
+				if err := os.Remove(job.FilePath); err != nil && !os.IsNotExist(err) {
+					return count, err
+				}
+			}
+			if err := s.jobRepo.Delete(ctx, job.ID); err != nil {
+				return count, err
+			}
+			count++
+		}
+		if len(jobs) < gcBatchSize {
+			return count, nil
+		}
+	}
+}
+
+// rewriteImages 替换正文中属于当前用户的图片引用，其他引用保持原样
+func (s *exportService) rewriteImages(ctx context.Context, userID uint, content string, link func(img *domain.Image, alt string) string) string {
+	return RewriteImageRefs(content, func(id uint, alt string) (string, bool) {
+		img, err := s.imageService.GetByID(ctx, id)
+		if err != nil || img.UserID != userID {
+			return "", false
+		}
+		return link(img, alt), true
+	})
+}
+
+// imageDataURI 读取图片并编码为 data URI
+func (s *exportService) imageDataURI(ctx context.Context, id uint) (string, error) {
+	_, file, err := s.imageService.Open(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("data:%s;base64,%s", http.DetectContentType(data), base64.StdEncoding.EncodeToString(data)), nil
+}
+
+func formatDiary(diary *domain.Diary, format string) []byte {
+	var sb strings.Builder
+
+	if format == "md" {
+		sb.WriteString("---\n")
+		sb.Write(frontMatter(diary))
+		sb.WriteString("---\n\n")
+		sb.WriteString(diary.PlainContent)
+	} else {
+		// TXT format
+		sb.WriteString(fmt.Sprintf("标题：%s\n", diary.Title))
+		sb.WriteString(fmt.Sprintf("日期：%s\n", diary.Date.Format("2006-01-02 15:04")))
+		sb.WriteString(fmt.Sprintf("天气：%s\n", diary.Weather))
+		sb.WriteString(fmt.Sprintf("心情：%s\n", diary.Mood))
+		if diary.Location != "" {
+			sb.WriteString(fmt.Sprintf("地点：%s\n", diary.Location))
+		}
+		sb.WriteString("\n----------------------------------------\n\n")
+		sb.WriteString(diary.PlainContent)
+	}
+
+	return []byte(sb.String())
+}
+
+// diaryFrontMatter Markdown 导出的 YAML front matter，字段与 Markdown 导入一致，可以重新导入
+type diaryFrontMatter struct {
+	Title    string   `yaml:"title"`
+	Date     string   `yaml:"date"`
+	Tags     []string `yaml:"tags,omitempty"`
+	Weather  string   `yaml:"weather,omitempty"`
+	Mood     string   `yaml:"mood,omitempty"`
+	Location string   `yaml:"location,omitempty"`
+	Music    string   `yaml:"music,omitempty"`
+	Public   bool     `yaml:"public,omitempty"`
+}
+
+// frontMatter 先写固定字段，自定义属性作为额外的键追加在后面
+func frontMatter(diary *domain.Diary) []byte {
+	fm := diaryFrontMatter{
+		Title:    diary.Title,
+		Date:     diary.Date.Format("2006-01-02 15:04:05"),
+		Weather:  diary.Weather,
+		Mood:     diary.Mood,
+		Location: diary.Location,
+		Music:    diary.Music,
+		Public:   diary.IsPublic,
+	}
+	for _, t := range diary.Tags {
+		fm.Tags = append(fm.Tags, t.Name)
+	}
+	out, err := yaml.Marshal(fm)
+	if err != nil {
+		return nil
+	}
+
+	props := make(map[string]interface{})
+	for k, v := range diary.Properties {
+		switch k {
+		case "title", "date", "tags", "weather", "mood", "location", "music", "public":
+			continue
+		}
+		props[k] = v
+	}
+	if len(props) > 0 {
+		if extra, err := yaml.Marshal(props); err == nil {
+			out = append(out, extra...)
+		}
+	}
+	return out
+}
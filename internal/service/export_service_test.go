@@ -0,0 +1,170 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"diary/config"
+	"diary/internal/domain"
+)
+
+func TestSanitizeFileName(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"今天的日记", "今天的日记"},
+		{"a/b\\c", "a_b_c"},
+		{"../../etc/passwd", "etc_passwd"},
+		{"..", "untitled"},
+		{".hidden", "hidden"},
+		{"", "untitled"},
+		{"   ", "untitled"},
+		{"what? <yes> | no: \"*\"", "what___yes____no"},
+		{"tab\there\nnewline", "tab_here_newline"},
+		{"ctrl\x00\x1f", "ctrl"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			if got := sanitizeFileName(tt.title); got != tt.want {
+				t.Fatalf("sanitizeFileName(%q) = %q, want %q", tt.title, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizeFileNameLength(t *testing.T) {
+	got := sanitizeFileName(strings.Repeat("长", 200))
+	if n := utf8.RuneCountInString(got); n != maxFileNameRunes {
+		t.Fatalf("长度 %d，want %d", n, maxFileNameRunes)
+	}
+}
+
+func TestUniqueFileName(t *testing.T) {
+	date := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	diaries := []domain.Diary{
+		{ID: 1, Title: "晴", Date: date},
+		{ID: 2, Title: "晴", Date: date},
+		{ID: 3, Title: "晴", Date: date},
+		{ID: 2, Title: "晴", Date: date}, // 同一篇重复选择
+		{ID: 4, Title: "晴_2", Date: date},
+		{ID: 5, Title: "a/b", Date: date},
+		{ID: 6, Title: "a\\b", Date: date},
+	}
+	want := []string{
+		"20260102_晴.md",
+		"20260102_晴_2.md",
+		"20260102_晴_3.md",
+		"20260102_晴_2_1.md",
+		"20260102_晴_2_4.md",
+		"20260102_a_b.md",
+		"20260102_a_b_6.md",
+	}
+
+	used := map[string]bool{}
+	for i := range diaries {
+		got := uniqueFileName(used, &diaries[i], "md")
+		if got != want[i] {
+			t.Errorf("第 %d 篇 = %q, want %q", i, got, want[i])
+		}
+		if strings.ContainsAny(got, `/\`) || strings.Contains(got, "..") {
+			t.Errorf("文件名 %q 包含路径", got)
+		}
+	}
+	if len(used) != len(diaries) {
+		t.Fatalf("%d 个文件名有重复", len(diaries))
+	}
+}
+
+func TestValidateExportParams(t *testing.T) {
+	tests := []struct {
+		name string
+		req  exportParams
+		ok   bool
+	}{
+		{"全部", exportParams{Type: "all"}, true},
+		{"日期范围", exportParams{Type: "date_range", StartDate: "2026-01-01", EndDate: "2026-01-31"}, true},
+		{"同一天", exportParams{Type: "date_range", StartDate: "2026-01-01", EndDate: "2026-01-01"}, true},
+		{"只有开始日期", exportParams{Type: "date_range", StartDate: "2026-01-01"}, true},
+		{"结束日期格式错误", exportParams{Type: "date_range", EndDate: "2026-13-01"}, false},
+		{"开始日期格式错误", exportParams{Type: "all", StartDate: "01/02/2026"}, false},
+		{"开始晚于结束", exportParams{Type: "date_range", StartDate: "2026-02-01", EndDate: "2026-01-01"}, false},
+		{"选择但没有ID", exportParams{Type: "selected"}, false},
+		{"未知格式", exportParams{Type: "all", Format: "docx"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateExportParams(&tt.req, time.UTC)
+			if tt.ok && err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if !tt.ok && !errors.Is(err, ErrInvalidExportRequest) {
+				t.Fatalf("err = %v, want %v", err, ErrInvalidExportRequest)
+			}
+		})
+	}
+}
+
+func TestExportDateRangeUsesLocation(t *testing.T) {
+	shanghai := time.FixedZone("UTC+8", 8*3600)
+	start, end, err := exportDateRange(exportParams{Type: "date_range", StartDate: "2026-01-02", EndDate: "2026-01-02"}, shanghai)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 用户时区的 1 月 2 日对应 UTC 1 月 1 日 16:00 到 1 月 2 日 16:00
+	if want := time.Date(2026, 1, 1, 16, 0, 0, 0, time.UTC); !start.Equal(want) {
+		t.Fatalf("start = %v, want %v", start, want)
+	}
+	if want := time.Date(2026, 1, 2, 16, 0, 0, 0, time.UTC); !end.Equal(want) {
+		t.Fatalf("end = %v, want %v", end, want)
+	}
+	// 用户时区 1 月 2 日 23:30 写的日记在范围内，1 月 3 日 00:30 的不在
+	if late := time.Date(2026, 1, 2, 23, 30, 0, 0, shanghai); !late.Before(end) {
+		t.Fatalf("%v 应在范围内", late)
+	}
+	if next := time.Date(2026, 1, 3, 0, 30, 0, 0, shanghai); next.Before(end) {
+		t.Fatalf("%v 不应在范围内", next)
+	}
+}
+
+func TestExportPendingLimit(t *testing.T) {
+	s := &exportService{pending: make(map[uint]int)}
+	for i := 0; i < exportMaxPendingPerUser; i++ {
+		if !s.reserve(1) {
+			t.Fatalf("第 %d 个任务应能创建", i+1)
+		}
+	}
+	if s.reserve(1) {
+		t.Fatal("超过上限的任务应被拒绝")
+	}
+	if !s.reserve(2) {
+		t.Fatal("其他用户不受影响")
+	}
+	s.release(1)
+	if !s.reserve(1) {
+		t.Fatal("任务完成后应释放名额")
+	}
+}
+
+func TestExportRemovePartFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.part", "b.part", "c.zip"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := &exportService{cfg: &config.Config{ExportDir: dir}}
+	n, err := s.removePartFiles()
+	if err != nil || n != 2 {
+		t.Fatalf("removePartFiles() = %d, %v, want 2", n, err)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 || entries[0].Name() != "c.zip" {
+		t.Fatalf("剩余文件 %v，want 只有 c.zip", entries)
+	}
+}
@@ -0,0 +1,382 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"diary/internal/domain"
+)
+
+// 测试用的内存仓储，只实现被测代码用到的方法，其余方法来自嵌入的 nil 接口，调用时 panic
+
+var errFakeNotFound = errors.New("record not found")
+
+type memTodoRepo struct {
+	domain.TodoRepository
+	todos  map[uint]*domain.Todo
+	nextID uint
+}
+
+func newMemTodoRepo() *memTodoRepo {
+	return &memTodoRepo{todos: map[uint]*domain.Todo{}}
+}
+
+func (r *memTodoRepo) Create(ctx context.Context, todo *domain.Todo) error {
+	r.nextID++
+	todo.ID = r.nextID
+	copied := *todo
+	r.todos[todo.ID] = &copied
+	return nil
+}
+
+func (r *memTodoRepo) GetByID(ctx context.Context, id uint) (*domain.Todo, error) {
+	t, ok := r.todos[id]
+	if !ok || t.IsDeleted {
+		return nil, errFakeNotFound
+	}
+	copied := *t
+	return &copied, nil
+}
+
+func (r *memTodoRepo) Update(ctx context.Context, todo *domain.Todo) error {
+	copied := *todo
+	r.todos[todo.ID] = &copied
+	return nil
+}
+
+func (r *memTodoRepo) Delete(ctx context.Context, id uint) error {
+	if t, ok := r.todos[id]; ok {
+		t.IsDeleted = true
+	}
+	return nil
+}
+
+func (r *memTodoRepo) MarkAsDone(ctx context.Context, id uint) error {
+	if t, ok := r.todos[id]; ok {
+		t.Done = true
+	}
+	return nil
+}
+
+func (r *memTodoRepo) children(parentID uint) []*domain.Todo {
+	var out []*domain.Todo
+	for _, t := range r.todos {
+		if t.ParentID != nil && *t.ParentID == parentID {
+			out = append(out, t)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+func (r *memTodoRepo) ListByParentIDs(ctx context.Context, parentIDs []uint) ([]domain.Todo, error) {
+	var out []domain.Todo
+	for _, id := range parentIDs {
+		for _, t := range r.children(id) {
+			if !t.IsDeleted {
+				out = append(out, *t)
+			}
+		}
+	}
+	return out, nil
+}
+
+func (r *memTodoRepo) SetDoneByParentID(ctx context.Context, parentID uint, done bool) error {
+	for _, t := range r.children(parentID) {
+		if !t.IsDeleted {
+			t.Done = done
+		}
+	}
+	return nil
+}
+
+func (r *memTodoRepo) SetProjectByParentID(ctx context.Context, parentID uint, projectID *uint) error {
+	for _, t := range r.children(parentID) {
+		t.ProjectID = projectID
+	}
+	return nil
+}
+
+func (r *memTodoRepo) DeleteByParentID(ctx context.Context, parentID uint) error {
+	for _, t := range r.children(parentID) {
+		t.IsDeleted = true
+	}
+	return nil
+}
+
+func (r *memTodoRepo) MaxSortOrder(ctx context.Context, userID uint, parentID *uint) (int, error) {
+	max := 0
+	for _, t := range r.todos {
+		if t.UserID != userID || t.IsDeleted || (t.ParentID == nil) != (parentID == nil) {
+			continue
+		}
+		if parentID != nil && *t.ParentID != *parentID {
+			continue
+		}
+		if t.SortOrder > max {
+			max = t.SortOrder
+		}
+	}
+	return max, nil
+}
+
+func (r *memTodoRepo) UpdateSortOrder(ctx context.Context, userID uint, ids []uint) error {
+	for i, id := range ids {
+		if t, ok := r.todos[id]; ok && t.UserID == userID && !t.IsDeleted {
+			t.SortOrder = i + 1
+		}
+	}
+	return nil
+}
+
+func (r *memTodoRepo) ListByUserID(ctx context.Context, userID uint, offset, limit int) ([]domain.Todo, int64, error) {
+	var all []domain.Todo
+	for _, t := range r.todos {
+		if t.UserID == userID && !t.IsDeleted {
+			all = append(all, *t)
+		}
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].ID < all[j].ID })
+	total := int64(len(all))
+	if offset >= len(all) {
+		return nil, total, nil
+	}
+	return all[offset:min(offset+limit, len(all))], total, nil
+}
+
+func (r *memTodoRepo) GetByOccurrence(ctx context.Context, seriesID uint, at time.Time) (*domain.Todo, error) {
+	for _, t := range r.todos {
+		if (t.ID == seriesID || (t.SeriesID != nil && *t.SeriesID == seriesID)) &&
+			t.OccurrenceAt != nil && t.OccurrenceAt.Equal(at) {
+			copied := *t
+			return &copied, nil
+		}
+	}
+	return nil, errFakeNotFound
+}
+
+// ListDueBetween 测试数据不超过一批，忽略游标
+func (r *memTodoRepo) ListDueBetween(ctx context.Context, start, end time.Time, after *domain.TimeCursor, limit int) ([]domain.Todo, error) {
+	var out []domain.Todo
+	for _, t := range r.live() {
+		if !t.Done && t.DueDate != nil && t.DueDate.After(start) && !t.DueDate.After(end) {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+// live 未删除的待办，按 ID 排序
+func (r *memTodoRepo) live() []domain.Todo {
+	var out []domain.Todo
+	for _, t := range r.todos {
+		if !t.IsDeleted {
+			out = append(out, *t)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+type memProjectRepo struct {
+	domain.TodoProjectRepository
+	projects map[uint]*domain.TodoProject
+	nextID   uint
+}
+
+func newMemProjectRepo() *memProjectRepo {
+	return &memProjectRepo{projects: map[uint]*domain.TodoProject{}}
+}
+
+func (r *memProjectRepo) Create(ctx context.Context, project *domain.TodoProject) error {
+	r.nextID++
+	project.ID = r.nextID
+	copied := *project
+	r.projects[project.ID] = &copied
+	return nil
+}
+
+func (r *memProjectRepo) GetByID(ctx context.Context, id uint) (*domain.TodoProject, error) {
+	p, ok := r.projects[id]
+	if !ok {
+		return nil, errFakeNotFound
+	}
+	copied := *p
+	return &copied, nil
+}
+
+func (r *memProjectRepo) ListByUserID(ctx context.Context, userID uint) ([]domain.TodoProject, error) {
+	var out []domain.TodoProject
+	for _, p := range r.projects {
+		if p.UserID == userID {
+			out = append(out, *p)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].SortOrder < out[j].SortOrder })
+	return out, nil
+}
+
+type memImageRepo struct {
+	domain.ImageRepository
+	images map[uint]*domain.Image
+	nextID uint
+}
+
+func newMemImageRepo() *memImageRepo {
+	return &memImageRepo{images: map[uint]*domain.Image{}}
+}
+
+func (r *memImageRepo) Create(ctx context.Context, image *domain.Image) error {
+	r.nextID++
+	image.ID = r.nextID
+	copied := *image
+	r.images[image.ID] = &copied
+	return nil
+}
+
+func (r *memImageRepo) GetByID(ctx context.Context, id uint) (*domain.Image, error) {
+	img, ok := r.images[id]
+	if !ok || img.IsDeleted {
+		return nil, errFakeNotFound
+	}
+	copied := *img
+	return &copied, nil
+}
+
+func (r *memImageRepo) GetByIDs(ctx context.Context, ids []uint) ([]domain.Image, error) {
+	var out []domain.Image
+	for _, id := range ids {
+		if img, ok := r.images[id]; ok && !img.IsDeleted {
+			out = append(out, *img)
+		}
+	}
+	return out, nil
+}
+
+func (r *memImageRepo) AttachToDiary(ctx context.Context, imageID, diaryID uint) error {
+	if img, ok := r.images[imageID]; ok {
+		img.DiaryID = &diaryID
+	}
+	return nil
+}
+
+func (r *memImageRepo) DetachFromDiary(ctx context.Context, imageID uint) error {
+	if img, ok := r.images[imageID]; ok {
+		img.DiaryID = nil
+	}
+	return nil
+}
+
+// sortedImages 按 ID 升序返回 afterID 之后满足 keep 的前 limit 张图片
+func (r *memImageRepo) sortedImages(afterID uint, limit int, keep func(*domain.Image) bool) []domain.Image {
+	var out []domain.Image
+	for _, img := range r.images {
+		if img.ID > afterID && keep(img) {
+			out = append(out, *img)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out[:min(limit, len(out))]
+}
+
+func (r *memImageRepo) ListExpiredUnattached(ctx context.Context, before time.Time, afterID uint, limit int) ([]domain.Image, error) {
+	return r.sortedImages(afterID, limit, func(img *domain.Image) bool {
+		return img.DiaryID == nil && !img.IsDeleted && img.CreatedAt.Before(before)
+	}), nil
+}
+
+func (r *memImageRepo) ListDeletedBefore(ctx context.Context, before time.Time, afterID uint, limit int) ([]domain.Image, error) {
+	return r.sortedImages(afterID, limit, func(img *domain.Image) bool {
+		return img.IsDeleted && img.DeleteTime.Before(before)
+	}), nil
+}
+
+func (r *memImageRepo) ListActiveAfterID(ctx context.Context, afterID uint, limit int) ([]domain.Image, error) {
+	return r.sortedImages(afterID, limit, func(img *domain.Image) bool { return !img.IsDeleted }), nil
+}
+
+func (r *memImageRepo) ListAllPaths(ctx context.Context) ([]string, error) {
+	var out []string
+	for _, img := range r.images {
+		out = append(out, img.Path)
+	}
+	return out, nil
+}
+
+func (r *memImageRepo) Delete(ctx context.Context, id uint) error {
+	if img, ok := r.images[id]; ok {
+		img.IsDeleted = true
+		img.DeleteTime = time.Now()
+	}
+	return nil
+}
+
+func (r *memImageRepo) HardDelete(ctx context.Context, id uint) error {
+	delete(r.images, id)
+	return nil
+}
+
+type memDiaryRepo struct {
+	domain.DiaryRepository
+	diaries map[uint]*domain.Diary
+}
+
+func newMemDiaryRepo(diaries ...domain.Diary) *memDiaryRepo {
+	r := &memDiaryRepo{diaries: map[uint]*domain.Diary{}}
+	for i := range diaries {
+		d := diaries[i]
+		r.diaries[d.ID] = &d
+	}
+	return r
+}
+
+func (r *memDiaryRepo) GetByID(ctx context.Context, id uint) (*domain.Diary, error) {
+	d, ok := r.diaries[id]
+	if !ok || d.IsDeleted {
+		return nil, errFakeNotFound
+	}
+	copied := *d
+	return &copied, nil
+}
+
+func (r *memDiaryRepo) GetWithAll(ctx context.Context, id uint) (*domain.Diary, error) {
+	return r.GetByID(ctx, id)
+}
+
+func (r *memDiaryRepo) GetByIDs(ctx context.Context, userID uint, ids []uint) ([]domain.Diary, error) {
+	var out []domain.Diary
+	for _, id := range ids {
+		if d, ok := r.diaries[id]; ok && !d.IsDeleted && d.UserID == userID {
+			out = append(out, *d)
+		}
+	}
+	return out, nil
+}
+
+func (r *memDiaryRepo) Update(ctx context.Context, diary *domain.Diary) error {
+	copied := *diary
+	r.diaries[diary.ID] = &copied
+	return nil
+}
+
+func (r *memDiaryRepo) UpdateCommentsDisabled(ctx context.Context, id uint, disabled bool) error {
+	r.diaries[id].CommentsDisabled = disabled
+	return nil
+}
+
+func (r *memImageRepo) ListByUserIDAndKind(ctx context.Context, userID uint, kind string, offset, limit int) ([]domain.Image, int64, error) {
+	var all []domain.Image
+	for _, img := range r.images {
+		if img.UserID == userID && !img.IsDeleted && (kind == "" || img.Kind == kind) {
+			all = append(all, *img)
+		}
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].ID < all[j].ID })
+	total := int64(len(all))
+	if offset >= len(all) {
+		return nil, total, nil
+	}
+	return all[offset:min(offset+limit, len(all))], total, nil
+}
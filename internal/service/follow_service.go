@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"diary/internal/domain"
+)
+
+var (
+	ErrFollowSelf = errors.New("不能关注自己")
+	ErrBlockSelf  = errors.New("不能屏蔽自己")
+)
+
+type FollowService interface {
+	// Follow 关注开启了公开主页的用户；被对方屏蔽时和主页不存在一样返回 ErrProfileNotFound
+	Follow(ctx context.Context, userID uint, username string) error
+	// Unfollow 取消关注，未关注时不报错
+	Unfollow(ctx context.Context, userID uint, username string) error
+	// Block 屏蔽用户，同时解除双方的关注
+	Block(ctx context.Context, userID uint, username string) error
+	// Unblock 取消屏蔽，未屏蔽时不报错
+	Unblock(ctx context.Context, userID uint, username string) error
+	// ListBlocks 获取屏蔽列表，已注销的用户不在结果中
+	ListBlocks(ctx context.Context, userID uint) ([]domain.Block, error)
+	// IsBlocked ownerID 是否屏蔽了 viewerID
+	IsBlocked(ctx context.Context, ownerID, viewerID uint) (bool, error)
+	// Feed 按日期降序获取关注的人的公开日记。cursor 为上一页返回的游标，为空时从最新开始；
+	// 没有更多时返回的游标为空
+	Feed(ctx context.Context, userID uint, cursor string, limit int) ([]domain.Diary, string, error)
+}
+
+type followService struct {
+	userRepo     domain.UserRepository
+	followRepo   domain.FollowRepository
+	blockRepo    domain.BlockRepository
+	diaryService DiaryService
+}
+
+func NewFollowService(userRepo domain.UserRepository, followRepo domain.FollowRepository, blockRepo domain.BlockRepository, diaryService DiaryService) FollowService {
+	return &followService{
+		userRepo:     userRepo,
+		followRepo:   followRepo,
+		blockRepo:    blockRepo,
+		diaryService: diaryService,
+	}
+}
+
+func (s *followService) Follow(ctx context.Context, userID uint, username string) error {
+	target, err := s.userRepo.GetByUsername(ctx, username)
+	if err != nil || !target.ProfilePublic {
+		return ErrProfileNotFound
+	}
+	if target.ID == userID {
+		return ErrFollowSelf
+	}
+	blocked, err := s.blockRepo.Exists(ctx, target.ID, userID)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return ErrProfileNotFound
+	}
+	return s.followRepo.Create(ctx, &domain.Follow{FollowerID: userID, FolloweeID: target.ID})
+}
+
+func (s *followService) Unfollow(ctx context.Context, userID uint, username string) error {
+	target, err := s.userRepo.GetByUsername(ctx, username)
+	if err != nil {
+		return ErrUserNotFound
+	}
+	return s.followRepo.Delete(ctx, userID, target.ID)
+}
+
+func (s *followService) Block(ctx context.Context, userID uint, username string) error {
+	target, err := s.userRepo.GetByUsername(ctx, username)
+	if err != nil {
+		return ErrUserNotFound
+	}
+	if target.ID == userID {
+		return ErrBlockSelf
+	}
+	if err := s.blockRepo.Create(ctx, &domain.Block{BlockerID: userID, BlockedID: target.ID}); err != nil {
+		return err
+	}
+	if err := s.followRepo.Delete(ctx, target.ID, userID); err != nil {
+		return err
+	}
+	return s.followRepo.Delete(ctx, userID, target.ID)
+}
+
+func (s *followService) Unblock(ctx context.Context, userID uint, username string) error {
+	target, err := s.userRepo.GetByUsername(ctx, username)
+	if err != nil {
+		return ErrUserNotFound
+	}
+	return s.blockRepo.Delete(ctx, userID, target.ID)
+}
+
+func (s *followService) ListBlocks(ctx context.Context, userID uint) ([]domain.Block, error) {
+	blocks, err := s.blockRepo.ListByBlockerID(ctx, userID)
+	if err != nil || len(blocks) == 0 {
+		return blocks, err
+	}
+
+	ids := make([]uint, len(blocks))
+	for i := range blocks {
+		ids[i] = blocks[i].BlockedID
+	}
+	users, err := s.userRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*domain.User, len(users))
+	for i := range users {
+		byID[users[i].ID] = &users[i]
+	}
+
+	result := make([]domain.Block, 0, len(blocks))
+	for _, b := range blocks {
+		if user, ok := byID[b.BlockedID]; ok {
+			b.Blocked = user
+			result = append(result, b)
+		}
+	}
+	return result, nil
+}
+
+func (s *followService) IsBlocked(ctx context.Context, ownerID, viewerID uint) (bool, error) {
+	if ownerID == viewerID {
+		return false, nil
+	}
+	return s.blockRepo.Exists(ctx, ownerID, viewerID)
+}
+
+func (s *followService) Feed(ctx context.Context, userID uint, cursor string, limit int) ([]domain.Diary, string, error) {
+	before, err := decodeDiaryCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	limit = cursorLimit(limit)
+
+	// 多取一条判断是否还有下一页
+	diaries, err := s.diaryService.ListFeed(ctx, userID, before, limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+	diaries, next := nextDiaryPage(diaries, limit, false)
+	return diaries, next, nil
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"diary/internal/domain"
+)
+
+type memFollowRepo struct {
+	domain.FollowRepository
+	follows map[[2]uint]bool
+}
+
+func (r *memFollowRepo) Create(ctx context.Context, follow *domain.Follow) error {
+	r.follows[[2]uint{follow.FollowerID, follow.FolloweeID}] = true
+	return nil
+}
+
+func TestFollow(t *testing.T) {
+	ctx := context.Background()
+	// alice 公开主页并屏蔽了用户 3，bob 没有公开主页
+	users := &memUserRepo{users: []domain.User{
+		{ID: 1, Username: "alice", ProfilePublic: true},
+		{ID: 2, Username: "bob"},
+	}}
+
+	tests := []struct {
+		name     string
+		userID   uint
+		username string
+		wantErr  error
+	}{
+		{"关注公开主页", 2, "alice", nil},
+		{"被对方屏蔽", 3, "alice", ErrProfileNotFound},
+		{"对方未公开主页", 1, "bob", ErrProfileNotFound},
+		{"用户不存在", 2, "carol", ErrProfileNotFound},
+		{"关注自己", 1, "alice", ErrFollowSelf},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			follows := &memFollowRepo{follows: map[[2]uint]bool{}}
+			blocks := &memBlockRepo{blocked: map[[2]uint]bool{{1, 3}: true}}
+			s := NewFollowService(users, follows, blocks, nil)
+
+			err := s.Follow(ctx, tt.userID, tt.username)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if created := len(follows.follows) > 0; created != (tt.wantErr == nil) {
+				t.Fatalf("创建了关注关系 = %v", created)
+			}
+		})
+	}
+}
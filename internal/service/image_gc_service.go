@@ -0,0 +1,267 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"diary/config"
+	"diary/internal/domain"
+)
+
+// gcBatchSize 每批处理的记录数
+const gcBatchSize = 200
+
+// 记录数不少于 gcMissingMinRows 且超过 gcMissingMaxRatio 的文件丢失时，认为是上传目录配置有误
+const (
+	gcMissingMinRows  = 20
+	gcMissingMaxRatio = 0.5
+)
+
+var (
+	ErrUploadDirUnavailable = errors.New("上传目录不存在或为空")
+	ErrTooManyMissingFiles  = errors.New("丢失文件的图片过多，请检查上传目录配置")
+)
+
+// ImageGCReport 一次回收的执行结果
+type ImageGCReport struct {
+	DryRun            bool      `json:"dry_run"`
+	StartedAt         time.Time `json:"started_at"`
+	FinishedAt        time.Time `json:"finished_at"`
+	ExpiredUnattached []string  `json:"expired_unattached"` // 超过宽限期仍未关联日记的图片
+	ExpiredDeleted    []string  `json:"expired_deleted"`    // 软删除超过保留期的图片
+	OrphanFiles       []string  `json:"orphan_files"`       // 磁盘上存在但没有数据库记录的文件
+	MissingFiles      []string  `json:"missing_files"`      // 数据库记录存在但文件已丢失
+	FreedBytes        int64     `json:"freed_bytes"`
+	Errors            []string  `json:"errors,omitempty"`
+}
+
+type ImageGCService interface {
+	// Run 执行一次回收，dryRun 为 true 时只报告不删除
+	Run(ctx context.Context, dryRun bool) (*ImageGCReport, error)
+	// Start 按配置的间隔在后台周期执行，ctx 取消时退出
+	Start(ctx context.Context)
+}
+
+type imageGCService struct {
+	imageRepo domain.ImageRepository
+	cfg       *config.Config
+}
+
+func NewImageGCService(imageRepo domain.ImageRepository, cfg *config.Config) ImageGCService {
+	return &imageGCService{
+		imageRepo: imageRepo,
+		cfg:       cfg,
+	}
+}
+
+func (s *imageGCService) Start(ctx context.Context) {
+	if s.cfg.ImageGCIntervalMinutes <= 0 {
+		return
+	}
+	interval := time.Duration(s.cfg.ImageGCIntervalMinutes) * time.Minute
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				report, err := s.Run(ctx, false)
+				if err != nil {
+					log.Printf("image gc failed: %v", err)
+					continue
+				}
+				log.Printf("image gc: unattached=%d deleted=%d orphan=%d missing=%d freed=%dB",
+					len(report.ExpiredUnattached), len(report.ExpiredDeleted),
+					len(report.OrphanFiles), len(report.MissingFiles), report.FreedBytes)
+			}
+		}
+	}()
+}
+
+func (s *imageGCService) Run(ctx context.Context, dryRun bool) (*ImageGCReport, error) {
+	now := time.Now()
+	report := &ImageGCReport{
+		DryRun:    dryRun,
+		StartedAt: now,
+	}
+
+	grace := now.Add(-time.Duration(s.cfg.ImageGCGraceHours) * time.Hour)
+	retention := now.Add(-time.Duration(s.cfg.ImageGCRetentionHours) * time.Hour)
+
+	// 1. 超过宽限期仍未关联的图片
+	if err := s.purge(ctx, report, dryRun, &report.ExpiredUnattached, func(ctx context.Context, afterID uint) ([]domain.Image, error) {
+		return s.imageRepo.ListExpiredUnattached(ctx, grace, afterID, gcBatchSize)
+	}); err != nil {
+		return nil, err
+	}
+
+	// 2. 软删除超过保留期的图片
+	if err := s.purge(ctx, report, dryRun, &report.ExpiredDeleted, func(ctx context.Context, afterID uint) ([]domain.Image, error) {
+		return s.imageRepo.ListDeletedBefore(ctx, retention, afterID, gcBatchSize)
+	}); err != nil {
+		return nil, err
+	}
+
+	// 3. 有记录但文件丢失：软删除记录，等保留期过后再清理
+	if err := s.reconcileMissing(ctx, report, dryRun); err != nil {
+		return nil, err
+	}
+
+	// 4. 有文件但没有记录：删除文件
+	if err := s.reconcileOrphans(ctx, report, dryRun, grace); err != nil {
+		return nil, err
+	}
+
+	report.FinishedAt = time.Now()
+	return report, nil
+}
+
+// purge 按 ID 分批拉取图片，删除文件和数据库记录，直到没有更多数据
+// dry-run 时记录不会被删除，因此按上一批最后的 ID 翻页而不是依赖记录消失
+func (s *imageGCService) purge(ctx context.Context, report *ImageGCReport, dryRun bool, out *[]string, next func(ctx context.Context, afterID uint) ([]domain.Image, error)) error {
+	var lastID uint
+	for {
+		images, err := next(ctx, lastID)
+		if err != nil {
+			return err
+		}
+
+		for _, img := range images {
+			lastID = img.ID
+			*out = append(*out, img.Path)
+			report.FreedBytes += s.fileSize(img.Path)
+			if dryRun {
+				continue
+			}
+			if err := s.removeFile(img.Path); err != nil {
+				report.Errors = append(report.Errors, err.Error())
+				continue
+			}
+			if err := s.imageRepo.HardDelete(ctx, img.ID); err != nil {
+				report.Errors = append(report.Errors, err.Error())
+			}
+		}
+
+		if len(images) < gcBatchSize {
+			return nil
+		}
+	}
+}
+
+// reconcileMissing 软删除文件已丢失的记录。UploadDir 不可用（未挂载、路径错误）时所有文件都会显得丢失，
+// 因此目录不存在或为空、或丢失比例过高时不做任何修改并返回错误
+func (s *imageGCService) reconcileMissing(ctx context.Context, report *ImageGCReport, dryRun bool) error {
+	entries, err := os.ReadDir(s.cfg.UploadDir)
+	if err != nil || len(entries) == 0 {
+		return fmt.Errorf("%w: %s", ErrUploadDirUnavailable, s.cfg.UploadDir)
+	}
+
+	var lastID uint
+	var checked int
+	var missing []string
+	for {
+		images, err := s.imageRepo.ListActiveAfterID(ctx, lastID, gcBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, img := range images {
+			lastID = img.ID
+			checked++
+			if _, err := os.Stat(s.absPath(img.Path)); os.IsNotExist(err) {
+				missing = append(missing, img.Path)
+			}
+		}
+		if len(images) < gcBatchSize {
+			break
+		}
+	}
+
+	if checked >= gcMissingMinRows && float64(len(missing)) > float64(checked)*gcMissingMaxRatio {
+		return fmt.Errorf("%w: %d/%d", ErrTooManyMissingFiles, len(missing), checked)
+	}
+
+	report.MissingFiles = append(report.MissingFiles, missing...)
+	if dryRun {
+		return nil
+	}
+	for _, path := range missing {
+		if err := s.imageRepo.DeleteByPath(ctx, path); err != nil {
+			report.Errors = append(report.Errors, err.Error())
+		}
+	}
+	return nil
+}
+
+func (s *imageGCService) reconcileOrphans(ctx context.Context, report *ImageGCReport, dryRun bool, grace time.Time) error {
+	paths, err := s.imageRepo.ListAllPaths(ctx)
+	if err != nil {
+		return err
+	}
+	known := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		known[filepath.Clean(p)] = true
+	}
+
+	root := s.cfg.UploadDir
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			report.Errors = append(report.Errors, err.Error())
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil || known[rel] {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		// 上传过程中文件先于记录写入，宽限期内的文件先不处理
+		if info.ModTime().After(grace) {
+			return nil
+		}
+
+		report.OrphanFiles = append(report.OrphanFiles, rel)
+		report.FreedBytes += info.Size()
+		if dryRun {
+			return nil
+		}
+		if err := os.Remove(path); err != nil {
+			report.Errors = append(report.Errors, err.Error())
+		}
+		return nil
+	})
+}
+
+func (s *imageGCService) absPath(rel string) string {
+	return filepath.Join(s.cfg.UploadDir, rel)
+}
+
+func (s *imageGCService) fileSize(rel string) int64 {
+	info, err := os.Stat(s.absPath(rel))
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// removeFile 删除文件，文件不存在视为成功
+func (s *imageGCService) removeFile(rel string) error {
+	if err := os.Remove(s.absPath(rel)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
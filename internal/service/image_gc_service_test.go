@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"diary/config"
+	"diary/internal/domain"
+)
+
+func TestImageGCPurgesEveryBatch(t *testing.T) {
+	ctx := context.Background()
+	old := time.Now().Add(-48 * time.Hour)
+	diaryID := uint(1)
+
+	for _, dryRun := range []bool{true, false} {
+		t.Run(fmt.Sprintf("dryRun=%v", dryRun), func(t *testing.T) {
+			dir := t.TempDir()
+			repo := newMemImageRepo()
+			// 超过一批的候选，中间夹着不该回收的图片
+			const candidates = gcBatchSize*2 + 17
+			for i := 0; i < candidates; i++ {
+				unattached, attached := fmt.Sprintf("u%d.png", i), fmt.Sprintf("a%d.png", i)
+				for _, path := range []string{unattached, attached} {
+					if err := os.WriteFile(filepath.Join(dir, path), []byte("x"), 0644); err != nil {
+						t.Fatal(err)
+					}
+				}
+				repo.Create(ctx, &domain.Image{Path: unattached, CreatedAt: old})
+				repo.Create(ctx, &domain.Image{Path: attached, DiaryID: &diaryID, CreatedAt: old})
+			}
+			for i := 0; i < gcBatchSize+1; i++ {
+				repo.Create(ctx, &domain.Image{Path: fmt.Sprintf("d%d.png", i), IsDeleted: true, DeleteTime: old})
+			}
+
+			s := NewImageGCService(repo, &config.Config{UploadDir: dir, ImageGCGraceHours: 24, ImageGCRetentionHours: 24})
+			report, err := s.Run(ctx, dryRun)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(report.ExpiredUnattached) != candidates {
+				t.Fatalf("expired unattached = %d, want %d", len(report.ExpiredUnattached), candidates)
+			}
+			if len(report.ExpiredDeleted) != gcBatchSize+1 {
+				t.Fatalf("expired deleted = %d, want %d", len(report.ExpiredDeleted), gcBatchSize+1)
+			}
+			if report.FreedBytes != candidates {
+				t.Fatalf("freed = %d, want %d", report.FreedBytes, candidates)
+			}
+
+			// 已关联的图片始终保留，其余只有非 dry-run 时删除
+			wantLeft := candidates
+			if dryRun {
+				wantLeft += candidates + gcBatchSize + 1
+			}
+			if len(repo.images) != wantLeft {
+				t.Fatalf("剩余 %d 条记录，want %d", len(repo.images), wantLeft)
+			}
+			_, err = os.Stat(filepath.Join(dir, "u0.png"))
+			if dryRun != (err == nil) {
+				t.Fatalf("dry-run=%v 时 u0.png stat err = %v", dryRun, err)
+			}
+		})
+	}
+}
+
+func TestImageGCRefusesWhenUploadDirLooksWrong(t *testing.T) {
+	ctx := context.Background()
+	diaryID := uint(1)
+	present := t.TempDir()
+	if err := os.WriteFile(filepath.Join(present, "keep.png"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		dir     string
+		wantErr error
+	}{
+		{"目录不存在", filepath.Join(t.TempDir(), "missing"), ErrUploadDirUnavailable},
+		{"目录为空", t.TempDir(), ErrUploadDirUnavailable},
+		{"大部分文件丢失", present, ErrTooManyMissingFiles},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMemImageRepo()
+			repo.Create(ctx, &domain.Image{Path: "keep.png", DiaryID: &diaryID})
+			for i := 0; i < gcMissingMinRows; i++ {
+				repo.Create(ctx, &domain.Image{Path: fmt.Sprintf("gone%d.png", i), DiaryID: &diaryID})
+			}
+
+			s := NewImageGCService(repo, &config.Config{UploadDir: tt.dir, ImageGCGraceHours: 24, ImageGCRetentionHours: 24})
+			if _, err := s.Run(ctx, false); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			for _, img := range repo.images {
+				if img.IsDeleted {
+					t.Fatalf("%s 不应被软删除", img.Path)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// 日记正文中通过 ![说明](image:123) 引用已上传的图片
+var (
+	imageRefPattern = regexp.MustCompile(`!\[([^\]]*)\]\(image:(\d+)\)`)
+	// 读取时生成的签名链接，保存时需要还原为 image:ID
+	signedImageRefPattern = regexp.MustCompile(`!\[([^\]]*)\]\((?:https?://[^/\s)]+)?/media/images/(\d+)\?[^)\s]*\)`)
+	// 渲染后的 HTML 中的图片引用
+	htmlImageRefPattern = regexp.MustCompile(`src="image:(\d+)"`)
+)
+
+// ImageRefIDs 按出现顺序返回正文中引用的图片ID（去重）
+func ImageRefIDs(content string) []uint {
+	var ids []uint
+	seen := make(map[uint]bool)
+	for _, m := range imageRefPattern.FindAllStringSubmatch(content, -1) {
+		id, err := strconv.ParseUint(m[2], 10, 32)
+		if err != nil || seen[uint(id)] {
+			continue
+		}
+		seen[uint(id)] = true
+		ids = append(ids, uint(id))
+	}
+	return ids
+}
+
+// RewriteImageRefs 将正文中的 image:ID 引用替换为 resolve 返回的地址
+// resolve 返回 false 时保留原引用
+func RewriteImageRefs(content string, resolve func(id uint, alt string) (string, bool)) string {
+	return imageRefPattern.ReplaceAllStringFunc(content, func(ref string) string {
+		m := imageRefPattern.FindStringSubmatch(ref)
+		id, err := strconv.ParseUint(m[2], 10, 32)
+		if err != nil {
+			return ref
+		}
+		replacement, ok := resolve(uint(id), m[1])
+		if !ok {
+			return ref
+		}
+		return replacement
+	})
+}
+
+// normalizeImageRefs 将客户端回传的签名链接还原为 image:ID 引用
+func normalizeImageRefs(content string) string {
+	return signedImageRefPattern.ReplaceAllString(content, "![$1](image:$2)")
+}
@@ -7,27 +7,48 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"time"
 
 	"diary/config"
 	"diary/internal/domain"
+	"diary/pkg/media"
+	"diary/pkg/utils"
 
 	"github.com/google/uuid"
 )
 
 var (
-	ErrImageNotFound = errors.New("图片不存在")
-	ErrImageUpload   = errors.New("图片上传失败")
+	ErrImageNotFound    = errors.New("图片不存在")
+	ErrImageUpload      = errors.New("图片上传失败")
+	ErrInvalidSignature = errors.New("链接无效或已过期")
+	ErrUnsupportedMedia = errors.New("不支持的文件类型")
+	ErrFileTooLarge     = errors.New("文件过大")
 )
 
 type ImageService interface {
+	// Upload 上传图片，只接受图片类型
 	Upload(ctx context.Context, userID uint, file io.Reader, filename string) (*domain.Image, error)
+	// UploadAttachment 上传附件，支持图片、音频和视频
+	UploadAttachment(ctx context.Context, userID uint, file io.Reader, filename string) (*domain.Image, error)
 	GetByID(ctx context.Context, id uint) (*domain.Image, error)
 	Delete(ctx context.Context, id uint) error
 	ListByUserID(ctx context.Context, userID uint, page, pageSize int) ([]domain.Image, int64, error)
 	ListUnattached(ctx context.Context, userID uint, page, pageSize int) ([]domain.Image, int64, error)
+	// ListByKind 按类型获取附件列表，kind 为空时返回全部
+	ListByKind(ctx context.Context, userID uint, kind string, page, pageSize int) ([]domain.Image, int64, error)
+	// ListByKindAfter 按游标获取附件列表，返回下一页的游标，为空表示没有更多
+	ListByKindAfter(ctx context.Context, userID uint, kind string, cursor string, limit int) ([]domain.Image, string, error)
 	AttachToDiary(ctx context.Context, imageID, diaryID uint) error
 	DetachFromDiary(ctx context.Context, imageID uint) error
+	// CheckAttachment 根据文件头和声明的大小预先判断附件能否上传，用于尽早拒绝分片上传
+	CheckAttachment(head []byte, size int64) error
+	// SignedURL 生成图片的限时访问地址
+	SignedURL(id uint) string
+	// OpenSigned 校验签名并打开图片文件
+	OpenSigned(ctx context.Context, id uint, expires int64, signature string) (*domain.Image, *os.File, error)
+	// Open 打开图片文件（调用方负责权限校验）
+	Open(ctx context.Context, id uint) (*domain.Image, *os.File, error)
 }
 
 type imageService struct {
@@ -49,9 +70,18 @@ func NewImageService(imageRepo domain.ImageRepository, cfg *config.Config) Image
 }
 
 func (s *imageService) Upload(ctx context.Context, userID uint, file io.Reader, filename string) (*domain.Image, error) {
-	// 生成唯一文件名
-	ext := filepath.Ext(filename)
-	newFilename := uuid.New().String() + ext
+	return s.save(ctx, userID, file, filename, domain.AttachmentKindImage)
+}
+
+func (s *imageService) UploadAttachment(ctx context.Context, userID uint, file io.Reader, filename string) (*domain.Image, error) {
+	return s.save(ctx, userID, file, filename,
+		domain.AttachmentKindImage, domain.AttachmentKindAudio, domain.AttachmentKindVideo)
+}
+
+// save 写入磁盘后识别文件类型，只接受 kinds 中的类型并按类型校验大小
+func (s *imageService) save(ctx context.Context, userID uint, file io.Reader, filename string, kinds ...string) (*domain.Image, error) {
+	// 生成唯一文件名，扩展名在识别类型后按实际类型补上，不使用客户端提供的扩展名
+	name := uuid.New().String()
 	// 按日期分目录，避免单目录文件过多
 	dateDir := time.Now().Format("2006/01/02")
 	saveDir := filepath.Join(s.cfg.UploadDir, dateDir)
@@ -60,9 +90,7 @@ func (s *imageService) Upload(ctx context.Context, userID uint, file io.Reader,
 		return nil, ErrImageUpload
 	}
 
-	savePath := filepath.Join(saveDir, newFilename)
-	// 相对路径用于存储和访问
-	relPath := filepath.Join(dateDir, newFilename)
+	savePath := filepath.Join(saveDir, name)
 
 	// 创建文件
 	dst, err := os.Create(savePath)
@@ -71,15 +99,54 @@ func (s *imageService) Upload(ctx context.Context, userID uint, file io.Reader,
 	}
 	defer dst.Close()
 
-	// 写入内容
-	if _, err := io.Copy(dst, file); err != nil {
+	// 写入内容，最多比允许的最大值多读 1 字节用于判断超限
+	var maxSize int64
+	for _, kind := range kinds {
+		if limit := s.maxSize(kind); limit > maxSize {
+			maxSize = limit
+		}
+	}
+	size, err := io.Copy(dst, io.LimitReader(file, maxSize+1))
+	if err != nil {
+		os.Remove(savePath)
+		return nil, err
+	}
+
+	// 识别类型并提取元数据
+	if _, err := dst.Seek(0, io.SeekStart); err != nil {
+		os.Remove(savePath)
 		return nil, err
 	}
+	info, err := media.Probe(dst, size)
+	if err != nil || !slices.Contains(kinds, info.Kind) {
+		os.Remove(savePath)
+		return nil, ErrUnsupportedMedia
+	}
+	if size > s.maxSize(info.Kind) {
+		os.Remove(savePath)
+		return nil, ErrFileTooLarge
+	}
+
+	newFilename := name + media.Extension(info.MimeType)
+	if err := os.Rename(savePath, filepath.Join(saveDir, newFilename)); err != nil {
+		os.Remove(savePath)
+		return nil, ErrImageUpload
+	}
+	savePath = filepath.Join(saveDir, newFilename)
+	// 相对路径用于存储和访问
+	relPath := filepath.Join(dateDir, newFilename)
 
 	// 保存记录
 	image := &domain.Image{
-		UserID: userID,
-		Path:   relPath, // 存储相对路径
+		UserID:       userID,
+		Path:         relPath, // 存储相对路径
+		Kind:         info.Kind,
+		MimeType:     info.MimeType,
+		Size:         size,
+		Duration:     info.Duration,
+		Width:        info.Width,
+		Height:       info.Height,
+		OriginalName: filepath.Base(filename),
 	}
 
 	if err := s.imageRepo.Create(ctx, image); err != nil {
@@ -91,6 +158,29 @@ func (s *imageService) Upload(ctx context.Context, userID uint, file io.Reader,
 	return image, nil
 }
 
+func (s *imageService) CheckAttachment(head []byte, size int64) error {
+	info, err := media.Sniff(head)
+	if err != nil {
+		return ErrUnsupportedMedia
+	}
+	if size > s.maxSize(info.Kind) {
+		return ErrFileTooLarge
+	}
+	return nil
+}
+
+// maxSize 各类附件的大小上限
+func (s *imageService) maxSize(kind string) int64 {
+	switch kind {
+	case domain.AttachmentKindAudio:
+		return int64(s.cfg.MaxAudioMB) << 20
+	case domain.AttachmentKindVideo:
+		return int64(s.cfg.MaxVideoMB) << 20
+	default:
+		return int64(s.cfg.MaxImageMB) << 20
+	}
+}
+
 func (s *imageService) GetByID(ctx context.Context, id uint) (*domain.Image, error) {
 	image, err := s.imageRepo.GetByID(ctx, id)
 	if err != nil {
@@ -135,6 +225,32 @@ func (s *imageService) ListUnattached(ctx context.Context, userID uint, page, pa
 	return s.imageRepo.ListUnattached(ctx, userID, offset, pageSize)
 }
 
+func (s *imageService) ListByKind(ctx context.Context, userID uint, kind string, page, pageSize int) ([]domain.Image, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 20
+	}
+	offset := (page - 1) * pageSize
+	return s.imageRepo.ListByUserIDAndKind(ctx, userID, kind, offset, pageSize)
+}
+
+func (s *imageService) ListByKindAfter(ctx context.Context, userID uint, kind string, cursor string, limit int) ([]domain.Image, string, error) {
+	after, err := decodeTimeCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	limit = cursorLimit(limit)
+	images, err := s.imageRepo.ListByUserIDAndKindAfter(ctx, userID, kind, after, limit+1)
+	if err != nil || len(images) <= limit {
+		return images, "", err
+	}
+	images = images[:limit]
+	last := images[limit-1]
+	return images, encodeCursor(domain.TimeCursor{Time: last.CreatedAt, ID: last.ID}), nil
+}
+
 func (s *imageService) AttachToDiary(ctx context.Context, imageID, diaryID uint) error {
 	return s.imageRepo.AttachToDiary(ctx, imageID, diaryID)
 }
@@ -142,3 +258,41 @@ func (s *imageService) AttachToDiary(ctx context.Context, imageID, diaryID uint)
 func (s *imageService) DetachFromDiary(ctx context.Context, imageID uint) error {
 	return s.imageRepo.DetachFromDiary(ctx, imageID)
 }
+
+func (s *imageService) SignedURL(id uint) string {
+	return signImageURL(s.cfg, id)
+}
+
+func (s *imageService) OpenSigned(ctx context.Context, id uint, expires int64, signature string) (*domain.Image, *os.File, error) {
+	if !utils.VerifyResource([]byte(s.cfg.JWTSecret), imageResource(id), expires, signature) {
+		return nil, nil, ErrInvalidSignature
+	}
+	return s.Open(ctx, id)
+}
+
+func (s *imageService) Open(ctx context.Context, id uint) (*domain.Image, *os.File, error) {
+	image, err := s.imageRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, ErrImageNotFound
+	}
+	f, err := os.Open(filepath.Join(s.cfg.UploadDir, image.Path))
+	if err != nil {
+		return nil, nil, ErrImageNotFound
+	}
+	return image, f, nil
+}
+
+func imageResource(id uint) string {
+	return fmt.Sprintf("images/%d", id)
+}
+
+// signImageURL 生成 /media/images/{id}?expires=&sig= 形式的签名地址
+func signImageURL(cfg *config.Config, id uint) string {
+	return signImageURLFor(cfg, id, time.Duration(cfg.SignedURLExpireMinutes)*time.Minute)
+}
+
+// signImageURLFor 按指定有效期生成签名地址
+func signImageURLFor(cfg *config.Config, id uint, ttl time.Duration) string {
+	expires, sig := utils.SignResource([]byte(cfg.JWTSecret), imageResource(id), time.Now().Add(ttl))
+	return fmt.Sprintf("%s/media/images/%d?expires=%d&sig=%s", cfg.PublicBaseURL, id, expires, sig)
+}
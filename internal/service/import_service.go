@@ -0,0 +1,189 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"diary/internal/domain"
+	"diary/pkg/importer"
+)
+
+// ImportCount 单类对象的导入结果
+type ImportCount struct {
+	Created int `json:"created"`
+	Skipped int `json:"skipped"` // 之前已导入过
+	Failed  int `json:"failed"`
+}
+
+// ImportPreview 预览时每篇日记的概要
+type ImportPreview struct {
+	SourceID string    `json:"source_id"`
+	Title    string    `json:"title"`
+	Date     time.Time `json:"date"`
+	Tags     []string  `json:"tags,omitempty"`
+	Media    int       `json:"media,omitempty"`
+	Exists   bool      `json:"exists"` // 之前已导入，提交时会跳过
+}
+
+// ImportReport 导入结果，DryRun 时为预览报告
+type ImportReport struct {
+	Source   string          `json:"source"`
+	DryRun   bool            `json:"dry_run"`
+	Diaries  ImportCount     `json:"diaries"`
+	Todos    ImportCount     `json:"todos"`
+	Images   ImportCount     `json:"images"`
+	Projects ImportCount     `json:"projects"`
+	Entries  []ImportPreview `json:"entries,omitempty"`
+	Warnings []string        `json:"warnings,omitempty"`
+}
+
+func (r *ImportReport) warn(format string, args ...interface{}) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+}
+
+type ImportService interface {
+	// Import 导入 Day One、Journey 或 Markdown 导出包，format 为空时自动识别；dryRun 时只返回预览
+	Import(ctx context.Context, userID uint, format string, r io.ReaderAt, size int64, dryRun bool) (*ImportReport, error)
+}
+
+type importService struct {
+	diaryService DiaryService
+	imageService ImageService
+	importRepo   domain.ImportRecordRepository
+}
+
+func NewImportService(diaryService DiaryService, imageService ImageService, importRepo domain.ImportRecordRepository) ImportService {
+	return &importService{
+		diaryService: diaryService,
+		imageService: imageService,
+		importRepo:   importRepo,
+	}
+}
+
+func (s *importService) Import(ctx context.Context, userID uint, format string, r io.ReaderAt, size int64, dryRun bool) (*ImportReport, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, ErrInvalidArchive
+	}
+	if format == "" {
+		if format, err = importer.Detect(zr); err != nil {
+			return nil, err
+		}
+	}
+	entries, err := importer.Parse(format, zr)
+	if err != nil {
+		if err == importer.ErrUnknownFormat || err == importer.ErrNoEntries {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+
+	report := &ImportReport{Source: format, DryRun: dryRun}
+	for _, entry := range entries {
+		_, created, err := importOnce(ctx, s.importRepo, userID, format, domain.ImportKindDiary, entry.SourceID, dryRun, func() (uint, error) {
+			return s.importEntry(ctx, userID, entry, report)
+		})
+		if err != nil {
+			report.Diaries.Failed++
+			report.warn("《%s》导入失败：%v", entry.Title, err)
+			continue
+		}
+		countImport(&report.Diaries, created)
+
+		if dryRun {
+			if created {
+				report.Images.Created += len(entry.Media)
+			}
+			report.Entries = append(report.Entries, ImportPreview{
+				SourceID: entry.SourceID,
+				Title:    entry.Title,
+				Date:     entry.Date,
+				Tags:     entry.Tags,
+				Media:    len(entry.Media),
+				Exists:   !created,
+			})
+		}
+	}
+	return report, nil
+}
+
+// importEntry 上传附件、改写正文中的引用后创建日记
+// 任何附件失败时删除本篇已上传的附件并返回错误，不写导入记录，重新导入时会完整重试
+func (s *importService) importEntry(ctx context.Context, userID uint, entry importer.Entry, report *ImportReport) (uint, error) {
+	content := entry.Content
+	var attach, uploaded []uint
+	rollback := func() {
+		for _, id := range uploaded {
+			s.imageService.Delete(ctx, id)
+		}
+	}
+	for _, m := range entry.Media {
+		image, err := s.uploadMedia(ctx, userID, m)
+		if err != nil {
+			rollback()
+			report.Images.Failed++
+			return 0, fmt.Errorf("附件 %s：%w", m.Name, err)
+		}
+		uploaded = append(uploaded, image.ID)
+
+		if m.Ref != "" {
+			// 正文引用的附件由 DiaryService 根据 image:ID 自动关联
+			content = strings.ReplaceAll(content, "]("+m.Ref+")", fmt.Sprintf("](image:%d)", image.ID))
+		} else {
+			attach = append(attach, image.ID)
+		}
+	}
+
+	diary, err := s.diaryService.Create(ctx, userID, nil, entry.Title, content, entry.Weather, entry.Mood, entry.Location, entry.Date, entry.IsPublic, entry.Tags, attach, entry.Properties, entry.Music)
+	if err != nil {
+		rollback()
+		return 0, err
+	}
+	report.Images.Created += len(uploaded)
+	return diary.ID, nil
+}
+
+func (s *importService) uploadMedia(ctx context.Context, userID uint, m importer.Media) (*domain.Image, error) {
+	rc, err := m.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return s.imageService.UploadAttachment(ctx, userID, rc, m.Name)
+}
+
+// importOnce 如果对象已从同一来源导入过则直接返回之前的ID，否则执行 create 并记录映射
+// dryRun 时不执行 create，返回值只表示是否会新建
+func importOnce(ctx context.Context, repo domain.ImportRecordRepository, userID uint, source, kind, sourceID string, dryRun bool, create func() (uint, error)) (uint, bool, error) {
+	if record, err := repo.Get(ctx, userID, source, kind, sourceID); err == nil {
+		return record.TargetID, false, nil
+	}
+	if dryRun {
+		return 0, true, nil
+	}
+
+	targetID, err := create()
+	if err != nil {
+		return 0, false, err
+	}
+	err = repo.Create(ctx, &domain.ImportRecord{
+		UserID:   userID,
+		Source:   source,
+		Kind:     kind,
+		SourceID: sourceID,
+		TargetID: targetID,
+	})
+	return targetID, true, err
+}
+
+func countImport(c *ImportCount, created bool) {
+	if created {
+		c.Created++
+	} else {
+		c.Skipped++
+	}
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"diary/config"
+	"diary/internal/domain"
+	"diary/pkg/importer"
+)
+
+func markdownArchive(t *testing.T, files map[string][]byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, data := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(data)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestImportRetriesEntryWhenMediaFails(t *testing.T) {
+	ctx := context.Background()
+	images := newMemImageRepo()
+	diaries := &stubDiaryService{}
+	cfg := &config.Config{UploadDir: t.TempDir(), MaxImageMB: 1, JWTSecret: "secret"}
+	s := NewImportService(diaries, NewImageService(images, cfg), &memImportRepo{records: map[string]*domain.ImportRecord{}})
+
+	note := []byte("# 旅行\n\n![](a.png)\n![](b.png)\n")
+	broken := markdownArchive(t, map[string][]byte{"trip.md": note, "a.png": testPNG(t), "b.png": []byte("not an image")})
+	fixed := markdownArchive(t, map[string][]byte{"trip.md": note, "a.png": testPNG(t), "b.png": testPNG(t)})
+
+	report, err := s.Import(ctx, 1, importer.FormatMarkdown, bytes.NewReader(broken), int64(len(broken)), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Diaries.Failed != 1 || report.Images.Created != 0 || report.Images.Failed != 1 {
+		t.Fatalf("diaries = %+v, images = %+v, want 1 failed diary and 1 failed image", report.Diaries, report.Images)
+	}
+	if len(diaries.diaries) != 0 {
+		t.Fatalf("附件失败时不应创建日记，got %d", len(diaries.diaries))
+	}
+	for _, img := range images.images {
+		if !img.IsDeleted {
+			t.Fatalf("已上传的附件 %d 应被删除", img.ID)
+		}
+	}
+
+	// 修复附件后重新导入，之前失败的日记完整导入
+	report, err = s.Import(ctx, 1, importer.FormatMarkdown, bytes.NewReader(fixed), int64(len(fixed)), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Diaries.Created != 1 || report.Images.Created != 2 {
+		t.Fatalf("diaries = %+v, images = %+v, want 1 diary and 2 images", report.Diaries, report.Images)
+	}
+	if got := diaries.diaries[0].PlainContent; strings.Contains(got, ".png") {
+		t.Fatalf("正文中的引用未改写：%q", got)
+	}
+}
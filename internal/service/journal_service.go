@@ -0,0 +1,335 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"diary/config"
+	"diary/internal/domain"
+	"diary/pkg/utils"
+)
+
+var (
+	ErrJournalNotFound       = errors.New("日记本不存在")
+	ErrJournalForbidden      = errors.New("无权操作此日记本")
+	ErrInvalidJournalRole    = errors.New("无效的成员角色")
+	ErrJournalMemberExists   = errors.New("该用户已经是成员")
+	ErrJournalMemberNotFound = errors.New("成员不存在")
+	ErrJournalOwnerLeave     = errors.New("所有者不能退出日记本")
+	ErrJournalKeyCorrupted   = errors.New("日记本密钥已损坏")
+)
+
+type JournalService interface {
+	// Create 创建日记本并生成它的数据密钥，创建者成为所有者
+	Create(ctx context.Context, userID uint, name, description string) (*domain.Journal, error)
+	// List 获取自己加入的日记本
+	List(ctx context.Context, userID uint) ([]domain.Journal, error)
+	// Get 获取日记本，非成员返回 ErrJournalNotFound
+	Get(ctx context.Context, userID, id uint) (*domain.Journal, error)
+	// Update 修改名称和简介，只有所有者可以修改
+	Update(ctx context.Context, userID, id uint, name, description string) (*domain.Journal, error)
+	// Delete 删除日记本及其中的日记，只有所有者可以删除
+	Delete(ctx context.Context, userID, id uint) error
+
+	// ListMembers 获取成员列表，成员都可以查看
+	ListMembers(ctx context.Context, userID, id uint) ([]domain.JournalMember, error)
+	// AddMember 所有者按用户名添加编辑或只读成员
+	AddMember(ctx context.Context, userID, id uint, username, role string) (*domain.JournalMember, error)
+	// UpdateMemberRole 所有者修改成员角色，所有者自己的角色不能修改
+	UpdateMemberRole(ctx context.Context, userID, id uint, username, role string) error
+	// RemoveMember 所有者移除成员，成员也可以移除自己（退出）
+	RemoveMember(ctx context.Context, userID, id uint, username string) error
+
+	// ListDiaries 获取日记本内的日记
+	ListDiaries(ctx context.Context, userID, id uint, page, pageSize int) ([]domain.Diary, int64, error)
+	// SearchDiaries 搜索日记本内的日记
+	SearchDiaries(ctx context.Context, userID, id uint, keyword string, page, pageSize int) ([]domain.Diary, int64, error)
+
+	// CheckWritable 用户能否在日记本中写日记
+	CheckWritable(ctx context.Context, userID, id uint) error
+	// CanRead 作者和日记本成员可以查看日记
+	CanRead(ctx context.Context, userID uint, diary *domain.Diary) bool
+	// CanWrite 作者和日记本的所有者、编辑可以修改日记，是否公开只有作者可以修改（见 DiaryService.Update）
+	CanWrite(ctx context.Context, userID uint, diary *domain.Diary) bool
+	// CanDelete 作者和日记本的所有者可以删除日记
+	CanDelete(ctx context.Context, userID uint, diary *domain.Diary) bool
+}
+
+type journalService struct {
+	journalRepo  domain.JournalRepository
+	userRepo     domain.UserRepository
+	diaryService DiaryService
+	cfg          *config.Config
+}
+
+func NewJournalService(journalRepo domain.JournalRepository, userRepo domain.UserRepository, diaryService DiaryService, cfg *config.Config) JournalService {
+	return &journalService{
+		journalRepo:  journalRepo,
+		userRepo:     userRepo,
+		diaryService: diaryService,
+		cfg:          cfg,
+	}
+}
+
+func (s *journalService) Create(ctx context.Context, userID uint, name, description string) (*domain.Journal, error) {
+	keyEnc, err := newJournalKey(s.cfg)
+	if err != nil {
+		return nil, err
+	}
+	journal := &domain.Journal{OwnerID: userID, Name: name, Description: description, KeyEnc: keyEnc}
+	if err := s.journalRepo.Create(ctx, journal); err != nil {
+		return nil, err
+	}
+	return journal, nil
+}
+
+func (s *journalService) List(ctx context.Context, userID uint) ([]domain.Journal, error) {
+	return s.journalRepo.ListByUserID(ctx, userID)
+}
+
+func (s *journalService) Get(ctx context.Context, userID, id uint) (*domain.Journal, error) {
+	journal, _, err := s.membership(ctx, userID, id)
+	return journal, err
+}
+
+func (s *journalService) Update(ctx context.Context, userID, id uint, name, description string) (*domain.Journal, error) {
+	journal, err := s.ownedJournal(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	journal.Name = name
+	journal.Description = description
+	if err := s.journalRepo.Update(ctx, journal); err != nil {
+		return nil, err
+	}
+	return journal, nil
+}
+
+func (s *journalService) Delete(ctx context.Context, userID, id uint) error {
+	if _, err := s.ownedJournal(ctx, userID, id); err != nil {
+		return err
+	}
+	return s.journalRepo.Delete(ctx, id)
+}
+
+func (s *journalService) ListMembers(ctx context.Context, userID, id uint) ([]domain.JournalMember, error) {
+	if _, _, err := s.membership(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	members, err := s.journalRepo.ListMembers(ctx, id)
+	if err != nil || len(members) == 0 {
+		return members, err
+	}
+
+	ids := make([]uint, len(members))
+	for i := range members {
+		ids[i] = members[i].UserID
+	}
+	users, err := s.userRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*domain.User, len(users))
+	for i := range users {
+		byID[users[i].ID] = &users[i]
+	}
+
+	// 已注销的成员不返回
+	result := make([]domain.JournalMember, 0, len(members))
+	for _, m := range members {
+		if user, ok := byID[m.UserID]; ok {
+			m.User = user
+			result = append(result, m)
+		}
+	}
+	return result, nil
+}
+
+func (s *journalService) AddMember(ctx context.Context, userID, id uint, username, role string) (*domain.JournalMember, error) {
+	if !validMemberRole(role) {
+		return nil, ErrInvalidJournalRole
+	}
+	if _, err := s.ownedJournal(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	user, err := s.userRepo.GetByUsername(ctx, username)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	if _, err := s.journalRepo.GetMember(ctx, id, user.ID); err == nil {
+		return nil, ErrJournalMemberExists
+	}
+
+	member := &domain.JournalMember{JournalID: id, UserID: user.ID, Role: role, User: user}
+	if err := s.journalRepo.AddMember(ctx, member); err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+func (s *journalService) UpdateMemberRole(ctx context.Context, userID, id uint, username, role string) error {
+	if !validMemberRole(role) {
+		return ErrInvalidJournalRole
+	}
+	journal, err := s.ownedJournal(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	member, err := s.memberByUsername(ctx, id, username)
+	if err != nil {
+		return err
+	}
+	if member.UserID == journal.OwnerID {
+		return fmt.Errorf("%w: 不能修改所有者的角色", ErrInvalidJournalRole)
+	}
+	return s.journalRepo.UpdateMemberRole(ctx, id, member.UserID, role)
+}
+
+func (s *journalService) RemoveMember(ctx context.Context, userID, id uint, username string) error {
+	journal, _, err := s.membership(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	member, err := s.memberByUsername(ctx, id, username)
+	if err != nil {
+		return err
+	}
+	if member.UserID == journal.OwnerID {
+		return ErrJournalOwnerLeave
+	}
+	if member.UserID != userID && journal.OwnerID != userID {
+		return ErrJournalForbidden
+	}
+	return s.journalRepo.RemoveMember(ctx, id, member.UserID)
+}
+
+func (s *journalService) ListDiaries(ctx context.Context, userID, id uint, page, pageSize int) ([]domain.Diary, int64, error) {
+	if _, _, err := s.membership(ctx, userID, id); err != nil {
+		return nil, 0, err
+	}
+	return s.diaryService.ListByJournalID(ctx, id, page, pageSize)
+}
+
+func (s *journalService) SearchDiaries(ctx context.Context, userID, id uint, keyword string, page, pageSize int) ([]domain.Diary, int64, error) {
+	if _, _, err := s.membership(ctx, userID, id); err != nil {
+		return nil, 0, err
+	}
+	return s.diaryService.SearchJournal(ctx, id, keyword, page, pageSize)
+}
+
+func (s *journalService) CheckWritable(ctx context.Context, userID, id uint) error {
+	_, member, err := s.membership(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if !member.CanWrite() {
+		return ErrJournalForbidden
+	}
+	return nil
+}
+
+func (s *journalService) CanRead(ctx context.Context, userID uint, diary *domain.Diary) bool {
+	if diary.UserID == userID {
+		return true
+	}
+	if diary.JournalID == nil {
+		return false
+	}
+	_, _, err := s.membership(ctx, userID, *diary.JournalID)
+	return err == nil
+}
+
+func (s *journalService) CanWrite(ctx context.Context, userID uint, diary *domain.Diary) bool {
+	if diary.UserID == userID {
+		return true
+	}
+	if diary.JournalID == nil {
+		return false
+	}
+	return s.CheckWritable(ctx, userID, *diary.JournalID) == nil
+}
+
+func (s *journalService) CanDelete(ctx context.Context, userID uint, diary *domain.Diary) bool {
+	if diary.UserID == userID {
+		return true
+	}
+	if diary.JournalID == nil {
+		return false
+	}
+	_, member, err := s.membership(ctx, userID, *diary.JournalID)
+	return err == nil && member.Role == domain.JournalRoleOwner
+}
+
+// membership 日记本存在且用户是成员，返回的日记本带上用户的角色
+func (s *journalService) membership(ctx context.Context, userID, id uint) (*domain.Journal, *domain.JournalMember, error) {
+	journal, err := s.journalRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, ErrJournalNotFound
+	}
+	member, err := s.journalRepo.GetMember(ctx, id, userID)
+	if err != nil {
+		return nil, nil, ErrJournalNotFound
+	}
+	journal.Role = member.Role
+	return journal, member, nil
+}
+
+// ownedJournal 日记本存在且用户是所有者
+func (s *journalService) ownedJournal(ctx context.Context, userID, id uint) (*domain.Journal, error) {
+	journal, member, err := s.membership(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if member.Role != domain.JournalRoleOwner {
+		return nil, ErrJournalForbidden
+	}
+	return journal, nil
+}
+
+func (s *journalService) memberByUsername(ctx context.Context, id uint, username string) (*domain.JournalMember, error) {
+	user, err := s.userRepo.GetByUsername(ctx, username)
+	if err != nil {
+		return nil, ErrJournalMemberNotFound
+	}
+	member, err := s.journalRepo.GetMember(ctx, id, user.ID)
+	if err != nil {
+		return nil, ErrJournalMemberNotFound
+	}
+	return member, nil
+}
+
+// validMemberRole 添加成员时只能指定编辑或只读，每个日记本只有一个所有者
+func validMemberRole(role string) bool {
+	return role == domain.JournalRoleEditor || role == domain.JournalRoleViewer
+}
+
+// newJournalKey 生成日记本的数据密钥，返回用主密钥加密后的形式；未配置主密钥时返回空字符串，日记本内容不加密
+func newJournalKey(cfg *config.Config) (string, error) {
+	if len(cfg.AESKey) != 32 {
+		return "", nil
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return utils.EncryptToString(cfg.AESKey, base64.StdEncoding.EncodeToString(key))
+}
+
+// unwrapJournalKey 用主密钥解开日记本的数据密钥
+func unwrapJournalKey(cfg *config.Config, keyEnc string) ([]byte, error) {
+	if keyEnc == "" || len(cfg.AESKey) != 32 {
+		return nil, nil
+	}
+	encoded, err := utils.DecryptFromString(cfg.AESKey, keyEnc)
+	if err != nil {
+		return nil, ErrJournalKeyCorrupted
+	}
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(key) != 32 {
+		return nil, ErrJournalKeyCorrupted
+	}
+	return key, nil
+}
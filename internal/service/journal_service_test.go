@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"diary/config"
+	"diary/internal/domain"
+	"diary/pkg/utils"
+)
+
+type memJournalRepo struct {
+	domain.JournalRepository
+	journals map[uint]*domain.Journal
+	members  map[[2]uint]*domain.JournalMember // 键为 [日记本, 用户]
+}
+
+func (r *memJournalRepo) GetByID(ctx context.Context, id uint) (*domain.Journal, error) {
+	j, ok := r.journals[id]
+	if !ok || j.IsDeleted {
+		return nil, errFakeNotFound
+	}
+	copied := *j
+	return &copied, nil
+}
+
+func (r *memJournalRepo) GetKeyEnc(ctx context.Context, id uint) (string, error) {
+	j, ok := r.journals[id]
+	if !ok {
+		return "", errFakeNotFound
+	}
+	return j.KeyEnc, nil
+}
+
+func (r *memJournalRepo) GetMember(ctx context.Context, journalID, userID uint) (*domain.JournalMember, error) {
+	m, ok := r.members[[2]uint{journalID, userID}]
+	if !ok {
+		return nil, errFakeNotFound
+	}
+	copied := *m
+	return &copied, nil
+}
+
+func (r *memJournalRepo) AddMember(ctx context.Context, member *domain.JournalMember) error {
+	copied := *member
+	r.members[[2]uint{member.JournalID, member.UserID}] = &copied
+	return nil
+}
+
+func (r *memJournalRepo) UpdateMemberRole(ctx context.Context, journalID, userID uint, role string) error {
+	r.members[[2]uint{journalID, userID}].Role = role
+	return nil
+}
+
+func (r *memJournalRepo) RemoveMember(ctx context.Context, journalID, userID uint) error {
+	delete(r.members, [2]uint{journalID, userID})
+	return nil
+}
+
+type memUserRepo struct {
+	domain.UserRepository
+	users      []domain.User
+	archiveIDs map[uint]string
+}
+
+func (r *memUserRepo) EnsureArchiveID(ctx context.Context, id uint, candidate string) (string, error) {
+	if r.archiveIDs == nil {
+		r.archiveIDs = map[uint]string{}
+	}
+	if r.archiveIDs[id] == "" {
+		r.archiveIDs[id] = candidate
+	}
+	return r.archiveIDs[id], nil
+}
+
+func (r *memUserRepo) GetByUsername(ctx context.Context, username string) (*domain.User, error) {
+	for i := range r.users {
+		if r.users[i].Username == username {
+			copied := r.users[i]
+			return &copied, nil
+		}
+	}
+	return nil, errFakeNotFound
+}
+
+// 日记本 1：所有者 10，编辑 11，只读 12；用户 13 不是成员
+func newTestJournalRepo() *memJournalRepo {
+	repo := &memJournalRepo{
+		journals: map[uint]*domain.Journal{1: {ID: 1, OwnerID: 10}},
+		members:  map[[2]uint]*domain.JournalMember{},
+	}
+	for userID, role := range map[uint]string{
+		10: domain.JournalRoleOwner,
+		11: domain.JournalRoleEditor,
+		12: domain.JournalRoleViewer,
+	} {
+		repo.AddMember(context.Background(), &domain.JournalMember{JournalID: 1, UserID: userID, Role: role})
+	}
+	return repo
+}
+
+func newTestJournalService() (JournalService, *memJournalRepo) {
+	repo := newTestJournalRepo()
+	users := &memUserRepo{users: []domain.User{
+		{ID: 10, Username: "owner"},
+		{ID: 11, Username: "editor"},
+		{ID: 12, Username: "viewer"},
+		{ID: 13, Username: "outsider"},
+	}}
+	return NewJournalService(repo, users, nil, &config.Config{}), repo
+}
+
+func TestJournalDiaryPermissions(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestJournalService()
+	journalID := uint(1)
+	shared := &domain.Diary{ID: 1, UserID: 11, JournalID: &journalID}
+	personal := &domain.Diary{ID: 2, UserID: 11}
+
+	tests := []struct {
+		name                      string
+		userID                    uint
+		diary                     *domain.Diary
+		canRead, canWrite, canDel bool
+	}{
+		{"作者", 11, shared, true, true, true},
+		{"日记本所有者", 10, shared, true, true, true},
+		{"只读成员", 12, shared, true, false, false},
+		{"非成员", 13, shared, false, false, false},
+		{"个人日记的作者", 11, personal, true, true, true},
+		{"日记本所有者不能访问成员的个人日记", 10, personal, false, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.CanRead(ctx, tt.userID, tt.diary); got != tt.canRead {
+				t.Errorf("CanRead = %v, want %v", got, tt.canRead)
+			}
+			if got := s.CanWrite(ctx, tt.userID, tt.diary); got != tt.canWrite {
+				t.Errorf("CanWrite = %v, want %v", got, tt.canWrite)
+			}
+			if got := s.CanDelete(ctx, tt.userID, tt.diary); got != tt.canDel {
+				t.Errorf("CanDelete = %v, want %v", got, tt.canDel)
+			}
+		})
+	}
+
+	// 编辑可以修改其他成员的日记，但不能删除
+	other := &domain.Diary{ID: 3, UserID: 10, JournalID: &journalID}
+	if !s.CanWrite(ctx, 11, other) || s.CanDelete(ctx, 11, other) {
+		t.Fatal("编辑应能修改但不能删除其他成员的日记")
+	}
+}
+
+func TestJournalMembership(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		run     func(s JournalService) error
+		wantErr error
+	}{
+		{"编辑不能添加成员", func(s JournalService) error {
+			_, err := s.AddMember(ctx, 11, 1, "outsider", domain.JournalRoleViewer)
+			return err
+		}, ErrJournalForbidden},
+		{"不能添加第二个所有者", func(s JournalService) error {
+			_, err := s.AddMember(ctx, 10, 1, "outsider", domain.JournalRoleOwner)
+			return err
+		}, ErrInvalidJournalRole},
+		{"重复添加", func(s JournalService) error {
+			_, err := s.AddMember(ctx, 10, 1, "viewer", domain.JournalRoleViewer)
+			return err
+		}, ErrJournalMemberExists},
+		{"非成员看不到日记本", func(s JournalService) error {
+			_, err := s.Get(ctx, 13, 1)
+			return err
+		}, ErrJournalNotFound},
+		{"所有者的角色不能修改", func(s JournalService) error {
+			return s.UpdateMemberRole(ctx, 10, 1, "owner", domain.JournalRoleViewer)
+		}, ErrInvalidJournalRole},
+		{"所有者不能退出", func(s JournalService) error {
+			return s.RemoveMember(ctx, 10, 1, "owner")
+		}, ErrJournalOwnerLeave},
+		{"编辑不能移除其他成员", func(s JournalService) error {
+			return s.RemoveMember(ctx, 11, 1, "viewer")
+		}, ErrJournalForbidden},
+		{"成员可以退出", func(s JournalService) error {
+			return s.RemoveMember(ctx, 12, 1, "viewer")
+		}, nil},
+		{"所有者移除成员", func(s JournalService) error {
+			return s.RemoveMember(ctx, 10, 1, "editor")
+		}, nil},
+		{"只读成员不能写日记", func(s JournalService) error {
+			return s.CheckWritable(ctx, 12, 1)
+		}, ErrJournalForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestJournalService()
+			if err := tt.run(s); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUnwrapJournalKey(t *testing.T) {
+	cfg := &config.Config{AESKey: []byte("0123456789abcdef0123456789abcdef")}
+	keyEnc, err := newJournalKey(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	short, err := utils.EncryptToString(cfg.AESKey, base64.StdEncoding.EncodeToString([]byte("short")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if key, err := unwrapJournalKey(cfg, keyEnc); err != nil || len(key) != 32 {
+		t.Fatalf("key = %d 字节, err = %v", len(key), err)
+	}
+	for name, enc := range map[string]string{"无法解密": "not-encrypted", "长度不对": short} {
+		if _, err := unwrapJournalKey(cfg, enc); !errors.Is(err, ErrJournalKeyCorrupted) {
+			t.Errorf("%s: err = %v, want %v", name, err, ErrJournalKeyCorrupted)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"diary/config"
+	"diary/internal/domain"
+	"diary/pkg/notify"
+)
+
+var (
+	ErrNotificationNotFound    = errors.New("通知不存在")
+	ErrInvalidNotificationConf = errors.New("无效的提醒设置")
+	ErrNotificationFailed      = errors.New("通知发送失败")
+)
+
+// Notifier 通知渠道，按用户设置决定是否发送
+type Notifier interface {
+	// Channel 渠道名称，用于错误信息
+	Channel() string
+	// Enabled 用户是否开启了该渠道
+	Enabled(setting *domain.NotificationSetting) bool
+	Notify(ctx context.Context, setting *domain.NotificationSetting, msg notify.Message) error
+}
+
+// DefaultNotifiers 站内通知、Webhook，以及配置了 SMTP 时的邮件
+func DefaultNotifiers(notificationRepo domain.NotificationRepository, cfg *config.Config) []Notifier {
+	notifiers := []Notifier{
+		&inAppNotifier{repo: notificationRepo},
+		&webhookNotifier{webhook: notify.NewWebhook(cfg.WebhookSecret)},
+	}
+	if cfg.SMTPHost != "" {
+		notifiers = append(notifiers, &emailNotifier{smtp: &notify.SMTP{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.SMTPFrom,
+		}})
+	}
+	return notifiers
+}
+
+type inAppNotifier struct {
+	repo domain.NotificationRepository
+}
+
+func (n *inAppNotifier) Channel() string { return "in_app" }
+
+func (n *inAppNotifier) Enabled(setting *domain.NotificationSetting) bool { return setting.InApp }
+
+func (n *inAppNotifier) Notify(ctx context.Context, setting *domain.NotificationSetting, msg notify.Message) error {
+	return n.repo.Create(ctx, &domain.Notification{
+		UserID: setting.UserID,
+		Kind:   msg.Kind,
+		Title:  msg.Title,
+		Body:   msg.Body,
+		Link:   msg.Link,
+	})
+}
+
+type webhookNotifier struct {
+	webhook *notify.Webhook
+}
+
+func (n *webhookNotifier) Channel() string { return "webhook" }
+
+func (n *webhookNotifier) Enabled(setting *domain.NotificationSetting) bool {
+	return setting.WebhookURL != ""
+}
+
+func (n *webhookNotifier) Notify(ctx context.Context, setting *domain.NotificationSetting, msg notify.Message) error {
+	return n.webhook.Send(ctx, setting.WebhookURL, msg)
+}
+
+type emailNotifier struct {
+	smtp *notify.SMTP
+}
+
+func (n *emailNotifier) Channel() string { return "email" }
+
+func (n *emailNotifier) Enabled(setting *domain.NotificationSetting) bool { return setting.Email != "" }
+
+func (n *emailNotifier) Notify(ctx context.Context, setting *domain.NotificationSetting, msg notify.Message) error {
+	// 只使用解析出的地址部分，不把显示名等原样写入邮件头
+	addr, err := mail.ParseAddress(setting.Email)
+	if err != nil {
+		return err
+	}
+	return n.smtp.Send(ctx, addr.Address, msg)
+}
+
+type NotificationService interface {
+	// Send 按用户设置通过各渠道发送，只有全部渠道都失败时返回错误
+	Send(ctx context.Context, userID uint, msg notify.Message) error
+	// Deliver 按用户设置发送，跳过 skip 中的渠道，返回本次发送成功的渠道。
+	// 任一渠道失败都返回 ErrNotificationFailed，调用方重试时把已成功的渠道放进 skip
+	Deliver(ctx context.Context, userID uint, msg notify.Message, skip []string) ([]string, error)
+	List(ctx context.Context, userID uint, unreadOnly bool, page, pageSize int) ([]domain.Notification, int64, error)
+	CountUnread(ctx context.Context, userID uint) (int64, error)
+	MarkRead(ctx context.Context, userID, id uint) error
+	MarkAllRead(ctx context.Context, userID uint) error
+	GetSetting(ctx context.Context, userID uint) (*domain.NotificationSetting, error)
+	UpdateSetting(ctx context.Context, setting *domain.NotificationSetting) error
+}
+
+type notificationService struct {
+	notificationRepo domain.NotificationRepository
+	settingRepo      domain.NotificationSettingRepository
+	notifiers        []Notifier
+}
+
+func NewNotificationService(notificationRepo domain.NotificationRepository, settingRepo domain.NotificationSettingRepository, notifiers []Notifier) NotificationService {
+	return &notificationService{
+		notificationRepo: notificationRepo,
+		settingRepo:      settingRepo,
+		notifiers:        notifiers,
+	}
+}
+
+func (s *notificationService) Send(ctx context.Context, userID uint, msg notify.Message) error {
+	sent, err := s.Deliver(ctx, userID, msg, nil)
+	if len(sent) > 0 {
+		return nil
+	}
+	return err
+}
+
+func (s *notificationService) Deliver(ctx context.Context, userID uint, msg notify.Message, skip []string) ([]string, error) {
+	setting, err := s.GetSetting(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if msg.SentAt.IsZero() {
+		msg.SentAt = time.Now()
+	}
+
+	var errs, sent []string
+	for _, n := range s.notifiers {
+		if !n.Enabled(setting) || slices.Contains(skip, n.Channel()) {
+			continue
+		}
+		if err := n.Notify(ctx, setting, msg); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", n.Channel(), err))
+			continue
+		}
+		sent = append(sent, n.Channel())
+	}
+	if len(errs) > 0 {
+		// 具体原因（连接错误等）只记录在服务端，返回给调用方会暴露内部网络信息
+		log.Printf("notify user %d failed: %s", userID, strings.Join(errs, "; "))
+		return sent, ErrNotificationFailed
+	}
+	return sent, nil
+}
+
+func (s *notificationService) List(ctx context.Context, userID uint, unreadOnly bool, page, pageSize int) ([]domain.Notification, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	offset := (page - 1) * pageSize
+
+	return s.notificationRepo.ListByUserID(ctx, userID, unreadOnly, offset, pageSize)
+}
+
+func (s *notificationService) CountUnread(ctx context.Context, userID uint) (int64, error) {
+	return s.notificationRepo.CountUnread(ctx, userID)
+}
+
+func (s *notificationService) MarkRead(ctx context.Context, userID, id uint) error {
+	if err := s.notificationRepo.MarkRead(ctx, userID, id); err != nil {
+		return ErrNotificationNotFound
+	}
+	return nil
+}
+
+func (s *notificationService) MarkAllRead(ctx context.Context, userID uint) error {
+	return s.notificationRepo.MarkAllRead(ctx, userID)
+}
+
+func (s *notificationService) GetSetting(ctx context.Context, userID uint) (*domain.NotificationSetting, error) {
+	setting, err := s.settingRepo.Get(ctx, userID)
+	if err != nil {
+		return domain.DefaultNotificationSetting(userID), nil
+	}
+	return setting, nil
+}
+
+func (s *notificationService) UpdateSetting(ctx context.Context, setting *domain.NotificationSetting) error {
+	if setting.PromptTime == "" {
+		setting.PromptTime = domain.DefaultNotificationSetting(setting.UserID).PromptTime
+	}
+	if _, err := time.Parse("15:04", setting.PromptTime); err != nil {
+		return fmt.Errorf("%w: prompt_time 格式应为 HH:MM", ErrInvalidNotificationConf)
+	}
+	if _, err := loadTimezone(setting.Timezone); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidNotificationConf, err)
+	}
+	if setting.WebhookURL != "" {
+		u, err := url.Parse(setting.WebhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%w: webhook_url 必须是 http(s) 地址", ErrInvalidNotificationConf)
+		}
+		// 域名在发送时按解析结果再检查一次，这里只提前拒绝明显的内网地址
+		host := u.Hostname()
+		if ip, err := netip.ParseAddr(host); strings.EqualFold(host, "localhost") || (err == nil && !notify.PublicAddr(ip)) {
+			return fmt.Errorf("%w: webhook_url 不能指向内网地址", ErrInvalidNotificationConf)
+		}
+	}
+	if setting.Email != "" {
+		if _, err := mail.ParseAddress(setting.Email); err != nil {
+			return fmt.Errorf("%w: 邮箱格式错误", ErrInvalidNotificationConf)
+		}
+	}
+	return s.settingRepo.Save(ctx, setting)
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"diary/config"
+	"diary/internal/domain"
+)
+
+var (
+	ErrProfileNotFound = errors.New("用户主页不存在")
+	ErrInvalidAvatar   = errors.New("头像必须是自己上传的图片")
+)
+
+type ProfileService interface {
+	// UpdateProfile 修改自己的主页资料，avatarImageID 为 nil 时清除头像
+	UpdateProfile(ctx context.Context, userID uint, displayName, bio string, avatarImageID *uint, public bool) (*domain.User, error)
+	// GetPublic 获取公开主页，用户不存在或未公开时返回 ErrProfileNotFound
+	GetPublic(ctx context.Context, username string) (*domain.User, error)
+	// ListDiaries 获取公开主页用户的公开日记，filter.UserID 会被忽略
+	ListDiaries(ctx context.Context, username string, filter domain.PublicDiaryFilter, page, pageSize int) (*domain.User, []domain.Diary, int64, error)
+	// ListDiariesAfter 按游标获取公开主页用户的公开日记，返回下一页的游标
+	ListDiariesAfter(ctx context.Context, username string, filter domain.PublicDiaryFilter, cursor string, limit int) (*domain.User, []domain.Diary, string, error)
+	// PublicAuthors 批量获取开启了公开主页的作者，未公开的不在结果中
+	PublicAuthors(ctx context.Context, userIDs []uint) (map[uint]*domain.User, error)
+	// Authors 批量获取评论者，未公开主页的用户只保留用户名
+	Authors(ctx context.Context, userIDs []uint) (map[uint]*domain.User, error)
+}
+
+type profileService struct {
+	userRepo     domain.UserRepository
+	imageRepo    domain.ImageRepository
+	followRepo   domain.FollowRepository
+	diaryService DiaryService
+	cfg          *config.Config
+}
+
+func NewProfileService(userRepo domain.UserRepository, imageRepo domain.ImageRepository, followRepo domain.FollowRepository, diaryService DiaryService, cfg *config.Config) ProfileService {
+	return &profileService{
+		userRepo:     userRepo,
+		imageRepo:    imageRepo,
+		followRepo:   followRepo,
+		diaryService: diaryService,
+		cfg:          cfg,
+	}
+}
+
+func (s *profileService) UpdateProfile(ctx context.Context, userID uint, displayName, bio string, avatarImageID *uint, public bool) (*domain.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	if avatarImageID != nil {
+		img, err := s.imageRepo.GetByID(ctx, *avatarImageID)
+		if err != nil || img.UserID != userID || img.Kind != domain.AttachmentKindImage {
+			return nil, ErrInvalidAvatar
+		}
+	}
+
+	user.DisplayName = displayName
+	user.Bio = bio
+	user.AvatarImageID = avatarImageID
+	user.ProfilePublic = public
+	if err := s.userRepo.UpdateProfile(ctx, user); err != nil {
+		return nil, err
+	}
+	s.signAvatar(user)
+	if err := s.fillFollowCounts(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *profileService) GetPublic(ctx context.Context, username string) (*domain.User, error) {
+	user, err := s.getPublic(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.fillFollowCounts(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *profileService) ListDiaries(ctx context.Context, username string, filter domain.PublicDiaryFilter, page, pageSize int) (*domain.User, []domain.Diary, int64, error) {
+	user, err := s.getPublic(ctx, username)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	filter.UserID = user.ID
+	diaries, total, err := s.diaryService.ListPublic(ctx, filter, page, pageSize)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	return user, diaries, total, nil
+}
+
+func (s *profileService) ListDiariesAfter(ctx context.Context, username string, filter domain.PublicDiaryFilter, cursor string, limit int) (*domain.User, []domain.Diary, string, error) {
+	user, err := s.getPublic(ctx, username)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	filter.UserID = user.ID
+	diaries, next, err := s.diaryService.ListPublicAfter(ctx, filter, cursor, limit)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	return user, diaries, next, nil
+}
+
+func (s *profileService) PublicAuthors(ctx context.Context, userIDs []uint) (map[uint]*domain.User, error) {
+	authors := make(map[uint]*domain.User)
+	if len(userIDs) == 0 {
+		return authors, nil
+	}
+	users, err := s.userRepo.GetByIDs(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range users {
+		if users[i].ProfilePublic {
+			s.signAvatar(&users[i])
+			authors[users[i].ID] = &users[i]
+		}
+	}
+	return authors, nil
+}
+
+func (s *profileService) Authors(ctx context.Context, userIDs []uint) (map[uint]*domain.User, error) {
+	authors := make(map[uint]*domain.User)
+	if len(userIDs) == 0 {
+		return authors, nil
+	}
+	users, err := s.userRepo.GetByIDs(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range users {
+		if users[i].ProfilePublic {
+			s.signAvatar(&users[i])
+		} else {
+			users[i].DisplayName = ""
+			users[i].AvatarURL = ""
+		}
+		authors[users[i].ID] = &users[i]
+	}
+	return authors, nil
+}
+
+func (s *profileService) getPublic(ctx context.Context, username string) (*domain.User, error) {
+	user, err := s.userRepo.GetByUsername(ctx, username)
+	if err != nil || !user.ProfilePublic {
+		return nil, ErrProfileNotFound
+	}
+	s.signAvatar(user)
+	return user, nil
+}
+
+func (s *profileService) fillFollowCounts(ctx context.Context, user *domain.User) error {
+	followers, following, err := s.followRepo.CountByUserID(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	user.FollowerCount = followers
+	user.FollowingCount = following
+	return nil
+}
+
+func (s *profileService) signAvatar(user *domain.User) {
+	if user.AvatarImageID != nil {
+		user.AvatarURL = signImageURL(s.cfg, *user.AvatarImageID)
+	}
+}
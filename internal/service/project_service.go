@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"diary/internal/domain"
+)
+
+var (
+	ErrProjectNotFound = errors.New("清单不存在")
+)
+
+// ProjectService 待办清单
+type ProjectService interface {
+	Create(ctx context.Context, userID uint, name, color string) (*domain.TodoProject, error)
+	List(ctx context.Context, userID uint) ([]domain.TodoProject, error)
+	Update(ctx context.Context, userID, id uint, name, color string, sortOrder int) (*domain.TodoProject, error)
+	// Delete 删除清单，其中的待办移出清单而不是一并删除
+	Delete(ctx context.Context, userID, id uint) error
+}
+
+type projectService struct {
+	projectRepo domain.TodoProjectRepository
+	todoRepo    domain.TodoRepository
+}
+
+func NewProjectService(projectRepo domain.TodoProjectRepository, todoRepo domain.TodoRepository) ProjectService {
+	return &projectService{
+		projectRepo: projectRepo,
+		todoRepo:    todoRepo,
+	}
+}
+
+func (s *projectService) Create(ctx context.Context, userID uint, name, color string) (*domain.TodoProject, error) {
+	projects, err := s.projectRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	project := &domain.TodoProject{
+		UserID:    userID,
+		Name:      name,
+		Color:     color,
+		SortOrder: len(projects) + 1,
+	}
+	if err := s.projectRepo.Create(ctx, project); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+func (s *projectService) List(ctx context.Context, userID uint) ([]domain.TodoProject, error) {
+	return s.projectRepo.ListByUserID(ctx, userID)
+}
+
+func (s *projectService) Update(ctx context.Context, userID, id uint, name, color string, sortOrder int) (*domain.TodoProject, error) {
+	project, err := s.get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	project.Name = name
+	project.Color = color
+	project.SortOrder = sortOrder
+	if err := s.projectRepo.Update(ctx, project); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+func (s *projectService) Delete(ctx context.Context, userID, id uint) error {
+	if _, err := s.get(ctx, userID, id); err != nil {
+		return err
+	}
+	if err := s.todoRepo.ClearProject(ctx, id); err != nil {
+		return err
+	}
+	return s.projectRepo.Delete(ctx, id)
+}
+
+func (s *projectService) get(ctx context.Context, userID, id uint) (*domain.TodoProject, error) {
+	project, err := s.projectRepo.GetByID(ctx, id)
+	if err != nil || project.UserID != userID {
+		return nil, ErrProjectNotFound
+	}
+	return project, nil
+}
@@ -0,0 +1,288 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"diary/config"
+	"diary/internal/domain"
+	"diary/pkg/notify"
+)
+
+const (
+	// reminderMaxAttempts 发送失败的最大尝试次数
+	reminderMaxAttempts = 3
+	// reminderCatchUp 首次启动时补发多久以内的提醒；每日写作提醒只补发这段时间内错过的
+	reminderCatchUp = 24 * time.Hour
+)
+
+// ReminderService 后台调度器：为到期的待办和每日写作提醒生成任务并发送
+//
+// 任务写入数据库后才发送，已规划到的时间也保存在数据库中，服务停机再久重启后也会从上次的位置继续规划，
+// 唯一索引保证不会重复生成
+type ReminderService interface {
+	// RunOnce 规划 (after, now] 内的提醒并发送所有到期任务
+	RunOnce(ctx context.Context, after, now time.Time) error
+	// Start 按配置的间隔在后台执行，ctx 取消时退出
+	Start(ctx context.Context)
+}
+
+type reminderService struct {
+	jobRepo             domain.ReminderJobRepository
+	todoRepo            domain.TodoRepository
+	diaryRepo           domain.DiaryRepository
+	settingRepo         domain.NotificationSettingRepository
+	notificationService NotificationService
+	cfg                 *config.Config
+}
+
+func NewReminderService(jobRepo domain.ReminderJobRepository, todoRepo domain.TodoRepository, diaryRepo domain.DiaryRepository,
+	settingRepo domain.NotificationSettingRepository, notificationService NotificationService, cfg *config.Config) ReminderService {
+	return &reminderService{
+		jobRepo:             jobRepo,
+		todoRepo:            todoRepo,
+		diaryRepo:           diaryRepo,
+		settingRepo:         settingRepo,
+		notificationService: notificationService,
+		cfg:                 cfg,
+	}
+}
+
+func (s *reminderService) Start(ctx context.Context) {
+	if s.cfg.ReminderIntervalSeconds <= 0 {
+		return
+	}
+	interval := time.Duration(s.cfg.ReminderIntervalSeconds) * time.Second
+
+	go func() {
+		run := func() {
+			if err := s.tick(ctx, time.Now()); err != nil {
+				log.Printf("reminder scheduler failed: %v", err)
+			}
+		}
+
+		run()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				run()
+			}
+		}
+	}()
+}
+
+// tick 从保存的水位规划到 now 并发送，成功后把水位推进到 now
+func (s *reminderService) tick(ctx context.Context, now time.Time) error {
+	after, err := s.jobRepo.Watermark(ctx)
+	if err != nil {
+		return err
+	}
+	if after.IsZero() {
+		after = now.Add(-reminderCatchUp)
+	}
+	if err := s.RunOnce(ctx, after, now); err != nil {
+		return err
+	}
+	return s.jobRepo.SaveWatermark(ctx, now)
+}
+
+func (s *reminderService) RunOnce(ctx context.Context, after, now time.Time) error {
+	if err := s.planTodos(ctx, after, now); err != nil {
+		return err
+	}
+	// 过去几天的写作提醒已经没有意义，停机较久时只补发最近的
+	promptAfter := after
+	if catchUp := now.Add(-reminderCatchUp); promptAfter.Before(catchUp) {
+		promptAfter = catchUp
+	}
+	if err := s.planPrompts(ctx, promptAfter, now); err != nil {
+		return err
+	}
+	return s.fire(ctx, now)
+}
+
+// planTodos 为截止时间落在 (after, now] 内的待办生成提醒
+func (s *reminderService) planTodos(ctx context.Context, after, now time.Time) error {
+	var cursor *domain.TimeCursor
+	for {
+		todos, err := s.todoRepo.ListDueBetween(ctx, after, now, cursor, gcBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, t := range todos {
+			if err := s.schedule(ctx, t.UserID, domain.ReminderKindTodoDue, t.ID, *t.DueDate); err != nil {
+				return err
+			}
+		}
+		if len(todos) < gcBatchSize {
+			return nil
+		}
+		last := todos[len(todos)-1]
+		cursor = &domain.TimeCursor{Time: *last.DueDate, ID: last.ID}
+	}
+}
+
+// planPrompts 为开启了每日提醒的用户生成 (after, now] 内的写作提醒
+func (s *reminderService) planPrompts(ctx context.Context, after, now time.Time) error {
+	var lastUserID uint
+	for {
+		settings, err := s.settingRepo.ListDailyPrompt(ctx, lastUserID, gcBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, setting := range settings {
+			for _, at := range promptTimes(&setting, after, now) {
+				if err := s.schedule(ctx, setting.UserID, domain.ReminderKindDailyPrompt, setting.UserID, at); err != nil {
+					return err
+				}
+			}
+		}
+		if len(settings) < gcBatchSize {
+			return nil
+		}
+		lastUserID = settings[len(settings)-1].UserID
+	}
+}
+
+// promptTimes 用户时区中落在 (after, now] 内的每日提醒时间
+func promptTimes(setting *domain.NotificationSetting, after, now time.Time) []time.Time {
+	loc, err := loadTimezone(setting.Timezone)
+	if err != nil {
+		return nil
+	}
+	clock, err := time.Parse("15:04", setting.PromptTime)
+	if err != nil {
+		return nil
+	}
+
+	var times []time.Time
+	day := after.In(loc)
+	for d := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc); !d.After(now); d = d.AddDate(0, 0, 1) {
+		at := time.Date(d.Year(), d.Month(), d.Day(), clock.Hour(), clock.Minute(), 0, 0, loc)
+		if at.After(after) && !at.After(now) {
+			times = append(times, at)
+		}
+	}
+	return times
+}
+
+func (s *reminderService) schedule(ctx context.Context, userID uint, kind string, refID uint, at time.Time) error {
+	return s.jobRepo.Create(ctx, &domain.ReminderJob{
+		UserID:    userID,
+		Kind:      kind,
+		RefID:     refID,
+		FireAt:    at,
+		Status:    domain.ReminderPending,
+		NextTryAt: at,
+	})
+}
+
+// fire 发送到期任务，失败时按次数延后重试
+func (s *reminderService) fire(ctx context.Context, now time.Time) error {
+	for {
+		jobs, err := s.jobRepo.ListDue(ctx, now, gcBatchSize)
+		if err != nil {
+			return err
+		}
+		for i := range jobs {
+			job := &jobs[i]
+			s.deliver(ctx, job, now)
+			if err := s.jobRepo.Update(ctx, job); err != nil {
+				return err
+			}
+		}
+		if len(jobs) < gcBatchSize {
+			return nil
+		}
+	}
+}
+
+func (s *reminderService) deliver(ctx context.Context, job *domain.ReminderJob, now time.Time) {
+	job.Attempts++
+	msg, ok, err := s.message(ctx, job)
+	if err == nil && !ok {
+		job.Status = domain.ReminderCancelled
+		return
+	}
+	if err == nil {
+		// 只重试失败的渠道，已成功的渠道（如站内通知）不重复发送
+		var delivered, sent []string
+		if job.Delivered != "" {
+			delivered = strings.Split(job.Delivered, ",")
+		}
+		sent, err = s.notificationService.Deliver(ctx, job.UserID, *msg, delivered)
+		job.Delivered = strings.Join(append(delivered, sent...), ",")
+	}
+	if err == nil {
+		job.Status = domain.ReminderSent
+		job.LastError = ""
+		return
+	}
+
+	job.LastError = truncate(err.Error(), 512)
+	if job.Attempts >= reminderMaxAttempts {
+		job.Status = domain.ReminderFailed
+		return
+	}
+	job.NextTryAt = now.Add(time.Duration(job.Attempts) * 5 * time.Minute)
+}
+
+// message 生成通知内容，条件已不成立（待办已完成、今天已写过日记等）时返回 ok=false
+func (s *reminderService) message(ctx context.Context, job *domain.ReminderJob) (*notify.Message, bool, error) {
+	setting, err := s.notificationService.GetSetting(ctx, job.UserID)
+	if err != nil {
+		return nil, false, err
+	}
+	loc, err := loadTimezone(setting.Timezone)
+	if err != nil {
+		loc = time.Local
+	}
+
+	switch job.Kind {
+	case domain.ReminderKindTodoDue:
+		todo, err := s.todoRepo.GetByID(ctx, job.RefID)
+		if err != nil || !setting.TodoReminders || todo.Done || todo.DueDate == nil || todo.DueDate.Unix() != job.FireAt.Unix() {
+			return nil, false, nil
+		}
+		body := fmt.Sprintf("截止时间：%s", todo.DueDate.In(loc).Format("2006-01-02 15:04"))
+		if todo.Description != "" {
+			body = todo.Description + "\n\n" + body
+		}
+		return &notify.Message{Kind: job.Kind, Title: "待办到期：" + todo.Title, Body: body}, true, nil
+
+	case domain.ReminderKindDailyPrompt:
+		if !setting.DailyPrompt {
+			return nil, false, nil
+		}
+		day := job.FireAt.In(loc)
+		start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc)
+		diaries, err := s.diaryRepo.GetByDateRange(ctx, job.UserID, start, start.AddDate(0, 0, 1).Add(-time.Second))
+		if err != nil {
+			return nil, false, err
+		}
+		if len(diaries) > 0 {
+			return nil, false, nil
+		}
+		return &notify.Message{Kind: job.Kind, Title: "今天还没有写日记", Body: "花几分钟记录一下今天发生的事吧。"}, true, nil
+	}
+	return nil, false, nil
+}
+
+// truncate 截断到 n 字节以内，不截断多字节字符
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
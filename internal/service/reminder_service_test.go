@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"diary/config"
+	"diary/internal/domain"
+	"diary/pkg/notify"
+)
+
+// memSettingRepo 没有保存过设置的用户使用默认设置，prompts 为开启了每日提醒的设置
+type memSettingRepo struct {
+	domain.NotificationSettingRepository
+	prompts []domain.NotificationSetting
+}
+
+func (r *memSettingRepo) ListDailyPrompt(ctx context.Context, afterUserID uint, limit int) ([]domain.NotificationSetting, error) {
+	return r.prompts, nil
+}
+
+// memReminderJobRepo 只记录规划结果，不返回到期任务
+type memReminderJobRepo struct {
+	domain.ReminderJobRepository
+	jobs      []domain.ReminderJob
+	watermark time.Time
+}
+
+func (r *memReminderJobRepo) Create(ctx context.Context, job *domain.ReminderJob) error {
+	r.jobs = append(r.jobs, *job)
+	return nil
+}
+
+func (r *memReminderJobRepo) ListDue(ctx context.Context, now time.Time, limit int) ([]domain.ReminderJob, error) {
+	return nil, nil
+}
+
+func (r *memReminderJobRepo) Watermark(ctx context.Context) (time.Time, error) {
+	return r.watermark, nil
+}
+
+func (r *memReminderJobRepo) SaveWatermark(ctx context.Context, at time.Time) error {
+	r.watermark = at
+	return nil
+}
+
+func (r *memSettingRepo) Get(ctx context.Context, userID uint) (*domain.NotificationSetting, error) {
+	return nil, errFakeNotFound
+}
+
+// fakeNotifier 前 fail 次发送失败
+type fakeNotifier struct {
+	channel string
+	fail    int
+	calls   int
+}
+
+func (n *fakeNotifier) Channel() string { return n.channel }
+
+func (n *fakeNotifier) Enabled(setting *domain.NotificationSetting) bool { return true }
+
+func (n *fakeNotifier) Notify(ctx context.Context, setting *domain.NotificationSetting, msg notify.Message) error {
+	n.calls++
+	if n.calls <= n.fail {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func TestReminderRetriesOnlyFailedChannels(t *testing.T) {
+	ctx := context.Background()
+	inApp := &fakeNotifier{channel: "in_app"}
+	webhook := &fakeNotifier{channel: "webhook", fail: 1}
+	notifications := NewNotificationService(nil, &memSettingRepo{}, []Notifier{inApp, webhook})
+
+	todos := newMemTodoRepo()
+	due := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	todo := &domain.Todo{UserID: 1, Title: "交房租", DueDate: &due}
+	if err := todos.Create(ctx, todo); err != nil {
+		t.Fatal(err)
+	}
+	s := NewReminderService(nil, todos, nil, nil, notifications, &config.Config{}).(*reminderService)
+	job := &domain.ReminderJob{UserID: 1, Kind: domain.ReminderKindTodoDue, RefID: todo.ID, FireAt: due, Status: domain.ReminderPending}
+
+	s.deliver(ctx, job, due)
+	if job.Status != domain.ReminderPending || job.Delivered != "in_app" {
+		t.Fatalf("webhook 失败后 status = %s, delivered = %q, want pending, in_app", job.Status, job.Delivered)
+	}
+
+	s.deliver(ctx, job, job.NextTryAt)
+	if job.Status != domain.ReminderSent {
+		t.Fatalf("重试后 status = %s, want sent", job.Status)
+	}
+	if inApp.calls != 1 || webhook.calls != 2 {
+		t.Fatalf("站内通知发送 %d 次、webhook %d 次，want 1、2", inApp.calls, webhook.calls)
+	}
+	if job.Delivered != "in_app,webhook" {
+		t.Fatalf("delivered = %q", job.Delivered)
+	}
+}
+
+func TestReminderTickResumesFromWatermark(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	due := time.Date(2026, 3, 8, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		watermark time.Time
+		want      []string
+	}{
+		// 停机两天多，待办提醒从水位继续规划，写作提醒只补最近 24 小时的
+		{name: "从保存的水位继续", watermark: now.AddDate(0, 0, -3), want: []string{"todo_due 2026-03-08 09:00", "daily_prompt 2026-03-09 21:00"}},
+		{name: "首次启动只补发最近 24 小时", want: []string{"daily_prompt 2026-03-09 21:00"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			todos := newMemTodoRepo()
+			if err := todos.Create(ctx, &domain.Todo{UserID: 1, Title: "交房租", DueDate: &due}); err != nil {
+				t.Fatal(err)
+			}
+			jobs := &memReminderJobRepo{watermark: tt.watermark}
+			settings := &memSettingRepo{prompts: []domain.NotificationSetting{{UserID: 1, DailyPrompt: true, PromptTime: "21:00", Timezone: "UTC"}}}
+			s := NewReminderService(jobs, todos, nil, settings, nil, &config.Config{}).(*reminderService)
+
+			if err := s.tick(ctx, now); err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, job := range jobs.jobs {
+				got = append(got, job.Kind+" "+job.FireAt.UTC().Format("2006-01-02 15:04"))
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("规划了 %v, want %v", got, tt.want)
+			}
+			if !jobs.watermark.Equal(now) {
+				t.Fatalf("watermark = %v, want %v", jobs.watermark, now)
+			}
+		})
+	}
+}
+
+func TestPromptTimesAcrossDST(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("缺少时区数据")
+	}
+	utc := func(s string) time.Time {
+		v, _ := time.Parse("2006-01-02 15:04", s)
+		return v
+	}
+
+	tests := []struct {
+		name       string
+		promptTime string
+		after, now time.Time
+		want       []string // 当地的日期和时间
+	}{
+		{
+			name: "夏令时开始前后都是当地 21:00", promptTime: "21:00",
+			after: utc("2026-03-28 12:00"), now: utc("2026-03-30 12:00"),
+			want: []string{"2026-03-28 21:00", "2026-03-29 21:00"},
+		},
+		{
+			name: "夏令时结束前后都是当地 21:00", promptTime: "21:00",
+			after: utc("2026-10-24 12:00"), now: utc("2026-10-26 12:00"),
+			want: []string{"2026-10-24 21:00", "2026-10-25 21:00"},
+		},
+		{
+			// 02:30 在拨快的那一小时里不存在，顺延到 03:30，当天仍只提醒一次
+			name: "不存在的当地时间", promptTime: "02:30",
+			after: utc("2026-03-28 12:00"), now: utc("2026-03-29 12:00"),
+			want: []string{"2026-03-29 03:30"},
+		},
+		{
+			name: "重复的当地时间只提醒一次", promptTime: "02:30",
+			after: utc("2026-10-24 12:00"), now: utc("2026-10-25 12:00"),
+			want: []string{"2026-10-25 02:30"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setting := &domain.NotificationSetting{PromptTime: tt.promptTime, Timezone: "Europe/Berlin"}
+			var got []string
+			for _, at := range promptTimes(setting, tt.after, tt.now) {
+				got = append(got, at.In(berlin).Format("2006-01-02 15:04"))
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("promptTimes = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
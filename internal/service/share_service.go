@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"diary/internal/domain"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrShareNotFound         = errors.New("分享链接不存在")
+	ErrShareExpired          = errors.New("分享链接已过期")
+	ErrShareRevoked          = errors.New("分享链接已失效")
+	ErrSharePasswordRequired = errors.New("需要访问密码")
+	ErrSharePasswordInvalid  = errors.New("访问密码错误")
+	ErrInvalidShareConf      = errors.New("无效的分享设置")
+)
+
+type ShareService interface {
+	// Create 为自己的日记创建分享链接，expiresAt 为 nil 时长期有效，password 为空时不需要密码；
+	// 令牌明文只在这里返回
+	Create(ctx context.Context, userID, diaryID uint, expiresAt *time.Time, password string) (string, *domain.ShareLink, error)
+	// List 获取日记的全部分享链接，包括已过期和已撤销的
+	List(ctx context.Context, userID, diaryID uint) ([]domain.ShareLink, error)
+	// Revoke 撤销分享链接，链接立即失效
+	Revoke(ctx context.Context, userID, id uint) error
+	// View 通过令牌读取日记，正文已解密并渲染为 HTML，成功后记录一次访问
+	View(ctx context.Context, token, password string) (*domain.Diary, error)
+	// Authorize 校验令牌是否可以访问指定日记，不记录访问
+	Authorize(ctx context.Context, token, password string, diaryID uint) error
+}
+
+type shareService struct {
+	shareRepo    domain.ShareLinkRepository
+	diaryRepo    domain.DiaryRepository
+	diaryService DiaryService
+}
+
+func NewShareService(shareRepo domain.ShareLinkRepository, diaryRepo domain.DiaryRepository, diaryService DiaryService) ShareService {
+	return &shareService{
+		shareRepo:    shareRepo,
+		diaryRepo:    diaryRepo,
+		diaryService: diaryService,
+	}
+}
+
+func (s *shareService) Create(ctx context.Context, userID, diaryID uint, expiresAt *time.Time, password string) (string, *domain.ShareLink, error) {
+	if err := s.checkOwner(ctx, userID, diaryID); err != nil {
+		return "", nil, err
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return "", nil, fmt.Errorf("%w: 过期时间必须晚于当前时间", ErrInvalidShareConf)
+	}
+
+	link := &domain.ShareLink{UserID: userID, DiaryID: diaryID, ExpiresAt: expiresAt}
+	if password != "" {
+		hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			return "", nil, fmt.Errorf("%w: 访问密码不能超过 72 字节", ErrInvalidShareConf)
+		}
+		link.PasswordHash = string(hashed)
+	}
+
+	token, err := newAccessToken()
+	if err != nil {
+		return "", nil, err
+	}
+	link.TokenHash = hashAccessToken(token)
+	if err := s.shareRepo.Create(ctx, link); err != nil {
+		return "", nil, err
+	}
+	return token, link, nil
+}
+
+func (s *shareService) List(ctx context.Context, userID, diaryID uint) ([]domain.ShareLink, error) {
+	if err := s.checkOwner(ctx, userID, diaryID); err != nil {
+		return nil, err
+	}
+	return s.shareRepo.ListByDiaryID(ctx, diaryID)
+}
+
+func (s *shareService) Revoke(ctx context.Context, userID, id uint) error {
+	link, err := s.shareRepo.GetByID(ctx, id)
+	if err != nil || link.UserID != userID {
+		return ErrShareNotFound
+	}
+	return s.shareRepo.Revoke(ctx, id, time.Now())
+}
+
+func (s *shareService) View(ctx context.Context, token, password string) (*domain.Diary, error) {
+	link, err := s.resolve(ctx, token, password)
+	if err != nil {
+		return nil, err
+	}
+
+	// 日记删除后链接随之失效
+	diary, err := s.diaryService.GetByID(ctx, link.DiaryID)
+	if err != nil || diary.UserID != link.UserID {
+		return nil, ErrShareNotFound
+	}
+	if err := s.diaryService.RenderHTML(ctx, diary); err != nil {
+		return nil, err
+	}
+
+	s.shareRepo.RecordView(ctx, link.ID, time.Now())
+	return diary, nil
+}
+
+func (s *shareService) Authorize(ctx context.Context, token, password string, diaryID uint) error {
+	link, err := s.resolve(ctx, token, password)
+	if err != nil {
+		return err
+	}
+	if link.DiaryID != diaryID {
+		return ErrShareNotFound
+	}
+	return nil
+}
+
+// resolve 按令牌查找仍然有效的链接并校验密码
+func (s *shareService) resolve(ctx context.Context, token, password string) (*domain.ShareLink, error) {
+	if token == "" {
+		return nil, ErrShareNotFound
+	}
+	link, err := s.shareRepo.GetByTokenHash(ctx, hashAccessToken(token))
+	if err != nil {
+		return nil, ErrShareNotFound
+	}
+
+	if link.RevokedAt != nil {
+		return nil, ErrShareRevoked
+	}
+	if link.Expired(time.Now()) {
+		return nil, ErrShareExpired
+	}
+	if link.HasPassword() {
+		if password == "" {
+			return nil, ErrSharePasswordRequired
+		}
+		if bcrypt.CompareHashAndPassword([]byte(link.PasswordHash), []byte(password)) != nil {
+			return nil, ErrSharePasswordInvalid
+		}
+	}
+	return link, nil
+}
+
+// checkOwner 日记存在且属于该用户
+func (s *shareService) checkOwner(ctx context.Context, userID, diaryID uint) error {
+	diary, err := s.diaryRepo.GetByID(ctx, diaryID)
+	if err != nil {
+		return ErrDiaryNotFound
+	}
+	if diary.UserID != userID {
+		return ErrDiaryForbidden
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"diary/internal/domain"
+)
+
+type memShareRepo struct {
+	domain.ShareLinkRepository
+	links  map[uint]*domain.ShareLink
+	nextID uint
+}
+
+func (r *memShareRepo) Create(ctx context.Context, link *domain.ShareLink) error {
+	r.nextID++
+	link.ID = r.nextID
+	copied := *link
+	r.links[link.ID] = &copied
+	return nil
+}
+
+func (r *memShareRepo) GetByID(ctx context.Context, id uint) (*domain.ShareLink, error) {
+	l, ok := r.links[id]
+	if !ok {
+		return nil, errFakeNotFound
+	}
+	copied := *l
+	return &copied, nil
+}
+
+func (r *memShareRepo) GetByTokenHash(ctx context.Context, tokenHash string) (*domain.ShareLink, error) {
+	for _, l := range r.links {
+		if l.TokenHash == tokenHash {
+			copied := *l
+			return &copied, nil
+		}
+	}
+	return nil, errFakeNotFound
+}
+
+func (r *memShareRepo) ListByDiaryID(ctx context.Context, diaryID uint) ([]domain.ShareLink, error) {
+	var out []domain.ShareLink
+	for _, l := range r.links {
+		if l.DiaryID == diaryID {
+			out = append(out, *l)
+		}
+	}
+	return out, nil
+}
+
+func (r *memShareRepo) Revoke(ctx context.Context, id uint, at time.Time) error {
+	if l := r.links[id]; l.RevokedAt == nil {
+		l.RevokedAt = &at
+	}
+	return nil
+}
+
+func newTestShareService() (ShareService, *memShareRepo) {
+	repo := &memShareRepo{links: map[uint]*domain.ShareLink{}}
+	diaries := newMemDiaryRepo(
+		domain.Diary{ID: 1, UserID: 1},
+		domain.Diary{ID: 2, UserID: 1},
+		domain.Diary{ID: 3, UserID: 2},
+	)
+	return NewShareService(repo, diaries, nil), repo
+}
+
+func TestShareOwnerOnly(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestShareService()
+
+	tests := []struct {
+		name    string
+		userID  uint
+		diaryID uint
+		wantErr error
+	}{
+		{"作者", 1, 1, nil},
+		{"其他用户的日记", 1, 3, ErrDiaryForbidden},
+		{"日记不存在", 1, 99, ErrDiaryNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := s.Create(ctx, tt.userID, tt.diaryID, nil, ""); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Create err = %v, want %v", err, tt.wantErr)
+			}
+			if _, err := s.List(ctx, tt.userID, tt.diaryID); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("List err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	_, link, err := s.Create(ctx, 1, 1, nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Revoke(ctx, 2, link.ID); !errors.Is(err, ErrShareNotFound) {
+		t.Fatalf("其他用户撤销 err = %v, want %v", err, ErrShareNotFound)
+	}
+	if err := s.Revoke(ctx, 1, link.ID); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestShareAuthorize(t *testing.T) {
+	ctx := context.Background()
+	past := time.Now().Add(-time.Minute)
+	future := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name     string
+		password string // 创建时的访问密码
+		mutate   func(*domain.ShareLink)
+		token    func(token string) string
+		given    string // 访问时提供的密码
+		diaryID  uint
+		wantErr  error
+	}{
+		{name: "有效链接", diaryID: 1},
+		{name: "没有令牌", token: func(string) string { return "" }, diaryID: 1, wantErr: ErrShareNotFound},
+		{name: "未知令牌", token: func(string) string { return "nope" }, diaryID: 1, wantErr: ErrShareNotFound},
+		{name: "令牌不能访问其他日记", diaryID: 2, wantErr: ErrShareNotFound},
+		{name: "已撤销", mutate: func(l *domain.ShareLink) { l.RevokedAt = &past }, diaryID: 1, wantErr: ErrShareRevoked},
+		{name: "已过期", mutate: func(l *domain.ShareLink) { l.ExpiresAt = &past }, diaryID: 1, wantErr: ErrShareExpired},
+		{name: "未到期", mutate: func(l *domain.ShareLink) { l.ExpiresAt = &future }, diaryID: 1},
+		{name: "需要密码", password: "secret", diaryID: 1, wantErr: ErrSharePasswordRequired},
+		{name: "密码错误", password: "secret", given: "guess", diaryID: 1, wantErr: ErrSharePasswordInvalid},
+		{name: "密码正确", password: "secret", given: "secret", diaryID: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, repo := newTestShareService()
+			token, link, err := s.Create(ctx, 1, 1, nil, tt.password)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.mutate != nil {
+				tt.mutate(repo.links[link.ID])
+			}
+			if tt.token != nil {
+				token = tt.token(token)
+			}
+			if err := s.Authorize(ctx, token, tt.given, tt.diaryID); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestShareCreateRejectsPastExpiry(t *testing.T) {
+	s, _ := newTestShareService()
+	past := time.Now().Add(-time.Second)
+	if _, _, err := s.Create(context.Background(), 1, 1, &past, ""); !errors.Is(err, ErrInvalidShareConf) {
+		t.Fatalf("err = %v, want %v", err, ErrInvalidShareConf)
+	}
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"diary/config"
+	"diary/internal/domain"
+	"diary/pkg/feed"
+)
+
+// syndicationSize 订阅源中最多包含的日记数
+const syndicationSize = 20
+
+// signedImageSrcPattern RenderHTML 生成的图片签名地址，订阅源中换成长有效期的签名
+var signedImageSrcPattern = regexp.MustCompile(`src="[^"]*/media/images/(\d+)\?[^"]*"`)
+
+type SyndicationService interface {
+	// UserFeed 用户最近的公开日记，正文渲染为 HTML；用户不存在或未开启公开主页时返回 ErrProfileNotFound。
+	// baseURL 为服务对外地址，用于生成订阅源中的绝对链接
+	UserFeed(ctx context.Context, username, baseURL string) (*feed.Feed, error)
+}
+
+type syndicationService struct {
+	profileService ProfileService
+	diaryService   DiaryService
+	cfg            *config.Config
+}
+
+func NewSyndicationService(profileService ProfileService, diaryService DiaryService, cfg *config.Config) SyndicationService {
+	return &syndicationService{profileService: profileService, diaryService: diaryService, cfg: cfg}
+}
+
+func (s *syndicationService) UserFeed(ctx context.Context, username, baseURL string) (*feed.Feed, error) {
+	user, diaries, _, err := s.profileService.ListDiaries(ctx, username, domain.PublicDiaryFilter{}, 1, syndicationSize)
+	if err != nil {
+		return nil, err
+	}
+
+	name := user.DisplayName
+	if name == "" {
+		name = user.Username
+	}
+	profileURL := baseURL + "/api/users/" + url.PathEscape(user.Username)
+	f := &feed.Feed{
+		Title:       name,
+		Description: user.Bio,
+		Link:        profileURL,
+		Author:      feed.Author{Name: name, URL: profileURL},
+		Updated:     user.UpdatedAt,
+		Items:       make([]feed.Item, 0, len(diaries)),
+	}
+	if user.AvatarImageID != nil {
+		f.Author.Avatar = s.imageURL(baseURL, *user.AvatarImageID)
+	}
+
+	for i := range diaries {
+		d := &diaries[i]
+		if err := s.diaryService.RenderHTML(ctx, d); err != nil {
+			return nil, err
+		}
+		item := feed.Item{
+			ID:          fmt.Sprintf("%s/api/diaries/%d", baseURL, d.ID),
+			Title:       d.Title,
+			ContentHTML: s.imageRefs(baseURL, d.ContentHTML),
+			Summary:     d.Summary,
+			Published:   d.CreatedAt,
+			Updated:     d.UpdatedAt,
+		}
+		if item.Title == "" {
+			item.Title = d.Date.Format("2006-01-02")
+		}
+		for _, t := range d.Tags {
+			item.Categories = append(item.Categories, t.Name)
+		}
+		for _, img := range d.Images {
+			item.Enclosures = append(item.Enclosures, feed.Enclosure{
+				URL:    s.imageURL(baseURL, img.ID),
+				Type:   img.MimeType,
+				Length: img.Size,
+			})
+		}
+		if d.UpdatedAt.After(f.Updated) {
+			f.Updated = d.UpdatedAt
+		}
+		f.Items = append(f.Items, item)
+	}
+	return f, nil
+}
+
+// imageURL 订阅源中的图片地址。阅读器会长期缓存条目，按 FeedURLExpireHours 签名，
+// 未配置 PublicBaseURL 时签名地址是相对路径，需要补全
+func (s *syndicationService) imageURL(baseURL string, id uint) string {
+	link := signImageURLFor(s.cfg, id, time.Duration(s.cfg.FeedURLExpireHours)*time.Hour)
+	if strings.HasPrefix(link, "/") {
+		return baseURL + link
+	}
+	return link
+}
+
+// imageRefs 把正文中的图片签名地址替换为订阅源使用的地址
+func (s *syndicationService) imageRefs(baseURL, html string) string {
+	return signedImageSrcPattern.ReplaceAllStringFunc(html, func(ref string) string {
+		m := signedImageSrcPattern.FindStringSubmatch(ref)
+		id, err := strconv.ParseUint(m[1], 10, 32)
+		if err != nil {
+			return ref
+		}
+		return fmt.Sprintf(`src="%s"`, template.HTMLEscapeString(s.imageURL(baseURL, uint(id))))
+	})
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"diary/config"
+	"diary/internal/domain"
+)
+
+type feedProfileService struct {
+	ProfileService
+	user    *domain.User
+	diaries []domain.Diary
+}
+
+func (s *feedProfileService) ListDiaries(ctx context.Context, username string, filter domain.PublicDiaryFilter, page, pageSize int) (*domain.User, []domain.Diary, int64, error) {
+	return s.user, s.diaries, int64(len(s.diaries)), nil
+}
+
+// stubRenderer 模拟 RenderHTML 按普通有效期签名正文中的图片
+type stubRenderer struct {
+	DiaryService
+	cfg *config.Config
+}
+
+func (s *stubRenderer) RenderHTML(ctx context.Context, d *domain.Diary) error {
+	d.ContentHTML = `<p><img src="` + strings.ReplaceAll(signImageURL(s.cfg, 7), "&", "&amp;") + `" alt=""></p>`
+	return nil
+}
+
+func TestUserFeedImageURLsUseFeedTTL(t *testing.T) {
+	cfg := &config.Config{JWTSecret: "secret", SignedURLExpireMinutes: 60, FeedURLExpireHours: 24 * 365}
+	avatar := uint(3)
+	profiles := &feedProfileService{
+		user:    &domain.User{Username: "alice", AvatarImageID: &avatar},
+		diaries: []domain.Diary{{ID: 1, Images: []domain.Image{{ID: 7, MimeType: "image/png"}}}},
+	}
+	s := NewSyndicationService(profiles, &stubRenderer{cfg: cfg}, cfg)
+
+	f, err := s.UserFeed(context.Background(), "alice", "https://diary.example")
+	if err != nil {
+		t.Fatal(err)
+	}
+	item := f.Items[0]
+	links := map[string]string{
+		"头像": f.Author.Avatar,
+		"附件": item.Enclosures[0].URL,
+		"正文": strings.ReplaceAll(strings.Split(item.ContentHTML, `"`)[1], "&amp;", "&"),
+	}
+	minExpires := time.Now().Add(time.Duration(cfg.FeedURLExpireHours)*time.Hour - time.Minute).Unix()
+	for name, link := range links {
+		u, err := url.Parse(link)
+		if err != nil || u.Host != "diary.example" {
+			t.Fatalf("%s地址 %q 应为绝对地址", name, link)
+		}
+		expires, _ := strconv.ParseInt(u.Query().Get("expires"), 10, 64)
+		if expires < minExpires {
+			t.Errorf("%s地址 %q 应按订阅源有效期签名", name, link)
+		}
+	}
+}
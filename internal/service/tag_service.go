@@ -19,6 +19,8 @@ type TagService interface {
 	Update(ctx context.Context, id uint, name string) error
 	Delete(ctx context.Context, id uint) error
 	List(ctx context.Context, page, pageSize int) ([]domain.Tag, int64, error)
+	// ListAfter 按游标获取标签，返回下一页的游标，为空表示没有更多
+	ListAfter(ctx context.Context, cursor string, limit int) ([]domain.Tag, string, error)
 	GetPopularTags(ctx context.Context, limit int) ([]domain.Tag, error)
 }
 
@@ -99,6 +101,21 @@ func (s *tagService) List(ctx context.Context, page, pageSize int) ([]domain.Tag
 	return s.tagRepo.List(ctx, offset, pageSize)
 }
 
+func (s *tagService) ListAfter(ctx context.Context, cursor string, limit int) ([]domain.Tag, string, error) {
+	after, err := decodeTimeCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	limit = cursorLimit(limit)
+	tags, err := s.tagRepo.ListAfter(ctx, after, limit+1)
+	if err != nil || len(tags) <= limit {
+		return tags, "", err
+	}
+	tags = tags[:limit]
+	last := tags[limit-1]
+	return tags, encodeCursor(domain.TimeCursor{Time: last.CreatedAt, ID: last.ID}), nil
+}
+
 func (s *tagService) GetPopularTags(ctx context.Context, limit int) ([]domain.Tag, error) {
 	if limit < 1 {
 		limit = 10
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"diary/internal/domain"
+
+	"github.com/teambition/rrule-go"
+)
+
+var (
+	ErrInvalidRRule           = errors.New("无效的重复规则")
+	ErrInvalidTimezone        = errors.New("无效的时区")
+	ErrRecurrenceNeedsDueDate = errors.New("重复待办需要设置截止时间")
+	ErrRecurringSubtask       = errors.New("子任务不能设置重复")
+	ErrNotRecurring           = errors.New("该待办不是重复待办")
+)
+
+// maxOccurrencePreview 预览时最多返回的次数
+const maxOccurrencePreview = 50
+
+// parseRecurrence 解析 RRULE，在 tz 时区中从 start 开始展开，夏令时切换前后本地时间保持不变
+func parseRecurrence(rule, tz string, start time.Time) (*rrule.RRule, error) {
+	loc, err := loadTimezone(tz)
+	if err != nil {
+		return nil, err
+	}
+
+	rule = strings.TrimPrefix(strings.TrimSpace(rule), "RRULE:")
+	if rule == "" || strings.Contains(rule, "\n") {
+		return nil, ErrInvalidRRule
+	}
+	opt, err := rrule.StrToROptionInLocation(rule, loc)
+	if err != nil {
+		return nil, ErrInvalidRRule
+	}
+	switch opt.Freq {
+	case rrule.DAILY, rrule.WEEKLY, rrule.MONTHLY, rrule.YEARLY:
+	default:
+		return nil, ErrInvalidRRule
+	}
+
+	opt.Dtstart = start.In(loc)
+	r, err := rrule.NewRRule(*opt)
+	if err != nil {
+		return nil, ErrInvalidRRule
+	}
+	return r, nil
+}
+
+// loadTimezone 空时区使用服务器本地时区
+func loadTimezone(tz string) (*time.Location, error) {
+	if tz == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, ErrInvalidTimezone
+	}
+	return loc, nil
+}
+
+// applyRecurrence 设置或清除待办的重复规则；规则不变时保留原有排期，只修改本次的截止时间不会影响后续
+func applyRecurrence(todo *domain.Todo, rule, tz string) error {
+	if rule == "" {
+		todo.RRule, todo.Timezone = "", ""
+		todo.RecurStart, todo.OccurrenceAt = nil, nil
+		return nil
+	}
+	if todo.ParentID != nil {
+		return ErrRecurringSubtask
+	}
+	if todo.DueDate == nil {
+		return ErrRecurrenceNeedsDueDate
+	}
+	if rule == todo.RRule && tz == todo.Timezone && todo.RecurStart != nil {
+		return nil
+	}
+	if _, err := parseRecurrence(rule, tz, *todo.DueDate); err != nil {
+		return err
+	}
+
+	// 新规则从当前截止时间重新开始排期
+	start := *todo.DueDate
+	todo.RRule, todo.Timezone = rule, tz
+	todo.RecurStart, todo.OccurrenceAt = &start, &start
+	return nil
+}
+
+// nextOccurrence 本次之后的下一次计划时间，系列已结束时返回 nil
+func nextOccurrence(todo *domain.Todo) (*time.Time, error) {
+	if todo.RRule == "" || todo.RecurStart == nil || todo.OccurrenceAt == nil {
+		return nil, nil
+	}
+	r, err := parseRecurrence(todo.RRule, todo.Timezone, *todo.RecurStart)
+	if err != nil {
+		return nil, err
+	}
+	next := r.After(*todo.OccurrenceAt, false)
+	if next.IsZero() {
+		return nil, nil
+	}
+	return &next, nil
+}
+
+// spawnNext 完成或跳过重复待办后创建下一次，已存在（包括被跳过的）则不再创建
+func (s *todoService) spawnNext(ctx context.Context, todo *domain.Todo) error {
+	next, err := nextOccurrence(todo)
+	if err != nil || next == nil {
+		return err
+	}
+	seriesID := todo.SeriesKey()
+	if _, err := s.todoRepo.GetByOccurrence(ctx, seriesID, *next); err == nil {
+		return nil
+	}
+
+	due := *next
+	occurrence := *next
+	instance := &domain.Todo{
+		UserID:       todo.UserID,
+		Title:        todo.Title,
+		Description:  todo.Description,
+		DueDate:      &due,
+		Priority:     todo.Priority,
+		ProjectID:    todo.ProjectID,
+		SortOrder:    todo.SortOrder,
+		RRule:        todo.RRule,
+		Timezone:     todo.Timezone,
+		RecurStart:   todo.RecurStart,
+		SeriesID:     &seriesID,
+		OccurrenceAt: &occurrence,
+	}
+	if err := s.todoRepo.Create(ctx, instance); err != nil {
+		return err
+	}
+
+	// 子任务作为清单一起复制到下一次，全部重置为未完成
+	subtasks, err := s.todoRepo.ListByParentIDs(ctx, []uint{todo.ID})
+	if err != nil {
+		return err
+	}
+	for _, sub := range subtasks {
+		copied := &domain.Todo{
+			UserID:      sub.UserID,
+			Title:       sub.Title,
+			Description: sub.Description,
+			Priority:    sub.Priority,
+			ProjectID:   instance.ProjectID,
+			ParentID:    &instance.ID,
+			SortOrder:   sub.SortOrder,
+		}
+		if err := s.todoRepo.Create(ctx, copied); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *todoService) Skip(ctx context.Context, id uint) error {
+	todo, err := s.todoRepo.GetByID(ctx, id)
+	if err != nil {
+		return ErrTodoNotFound
+	}
+	if todo.RRule == "" {
+		return ErrNotRecurring
+	}
+	if err := s.spawnNext(ctx, todo); err != nil {
+		return err
+	}
+	// 跳过的这次保留为已删除记录，避免之后被重新生成
+	return s.Delete(ctx, id)
+}
+
+func (s *todoService) Occurrences(ctx context.Context, id uint, count int) ([]time.Time, error) {
+	todo, err := s.todoRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, ErrTodoNotFound
+	}
+	if todo.RRule == "" || todo.RecurStart == nil || todo.OccurrenceAt == nil {
+		return nil, ErrNotRecurring
+	}
+	r, err := parseRecurrence(todo.RRule, todo.Timezone, *todo.RecurStart)
+	if err != nil {
+		return nil, err
+	}
+	return takeOccurrences(r, *todo.OccurrenceAt, count), nil
+}
+
+func (s *todoService) PreviewRecurrence(rule, tz string, start time.Time, count int) ([]time.Time, error) {
+	r, err := parseRecurrence(rule, tz, start)
+	if err != nil {
+		return nil, err
+	}
+	return takeOccurrences(r, start, count), nil
+}
+
+// takeOccurrences 从 from（包含）开始取最多 count 次
+func takeOccurrences(r *rrule.RRule, from time.Time, count int) []time.Time {
+	if count < 1 || count > maxOccurrencePreview {
+		count = 10
+	}
+	times := []time.Time{}
+	next := r.Iterator()
+	for len(times) < count {
+		t, ok := next()
+		if !ok {
+			break
+		}
+		if !t.Before(from) {
+			times = append(times, t)
+		}
+	}
+	return times
+}
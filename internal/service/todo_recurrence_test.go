@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"diary/internal/domain"
+)
+
+func mustLoad(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("时区数据不可用：%v", err)
+	}
+	return loc
+}
+
+func TestNextOccurrence(t *testing.T) {
+	ny := mustLoad(t, "America/New_York")
+	at := func(loc *time.Location, y int, m time.Month, d, h int) time.Time {
+		return time.Date(y, m, d, h, 0, 0, 0, loc)
+	}
+
+	tests := []struct {
+		name       string
+		rule, tz   string
+		start, cur time.Time
+		want       *time.Time
+	}{
+		{"每天", "FREQ=DAILY", "UTC", at(time.UTC, 2026, 1, 1, 9), at(time.UTC, 2026, 1, 3, 9), ptrTime(at(time.UTC, 2026, 1, 4, 9))},
+		{"每周一三", "FREQ=WEEKLY;BYDAY=MO,WE", "UTC", at(time.UTC, 2026, 1, 5, 9), at(time.UTC, 2026, 1, 5, 9), ptrTime(at(time.UTC, 2026, 1, 7, 9))},
+		{"每月31日跳过小月", "FREQ=MONTHLY;BYMONTHDAY=31", "UTC", at(time.UTC, 2026, 1, 31, 9), at(time.UTC, 2026, 1, 31, 9), ptrTime(at(time.UTC, 2026, 3, 31, 9))},
+		{"夏令时切换后本地时间不变", "FREQ=DAILY", "America/New_York", at(ny, 2026, 3, 7, 9), at(ny, 2026, 3, 7, 9), ptrTime(at(ny, 2026, 3, 8, 9))},
+		{"COUNT 用完后结束", "FREQ=DAILY;COUNT=2", "UTC", at(time.UTC, 2026, 1, 1, 9), at(time.UTC, 2026, 1, 2, 9), nil},
+		{"UNTIL 之后结束", "FREQ=DAILY;UNTIL=20260102T090000Z", "UTC", at(time.UTC, 2026, 1, 1, 9), at(time.UTC, 2026, 1, 2, 9), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, cur := tt.start, tt.cur
+			todo := &domain.Todo{RRule: tt.rule, Timezone: tt.tz, RecurStart: &start, OccurrenceAt: &cur}
+			got, err := nextOccurrence(todo)
+			if err != nil {
+				t.Fatal(err)
+			}
+			switch {
+			case tt.want == nil && got != nil:
+				t.Fatalf("got %v, want 系列结束", got)
+			case tt.want != nil && (got == nil || !got.Equal(*tt.want)):
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyRecurrence(t *testing.T) {
+	due := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	parent := uint(1)
+	tests := []struct {
+		name    string
+		todo    domain.Todo
+		rule    string
+		tz      string
+		wantErr error
+	}{
+		{"正常", domain.Todo{DueDate: &due}, "FREQ=WEEKLY", "", nil},
+		{"带 RRULE 前缀", domain.Todo{DueDate: &due}, "RRULE:FREQ=DAILY", "UTC", nil},
+		{"子任务", domain.Todo{DueDate: &due, ParentID: &parent}, "FREQ=DAILY", "", ErrRecurringSubtask},
+		{"没有截止时间", domain.Todo{}, "FREQ=DAILY", "", ErrRecurrenceNeedsDueDate},
+		{"不支持按小时", domain.Todo{DueDate: &due}, "FREQ=HOURLY", "", ErrInvalidRRule},
+		{"多行规则", domain.Todo{DueDate: &due}, "FREQ=DAILY\nEXDATE:20260102", "", ErrInvalidRRule},
+		{"无效时区", domain.Todo{DueDate: &due}, "FREQ=DAILY", "Mars/Base", ErrInvalidTimezone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			todo := tt.todo
+			err := applyRecurrence(&todo, tt.rule, tt.tz)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (todo.RecurStart == nil || !todo.RecurStart.Equal(due)) {
+				t.Fatalf("RecurStart = %v, want %v", todo.RecurStart, due)
+			}
+		})
+	}
+}
+
+func TestRecurringTodoSpawn(t *testing.T) {
+	ctx := context.Background()
+	due := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	// dueDates 未删除的顶层待办的截止时间
+	dueDates := func(repo *memTodoRepo) []time.Time {
+		var out []time.Time
+		for _, t := range repo.live() {
+			if t.ParentID == nil {
+				out = append(out, *t.DueDate)
+			}
+		}
+		return out
+	}
+
+	tests := []struct {
+		name string
+		rule string
+		run  func(s TodoService, first uint) error
+		want []time.Time
+	}{
+		{
+			name: "完成后生成下一次",
+			rule: "FREQ=DAILY",
+			run:  func(s TodoService, first uint) error { return s.MarkAsDone(ctx, first) },
+			want: []time.Time{due, due.Add(day)},
+		},
+		{
+			name: "跳过本次删除当前并生成下一次",
+			rule: "FREQ=DAILY",
+			run:  func(s TodoService, first uint) error { return s.Skip(ctx, first) },
+			want: []time.Time{due.Add(day)},
+		},
+		{
+			name: "下一次已存在时不重复生成",
+			rule: "FREQ=DAILY",
+			run: func(s TodoService, first uint) error {
+				if err := s.Skip(ctx, first); err != nil {
+					return err
+				}
+				// 被跳过的那次已删除，再次完成它不应再生成第二个 1 月 2 日
+				return s.(*todoService).spawnNext(ctx, mustGet(s, first))
+			},
+			want: []time.Time{due.Add(day)},
+		},
+		{
+			name: "系列结束后不再生成",
+			rule: "FREQ=DAILY;COUNT=1",
+			run:  func(s TodoService, first uint) error { return s.MarkAsDone(ctx, first) },
+			want: []time.Time{due},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMemTodoRepo()
+			s := NewTodoService(repo, newMemProjectRepo())
+			first, err := s.Create(ctx, 1, "喝水", "", &due, 0, nil, nil, tt.rule, "UTC")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := tt.run(s, first.ID); err != nil {
+				t.Fatal(err)
+			}
+			got := dueDates(repo)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if !got[i].Equal(tt.want[i]) {
+					t.Fatalf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestRecurringTodoCopiesSubtasks(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemTodoRepo()
+	s := NewTodoService(repo, newMemProjectRepo())
+	due := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	first, err := s.Create(ctx, 1, "周报", "", &due, 0, nil, nil, "FREQ=WEEKLY", "UTC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, title := range []string{"整理数据", "写总结"} {
+		if _, err := s.Create(ctx, 1, title, "", nil, 0, nil, &first.ID, "", ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.MarkAsDone(ctx, first.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	var next *domain.Todo
+	for _, todo := range repo.live() {
+		if todo.SeriesID != nil && *todo.SeriesID == first.ID {
+			next = &todo
+		}
+	}
+	if next == nil {
+		t.Fatal("没有生成下一次")
+	}
+	subtasks, _ := repo.ListByParentIDs(ctx, []uint{next.ID})
+	if len(subtasks) != 2 {
+		t.Fatalf("复制了 %d 个子任务，want 2", len(subtasks))
+	}
+	for _, sub := range subtasks {
+		if sub.Done {
+			t.Fatalf("子任务 %q 应重置为未完成", sub.Title)
+		}
+	}
+}
+
+func ptrTime(t time.Time) *time.Time { return &t }
+
+func mustGet(s TodoService, id uint) *domain.Todo {
+	todo, ok := s.(*todoService).todoRepo.(*memTodoRepo).todos[id]
+	if !ok {
+		panic("todo not found")
+	}
+	return todo
+}
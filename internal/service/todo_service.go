@@ -9,39 +9,91 @@ import (
 )
 
 var (
-	ErrTodoNotFound = errors.New("待办事项不存在")
+	ErrTodoNotFound      = errors.New("待办事项不存在")
+	ErrInvalidPriority   = errors.New("无效的优先级")
+	ErrInvalidTodoParent = errors.New("父任务不存在或不能再添加子任务")
 )
 
 type TodoService interface {
-	Create(ctx context.Context, userID uint, title, description string, dueDate *time.Time) (*domain.Todo, error)
+	// Create 创建待办，parentID 不为空时作为子任务，子任务沿用父任务的清单
+	// rule 为 iCalendar RRULE，不为空时从 dueDate 开始重复，tz 为空时使用服务器时区
+	Create(ctx context.Context, userID uint, title, description string, dueDate *time.Time, priority int, projectID, parentID *uint, rule, tz string) (*domain.Todo, error)
 	GetByID(ctx context.Context, id uint) (*domain.Todo, error)
-	Update(ctx context.Context, id uint, title, description string, dueDate *time.Time) error
+	// Update 修改重复规则会从新的截止时间重新排期；规则不变时只修改本次
+	Update(ctx context.Context, id uint, title, description string, dueDate *time.Time, priority int, projectID *uint, rule, tz string) error
+	// Delete 删除待办及其子任务
 	Delete(ctx context.Context, id uint) error
+	// MarkAsDone 完成父任务时子任务一并完成；最后一个子任务完成时父任务自动完成
+	// 重复待办完成后按规则生成下一次
 	MarkAsDone(ctx context.Context, id uint) error
+	// MarkAsUndone 重新打开子任务时父任务也变为未完成
 	MarkAsUndone(ctx context.Context, id uint) error
 	ListByUserID(ctx context.Context, userID uint, page, pageSize int) ([]domain.Todo, int64, error)
 	ListByStatus(ctx context.Context, userID uint, done bool, page, pageSize int) ([]domain.Todo, int64, error)
 	ListByDueDate(ctx context.Context, userID uint, startDate, endDate time.Time) ([]domain.Todo, error)
-	GetStats(ctx context.Context, userID uint) (total, pending int64, err error)
+	// List 按条件筛选和排序
+	List(ctx context.Context, userID uint, filter domain.TodoFilter, page, pageSize int) ([]domain.Todo, int64, error)
+	// ListAfter 按游标获取，游标只能用于生成它时的排序方式；返回下一页的游标，为空表示没有更多
+	ListAfter(ctx context.Context, userID uint, filter domain.TodoFilter, cursor string, limit int) ([]domain.Todo, string, error)
+	// ListSubtasks 批量获取子任务，按父任务ID分组
+	ListSubtasks(ctx context.Context, parentIDs []uint) (map[uint][]domain.Todo, error)
+	// Reorder 按 ids 的顺序设置手动排序
+	Reorder(ctx context.Context, userID uint, ids []uint) error
+	GetStats(ctx context.Context, userID uint) (*domain.TodoStats, error)
+	// Skip 跳过重复待办的本次，生成下一次
+	Skip(ctx context.Context, id uint) error
+	// Occurrences 重复待办从本次开始的后续计划时间
+	Occurrences(ctx context.Context, id uint, count int) ([]time.Time, error)
+	// PreviewRecurrence 预览规则从 start 开始的计划时间，不保存
+	PreviewRecurrence(rule, tz string, start time.Time, count int) ([]time.Time, error)
 }
 
 type todoService struct {
-	todoRepo domain.TodoRepository
+	todoRepo    domain.TodoRepository
+	projectRepo domain.TodoProjectRepository
 }
 
-func NewTodoService(todoRepo domain.TodoRepository) TodoService {
+func NewTodoService(todoRepo domain.TodoRepository, projectRepo domain.TodoProjectRepository) TodoService {
 	return &todoService{
-		todoRepo: todoRepo,
+		todoRepo:    todoRepo,
+		projectRepo: projectRepo,
 	}
 }
 
-func (s *todoService) Create(ctx context.Context, userID uint, title, description string, dueDate *time.Time) (*domain.Todo, error) {
+func (s *todoService) Create(ctx context.Context, userID uint, title, description string, dueDate *time.Time, priority int, projectID, parentID *uint, rule, tz string) (*domain.Todo, error) {
+	if err := validatePriority(priority); err != nil {
+		return nil, err
+	}
+
+	if parentID != nil {
+		// 只支持一层子任务
+		parent, err := s.todoRepo.GetByID(ctx, *parentID)
+		if err != nil || parent.UserID != userID || parent.ParentID != nil {
+			return nil, ErrInvalidTodoParent
+		}
+		projectID = parent.ProjectID
+	} else if err := s.checkProject(ctx, userID, projectID); err != nil {
+		return nil, err
+	}
+
+	order, err := s.todoRepo.MaxSortOrder(ctx, userID, parentID)
+	if err != nil {
+		return nil, err
+	}
+
 	todo := &domain.Todo{
 		UserID:      userID,
 		Title:       title,
 		Description: description,
 		Done:        false,
 		DueDate:     dueDate,
+		Priority:    priority,
+		ProjectID:   projectID,
+		ParentID:    parentID,
+		SortOrder:   order + 1,
+	}
+	if err := applyRecurrence(todo, rule, tz); err != nil {
+		return nil, err
 	}
 
 	if err := s.todoRepo.Create(ctx, todo); err != nil {
@@ -59,29 +111,96 @@ func (s *todoService) GetByID(ctx context.Context, id uint) (*domain.Todo, error
 	return todo, nil
 }
 
-func (s *todoService) Update(ctx context.Context, id uint, title, description string, dueDate *time.Time) error {
+func (s *todoService) Update(ctx context.Context, id uint, title, description string, dueDate *time.Time, priority int, projectID *uint, rule, tz string) error {
+	if err := validatePriority(priority); err != nil {
+		return err
+	}
+
 	todo, err := s.todoRepo.GetByID(ctx, id)
 	if err != nil {
 		return ErrTodoNotFound
 	}
 
+	// 子任务始终跟随父任务所在的清单
+	moved := false
+	if todo.ParentID == nil {
+		if err := s.checkProject(ctx, todo.UserID, projectID); err != nil {
+			return err
+		}
+		moved = !sameProject(todo.ProjectID, projectID)
+		todo.ProjectID = projectID
+	}
 	todo.Title = title
 	todo.Description = description
 	todo.DueDate = dueDate
+	todo.Priority = priority
+	if err := applyRecurrence(todo, rule, tz); err != nil {
+		return err
+	}
 
-	return s.todoRepo.Update(ctx, todo)
+	if err := s.todoRepo.Update(ctx, todo); err != nil {
+		return err
+	}
+	if moved {
+		return s.todoRepo.SetProjectByParentID(ctx, todo.ID, projectID)
+	}
+	return nil
+}
+
+func sameProject(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
 }
 
 func (s *todoService) Delete(ctx context.Context, id uint) error {
-	return s.todoRepo.Delete(ctx, id)
+	if err := s.todoRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	return s.todoRepo.DeleteByParentID(ctx, id)
 }
 
 func (s *todoService) MarkAsDone(ctx context.Context, id uint) error {
-	return s.todoRepo.MarkAsDone(ctx, id)
+	todo, err := s.todoRepo.GetByID(ctx, id)
+	if err != nil {
+		return ErrTodoNotFound
+	}
+	if err := s.todoRepo.MarkAsDone(ctx, id); err != nil {
+		return err
+	}
+
+	if todo.ParentID == nil {
+		if err := s.todoRepo.SetDoneByParentID(ctx, id, true); err != nil {
+			return err
+		}
+		return s.spawnNext(ctx, todo)
+	}
+
+	siblings, err := s.todoRepo.ListByParentIDs(ctx, []uint{*todo.ParentID})
+	if err != nil {
+		return err
+	}
+	for _, t := range siblings {
+		if !t.Done && t.ID != id {
+			return nil
+		}
+	}
+	return s.MarkAsDone(ctx, *todo.ParentID)
 }
 
 func (s *todoService) MarkAsUndone(ctx context.Context, id uint) error {
-	return s.todoRepo.MarkAsUndone(ctx, id)
+	todo, err := s.todoRepo.GetByID(ctx, id)
+	if err != nil {
+		return ErrTodoNotFound
+	}
+	if err := s.todoRepo.MarkAsUndone(ctx, id); err != nil {
+		return err
+	}
+	if todo.ParentID != nil {
+		return s.todoRepo.MarkAsUndone(ctx, *todo.ParentID)
+	}
+	return nil
 }
 
 func (s *todoService) ListByUserID(ctx context.Context, userID uint, page, pageSize int) ([]domain.Todo, int64, error) {
@@ -112,14 +231,123 @@ func (s *todoService) ListByDueDate(ctx context.Context, userID uint, startDate,
 	return s.todoRepo.ListByDueDate(ctx, userID, startDate, endDate)
 }
 
-func (s *todoService) GetStats(ctx context.Context, userID uint) (int64, int64, error) {
+func (s *todoService) List(ctx context.Context, userID uint, filter domain.TodoFilter, page, pageSize int) ([]domain.Todo, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	offset := (page - 1) * pageSize
+
+	return s.todoRepo.List(ctx, userID, filter, offset, pageSize)
+}
+
+func (s *todoService) ListAfter(ctx context.Context, userID uint, filter domain.TodoFilter, cursor string, limit int) ([]domain.Todo, string, error) {
+	sort := todoCursorSort(filter.Sort)
+	var after *domain.TodoCursor
+	var c domain.TodoCursor
+	if ok, err := decodeCursor(cursor, &c); err != nil {
+		return nil, "", err
+	} else if ok {
+		if c.Sort != sort || c.Desc != filter.Desc {
+			return nil, "", ErrInvalidCursor
+		}
+		after = &c
+	}
+
+	limit = cursorLimit(limit)
+	todos, err := s.todoRepo.ListAfter(ctx, userID, filter, after, limit+1)
+	if err != nil || len(todos) <= limit {
+		return todos, "", err
+	}
+	todos = todos[:limit]
+	last := todos[limit-1]
+	next := domain.TodoCursor{Sort: sort, Desc: filter.Desc, ID: last.ID}
+	switch sort {
+	case "due_date":
+		next.Time = last.DueDate
+	case "priority":
+		next.Int = last.Priority
+	case "order":
+		next.Int = last.SortOrder
+	case "title":
+		next.Text = last.Title
+	default:
+		next.Time = &last.CreatedAt
+	}
+	return todos, encodeCursor(next), nil
+}
+
+// todoCursorSort 游标中记录的排序字段，未知字段和仓储层一样按创建时间
+func todoCursorSort(sort string) string {
+	switch sort {
+	case "due_date", "priority", "order", "title":
+		return sort
+	}
+	return "created_at"
+}
+
+func (s *todoService) ListSubtasks(ctx context.Context, parentIDs []uint) (map[uint][]domain.Todo, error) {
+	subtasks, err := s.todoRepo.ListByParentIDs(ctx, parentIDs)
+	if err != nil {
+		return nil, err
+	}
+	grouped := make(map[uint][]domain.Todo)
+	for _, t := range subtasks {
+		grouped[*t.ParentID] = append(grouped[*t.ParentID], t)
+	}
+	return grouped, nil
+}
+
+func (s *todoService) Reorder(ctx context.Context, userID uint, ids []uint) error {
+	return s.todoRepo.UpdateSortOrder(ctx, userID, ids)
+}
+
+func (s *todoService) GetStats(ctx context.Context, userID uint) (*domain.TodoStats, error) {
 	total, err := s.todoRepo.CountByUserID(ctx, userID)
 	if err != nil {
-		return 0, 0, err
+		return nil, err
 	}
 	pending, err := s.todoRepo.CountPending(ctx, userID)
 	if err != nil {
-		return 0, 0, err
+		return nil, err
+	}
+	byProject, err := s.todoRepo.CountByProject(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	projects, err := s.projectRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[uint]string)
+	for _, p := range projects {
+		names[p.ID] = p.Name
+	}
+	for i := range byProject {
+		// 已删除清单中的待办已被移出，这里只会剩下未归入清单的一组
+		byProject[i].Name = names[byProject[i].ProjectID]
+	}
+
+	return &domain.TodoStats{Total: total, Pending: pending, Projects: byProject}, nil
+}
+
+func (s *todoService) checkProject(ctx context.Context, userID uint, projectID *uint) error {
+	if projectID == nil {
+		return nil
+	}
+	project, err := s.projectRepo.GetByID(ctx, *projectID)
+	if err != nil || project.UserID != userID {
+		return ErrProjectNotFound
+	}
+	return nil
+}
+
+func validatePriority(priority int) error {
+	if priority < domain.TodoPriorityNone || priority > domain.TodoPriorityHigh {
+		return ErrInvalidPriority
 	}
-	return total, pending, nil
+	return nil
 }
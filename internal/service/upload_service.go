@@ -0,0 +1,360 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"diary/config"
+	"diary/internal/domain"
+	"diary/pkg/media"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUploadNotFound         = errors.New("上传会话不存在")
+	ErrUploadExpired          = errors.New("上传会话已过期")
+	ErrUploadOffsetMismatch   = errors.New("上传偏移量不匹配")
+	ErrUploadChecksumMismatch = errors.New("校验和不匹配")
+	ErrUploadTooLarge         = errors.New("文件过大")
+	ErrUploadCompleted        = errors.New("上传已完成")
+	ErrUnsupportedChecksum    = errors.New("不支持的校验算法")
+)
+
+// ChunkChecksum 分片校验信息，对应 tus 的 Upload-Checksum 头
+type ChunkChecksum struct {
+	Algorithm string // 目前只支持 sha256
+	Sum       []byte
+}
+
+type UploadService interface {
+	// Create 创建上传会话，checksum 为完整文件的 sha256（hex），可为空
+	Create(ctx context.Context, userID uint, filename string, size int64, checksum string) (*domain.Upload, error)
+	// Status 查询上传进度
+	Status(ctx context.Context, userID uint, id string) (*domain.Upload, error)
+	// Append 从 offset 处追加分片，最后一个分片写入后自动转存为图片。
+	// 没有分块校验时，传输中断前已收到的数据会保留，偏移量前移
+	Append(ctx context.Context, userID uint, id string, offset int64, chunk io.Reader, checksum *ChunkChecksum) (*domain.Upload, *domain.Image, error)
+	// Terminate 取消上传并删除临时文件
+	Terminate(ctx context.Context, userID uint, id string) error
+	// CleanupExpired 清理过期的上传会话及其临时文件
+	CleanupExpired(ctx context.Context) (int, error)
+	// Start 在后台定期清理过期上传，ctx 取消时退出
+	Start(ctx context.Context)
+}
+
+type uploadService struct {
+	uploadRepo   domain.UploadRepository
+	imageService ImageService
+	cfg          *config.Config
+
+	// 同一个上传会话的分片需要串行写入
+	locks sync.Map
+}
+
+func NewUploadService(uploadRepo domain.UploadRepository, imageService ImageService, cfg *config.Config) UploadService {
+	if err := os.MkdirAll(cfg.UploadTmpDir, 0755); err != nil {
+		log.Printf("create upload tmp dir failed: %v", err)
+	}
+
+	return &uploadService{
+		uploadRepo:   uploadRepo,
+		imageService: imageService,
+		cfg:          cfg,
+	}
+}
+
+func (s *uploadService) Create(ctx context.Context, userID uint, filename string, size int64, checksum string) (*domain.Upload, error) {
+	if size <= 0 {
+		return nil, ErrImageUpload
+	}
+	if size > s.maxSize() {
+		return nil, ErrUploadTooLarge
+	}
+
+	upload := &domain.Upload{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Filename:  filepath.Base(filename),
+		Size:      size,
+		Checksum:  strings.ToLower(checksum),
+		ExpiresAt: time.Now().Add(time.Duration(s.cfg.UploadExpireHours) * time.Hour),
+	}
+
+	// 预先创建空的临时文件
+	f, err := os.Create(s.tmpPath(upload.ID))
+	if err != nil {
+		return nil, err
+	}
+	f.Close()
+
+	if err := s.uploadRepo.Create(ctx, upload); err != nil {
+		os.Remove(s.tmpPath(upload.ID))
+		return nil, err
+	}
+	return upload, nil
+}
+
+func (s *uploadService) Status(ctx context.Context, userID uint, id string) (*domain.Upload, error) {
+	upload, err := s.uploadRepo.GetByID(ctx, id)
+	if err != nil || upload.UserID != userID {
+		return nil, ErrUploadNotFound
+	}
+	return upload, nil
+}
+
+func (s *uploadService) Append(ctx context.Context, userID uint, id string, offset int64, chunk io.Reader, checksum *ChunkChecksum) (*domain.Upload, *domain.Image, error) {
+	var h hash.Hash
+	if checksum != nil {
+		if checksum.Algorithm != "sha256" {
+			return nil, nil, ErrUnsupportedChecksum
+		}
+		h = sha256.New()
+	}
+
+	if _, err := s.Status(ctx, userID, id); err != nil {
+		return nil, nil, err
+	}
+	mu := s.lock(id)
+	mu.Lock()
+	defer mu.Unlock()
+
+	// 拿到锁之后重新读取，确保偏移量是最新的
+	upload, err := s.Status(ctx, userID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if upload.ImageID != nil {
+		return upload, nil, ErrUploadCompleted
+	}
+	if time.Now().After(upload.ExpiresAt) {
+		return nil, nil, ErrUploadExpired
+	}
+	if offset != upload.Offset {
+		return upload, nil, ErrUploadOffsetMismatch
+	}
+
+	f, err := os.OpenFile(s.tmpPath(id), os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, nil, ErrUploadNotFound
+	}
+	defer f.Close()
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, nil, err
+	}
+
+	// 最多只接收剩余长度，多出的数据视为文件过大
+	remaining := upload.Size - offset
+	reader := io.LimitReader(chunk, remaining+1)
+	var w io.Writer = f
+	if h != nil {
+		w = io.MultiWriter(f, h)
+	}
+	n, err := io.Copy(w, reader)
+	// 客户端断开时 net/http 会取消请求 ctx，已收到的数据仍需记录下来
+	persistCtx := context.WithoutCancel(ctx)
+	switch {
+	case err != nil && h == nil && n > 0 && n <= remaining:
+		// 传输中断（如客户端断开）且没有分块校验时保留已写入的数据，客户端 HEAD 后从新的偏移量续传
+		if ok, uerr := s.uploadRepo.UpdateOffset(persistCtx, id, offset, offset+n); uerr == nil && ok {
+			upload.Offset = offset + n
+			return upload, nil, err
+		}
+	case err == nil && n > remaining:
+		err = ErrUploadTooLarge
+	case err == nil && h != nil && !bytes.Equal(h.Sum(nil), checksum.Sum):
+		err = ErrUploadChecksumMismatch
+	}
+	if err != nil {
+		// 校验失败或无法保留时丢弃本次写入的数据，客户端可以从原偏移量重试
+		f.Truncate(offset)
+		return upload, nil, err
+	}
+
+	newOffset := offset + n
+	ok, err := s.uploadRepo.UpdateOffset(persistCtx, id, offset, newOffset)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !ok {
+		return upload, nil, ErrUploadOffsetMismatch
+	}
+	upload.Offset = newOffset
+
+	// 收到文件头后立即检查类型和按类型的大小上限，不必等整个文件传完才失败
+	if offset < media.HeadSize && (newOffset >= media.HeadSize || newOffset == upload.Size) {
+		if err := s.checkHead(upload); err != nil {
+			if isPermanentUploadError(err) {
+				s.discard(persistCtx, id)
+			}
+			return upload, nil, err
+		}
+	}
+
+	if newOffset < upload.Size {
+		return upload, nil, nil
+	}
+
+	image, err := s.complete(persistCtx, upload)
+	if err != nil {
+		return upload, nil, err
+	}
+	upload.ImageID = &image.ID
+	return upload, image, nil
+}
+
+// complete 校验完整文件并交给 ImageService 保存
+func (s *uploadService) complete(ctx context.Context, upload *domain.Upload) (*domain.Image, error) {
+	path := s.tmpPath(upload.ID)
+
+	if upload.Checksum != "" {
+		sum, err := fileSHA256(path)
+		if err != nil {
+			return nil, err
+		}
+		if sum != upload.Checksum {
+			// 完整文件校验失败时只能重新上传
+			s.discard(ctx, upload.ID)
+			return nil, ErrUploadChecksumMismatch
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	image, err := s.imageService.UploadAttachment(ctx, upload.UserID, f, upload.Filename)
+	f.Close()
+	if err != nil {
+		if isPermanentUploadError(err) {
+			// 文件本身不可接受，重试也不会成功，删除会话让客户端不再卡在 offset == size
+			s.discard(ctx, upload.ID)
+		}
+		return nil, err
+	}
+
+	if err := s.uploadRepo.MarkCompleted(ctx, upload.ID, image.ID); err != nil {
+		return nil, err
+	}
+	os.Remove(path)
+	s.locks.Delete(upload.ID)
+	return image, nil
+}
+
+// checkHead 读取临时文件的文件头交给 ImageService 预检
+func (s *uploadService) checkHead(upload *domain.Upload) error {
+	f, err := os.Open(s.tmpPath(upload.ID))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	head := make([]byte, media.HeadSize)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return err
+	}
+	return s.imageService.CheckAttachment(head[:n], upload.Size)
+}
+
+// isPermanentUploadError 文件类型或大小不被接受，重新上传同一个文件也不会成功
+func isPermanentUploadError(err error) bool {
+	return errors.Is(err, ErrUnsupportedMedia) || errors.Is(err, ErrFileTooLarge)
+}
+
+func (s *uploadService) Terminate(ctx context.Context, userID uint, id string) error {
+	if _, err := s.Status(ctx, userID, id); err != nil {
+		return err
+	}
+	mu := s.lock(id)
+	mu.Lock()
+	defer mu.Unlock()
+	return s.discard(ctx, id)
+}
+
+func (s *uploadService) CleanupExpired(ctx context.Context) (int, error) {
+	count := 0
+	for {
+		uploads, err := s.uploadRepo.ListExpired(ctx, time.Now(), gcBatchSize)
+		if err != nil {
+			return count, err
+		}
+		for _, u := range uploads {
+			// 与 Append 持有同一把锁，避免删除正在写入的文件
+			mu := s.lock(u.ID)
+			mu.Lock()
+			err := s.discard(ctx, u.ID)
+			mu.Unlock()
+			if err != nil {
+				return count, err
+			}
+			count++
+		}
+		if len(uploads) < gcBatchSize {
+			return count, nil
+		}
+	}
+}
+
+func (s *uploadService) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if n, err := s.CleanupExpired(ctx); err != nil {
+					log.Printf("cleanup uploads failed: %v", err)
+				} else if n > 0 {
+					log.Printf("cleanup uploads: removed %d expired uploads", n)
+				}
+			}
+		}
+	}()
+}
+
+func (s *uploadService) discard(ctx context.Context, id string) error {
+	if err := os.Remove(s.tmpPath(id)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	s.locks.Delete(id)
+	return s.uploadRepo.Delete(ctx, id)
+}
+
+func (s *uploadService) lock(id string) *sync.Mutex {
+	mu, _ := s.locks.LoadOrStore(id, &sync.Mutex{})
+	return mu.(*sync.Mutex)
+}
+
+func (s *uploadService) tmpPath(id string) string {
+	return filepath.Join(s.cfg.UploadTmpDir, id)
+}
+
+func (s *uploadService) maxSize() int64 {
+	return int64(s.cfg.ResumableMaxMB) << 20
+}
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,314 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"diary/config"
+	"diary/internal/domain"
+)
+
+type memUploadRepo struct {
+	domain.UploadRepository
+	uploads map[string]*domain.Upload
+}
+
+func (r *memUploadRepo) Create(ctx context.Context, upload *domain.Upload) error {
+	copied := *upload
+	r.uploads[upload.ID] = &copied
+	return nil
+}
+
+func (r *memUploadRepo) GetByID(ctx context.Context, id string) (*domain.Upload, error) {
+	u, ok := r.uploads[id]
+	if !ok {
+		return nil, errFakeNotFound
+	}
+	copied := *u
+	return &copied, nil
+}
+
+func (r *memUploadRepo) UpdateOffset(ctx context.Context, id string, from, to int64) (bool, error) {
+	// 与真实数据库一样，请求 ctx 已取消时写入失败
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	u, ok := r.uploads[id]
+	if !ok || u.Offset != from {
+		return false, nil
+	}
+	u.Offset = to
+	return true, nil
+}
+
+func (r *memUploadRepo) MarkCompleted(ctx context.Context, id string, imageID uint) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.uploads[id].ImageID = &imageID
+	return nil
+}
+
+func (r *memUploadRepo) Delete(ctx context.Context, id string) error {
+	delete(r.uploads, id)
+	return nil
+}
+
+// brokenReader 读出 data 后返回 err，模拟客户端中途断开
+// cancel 不为空时在出错前调用，与 net/http 断开连接时取消请求 ctx 的行为一致
+type brokenReader struct {
+	data   []byte
+	err    error
+	cancel context.CancelFunc
+}
+
+func (b *brokenReader) Read(p []byte) (int, error) {
+	if len(b.data) == 0 {
+		if b.cancel != nil {
+			b.cancel()
+		}
+		return 0, b.err
+	}
+	n := copy(p, b.data)
+	b.data = b.data[n:]
+	return n, nil
+}
+
+func testPNG(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 8, 8))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func sha256Of(b []byte) []byte {
+	sum := sha256.Sum256(b)
+	return sum[:]
+}
+
+func newTestUploadService(t *testing.T) (*uploadService, *memUploadRepo) {
+	t.Helper()
+	dir := t.TempDir()
+	cfg := &config.Config{
+		UploadDir: dir, UploadTmpDir: dir,
+		MaxImageMB: 1, MaxAudioMB: 1, ResumableMaxMB: 1, UploadExpireHours: 1,
+	}
+	repo := &memUploadRepo{uploads: map[string]*domain.Upload{}}
+	s := NewUploadService(repo, NewImageService(newMemImageRepo(), cfg), cfg).(*uploadService)
+	return s, repo
+}
+
+func TestUploadAppend(t *testing.T) {
+	ctx := context.Background()
+	file := testPNG(t)
+	half := int64(len(file) / 2)
+	disconnected := errors.New("connection reset")
+
+	tests := []struct {
+		name       string
+		checksum   string // 整个文件的校验和
+		offset     int64
+		chunk      func() io.Reader
+		chunkSum   *ChunkChecksum
+		wantErr    error
+		wantOffset int64
+		wantImage  bool
+	}{
+		{
+			name:       "一次上传完成",
+			chunk:      func() io.Reader { return bytes.NewReader(file) },
+			wantOffset: int64(len(file)),
+			wantImage:  true,
+		},
+		{
+			name:       "分片校验通过",
+			chunk:      func() io.Reader { return bytes.NewReader(file[:half]) },
+			chunkSum:   &ChunkChecksum{Algorithm: "sha256", Sum: sha256Of(file[:half])},
+			wantOffset: half,
+		},
+		{
+			name:       "分片校验失败时丢弃本次数据",
+			chunk:      func() io.Reader { return bytes.NewReader(file[:half]) },
+			chunkSum:   &ChunkChecksum{Algorithm: "sha256", Sum: sha256Of(file)},
+			wantErr:    ErrUploadChecksumMismatch,
+			wantOffset: 0,
+		},
+		{
+			name:     "不支持的校验算法",
+			chunk:    func() io.Reader { return bytes.NewReader(file) },
+			chunkSum: &ChunkChecksum{Algorithm: "md5"},
+			wantErr:  ErrUnsupportedChecksum,
+		},
+		{
+			name:    "偏移量不匹配",
+			offset:  1,
+			chunk:   func() io.Reader { return bytes.NewReader(file) },
+			wantErr: ErrUploadOffsetMismatch,
+		},
+		{
+			name:       "超过声明的长度",
+			chunk:      func() io.Reader { return io.MultiReader(bytes.NewReader(file), bytes.NewReader([]byte("x"))) },
+			wantErr:    ErrUploadTooLarge,
+			wantOffset: 0,
+		},
+		{
+			name:       "没有分片校验时中断保留已收到的数据",
+			chunk:      func() io.Reader { return &brokenReader{data: file[:half], err: disconnected} },
+			wantErr:    disconnected,
+			wantOffset: half,
+		},
+		{
+			name:       "有分片校验时中断丢弃本次数据",
+			chunk:      func() io.Reader { return &brokenReader{data: file[:half], err: disconnected} },
+			chunkSum:   &ChunkChecksum{Algorithm: "sha256", Sum: sha256Of(file[:half])},
+			wantErr:    disconnected,
+			wantOffset: 0,
+		},
+		{
+			name:     "完整文件校验失败时删除会话",
+			checksum: hex.EncodeToString(sha256Of([]byte("other"))),
+			chunk:    func() io.Reader { return bytes.NewReader(file) },
+			wantErr:  ErrUploadChecksumMismatch,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, repo := newTestUploadService(t)
+			upload, err := s.Create(ctx, 1, "a.png", int64(len(file)), tt.checksum)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, img, err := s.Append(ctx, 1, upload.ID, tt.offset, tt.chunk(), tt.chunkSum)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if (img != nil) != tt.wantImage {
+				t.Fatalf("image = %v, want image %v", img, tt.wantImage)
+			}
+
+			stored, ok := repo.uploads[upload.ID]
+			if errors.Is(tt.wantErr, ErrUploadChecksumMismatch) && tt.checksum != "" {
+				if ok {
+					t.Fatal("完整文件校验失败后会话应被删除")
+				}
+				return
+			}
+			if stored.Offset != tt.wantOffset {
+				t.Fatalf("offset = %d, want %d", stored.Offset, tt.wantOffset)
+			}
+			// 未完成时临时文件的长度与偏移量一致，下次从这里续传
+			if stored.ImageID == nil {
+				info, err := os.Stat(s.tmpPath(upload.ID))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if info.Size() != tt.wantOffset {
+					t.Fatalf("临时文件 %d 字节，want %d", info.Size(), tt.wantOffset)
+				}
+			}
+		})
+	}
+}
+
+func TestUploadResumeAfterDisconnect(t *testing.T) {
+	ctx := context.Background()
+	file := testPNG(t)
+	s, _ := newTestUploadService(t)
+	upload, err := s.Create(ctx, 1, "a.png", int64(len(file)), hex.EncodeToString(sha256Of(file)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cut := int64(len(file) / 3)
+	reqCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	broken := &brokenReader{data: file[:cut], err: io.ErrUnexpectedEOF, cancel: cancel}
+	if _, _, err := s.Append(reqCtx, 1, upload.ID, 0, broken, nil); err == nil {
+		t.Fatal("中断的请求应返回错误")
+	}
+	status, err := s.Status(ctx, 1, upload.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Offset != cut {
+		t.Fatalf("offset = %d, want %d", status.Offset, cut)
+	}
+
+	_, img, err := s.Append(ctx, 1, upload.ID, status.Offset, bytes.NewReader(file[cut:]), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img == nil || img.MimeType != "image/png" {
+		t.Fatalf("image = %+v, want png", img)
+	}
+	if _, ok := s.locks.Load(upload.ID); ok {
+		t.Fatal("上传完成后应释放会话锁")
+	}
+}
+
+func TestUploadPermanentErrorsDiscardSession(t *testing.T) {
+	ctx := context.Background()
+	// fmt 块声明的长度超出文件，文件头能识别为 WAV，完整解析时才失败
+	brokenWAV := append([]byte("RIFF\x00\x00\x00\x00WAVEfmt \xf0\xff\xff\xff"), make([]byte, 44)...)
+	bigPNG := append(testPNG(t), make([]byte, 600)...)
+
+	tests := []struct {
+		name    string
+		size    int64
+		chunk   []byte
+		wantErr error
+	}{
+		{name: "不支持的类型", size: 10, chunk: []byte("0123456789"), wantErr: ErrUnsupportedMedia},
+		{name: "文件头可识别但结构损坏", size: int64(len(brokenWAV)), chunk: brokenWAV, wantErr: ErrUnsupportedMedia},
+		// 收到文件头就能判断超出图片上限，不必等剩余的数据
+		{name: "图片超过按类型的上限", size: 2 << 20, chunk: bigPNG, wantErr: ErrFileTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestUploadService(t)
+			s.cfg.ResumableMaxMB = 4
+			upload, err := s.Create(ctx, 1, "a.bin", tt.size, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, _, err := s.Append(ctx, 1, upload.ID, 0, bytes.NewReader(tt.chunk), nil); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if _, err := s.Status(ctx, 1, upload.ID); !errors.Is(err, ErrUploadNotFound) {
+				t.Fatalf("会话应被删除，Status err = %v", err)
+			}
+			if _, err := os.Stat(s.tmpPath(upload.ID)); !os.IsNotExist(err) {
+				t.Fatalf("临时文件应被删除，stat err = %v", err)
+			}
+		})
+	}
+}
+
+func TestUploadExpired(t *testing.T) {
+	ctx := context.Background()
+	s, repo := newTestUploadService(t)
+	upload, err := s.Create(ctx, 1, "a.png", 10, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo.uploads[upload.ID].ExpiresAt = time.Now().Add(-time.Minute)
+
+	if _, _, err := s.Append(ctx, 1, upload.ID, 0, bytes.NewReader([]byte("0123456789")), nil); !errors.Is(err, ErrUploadExpired) {
+		t.Fatalf("err = %v, want %v", err, ErrUploadExpired)
+	}
+	if _, err := s.Status(ctx, 2, upload.ID); !errors.Is(err, ErrUploadNotFound) {
+		t.Fatalf("其他用户查询 err = %v, want %v", err, ErrUploadNotFound)
+	}
+}
@@ -18,6 +18,7 @@ var (
 	ErrInvalidPassword   = errors.New("密码错误")
 	ErrInvalidUsername   = errors.New("用户名格式不正确")
 	ErrUnableResgister   = errors.New("不允许注册")
+	ErrPasswordTooShort  = errors.New("密码至少需要6位")
 )
 
 type UserService interface {
@@ -63,7 +64,7 @@ func (s *userService) Register(ctx context.Context, username, password string) (
 
 	// 验证密码长度
 	if len(password) < 6 {
-		return nil, errors.New("密码至少需要6位")
+		return nil, ErrPasswordTooShort
 	}
 
 	// 检查用户名是否已存在
@@ -158,7 +159,7 @@ func (s *userService) UpdateUsername(ctx context.Context, id uint, newUsername s
 func (s *userService) UpdatePassword(ctx context.Context, id uint, oldPassword, newPassword string) error {
 	// 验证新密码
 	if len(newPassword) < 6 {
-		return errors.New("密码至少需要6位")
+		return ErrPasswordTooShort
 	}
 
 	// 获取用户并验证旧密码
@@ -2,6 +2,7 @@ package main
 
 
 import (
+	"context"
 	"log"
 	"net/http"
 	"os"
@@ -41,7 +42,8 @@ func main() {
 	}
 
 
-	r := app.SetupRouter(db, cfg)
+	r, start := app.SetupRouter(db, cfg)
+	start(context.Background())
 	addr := ":" + cfg.Port
 	log.Printf("server running at %s", addr)
 	if err := http.ListenAndServe(addr, r); err != nil {
@@ -0,0 +1,268 @@
+// Package client 日记服务的 Go 客户端。
+// 接口方法和类型在 client_gen.go 中由 OpenAPI 文档生成，修改接口后运行 go generate 更新
+package client
+
+//go:generate go run diary/cmd/openapi-gen -client client_gen.go
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Client 访问日记服务，可并发使用
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	token      string
+	language   string
+}
+
+// Option 客户端选项
+type Option func(*Client)
+
+// WithHTTPClient 使用自定义的 http.Client
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.httpClient = hc }
+}
+
+// WithToken 设置登录令牌
+func WithToken(token string) Option {
+	return func(c *Client) { c.token = token }
+}
+
+// WithLanguage 设置 Accept-Language，错误消息按该语言返回
+func WithLanguage(lang string) Option {
+	return func(c *Client) { c.language = lang }
+}
+
+// New 创建客户端，baseURL 例如 http://localhost:8080
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// SetToken 登录后设置令牌，之后的请求都会带上
+func (c *Client) SetToken(token string) {
+	c.token = token
+}
+
+// APIError 服务端返回的错误
+type APIError struct {
+	StatusCode int
+	ErrorResponse
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("%d %s: %s", e.StatusCode, e.ErrorCode, e.Message)
+	if e.ErrorResponse.Error != "" {
+		msg += " (" + e.ErrorResponse.Error + ")"
+	}
+	return msg
+}
+
+// IsErrorCode 判断 err 是否为指定错误码的 APIError
+func IsErrorCode(err error, code string) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode == code
+}
+
+// Int 返回指针，用于可选参数
+func Int(v int64) *int64 { return &v }
+
+// String 返回指针，用于可选参数
+func String(v string) *string { return &v }
+
+// Bool 返回指针，用于可选参数
+func Bool(v bool) *bool { return &v }
+
+// request 一次请求的内容，json、form、body 最多设置一个
+type request struct {
+	method      string
+	path        string
+	query       url.Values
+	header      http.Header
+	json        interface{}
+	form        []formPart
+	body        io.Reader
+	contentType string
+}
+
+// formPart multipart 字段，file 不为空时作为文件上传
+type formPart struct {
+	name     string
+	value    interface{}
+	file     io.Reader
+	fileName string
+}
+
+// do 发送请求并把响应中的 data 解码到 out，返回状态码
+func (c *Client) do(ctx context.Context, req request, out interface{}) (int, error) {
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNoContent || out == nil {
+		return resp.StatusCode, nil
+	}
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return resp.StatusCode, fmt.Errorf("decode response: %w", err)
+	}
+	if len(envelope.Data) > 0 {
+		if err := json.Unmarshal(envelope.Data, out); err != nil {
+			return resp.StatusCode, fmt.Errorf("decode response data: %w", err)
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+// download 发送请求并返回文件内容，调用方负责关闭
+func (c *Client) download(ctx context.Context, req request) (io.ReadCloser, error) {
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// send 发送请求，非 2xx 响应转换为 *APIError
+func (c *Client) send(ctx context.Context, req request) (*http.Response, error) {
+	body, contentType, err := req.encode()
+	if err != nil {
+		return nil, err
+	}
+
+	u := c.baseURL + req.path
+	if len(req.query) > 0 {
+		u += "?" + req.query.Encode()
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range req.header {
+		httpReq.Header[name] = values
+	}
+	if contentType != "" {
+		httpReq.Header.Set("Content-Type", contentType)
+	}
+	if c.token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if c.language != "" {
+		httpReq.Header.Set("Accept-Language", c.language)
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	apiErr := &APIError{StatusCode: resp.StatusCode}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if json.Unmarshal(data, &apiErr.ErrorResponse) != nil || apiErr.ErrorCode == "" {
+		apiErr.Message = strings.TrimSpace(string(data))
+		if apiErr.Message == "" {
+			apiErr.Message = http.StatusText(resp.StatusCode)
+		}
+	}
+	return nil, apiErr
+}
+
+func (r request) encode() (io.Reader, string, error) {
+	switch {
+	case r.json != nil:
+		data, err := json.Marshal(r.json)
+		if err != nil {
+			return nil, "", err
+		}
+		return bytes.NewReader(data), "application/json", nil
+	case r.form != nil:
+		var buf bytes.Buffer
+		mw := multipart.NewWriter(&buf)
+		for _, part := range r.form {
+			if part.file != nil {
+				fw, err := mw.CreateFormFile(part.name, part.fileName)
+				if err != nil {
+					return nil, "", err
+				}
+				if _, err := io.Copy(fw, part.file); err != nil {
+					return nil, "", err
+				}
+				continue
+			}
+			if v, ok := formatParam(part.value); ok {
+				if err := mw.WriteField(part.name, v); err != nil {
+					return nil, "", err
+				}
+			}
+		}
+		if err := mw.Close(); err != nil {
+			return nil, "", err
+		}
+		return &buf, mw.FormDataContentType(), nil
+	}
+	return r.body, r.contentType, nil
+}
+
+// addQuery 添加查询参数，nil 指针跳过
+func addQuery(q url.Values, name string, value interface{}) {
+	if v, ok := formatParam(value); ok {
+		q.Set(name, v)
+	}
+}
+
+// addHeader 添加请求头，nil 指针跳过
+func addHeader(h http.Header, name string, value interface{}) {
+	if v, ok := formatParam(value); ok {
+		h.Set(name, v)
+	}
+}
+
+func formatParam(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case bool:
+		return strconv.FormatBool(v), true
+	case *string:
+		if v != nil {
+			return *v, true
+		}
+	case *int64:
+		if v != nil {
+			return strconv.FormatInt(*v, 10), true
+		}
+	case *bool:
+		if v != nil {
+			return strconv.FormatBool(*v), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,43 @@
+// Package feed 把日记列表写成订阅源，支持 Atom (RFC 4287)、RSS 2.0 和 JSON Feed 1.1。
+// 三种格式共用同一个 Feed 结构，调用方只需要填一次
+package feed
+
+import "time"
+
+// Feed 订阅源，链接都应为绝对地址
+type Feed struct {
+	Title       string
+	Description string
+	Link        string // 对应的网页（主页）地址
+	FeedURL     string // 订阅源自身的地址，不同格式各不相同，由调用方在写出前设置
+	Language    string
+	Author      Author
+	Updated     time.Time
+	Items       []Item
+}
+
+type Author struct {
+	Name   string
+	URL    string
+	Avatar string
+}
+
+// Item 一篇文章，ID 在订阅源内唯一且不随内容变化
+type Item struct {
+	ID          string
+	Title       string
+	Link        string // 为空时不写出
+	ContentHTML string
+	Summary     string
+	Published   time.Time
+	Updated     time.Time
+	Categories  []string
+	Enclosures  []Enclosure
+}
+
+// Enclosure 附件，例如图片、音频
+type Enclosure struct {
+	URL    string
+	Type   string
+	Length int64
+}
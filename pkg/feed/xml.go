@@ -0,0 +1,189 @@
+package feed
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"time"
+)
+
+const atomNS = "http://www.w3.org/2005/Atom"
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"feed"`
+	NS       string      `xml:"xmlns,attr"`
+	Lang     string      `xml:"xml:lang,attr,omitempty"`
+	ID       string      `xml:"id"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Author   atomAuthor  `xml:"author"`
+	Icon     string      `xml:"icon,omitempty"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel    string `xml:"rel,attr,omitempty"`
+	Href   string `xml:"href,attr"`
+	Type   string `xml:"type,attr,omitempty"`
+	Length int64  `xml:"length,attr,omitempty"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+	URI  string `xml:"uri,omitempty"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Links      []atomLink     `xml:"link"`
+	Categories []atomCategory `xml:"category"`
+	Summary    *atomText      `xml:"summary"`
+	Content    atomText       `xml:"content"`
+}
+
+// WriteAtom 写出 Atom 订阅源，Feed.Link 作为订阅源的 id
+func WriteAtom(w io.Writer, f *Feed) error {
+	out := atomFeed{
+		NS:       atomNS,
+		Lang:     f.Language,
+		ID:       f.Link,
+		Title:    f.Title,
+		Subtitle: f.Description,
+		Updated:  f.Updated.UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Rel: "self", Href: f.FeedURL, Type: "application/atom+xml"},
+			{Rel: "alternate", Href: f.Link},
+		},
+		Author: atomAuthor{Name: f.Author.Name, URI: f.Author.URL},
+		Icon:   f.Author.Avatar,
+	}
+	for _, item := range f.Items {
+		e := atomEntry{
+			ID:        item.ID,
+			Title:     item.Title,
+			Published: item.Published.UTC().Format(time.RFC3339),
+			Updated:   item.Updated.UTC().Format(time.RFC3339),
+			Content:   atomText{Type: "html", Body: item.ContentHTML},
+		}
+		if item.Link != "" {
+			e.Links = append(e.Links, atomLink{Rel: "alternate", Href: item.Link})
+		}
+		for _, enc := range item.Enclosures {
+			e.Links = append(e.Links, atomLink{Rel: "enclosure", Href: enc.URL, Type: enc.Type, Length: enc.Length})
+		}
+		for _, c := range item.Categories {
+			e.Categories = append(e.Categories, atomCategory{Term: c})
+		}
+		if item.Summary != "" {
+			e.Summary = &atomText{Type: "text", Body: item.Summary}
+		}
+		out.Entries = append(out.Entries, e)
+	}
+	return writeXML(w, out)
+}
+
+type rssRoot struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Language      string    `xml:"language,omitempty"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Self          rssSelf   `xml:"atom:link"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssSelf struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Length string `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+type rssItem struct {
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link,omitempty"`
+	GUID        rssGUID       `xml:"guid"`
+	PubDate     string        `xml:"pubDate"`
+	Categories  []string      `xml:"category"`
+	Description string        `xml:"description"`
+	Enclosure   *rssEnclosure `xml:"enclosure"`
+}
+
+// WriteRSS 写出 RSS 2.0 订阅源。RSS 每篇只能有一个附件，只写出第一个
+func WriteRSS(w io.Writer, f *Feed) error {
+	out := rssRoot{
+		Version: "2.0",
+		AtomNS:  atomNS,
+		Channel: rssChannel{
+			Title:         f.Title,
+			Link:          f.Link,
+			Description:   f.Description,
+			Language:      f.Language,
+			LastBuildDate: f.Updated.UTC().Format(time.RFC1123Z),
+			Self:          rssSelf{Href: f.FeedURL, Rel: "self", Type: "application/rss+xml"},
+		},
+	}
+	for _, item := range f.Items {
+		it := rssItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			GUID:        rssGUID{IsPermaLink: false, Value: item.ID},
+			PubDate:     item.Published.UTC().Format(time.RFC1123Z),
+			Categories:  item.Categories,
+			Description: item.ContentHTML,
+		}
+		if len(item.Enclosures) > 0 {
+			enc := item.Enclosures[0]
+			it.Enclosure = &rssEnclosure{URL: enc.URL, Length: strconv.FormatInt(enc.Length, 10), Type: enc.Type}
+		}
+		out.Channel.Items = append(out.Channel.Items, it)
+	}
+	return writeXML(w, out)
+}
+
+func writeXML(w io.Writer, v interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
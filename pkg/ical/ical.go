@@ -0,0 +1,209 @@
+// Package ical 读写 iCalendar (RFC 5545) 文件，只实现日历订阅和导入需要的部分：
+// 组件、属性和参数的编码与解析，长行折叠，文本转义和日期时间值
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+var ErrInvalidCalendar = errors.New("无效的 iCalendar 文件")
+
+const (
+	dateFormat     = "20060102"
+	dateTimeFormat = "20060102T150405"
+	utcFormat      = "20060102T150405Z"
+)
+
+// Property 一个属性，例如 DTSTART;TZID=Asia/Shanghai:20240101T090000
+type Property struct {
+	Name   string
+	Params map[string]string
+	Value  string // 原始值，文本类型需要用 Text 取出
+}
+
+// Param 获取参数值，参数名不区分大小写
+func (p *Property) Param(name string) string {
+	return p.Params[strings.ToUpper(name)]
+}
+
+// Text 取出转义前的文本值
+func (p *Property) Text() string {
+	return unescape(p.Value)
+}
+
+// TZID 时间值的时区名，去掉部分客户端加在前面的 "/"
+func (p *Property) TZID() string {
+	return strings.Trim(p.Param("TZID"), "/")
+}
+
+// Time 解析日期或日期时间值，allDay 表示只有日期；没有时区的浮动时间和日期按 loc 解释
+func (p *Property) Time(loc *time.Location) (t time.Time, allDay bool, err error) {
+	if tzid := p.TZID(); tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	v := p.Value
+	switch {
+	case p.Param("VALUE") == "DATE" || len(v) == len(dateFormat):
+		t, err = time.ParseInLocation(dateFormat, v, loc)
+		allDay = true
+	case strings.HasSuffix(v, "Z"):
+		t, err = time.Parse(utcFormat, v)
+	default:
+		t, err = time.ParseInLocation(dateTimeFormat, v, loc)
+	}
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("%w: %s 的时间格式错误", ErrInvalidCalendar, p.Name)
+	}
+	return t, allDay, nil
+}
+
+// Component 一个组件，例如 VCALENDAR、VEVENT、VTODO
+type Component struct {
+	Name       string
+	Properties []*Property
+	Children   []*Component
+}
+
+// Get 获取第一个同名属性，不存在时返回 nil
+func (c *Component) Get(name string) *Property {
+	for _, p := range c.Properties {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// Text 获取第一个同名属性的文本值
+func (c *Component) Text(name string) string {
+	if p := c.Get(name); p != nil {
+		return p.Text()
+	}
+	return ""
+}
+
+// Add 添加属性，value 按原样写出，文本请用 AddText
+func (c *Component) Add(name, value string, params ...string) *Component {
+	p := &Property{Name: name, Value: value}
+	if len(params) > 0 {
+		p.Params = make(map[string]string, len(params)/2)
+		for i := 0; i+1 < len(params); i += 2 {
+			p.Params[params[i]] = params[i+1]
+		}
+	}
+	c.Properties = append(c.Properties, p)
+	return c
+}
+
+// AddText 添加文本属性，空文本不写出
+func (c *Component) AddText(name, value string) *Component {
+	if value == "" {
+		return c
+	}
+	return c.Add(name, escape(value))
+}
+
+// AddTime 添加日期时间属性，统一写成 UTC
+func (c *Component) AddTime(name string, t time.Time) *Component {
+	return c.Add(name, t.UTC().Format(utcFormat))
+}
+
+// AddDate 添加只有日期的属性
+func (c *Component) AddDate(name string, t time.Time) *Component {
+	return c.Add(name, t.Format(dateFormat), "VALUE", "DATE")
+}
+
+// NewCalendar 创建 VCALENDAR，name 为订阅时显示的日历名称
+func NewCalendar(prodID, name string) *Component {
+	c := &Component{Name: "VCALENDAR"}
+	c.Add("VERSION", "2.0")
+	c.Add("PRODID", prodID)
+	c.Add("CALSCALE", "GREGORIAN")
+	c.Add("METHOD", "PUBLISH")
+	c.AddText("X-WR-CALNAME", name)
+	return c
+}
+
+// Encode 写出组件，行尾为 CRLF，超过 75 字节的行按规范折叠
+func (c *Component) Encode(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	c.encode(bw)
+	return bw.Flush()
+}
+
+func (c *Component) encode(w *bufio.Writer) {
+	writeLine(w, "BEGIN:"+c.Name)
+	for _, p := range c.Properties {
+		var sb strings.Builder
+		sb.WriteString(p.Name)
+		keys := make([]string, 0, len(p.Params))
+		for k := range p.Params {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			v := p.Params[k]
+			sb.WriteString(";" + k + "=")
+			if strings.ContainsAny(v, ";:,") {
+				v = `"` + strings.ReplaceAll(v, `"`, "") + `"`
+			}
+			sb.WriteString(v)
+		}
+		sb.WriteString(":" + p.Value)
+		writeLine(w, sb.String())
+	}
+	for _, child := range c.Children {
+		child.encode(w)
+	}
+	writeLine(w, "END:"+c.Name)
+}
+
+// writeLine 折叠长行，不在多字节字符中间断开
+func writeLine(w *bufio.Writer, line string) {
+	limit := 75
+	for len(line) > limit {
+		n := limit
+		for n > 0 && line[n]&0xC0 == 0x80 {
+			n--
+		}
+		w.WriteString(line[:n] + "\r\n ")
+		line = line[n:]
+		limit = 74 // 续行开头的空格占一个字节
+	}
+	w.WriteString(line + "\r\n")
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+func escape(s string) string {
+	return escaper.Replace(s)
+}
+
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			sb.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			sb.WriteByte('\n')
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+	return sb.String()
+}
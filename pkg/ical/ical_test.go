@@ -0,0 +1,141 @@
+package ical
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestEncodeParseRoundTrip(t *testing.T) {
+	description := strings.Repeat("今天的待办：买菜；写日记，还有 a\\b\n", 6)
+	due := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+
+	cal := NewCalendar("-//diary//EN", "我的待办")
+	todo := &Component{Name: "VTODO"}
+	todo.Add("UID", "todo-1@diary")
+	todo.AddText("SUMMARY", "交房租, 记得")
+	todo.AddText("DESCRIPTION", description)
+	todo.AddTime("DUE", due)
+	todo.AddDate("DTSTART", time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC))
+	todo.Add("X-LOCATION", "home", "ALTREP", "cid:a;b")
+	cal.Children = append(cal.Children, todo)
+
+	var buf bytes.Buffer
+	if err := cal.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("行超过 75 字节：%q", line)
+		}
+		if !utf8.ValidString(line) {
+			t.Errorf("折叠断开了多字节字符：%q", line)
+		}
+	}
+
+	parsed, err := Parse(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := parsed.Text("X-WR-CALNAME"); got != "我的待办" {
+		t.Errorf("X-WR-CALNAME = %q", got)
+	}
+	if len(parsed.Children) != 1 {
+		t.Fatalf("子组件 %d 个，want 1", len(parsed.Children))
+	}
+	got := parsed.Children[0]
+	if got.Text("SUMMARY") != "交房租, 记得" {
+		t.Errorf("SUMMARY = %q", got.Text("SUMMARY"))
+	}
+	if got.Text("DESCRIPTION") != description {
+		t.Errorf("DESCRIPTION = %q, want %q", got.Text("DESCRIPTION"), description)
+	}
+	if p := got.Get("X-LOCATION"); p == nil || p.Param("altrep") != "cid:a;b" {
+		t.Errorf("带引号的参数解析错误：%+v", p)
+	}
+	if at, allDay, err := got.Get("DUE").Time(time.Local); err != nil || allDay || !at.Equal(due) {
+		t.Errorf("DUE = %v, %v, %v", at, allDay, err)
+	}
+	if _, allDay, err := got.Get("DTSTART").Time(time.UTC); err != nil || !allDay {
+		t.Errorf("DTSTART 应为全天，allDay = %v, err = %v", allDay, err)
+	}
+}
+
+func TestWriteLineFoldsAtRuneBoundary(t *testing.T) {
+	// 不同的前缀长度让折叠位置落在汉字的不同字节上
+	for prefix := 0; prefix < 3; prefix++ {
+		value := strings.Repeat("x", prefix) + strings.Repeat("日", 60)
+		cal := &Component{Name: "VCALENDAR"}
+		cal.Add("DESCRIPTION", value)
+		var buf bytes.Buffer
+		if err := cal.Encode(&buf); err != nil {
+			t.Fatal(err)
+		}
+		for _, line := range strings.Split(buf.String(), "\r\n") {
+			if !utf8.ValidString(line) || len(line) > 75 {
+				t.Fatalf("prefix %d: 折叠后的行 %q", prefix, line)
+			}
+		}
+		parsed, err := Parse(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := parsed.Get("DESCRIPTION").Value; got != value {
+			t.Fatalf("prefix %d: 展开后 %q, want %q", prefix, got, value)
+		}
+	}
+}
+
+func TestUnescape(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{`plain`, "plain"},
+		{`a\nb`, "a\nb"},
+		{`a\Nb`, "a\nb"},
+		{`a\,b\;c`, "a,b;c"},
+		{`back\\slash`, `back\slash`},
+		{`\\n`, `\n`},
+		{`trailing\`, `trailing\`},
+	}
+	for _, tt := range tests {
+		if got := unescape(tt.in); got != tt.want {
+			t.Errorf("unescape(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestPropertyTZID(t *testing.T) {
+	cal, err := Parse(strings.NewReader("BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\n" +
+		"DTSTART;TZID=/Europe/Berlin:20260301T090000\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := cal.Children[0].Get("DTSTART")
+	if p.TZID() != "Europe/Berlin" {
+		t.Fatalf("TZID() = %q", p.TZID())
+	}
+	at, _, err := p.Time(time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC); !at.Equal(want) {
+		t.Fatalf("Time() = %v, want %v", at, want)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for name, input := range map[string]string{
+		"缺少 VCALENDAR": "BEGIN:VEVENT\r\nEND:VEVENT\r\n",
+		"END 不匹配":      "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nEND:VTODO\r\nEND:VCALENDAR\r\n",
+		"缺少 END":       "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\n",
+		"引号未闭合":        "BEGIN:VCALENDAR\r\nX;A=\"b:c\r\nEND:VCALENDAR\r\n",
+	} {
+		if _, err := Parse(strings.NewReader(input)); !errors.Is(err, ErrInvalidCalendar) {
+			t.Errorf("%s: err = %v, want %v", name, err, ErrInvalidCalendar)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// maxLineBytes 展开折叠后单行的最大长度
+const maxLineBytes = 1 << 20
+
+// Parse 解析 iCalendar 文件，返回最外层的 VCALENDAR
+func Parse(r io.Reader) (*Component, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var root *Component
+	var stack []*Component
+	for _, line := range lines {
+		p, err := parseLine(line)
+		if err != nil {
+			return nil, err
+		}
+		switch p.Name {
+		case "BEGIN":
+			c := &Component{Name: strings.ToUpper(p.Value)}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.Children = append(parent.Children, c)
+			} else if root != nil {
+				return nil, fmt.Errorf("%w: 只支持一个 VCALENDAR", ErrInvalidCalendar)
+			} else {
+				root = c
+			}
+			stack = append(stack, c)
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1].Name != strings.ToUpper(p.Value) {
+				return nil, fmt.Errorf("%w: END:%s 不匹配", ErrInvalidCalendar, p.Value)
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("%w: 属性 %s 不在组件内", ErrInvalidCalendar, p.Name)
+			}
+			c := stack[len(stack)-1]
+			c.Properties = append(c.Properties, p)
+		}
+	}
+	if root == nil || root.Name != "VCALENDAR" {
+		return nil, fmt.Errorf("%w: 缺少 VCALENDAR", ErrInvalidCalendar)
+	}
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("%w: 缺少 END:%s", ErrInvalidCalendar, stack[len(stack)-1].Name)
+	}
+	return root, nil
+}
+
+// unfold 按行读取并合并以空格或制表符开头的续行，兼容 LF 换行
+func unfold(r io.Reader) ([]string, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), maxLineBytes)
+	var lines []string
+	for sc.Scan() {
+		line := strings.TrimSuffix(sc.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		if (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCalendar, err)
+	}
+	if len(lines) > 0 {
+		lines[0] = strings.TrimPrefix(lines[0], "\ufeff")
+	}
+	return lines, nil
+}
+
+// parseLine 解析 name;param=value;param="quoted":value
+func parseLine(line string) (*Property, error) {
+	p := &Property{}
+	i := strings.IndexAny(line, ";:")
+	if i <= 0 {
+		return nil, fmt.Errorf("%w: 无法解析的行 %q", ErrInvalidCalendar, truncate(line))
+	}
+	p.Name = strings.ToUpper(line[:i])
+
+	for line[i] == ';' {
+		rest := line[i+1:]
+		eq := strings.IndexByte(rest, '=')
+		if eq <= 0 {
+			return nil, fmt.Errorf("%w: 无法解析的参数 %q", ErrInvalidCalendar, truncate(line))
+		}
+		name := strings.ToUpper(rest[:eq])
+		rest = rest[eq+1:]
+
+		var value string
+		var n int
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("%w: 引号未闭合 %q", ErrInvalidCalendar, truncate(line))
+			}
+			value, n = rest[1:end+1], end+2
+		} else {
+			n = strings.IndexAny(rest, ";:")
+			if n < 0 {
+				return nil, fmt.Errorf("%w: 缺少属性值 %q", ErrInvalidCalendar, truncate(line))
+			}
+			value = rest[:n]
+		}
+		if p.Params == nil {
+			p.Params = make(map[string]string)
+		}
+		p.Params[name] = value
+
+		i += 1 + eq + 1 + n
+		if i >= len(line) {
+			return nil, fmt.Errorf("%w: 缺少属性值 %q", ErrInvalidCalendar, truncate(line))
+		}
+	}
+	if line[i] != ':' {
+		return nil, fmt.Errorf("%w: 无法解析的行 %q", ErrInvalidCalendar, truncate(line))
+	}
+	p.Value = line[i+1:]
+	return p, nil
+}
+
+func truncate(s string) string {
+	if len(s) > 60 {
+		return s[:60] + "..."
+	}
+	return s
+}
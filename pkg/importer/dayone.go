@@ -0,0 +1,115 @@
+package importer
+
+import (
+	"archive/zip"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+)
+
+// Day One 的 JSON 导出：每个日志一个 JSON 文件，照片位于 photos/{md5}.{type}
+type dayOneExport struct {
+	Entries []dayOneEntry `json:"entries"`
+}
+
+type dayOneEntry struct {
+	UUID         string   `json:"uuid"`
+	CreationDate string   `json:"creationDate"`
+	TimeZone     string   `json:"timeZone"`
+	Text         string   `json:"text"`
+	Tags         []string `json:"tags"`
+	Starred      bool     `json:"starred"`
+	Location     *struct {
+		PlaceName          string `json:"placeName"`
+		LocalityName       string `json:"localityName"`
+		AdministrativeArea string `json:"administrativeArea"`
+		Country            string `json:"country"`
+	} `json:"location"`
+	Weather *struct {
+		ConditionsDescription string   `json:"conditionsDescription"`
+		TemperatureCelsius    *float64 `json:"temperatureCelsius"`
+	} `json:"weather"`
+	Photos []struct {
+		Identifier string `json:"identifier"`
+		MD5        string `json:"md5"`
+		Type       string `json:"type"`
+	} `json:"photos"`
+}
+
+func parseDayOne(zr *zip.Reader) ([]Entry, error) {
+	files := make(map[string]*zip.File)
+	var journals []*zip.File
+	for _, f := range zr.File {
+		if skipFile(f) {
+			continue
+		}
+		files[f.Name] = f
+		if strings.EqualFold(path.Ext(f.Name), ".json") {
+			journals = append(journals, f)
+		}
+	}
+
+	var entries []Entry
+	for _, f := range journals {
+		var export dayOneExport
+		if err := readJSON(f, &export); err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Name, err)
+		}
+		dir := path.Dir(f.Name)
+		journal := strings.TrimSuffix(path.Base(f.Name), path.Ext(f.Name))
+
+		for _, e := range export.Entries {
+			date, err := time.Parse(time.RFC3339, e.CreationDate)
+			if err != nil {
+				continue
+			}
+			if loc, err := time.LoadLocation(e.TimeZone); err == nil {
+				date = date.In(loc)
+			}
+
+			title, content := splitTitle(e.Text)
+			entry := Entry{
+				SourceID: e.UUID,
+				Title:    title,
+				Content:  content,
+				Date:     date,
+				Tags:     e.Tags,
+				Properties: map[string]interface{}{
+					"source":  FormatDayOne,
+					"journal": journal,
+				},
+			}
+			if e.Starred {
+				entry.Properties["starred"] = true
+			}
+			if e.Location != nil {
+				entry.Location = joinNonEmpty(", ", e.Location.PlaceName, e.Location.LocalityName, e.Location.AdministrativeArea, e.Location.Country)
+			}
+			if e.Weather != nil {
+				entry.Weather = e.Weather.ConditionsDescription
+				if e.Weather.TemperatureCelsius != nil {
+					entry.Weather = joinNonEmpty(" ", entry.Weather, fmt.Sprintf("%.0f°C", *e.Weather.TemperatureCelsius))
+				}
+			}
+
+			for _, p := range e.Photos {
+				f, ok := files[path.Join(dir, "photos", p.MD5+"."+p.Type)]
+				if !ok {
+					continue
+				}
+				ref := "dayone-moment://" + p.Identifier
+				if !strings.Contains(content, "]("+ref+")") {
+					ref = ""
+				}
+				entry.Media = append(entry.Media, Media{
+					Ref:  ref,
+					Name: path.Base(f.Name),
+					Open: zipOpener(f),
+				})
+			}
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
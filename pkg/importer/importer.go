@@ -0,0 +1,162 @@
+// Package importer 解析其他日记应用的导出文件，转换为统一的 Entry
+package importer
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// 支持的导入格式
+const (
+	FormatDayOne   = "dayone"
+	FormatJourney  = "journey"
+	FormatMarkdown = "markdown"
+)
+
+var (
+	ErrUnknownFormat = errors.New("无法识别的导入格式")
+	ErrNoEntries     = errors.New("没有找到可导入的日记")
+)
+
+// Entry 一篇待导入的日记
+type Entry struct {
+	SourceID   string // 在来源中的唯一标识，用于重复导入时去重
+	Title      string
+	Content    string // Markdown，附件引用保留原始地址
+	Weather    string
+	Mood       string
+	Location   string
+	Music      string
+	Date       time.Time
+	IsPublic   bool
+	Tags       []string
+	Properties map[string]interface{}
+	Media      []Media
+}
+
+// Media 日记附带的图片等文件
+type Media struct {
+	// Ref 正文中引用该文件的地址（Markdown 图片语法括号内的内容），为空表示未在正文中引用
+	Ref  string
+	Name string
+	Open func() (io.ReadCloser, error)
+}
+
+// Parse 按格式解析导出包
+func Parse(format string, zr *zip.Reader) ([]Entry, error) {
+	var entries []Entry
+	var err error
+	switch format {
+	case FormatDayOne:
+		entries, err = parseDayOne(zr)
+	case FormatJourney:
+		entries, err = parseJourney(zr)
+	case FormatMarkdown:
+		entries, err = parseMarkdown(zr)
+	default:
+		return nil, ErrUnknownFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, ErrNoEntries
+	}
+	// 按日期先后导入
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Date.Before(entries[j].Date)
+	})
+	return entries, nil
+}
+
+// Detect 根据压缩包内容推断格式
+func Detect(zr *zip.Reader) (string, error) {
+	hasMarkdown := false
+	for _, f := range zr.File {
+		if skipFile(f) {
+			continue
+		}
+		switch strings.ToLower(path.Ext(f.Name)) {
+		case ".md", ".markdown":
+			hasMarkdown = true
+		case ".json":
+			// Day One 的日志文件顶层是 {"metadata":..., "entries":[...]}，Journey 每篇日记一个 JSON
+			var probe struct {
+				Entries json.RawMessage `json:"entries"`
+			}
+			if err := readJSON(f, &probe); err != nil {
+				continue
+			}
+			if probe.Entries != nil {
+				return FormatDayOne, nil
+			}
+			return FormatJourney, nil
+		}
+	}
+	if hasMarkdown {
+		return FormatMarkdown, nil
+	}
+	return "", ErrUnknownFormat
+}
+
+// skipFile 忽略目录和 macOS 压缩时附带的元数据文件
+func skipFile(f *zip.File) bool {
+	if f.FileInfo().IsDir() {
+		return true
+	}
+	if strings.HasPrefix(f.Name, "__MACOSX/") {
+		return true
+	}
+	return strings.HasPrefix(path.Base(f.Name), ".")
+}
+
+func readJSON(f *zip.File, v interface{}) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return json.NewDecoder(rc).Decode(v)
+}
+
+var headingPattern = regexp.MustCompile(`^#{1,6}\s+`)
+
+// splitTitle 从正文中提取标题：首行是 Markdown 标题时将其移出正文，否则截取首行作为标题
+func splitTitle(text string) (string, string) {
+	text = strings.TrimLeft(text, "\r\n")
+	first, rest, _ := strings.Cut(text, "\n")
+	first = strings.TrimSpace(first)
+	if headingPattern.MatchString(first) {
+		return headingPattern.ReplaceAllString(first, ""), strings.TrimLeft(rest, "\r\n")
+	}
+	return truncate(first, 50), text
+}
+
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n]) + "…"
+}
+
+func joinNonEmpty(sep string, parts ...string) string {
+	var out []string
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return strings.Join(out, sep)
+}
+
+func zipOpener(f *zip.File) func() (io.ReadCloser, error) {
+	return f.Open
+}
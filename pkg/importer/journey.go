@@ -0,0 +1,111 @@
+package importer
+
+import (
+	"archive/zip"
+	"fmt"
+	"html"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Journey 的导出：每篇日记一个 {id}.json，照片与 JSON 放在同一目录
+type journeyEntry struct {
+	ID          string   `json:"id"`
+	Text        string   `json:"text"`
+	Type        string   `json:"type"` // html 或 markdown，旧版本没有该字段
+	DateJournal int64    `json:"date_journal"`
+	Timezone    string   `json:"timezone"`
+	Address     string   `json:"address"`
+	MusicTitle  string   `json:"music_title"`
+	MusicArtist string   `json:"music_artist"`
+	Favourite   bool     `json:"favourite"`
+	Tags        []string `json:"tags"`
+	Photos      []string `json:"photos"`
+	Weather     *struct {
+		DegreeC     *float64 `json:"degree_c"`
+		Description string   `json:"description"`
+		Place       string   `json:"place"`
+	} `json:"weather"`
+}
+
+func parseJourney(zr *zip.Reader) ([]Entry, error) {
+	files := make(map[string]*zip.File)
+	for _, f := range zr.File {
+		if !skipFile(f) {
+			files[f.Name] = f
+		}
+	}
+
+	var entries []Entry
+	for name, f := range files {
+		if !strings.EqualFold(path.Ext(name), ".json") {
+			continue
+		}
+		var e journeyEntry
+		if err := readJSON(f, &e); err != nil || e.DateJournal == 0 {
+			continue
+		}
+		if e.ID == "" {
+			e.ID = strings.TrimSuffix(path.Base(name), path.Ext(name))
+		}
+
+		date := time.UnixMilli(e.DateJournal)
+		if loc, err := time.LoadLocation(e.Timezone); err == nil {
+			date = date.In(loc)
+		}
+
+		text := e.Text
+		if e.Type == "html" {
+			text = htmlToText(text)
+		}
+		title, content := splitTitle(text)
+
+		entry := Entry{
+			SourceID: e.ID,
+			Title:    title,
+			Content:  content,
+			Date:     date,
+			Location: e.Address,
+			Music:    joinNonEmpty(" - ", e.MusicTitle, e.MusicArtist),
+			Tags:     e.Tags,
+			Properties: map[string]interface{}{
+				"source": FormatJourney,
+			},
+		}
+		if e.Favourite {
+			entry.Properties["starred"] = true
+		}
+		if e.Weather != nil {
+			entry.Weather = e.Weather.Description
+			if e.Weather.DegreeC != nil {
+				entry.Weather = joinNonEmpty(" ", entry.Weather, fmt.Sprintf("%.0f°C", *e.Weather.DegreeC))
+			}
+			if entry.Location == "" {
+				entry.Location = e.Weather.Place
+			}
+		}
+
+		dir := path.Dir(name)
+		for _, photo := range e.Photos {
+			if f, ok := files[path.Join(dir, photo)]; ok {
+				entry.Media = append(entry.Media, Media{Name: photo, Open: zipOpener(f)})
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+var (
+	htmlBreakPattern = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|li|h[1-6])>`)
+	htmlTagPattern   = regexp.MustCompile(`<[^>]+>`)
+)
+
+// htmlToText 将 Journey 的富文本转换为纯文本段落
+func htmlToText(s string) string {
+	s = htmlBreakPattern.ReplaceAllString(s, "\n")
+	s = htmlTagPattern.ReplaceAllString(s, "")
+	return strings.TrimSpace(html.UnescapeString(s))
+}
@@ -0,0 +1,216 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Markdown 文件夹（打包为 zip）：每个 .md 文件一篇日记，可带 YAML front matter
+//
+//	---
+//	title: 标题
+//	date: 2024-01-02 08:30
+//	tags: [旅行, 家人]
+//	mood: 开心
+//	weather: 晴
+//	location: 杭州
+//	---
+type frontMatter struct {
+	Title    string      `yaml:"title"`
+	Date     string      `yaml:"date"`
+	Tags     interface{} `yaml:"tags"` // 列表或逗号分隔的字符串
+	Mood     string      `yaml:"mood"`
+	Weather  string      `yaml:"weather"`
+	Location string      `yaml:"location"`
+	Music    string      `yaml:"music"`
+	Public   bool        `yaml:"public"`
+}
+
+// front matter 中已映射到日记字段的键，其余键保存到 Properties
+var frontMatterKeys = map[string]bool{
+	"title": true, "date": true, "tags": true, "mood": true,
+	"weather": true, "location": true, "music": true, "public": true,
+}
+
+var (
+	markdownImagePattern = regexp.MustCompile(`!\[[^\]]*\]\(([^)]+)\)`)
+	datePrefixPattern    = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}`)
+	dateLayouts          = []string{
+		time.RFC3339,
+		"2006-01-02 15:04:05",
+		"2006-01-02 15:04",
+		"2006-01-02T15:04:05",
+		"2006-01-02T15:04",
+		"2006-01-02",
+	}
+)
+
+func parseMarkdown(zr *zip.Reader) ([]Entry, error) {
+	files := make(map[string]*zip.File)
+	for _, f := range zr.File {
+		if !skipFile(f) {
+			files[f.Name] = f
+		}
+	}
+
+	var entries []Entry
+	for name, f := range files {
+		switch strings.ToLower(path.Ext(name)) {
+		case ".md", ".markdown":
+		default:
+			continue
+		}
+		raw, err := readAll(f)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		entry, err := parseMarkdownFile(name, raw, f.Modified)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		// 正文中以相对路径引用的图片
+		for _, m := range markdownImagePattern.FindAllStringSubmatch(entry.Content, -1) {
+			ref := m[1]
+			target, _, _ := strings.Cut(strings.TrimSpace(ref), " ")
+			target = strings.Trim(target, "<>")
+			if strings.Contains(target, ":") {
+				continue // http(s)、data、image: 等绝对地址
+			}
+			if unescaped, err := url.PathUnescape(target); err == nil {
+				target = unescaped
+			}
+			mf, ok := files[path.Join(path.Dir(name), target)]
+			if !ok {
+				continue
+			}
+			entry.Media = append(entry.Media, Media{Ref: ref, Name: path.Base(mf.Name), Open: zipOpener(mf)})
+		}
+		entries = append(entries, *entry)
+	}
+	return entries, nil
+}
+
+func parseMarkdownFile(name string, raw []byte, modified time.Time) (*Entry, error) {
+	raw = bytes.TrimPrefix(raw, []byte("\xef\xbb\xbf"))
+	text := strings.ReplaceAll(string(raw), "\r\n", "\n")
+
+	// 文件路径和内容一起决定来源ID，内容修改后重新导入视为新日记
+	sum := sha256.Sum256(append([]byte(name+"\n"), raw...))
+	entry := &Entry{
+		SourceID:   hex.EncodeToString(sum[:]),
+		Properties: map[string]interface{}{"source": FormatMarkdown},
+	}
+
+	var fm frontMatter
+	if header, body, ok := cutFrontMatter(text); ok {
+		if err := yaml.Unmarshal([]byte(header), &fm); err != nil {
+			return nil, err
+		}
+		var extra map[string]interface{}
+		if err := yaml.Unmarshal([]byte(header), &extra); err == nil {
+			for k, v := range extra {
+				if !frontMatterKeys[k] {
+					entry.Properties[k] = v
+				}
+			}
+		}
+		text = body
+	}
+
+	entry.Title = fm.Title
+	entry.Content = strings.TrimLeft(text, "\n")
+	if entry.Title == "" {
+		entry.Title, entry.Content = splitTitle(text)
+	}
+	if entry.Title == "" {
+		entry.Title = strings.TrimSuffix(path.Base(name), path.Ext(name))
+	}
+	entry.Mood = fm.Mood
+	entry.Weather = fm.Weather
+	entry.Location = fm.Location
+	entry.Music = fm.Music
+	entry.IsPublic = fm.Public
+	entry.Tags = parseTags(fm.Tags)
+
+	// 日期优先取 front matter，其次是文件名前缀，最后是文件修改时间
+	entry.Date = modified
+	if d, ok := parseDate(fm.Date); ok {
+		entry.Date = d
+	} else if d, ok := parseDate(datePrefixPattern.FindString(path.Base(name))); ok {
+		entry.Date = d
+	}
+	return entry, nil
+}
+
+// cutFrontMatter 拆分 --- 包裹的 front matter 和正文
+func cutFrontMatter(text string) (string, string, bool) {
+	if !strings.HasPrefix(text, "---\n") {
+		return "", text, false
+	}
+	rest := text[len("---\n"):]
+	for _, end := range []string{"\n---\n", "\n...\n"} {
+		if i := strings.Index(rest, end); i >= 0 {
+			return rest[:i], rest[i+len(end):], true
+		}
+	}
+	// front matter 后没有正文
+	for _, end := range []string{"\n---", "\n..."} {
+		if strings.HasSuffix(rest, end) {
+			return strings.TrimSuffix(rest, end), "", true
+		}
+	}
+	return "", text, false
+}
+
+func parseTags(v interface{}) []string {
+	var tags []string
+	switch t := v.(type) {
+	case string:
+		for _, s := range strings.FieldsFunc(t, func(r rune) bool { return r == ',' || r == '，' }) {
+			if s = strings.TrimSpace(s); s != "" {
+				tags = append(tags, strings.TrimPrefix(s, "#"))
+			}
+		}
+	case []interface{}:
+		for _, item := range t {
+			if s := strings.TrimSpace(fmt.Sprint(item)); s != "" {
+				tags = append(tags, strings.TrimPrefix(s, "#"))
+			}
+		}
+	}
+	return tags
+}
+
+func parseDate(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, false
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func readAll(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
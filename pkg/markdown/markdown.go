@@ -0,0 +1,122 @@
+package markdown
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"sync"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	highlighting "github.com/yuin/goldmark-highlighting/v2"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+// Renderer 将 Markdown 渲染为经过 XSS 过滤的 HTML，并按内容哈希缓存结果
+type Renderer struct {
+	md     goldmark.Markdown
+	policy *bluemonday.Policy
+
+	mu      sync.Mutex
+	maxSize int
+	entries map[string]*list.Element
+	order   *list.List // 最近使用的在前
+}
+
+type cacheEntry struct {
+	key  string
+	html string
+}
+
+// NewRenderer 创建渲染器，cacheSize <= 0 时不缓存
+func NewRenderer(cacheSize int) *Renderer {
+	md := goldmark.New(
+		goldmark.WithExtensions(
+			extension.GFM, // 表格、任务列表、删除线、自动链接
+			highlighting.NewHighlighting(
+				highlighting.WithStyle("github"),
+			),
+		),
+		goldmark.WithRendererOptions(
+			html.WithHardWraps(),
+		),
+	)
+
+	return &Renderer{
+		md:      md,
+		policy:  newPolicy(),
+		maxSize: cacheSize,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// newPolicy 在 UGC 策略基础上放开代码高亮和任务列表需要的属性
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	// 正文中的 image:ID 引用在输出前才替换为签名地址
+	p.AllowURLSchemes("mailto", "http", "https", "image")
+	p.AllowDataURIImages()
+	p.AllowAttrs("style").Matching(regexp.MustCompile(`^[a-z\-]+:[#0-9a-zA-Z ;:\-]+$`)).OnElements("span", "pre")
+	p.AllowElements("input")
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	p.AllowAttrs("checked", "disabled").OnElements("input")
+	return p
+}
+
+// Render 渲染 Markdown，命中缓存时直接返回
+func (r *Renderer) Render(source string) (string, error) {
+	if source == "" {
+		return "", nil
+	}
+	key := hashContent(source)
+	if html, ok := r.get(key); ok {
+		return html, nil
+	}
+
+	var buf bytes.Buffer
+	if err := r.md.Convert([]byte(source), &buf); err != nil {
+		return "", err
+	}
+	html := r.policy.Sanitize(buf.String())
+
+	r.put(key, html)
+	return html, nil
+}
+
+func (r *Renderer) get(key string) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	el, ok := r.entries[key]
+	if !ok {
+		return "", false
+	}
+	r.order.MoveToFront(el)
+	return el.Value.(*cacheEntry).html, true
+}
+
+func (r *Renderer) put(key, html string) {
+	if r.maxSize <= 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if el, ok := r.entries[key]; ok {
+		r.order.MoveToFront(el)
+		return
+	}
+	r.entries[key] = r.order.PushFront(&cacheEntry{key: key, html: html})
+	for r.order.Len() > r.maxSize {
+		oldest := r.order.Back()
+		r.order.Remove(oldest)
+		delete(r.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+func hashContent(source string) string {
+	sum := sha256.Sum256([]byte(source))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,430 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// 附件类型
+const (
+	KindImage = "image"
+	KindAudio = "audio"
+	KindVideo = "video"
+)
+
+var (
+	ErrUnsupportedType = errors.New("unsupported media type")
+	// ErrMalformed 文件头声明的长度超出实际数据
+	ErrMalformed = errors.New("malformed media file")
+)
+
+// HeadSize 识别类型时读取的文件头长度
+const HeadSize = 512
+
+// Info 媒体文件的基本信息，解析不到的字段保持零值
+type Info struct {
+	Kind     string
+	MimeType string
+	Duration time.Duration
+	Width    int
+	Height   int
+}
+
+// Probe 识别文件类型并尽量提取时长、尺寸等元数据
+// 只依赖标准库，无法解析的格式返回 ErrUnsupportedType
+func Probe(r io.ReadSeeker, size int64) (*Info, error) {
+	head := make([]byte, HeadSize)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	head = head[:n]
+
+	info := sniff(head)
+	if info == nil {
+		return nil, ErrUnsupportedType
+	}
+
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	// 缺少元数据不影响上传，只是对应字段为零值；结构损坏的 WAV/Ogg 返回 ErrMalformed
+	switch info.MimeType {
+	case "audio/mp4", "video/mp4", "video/quicktime":
+		probeMP4(r, size, info)
+	case "audio/mpeg":
+		probeMP3(r, size, info)
+	case "audio/wav":
+		if err := probeWAV(r, size, info); err != nil {
+			return nil, err
+		}
+	case "audio/ogg":
+		if err := probeOgg(r, size, info); err != nil {
+			return nil, err
+		}
+	default:
+		if info.Kind == KindImage {
+			if cfg, _, err := image.DecodeConfig(r); err == nil {
+				info.Width, info.Height = cfg.Width, cfg.Height
+			}
+		}
+	}
+	return info, nil
+}
+
+// extensions 识别出的类型对应的文件扩展名
+var extensions = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"image/bmp":       ".bmp",
+	"audio/mp4":       ".m4a",
+	"audio/mpeg":      ".mp3",
+	"audio/wav":       ".wav",
+	"audio/ogg":       ".ogg",
+	"audio/flac":      ".flac",
+	"audio/amr":       ".amr",
+	"video/mp4":       ".mp4",
+	"video/quicktime": ".mov",
+	"video/webm":      ".webm",
+}
+
+// Sniff 只根据文件头（最多 HeadSize 字节）判断类型，不解析元数据
+func Sniff(head []byte) (*Info, error) {
+	info := sniff(head)
+	if info == nil {
+		return nil, ErrUnsupportedType
+	}
+	return info, nil
+}
+
+// Extension 返回 Probe 识别出的 MIME 类型对应的扩展名（带点号），未知类型返回空字符串
+func Extension(mimeType string) string {
+	return extensions[mimeType]
+}
+
+// sniff 根据文件头判断类型
+func sniff(head []byte) *Info {
+	switch {
+	case len(head) >= 12 && string(head[4:8]) == "ftyp":
+		brand := string(head[8:12])
+		switch {
+		case strings.HasPrefix(brand, "M4A"), strings.HasPrefix(brand, "M4B"):
+			return &Info{Kind: KindAudio, MimeType: "audio/mp4"}
+		case brand == "qt  ":
+			return &Info{Kind: KindVideo, MimeType: "video/quicktime"}
+		default:
+			return &Info{Kind: KindVideo, MimeType: "video/mp4"}
+		}
+	case bytes.HasPrefix(head, []byte("ID3")),
+		len(head) >= 2 && head[0] == 0xFF && head[1]&0xE0 == 0xE0:
+		return &Info{Kind: KindAudio, MimeType: "audio/mpeg"}
+	case len(head) >= 12 && string(head[0:4]) == "RIFF" && string(head[8:12]) == "WAVE":
+		return &Info{Kind: KindAudio, MimeType: "audio/wav"}
+	case bytes.HasPrefix(head, []byte("OggS")):
+		return &Info{Kind: KindAudio, MimeType: "audio/ogg"}
+	case bytes.HasPrefix(head, []byte("fLaC")):
+		return &Info{Kind: KindAudio, MimeType: "audio/flac"}
+	case bytes.HasPrefix(head, []byte("#!AMR")):
+		return &Info{Kind: KindAudio, MimeType: "audio/amr"}
+	case bytes.HasPrefix(head, []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		return &Info{Kind: KindVideo, MimeType: "video/webm"}
+	}
+
+	mimeType := http.DetectContentType(head)
+	switch mimeType {
+	case "image/jpeg", "image/png", "image/gif", "image/webp", "image/bmp":
+		return &Info{Kind: KindImage, MimeType: mimeType}
+	}
+	return nil
+}
+
+// probeMP4 读取 moov/mvhd 中的时长，以及视频轨道 tkhd 中的宽高
+func probeMP4(r io.ReadSeeker, size int64, info *Info) {
+	walkMP4Boxes(r, 0, size, info)
+}
+
+func walkMP4Boxes(r io.ReadSeeker, start, end int64, info *Info) {
+	pos := start
+	hdr := make([]byte, 8)
+	for pos+8 <= end {
+		if _, err := r.Seek(pos, io.SeekStart); err != nil {
+			return
+		}
+		if _, err := io.ReadFull(r, hdr); err != nil {
+			return
+		}
+		boxSize := int64(binary.BigEndian.Uint32(hdr[0:4]))
+		boxType := string(hdr[4:8])
+		headerLen := int64(8)
+		switch boxSize {
+		case 0:
+			boxSize = end - pos
+		case 1:
+			var large uint64
+			if err := binary.Read(r, binary.BigEndian, &large); err != nil {
+				return
+			}
+			boxSize = int64(large)
+			headerLen = 16
+		}
+		if boxSize < headerLen || pos+boxSize > end {
+			return
+		}
+
+		body := pos + headerLen
+		switch boxType {
+		case "moov", "trak":
+			walkMP4Boxes(r, body, pos+boxSize, info)
+		case "mvhd":
+			readMvhd(r, info)
+		case "tkhd":
+			readTkhd(r, info)
+		}
+		pos += boxSize
+	}
+}
+
+func readMvhd(r io.Reader, info *Info) {
+	var version [4]byte
+	if _, err := io.ReadFull(r, version[:]); err != nil {
+		return
+	}
+	var timescale uint32
+	var duration uint64
+	if version[0] == 1 {
+		var buf [28]byte
+		if _, err := io.ReadFull(r, buf[:]); err != nil {
+			return
+		}
+		timescale = binary.BigEndian.Uint32(buf[16:20])
+		duration = binary.BigEndian.Uint64(buf[20:28])
+	} else {
+		var buf [16]byte
+		if _, err := io.ReadFull(r, buf[:]); err != nil {
+			return
+		}
+		timescale = binary.BigEndian.Uint32(buf[8:12])
+		duration = uint64(binary.BigEndian.Uint32(buf[12:16]))
+	}
+	if timescale > 0 {
+		info.Duration = time.Duration(float64(duration) / float64(timescale) * float64(time.Second))
+	}
+}
+
+func readTkhd(r io.Reader, info *Info) {
+	var version [4]byte
+	if _, err := io.ReadFull(r, version[:]); err != nil {
+		return
+	}
+	// 跳过时间、轨道ID、时长、图层、音量、矩阵等字段，宽高位于末尾（16.16 定点数）
+	// v1 的时间和时长为 64 位，比 v0 多 12 字节
+	skip := 72
+	if version[0] == 1 {
+		skip = 84
+	}
+	buf := make([]byte, skip+8)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return
+	}
+	width := int(binary.BigEndian.Uint32(buf[skip:skip+4]) >> 16)
+	height := int(binary.BigEndian.Uint32(buf[skip+4:skip+8]) >> 16)
+	// 音频轨道宽高为 0
+	if width > 0 && height > 0 && info.Width == 0 {
+		info.Width, info.Height = width, height
+	}
+}
+
+var (
+	mp3BitratesV1 = []int{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320}
+	mp3BitratesV2 = []int{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160}
+	mp3Rates      = map[int][]int{3: {44100, 48000, 32000}, 2: {22050, 24000, 16000}, 0: {11025, 12000, 8000}}
+)
+
+// probeMP3 优先读取 Xing/Info 头中的帧数（VBR），否则按首帧码率估算（CBR）
+func probeMP3(r io.ReadSeeker, size int64, info *Info) {
+	var offset int64
+	id3 := make([]byte, 10)
+	if _, err := io.ReadFull(r, id3); err != nil {
+		return
+	}
+	if string(id3[0:3]) == "ID3" {
+		// ID3v2 的长度为 syncsafe 整数
+		offset = 10 + (int64(id3[6])<<21 | int64(id3[7])<<14 | int64(id3[8])<<7 | int64(id3[9]))
+	}
+	if _, err := r.Seek(offset, io.SeekStart); err != nil {
+		return
+	}
+
+	buf := make([]byte, 4096)
+	n, _ := io.ReadFull(r, buf)
+	buf = buf[:n]
+	i := 0
+	for ; i+4 <= len(buf); i++ {
+		if buf[i] == 0xFF && buf[i+1]&0xE0 == 0xE0 {
+			break
+		}
+	}
+	if i+4 > len(buf) {
+		return
+	}
+	frame := buf[i:]
+
+	version := int(frame[1]>>3) & 3 // 3: MPEG1, 2: MPEG2, 0: MPEG2.5
+	layer := int(frame[1]>>1) & 3   // 1: Layer III
+	bitrateIdx := int(frame[2] >> 4)
+	rateIdx := int(frame[2]>>2) & 3
+	mono := frame[3]>>6 == 3
+	rates, ok := mp3Rates[version]
+	if !ok || layer != 1 || rateIdx > 2 || bitrateIdx == 0 || bitrateIdx > 14 {
+		return
+	}
+	sampleRate := rates[rateIdx]
+	samplesPerFrame := 1152
+	bitrate := mp3BitratesV1[bitrateIdx]
+	sideInfo := 32
+	if mono {
+		sideInfo = 17
+	}
+	if version != 3 {
+		samplesPerFrame = 576
+		bitrate = mp3BitratesV2[bitrateIdx]
+		sideInfo = 17
+		if mono {
+			sideInfo = 9
+		}
+	}
+
+	if x := 4 + sideInfo; len(frame) >= x+12 {
+		tag := string(frame[x : x+4])
+		flags := binary.BigEndian.Uint32(frame[x+4 : x+8])
+		if (tag == "Xing" || tag == "Info") && flags&1 == 1 {
+			frames := binary.BigEndian.Uint32(frame[x+8 : x+12])
+			info.Duration = time.Duration(float64(frames) * float64(samplesPerFrame) / float64(sampleRate) * float64(time.Second))
+			return
+		}
+	}
+
+	audioBytes := size - offset - int64(i)
+	if audioBytes > 0 {
+		info.Duration = time.Duration(float64(audioBytes*8) / float64(bitrate*1000) * float64(time.Second))
+	}
+}
+
+// wavFmtMax fmt 块只需要前 16 字节，WAVE_FORMAT_EXTENSIBLE 也不超过 40 字节
+const wavFmtMax = 40
+
+// probeWAV 根据 fmt 块的字节率和 data 块的长度计算时长
+func probeWAV(r io.ReadSeeker, size int64, info *Info) error {
+	pos := int64(12)
+	if _, err := r.Seek(pos, io.SeekStart); err != nil {
+		return err
+	}
+	var byteRate uint32
+	hdr := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(r, hdr); err != nil {
+			// 没有 data 块只是缺少时长
+			return nil
+		}
+		pos += 8
+		chunkSize := int64(binary.LittleEndian.Uint32(hdr[4:8]))
+		if chunkSize > size-pos {
+			return ErrMalformed
+		}
+		// 块按偶数字节对齐
+		padded := chunkSize + chunkSize%2
+		switch string(hdr[0:4]) {
+		case "fmt ":
+			fmtChunk := make([]byte, min(chunkSize, wavFmtMax))
+			if _, err := io.ReadFull(r, fmtChunk); err != nil || len(fmtChunk) < 12 {
+				return ErrMalformed
+			}
+			byteRate = binary.LittleEndian.Uint32(fmtChunk[8:12])
+			if _, err := io.CopyN(io.Discard, r, padded-int64(len(fmtChunk))); err != nil && err != io.EOF {
+				return err
+			}
+		case "data":
+			if byteRate > 0 {
+				info.Duration = time.Duration(float64(chunkSize) / float64(byteRate) * float64(time.Second))
+			}
+			return nil
+		default:
+			if _, err := r.Seek(padded, io.SeekCurrent); err != nil {
+				return err
+			}
+		}
+		pos += padded
+	}
+}
+
+// probeOgg 从首页的 Vorbis/Opus 标识头取采样率，从最后一页的 granule position 计算时长
+func probeOgg(r io.ReadSeeker, size int64, info *Info) error {
+	// 页头 27 字节，第 27 字节为分段表长度
+	hdr := make([]byte, 27)
+	if _, err := io.ReadFull(r, hdr); err != nil {
+		return ErrMalformed
+	}
+	lacing := make([]byte, int(hdr[26]))
+	if _, err := io.ReadFull(r, lacing); err != nil {
+		return ErrMalformed
+	}
+	// 首个数据包的长度为分段值之和，遇到小于 255 的分段结束
+	packetLen := 0
+	for _, l := range lacing {
+		packetLen += int(l)
+		if l < 255 {
+			break
+		}
+	}
+	if int64(27+len(lacing)+packetLen) > size {
+		return ErrMalformed
+	}
+	packet := make([]byte, packetLen)
+	if _, err := io.ReadFull(r, packet); err != nil {
+		return ErrMalformed
+	}
+
+	var sampleRate, preSkip int64
+	switch {
+	case len(packet) >= 16 && string(packet[1:7]) == "vorbis":
+		sampleRate = int64(binary.LittleEndian.Uint32(packet[12:16]))
+	case len(packet) >= 12 && string(packet[0:8]) == "OpusHead":
+		sampleRate = 48000 // Opus 的 granule 固定以 48kHz 计
+		preSkip = int64(binary.LittleEndian.Uint16(packet[10:12]))
+	default:
+		return nil
+	}
+
+	tailSize := int64(64 << 10)
+	if tailSize > size {
+		tailSize = size
+	}
+	if _, err := r.Seek(size-tailSize, io.SeekStart); err != nil {
+		return err
+	}
+	tail := make([]byte, tailSize)
+	if _, err := io.ReadFull(r, tail); err != nil {
+		return nil
+	}
+	idx := bytes.LastIndex(tail, []byte("OggS"))
+	if idx < 0 || idx+14 > len(tail) {
+		return nil
+	}
+	granule := int64(binary.LittleEndian.Uint64(tail[idx+6 : idx+14]))
+	if sampleRate > 0 && granule > preSkip {
+		info.Duration = time.Duration(float64(granule-preSkip) / float64(sampleRate) * float64(time.Second))
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+	"time"
+)
+
+func u16le(v uint16) []byte { return binary.LittleEndian.AppendUint16(nil, v) }
+func u32le(v uint32) []byte { return binary.LittleEndian.AppendUint32(nil, v) }
+func u32be(v uint32) []byte { return binary.BigEndian.AppendUint32(nil, v) }
+func u64be(v uint64) []byte { return binary.BigEndian.AppendUint64(nil, v) }
+
+func join(parts ...[]byte) []byte { return bytes.Join(parts, nil) }
+
+func box(typ string, body ...[]byte) []byte {
+	payload := join(body...)
+	return join(u32be(uint32(8+len(payload))), []byte(typ), payload)
+}
+
+// mp4File 生成 ftyp + moov(mvhd, trak(tkhd))，version 同时用于 mvhd 和 tkhd
+func mp4File(version byte, timescale uint32, duration uint64, width, height uint32) []byte {
+	ftyp := box("ftyp", []byte("isom"), u32be(0x200), []byte("isomiso2"))
+
+	var mvhd, tkhd []byte
+	if version == 1 {
+		mvhd = box("mvhd", []byte{1, 0, 0, 0}, u64be(0), u64be(0), u32be(timescale), u64be(duration), make([]byte, 80))
+		// 创建/修改时间、轨道ID、保留、时长，共 32 字节
+		tkhd = box("tkhd", []byte{1, 0, 0, 7}, u64be(0), u64be(0), u32be(1), u32be(0), u64be(duration),
+			make([]byte, 16), make([]byte, 36), u32be(width<<16), u32be(height<<16))
+	} else {
+		mvhd = box("mvhd", []byte{0, 0, 0, 0}, u32be(0), u32be(0), u32be(timescale), u32be(uint32(duration)), make([]byte, 80))
+		tkhd = box("tkhd", []byte{0, 0, 0, 7}, u32be(0), u32be(0), u32be(1), u32be(0), u32be(uint32(duration)),
+			make([]byte, 16), make([]byte, 36), u32be(width<<16), u32be(height<<16))
+	}
+	return join(ftyp, box("moov", mvhd, box("trak", tkhd)))
+}
+
+// mp3File 生成 MPEG1 Layer III 128kbps 44.1kHz 立体声的 CBR 数据
+func mp3File(audioBytes int) []byte {
+	data := make([]byte, audioBytes)
+	copy(data, []byte{0xFF, 0xFB, 0x90, 0x00})
+	return data
+}
+
+func wavFile(fmtSize uint32, fmtBody []byte, dataSize uint32, data []byte) []byte {
+	return join([]byte("RIFF"), u32le(0), []byte("WAVE"),
+		[]byte("fmt "), u32le(fmtSize), fmtBody,
+		[]byte("data"), u32le(dataSize), data)
+}
+
+// pcmFmt 16 位立体声 PCM，字节率 = rate * 4
+func pcmFmt(rate uint32) []byte {
+	return join(u16le(1), u16le(2), u32le(rate), u32le(rate*4), u16le(4), u16le(16))
+}
+
+func oggPage(granule uint64, segments []byte, packet []byte) []byte {
+	return join([]byte("OggS"), []byte{0, 2}, binary.LittleEndian.AppendUint64(nil, granule),
+		make([]byte, 12), []byte{byte(len(segments))}, segments, packet)
+}
+
+func opusHead() []byte {
+	return join([]byte("OpusHead"), []byte{1, 2}, u16le(312), u32le(48000), u16le(0), []byte{0})
+}
+
+func TestProbe(t *testing.T) {
+	head := opusHead()
+	tests := []struct {
+		name    string
+		file    []byte
+		want    Info
+		wantErr error
+	}{
+		{
+			name: "MP4 v0 tkhd",
+			file: mp4File(0, 1000, 2500, 1920, 1080),
+			want: Info{Kind: KindVideo, MimeType: "video/mp4", Duration: 2500 * time.Millisecond, Width: 1920, Height: 1080},
+		},
+		{
+			name: "MP4 v1 tkhd",
+			file: mp4File(1, 600, 1800, 640, 480),
+			want: Info{Kind: KindVideo, MimeType: "video/mp4", Duration: 3 * time.Second, Width: 640, Height: 480},
+		},
+		{
+			name: "MP4 截断只保留 ftyp",
+			file: mp4File(0, 1000, 2500, 1920, 1080)[:40],
+			want: Info{Kind: KindVideo, MimeType: "video/mp4"},
+		},
+		{
+			name: "MP3 按码率估算",
+			file: mp3File(16000),
+			want: Info{Kind: KindAudio, MimeType: "audio/mpeg", Duration: time.Second},
+		},
+		{
+			name: "MP3 截断不足 ID3 头长度",
+			file: mp3File(4),
+			want: Info{Kind: KindAudio, MimeType: "audio/mpeg"},
+		},
+		{
+			name: "WAV",
+			file: wavFile(16, pcmFmt(8000), 64000, make([]byte, 64000)),
+			want: Info{Kind: KindAudio, MimeType: "audio/wav", Duration: 2 * time.Second},
+		},
+		{
+			name: "WAV 扩展 fmt 块",
+			file: wavFile(40, join(pcmFmt(8000), make([]byte, 24)), 32000, make([]byte, 32000)),
+			want: Info{Kind: KindAudio, MimeType: "audio/wav", Duration: time.Second},
+		},
+		{
+			name:    "WAV fmt 块声明超出文件",
+			file:    wavFile(0xFFFFFFF0, pcmFmt(8000), 4, make([]byte, 4)),
+			wantErr: ErrMalformed,
+		},
+		{
+			name:    "WAV data 块声明超出文件",
+			file:    wavFile(16, pcmFmt(8000), 1<<20, make([]byte, 16)),
+			wantErr: ErrMalformed,
+		},
+		{
+			name:    "WAV fmt 块过短",
+			file:    wavFile(4, []byte{1, 0, 2, 0}, 0, nil),
+			wantErr: ErrMalformed,
+		},
+		{
+			name: "WAV 截断在块头",
+			file: wavFile(16, pcmFmt(8000), 0, nil)[:40],
+			want: Info{Kind: KindAudio, MimeType: "audio/wav"},
+		},
+		{
+			name: "Ogg Opus",
+			file: join(oggPage(0, []byte{byte(len(head))}, head), oggPage(48000*2+312, nil, nil)),
+			want: Info{Kind: KindAudio, MimeType: "audio/ogg", Duration: 2 * time.Second},
+		},
+		{
+			name:    "Ogg 分段表超出文件",
+			file:    join(oggPage(0, nil, nil)[:26], []byte{250}, make([]byte, 73)),
+			wantErr: ErrMalformed,
+		},
+		{
+			name:    "Ogg 数据包超出文件",
+			file:    oggPage(0, []byte{200}, head),
+			wantErr: ErrMalformed,
+		},
+		{
+			name:    "未知格式",
+			file:    []byte("plain text"),
+			wantErr: ErrUnsupportedType,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Probe(bytes.NewReader(tt.file), int64(len(tt.file)))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if *got != tt.want {
+				t.Fatalf("got %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtension(t *testing.T) {
+	for mimeType, want := range map[string]string{"video/mp4": ".mp4", "audio/ogg": ".ogg", "text/plain": ""} {
+		if got := Extension(mimeType); got != want {
+			t.Errorf("Extension(%q) = %q, want %q", mimeType, got, want)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+// Package notify 通过 Webhook 和邮件发送通知
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenAddress Webhook 地址解析到了回环、内网或链路本地地址
+var ErrForbiddenAddress = errors.New("webhook address is not allowed")
+
+// Message 一条通知
+type Message struct {
+	Kind   string    `json:"kind"`
+	Title  string    `json:"title"`
+	Body   string    `json:"body"`
+	Link   string    `json:"link,omitempty"`
+	SentAt time.Time `json:"sent_at"`
+}
+
+// Webhook 以 JSON POST 发送通知，配置了密钥时在 X-Diary-Signature 中附带 HMAC-SHA256 签名
+type Webhook struct {
+	Client *http.Client
+	Secret string
+}
+
+// NewWebhook 只允许连接公网地址，Webhook 地址由用户填写，不能借此访问服务器所在的内网。
+// 检查在建立连接时对解析后的 IP 进行，重定向和 DNS 重绑定同样受限
+func NewWebhook(secret string) *Webhook {
+	dialer := &net.Dialer{Timeout: 5 * time.Second, Control: denyPrivate}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil // 经代理连接时拿不到目标 IP
+	transport.DialContext = dialer.DialContext
+	return &Webhook{
+		Client: &http.Client{Timeout: 10 * time.Second, Transport: transport},
+		Secret: secret,
+	}
+}
+
+func denyPrivate(network, address string, _ syscall.RawConn) error {
+	ap, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return ErrForbiddenAddress
+	}
+	if !PublicAddr(ap.Addr()) {
+		return ErrForbiddenAddress
+	}
+	return nil
+}
+
+// cgnat 运营商级 NAT 的共享地址段，同样不可从公网访问
+var cgnat = netip.MustParsePrefix("100.64.0.0/10")
+
+// PublicAddr 判断 IP 是否为可以访问的公网地址
+func PublicAddr(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsValid() && ip.IsGlobalUnicast() &&
+		!ip.IsPrivate() && !ip.IsLoopback() && !ip.IsLinkLocalUnicast() && !cgnat.Contains(ip)
+}
+
+func (w *Webhook) Send(ctx context.Context, url string, msg Message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if w.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(w.Secret))
+		mac.Write(body)
+		req.Header.Set("X-Diary-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := w.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// SMTP 发送纯文本邮件，Username 为空时不做认证，便于连接本地测试服务器
+type SMTP struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+func (s *SMTP) Send(ctx context.Context, to string, msg Message) error {
+	addr := net.JoinHostPort(s.Host, strconv.Itoa(s.Port))
+
+	var auth smtp.Auth
+	if s.Username != "" {
+		auth = smtp.PlainAuth("", s.Username, s.Password, s.Host)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("From: " + s.From + "\r\n")
+	sb.WriteString("To: " + to + "\r\n")
+	sb.WriteString("Subject: " + mime.BEncoding.Encode("UTF-8", msg.Title) + "\r\n")
+	sb.WriteString("Date: " + msg.SentAt.Format(time.RFC1123Z) + "\r\n")
+	sb.WriteString("MIME-Version: 1.0\r\n")
+	sb.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	sb.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	sb.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	if msg.Link != "" {
+		sb.WriteString("\r\n\r\n" + msg.Link)
+	}
+	sb.WriteString("\r\n")
+
+	// net/smtp 不支持 context，超时交给连接本身
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(addr, auth, s.From, []string{to}, []byte(sb.String()))
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,65 @@
+package notify
+
+import (
+	"errors"
+	"net/netip"
+	"testing"
+)
+
+func TestPublicAddr(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"8.8.8.8", true},
+		{"2606:4700:4700::1111", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"100.64.0.1", false},
+		{"100.127.255.254", false},
+		{"100.128.0.1", true},
+		{"fc00::1", false},
+		{"fe80::1", false},
+		{"0.0.0.0", false},
+		{"::", false},
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:10.0.0.1", false},
+		{"::ffff:100.64.0.1", false},
+		{"::ffff:8.8.8.8", true},
+	}
+	for _, tt := range tests {
+		if got := PublicAddr(netip.MustParseAddr(tt.addr)); got != tt.want {
+			t.Errorf("PublicAddr(%s) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+	if PublicAddr(netip.Addr{}) {
+		t.Error("零值地址不应视为公网地址")
+	}
+}
+
+func TestDenyPrivate(t *testing.T) {
+	tests := []struct {
+		address string
+		wantErr error
+	}{
+		{"8.8.8.8:443", nil},
+		{"[2606:4700:4700::1111]:443", nil},
+		{"127.0.0.1:80", ErrForbiddenAddress},
+		{"[::1]:80", ErrForbiddenAddress},
+		{"192.168.0.10:8080", ErrForbiddenAddress},
+		{"100.100.100.200:80", ErrForbiddenAddress},
+		{"[::ffff:127.0.0.1]:80", ErrForbiddenAddress},
+		{"[::ffff:169.254.169.254]:80", ErrForbiddenAddress},
+		// Dial 传入的总是解析后的地址，域名视为异常
+		{"localhost:80", ErrForbiddenAddress},
+	}
+	for _, tt := range tests {
+		if err := denyPrivate("tcp", tt.address, nil); !errors.Is(err, tt.wantErr) {
+			t.Errorf("denyPrivate(%s) = %v, want %v", tt.address, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,273 @@
+// Package pdfbook 将日记排版为可打印的 PDF 书籍（封面、按月目录、正文）
+package pdfbook
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+)
+
+var ErrFontNotConfigured = errors.New("未配置 PDF 字体")
+
+const (
+	fontFamily = "cjk"
+	margin     = 20.0 // mm
+	lineHeight = 7.0
+)
+
+// Book 待排版的书
+type Book struct {
+	Title    string
+	Subtitle string
+	Entries  []Entry // 按日期升序
+}
+
+// Entry 一篇日记
+type Entry struct {
+	Title  string
+	Date   time.Time
+	Meta   []string // 天气、心情、地点等，显示在日期之后
+	Tags   []string
+	Blocks []Block
+}
+
+// Block 正文片段，Text 和 Image 二选一
+type Block struct {
+	Text  string
+	Image *Image
+}
+
+// Image 正文中的图片，写入时才读取数据
+type Image struct {
+	Alt  string
+	Load func() ([]byte, error)
+}
+
+var headingPattern = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+
+// Write 生成 PDF 并写入 w，fontPath 为支持中文的 TrueType 字体文件
+func Write(w io.Writer, fontPath string, book *Book) error {
+	if fontPath == "" {
+		return ErrFontNotConfigured
+	}
+
+	// fpdf 会把字体路径拼接到字体目录下，这里直接读取文件
+	font, err := os.ReadFile(fontPath)
+	if err != nil {
+		return err
+	}
+
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(margin, margin, margin)
+	pdf.SetAutoPageBreak(true, margin)
+	pdf.AddUTF8FontFromBytes(fontFamily, "", font)
+	if err := pdf.Error(); err != nil {
+		return err
+	}
+	pdf.SetTitle(book.Title, true)
+	pdf.SetCreator("diary", true)
+
+	// 封面不显示页码；目录中引用的页码都会出现在对应页的页脚，保证字体子集包含这些数字
+	pdf.SetFooterFunc(func() {
+		if pdf.PageNo() == 1 {
+			return
+		}
+		pdf.SetY(-15)
+		pdf.SetFont(fontFamily, "", 9)
+		pdf.SetTextColor(128, 128, 128)
+		pdf.CellFormat(0, 10, fmt.Sprintf("%d", pdf.PageNo()), "", 0, "C", false, 0, "")
+		pdf.SetTextColor(0, 0, 0)
+	})
+
+	writeCover(pdf, book)
+	links := writeTOC(pdf, book.Entries)
+
+	var month string
+	for i, entry := range book.Entries {
+		// 每个月从新的一页开始，月内剩余空间不足时换页
+		if m := monthTitle(entry.Date); m != month {
+			month = m
+			pdf.AddPage()
+			pdf.SetFont(fontFamily, "", 20)
+			pdf.CellFormat(0, 14, month, "", 1, "L", false, 0, "")
+			pdf.Ln(4)
+		} else if _, pageHeight := pdf.GetPageSize(); pdf.GetY() > pageHeight-margin-40 {
+			pdf.AddPage()
+		}
+
+		pdf.SetLink(links[i], pdf.GetY(), pdf.PageNo())
+		pdf.RegisterAlias(pageAlias(i), fmt.Sprintf("%d", pdf.PageNo()))
+		writeEntry(pdf, i, entry)
+
+		if err := pdf.Error(); err != nil {
+			return err
+		}
+	}
+
+	return pdf.Output(w)
+}
+
+func writeCover(pdf *fpdf.Fpdf, book *Book) {
+	pdf.AddPage()
+	pdf.SetY(100)
+	pdf.SetFont(fontFamily, "", 32)
+	pdf.CellFormat(0, 16, book.Title, "", 1, "C", false, 0, "")
+	if book.Subtitle != "" {
+		pdf.Ln(6)
+		pdf.SetFont(fontFamily, "", 14)
+		pdf.SetTextColor(96, 96, 96)
+		pdf.CellFormat(0, 10, book.Subtitle, "", 1, "C", false, 0, "")
+		pdf.SetTextColor(0, 0, 0)
+	}
+}
+
+// writeTOC 输出按月分组的目录，页码先用别名占位，正文写完后再替换
+func writeTOC(pdf *fpdf.Fpdf, entries []Entry) []int {
+	pdf.AddPage()
+	pdf.SetFont(fontFamily, "", 20)
+	pdf.CellFormat(0, 14, "目录", "", 1, "L", false, 0, "")
+	pdf.Ln(4)
+
+	pageWidth, _ := pdf.GetPageSize()
+	width := pageWidth - 2*margin
+	links := make([]int, len(entries))
+	var month string
+	for i, entry := range entries {
+		if m := monthTitle(entry.Date); m != month {
+			month = m
+			pdf.Ln(2)
+			pdf.SetFont(fontFamily, "", 14)
+			pdf.CellFormat(0, 9, month, "", 1, "L", false, 0, "")
+		}
+
+		links[i] = pdf.AddLink()
+		pdf.SetFont(fontFamily, "", 11)
+		title := fmt.Sprintf("%s  %s", entry.Date.Format("01-02"), entry.Title)
+		pdf.CellFormat(width-20, lineHeight, fitText(pdf, title, width-24), "", 0, "L", false, links[i], "")
+		pdf.CellFormat(20, lineHeight, pageAlias(i), "", 1, "R", false, links[i], "")
+	}
+	return links
+}
+
+func writeEntry(pdf *fpdf.Fpdf, index int, entry Entry) {
+	pdf.SetFont(fontFamily, "", 16)
+	pdf.MultiCell(0, 9, entry.Title, "", "L", false)
+
+	pdf.SetFont(fontFamily, "", 10)
+	pdf.SetTextColor(110, 110, 110)
+	meta := append([]string{entry.Date.Format("2006-01-02 15:04")}, entry.Meta...)
+	pdf.MultiCell(0, 6, strings.Join(meta, " · "), "", "L", false)
+	if len(entry.Tags) > 0 {
+		tags := make([]string, len(entry.Tags))
+		for i, t := range entry.Tags {
+			tags[i] = "#" + t
+		}
+		pdf.MultiCell(0, 6, strings.Join(tags, "  "), "", "L", false)
+	}
+	pdf.SetTextColor(0, 0, 0)
+	pdf.Ln(3)
+
+	for j, block := range entry.Blocks {
+		if block.Image != nil {
+			writeImage(pdf, fmt.Sprintf("entry%d-%d", index, j), block.Image)
+			continue
+		}
+		writeText(pdf, block.Text)
+	}
+	pdf.Ln(8)
+}
+
+// writeText 按行输出正文，Markdown 标题使用较大字号，其余保持原样
+func writeText(pdf *fpdf.Fpdf, text string) {
+	for _, line := range strings.Split(strings.Trim(text, "\n"), "\n") {
+		if m := headingPattern.FindStringSubmatch(line); m != nil {
+			pdf.SetFont(fontFamily, "", float64(16-len(m[1])))
+			pdf.MultiCell(0, lineHeight+1, m[2], "", "L", false)
+			continue
+		}
+		pdf.SetFont(fontFamily, "", 11)
+		if strings.TrimSpace(line) == "" {
+			pdf.Ln(lineHeight / 2)
+			continue
+		}
+		pdf.MultiCell(0, lineHeight, line, "", "L", false)
+	}
+}
+
+// writeImage 等比缩放到版心宽度以内，放不下时换页；不支持的格式输出占位文字
+func writeImage(pdf *fpdf.Fpdf, name string, img *Image) {
+	data, err := img.Load()
+	imageType := ""
+	if err == nil {
+		switch http.DetectContentType(data) {
+		case "image/jpeg":
+			imageType = "JPG"
+		case "image/png":
+			imageType = "PNG"
+		case "image/gif":
+			imageType = "GIF"
+		}
+	}
+	if imageType == "" {
+		pdf.SetFont(fontFamily, "", 11)
+		pdf.MultiCell(0, lineHeight, fmt.Sprintf("[图片：%s]", img.Alt), "", "L", false)
+		return
+	}
+
+	info := pdf.RegisterImageOptionsReader(name, fpdf.ImageOptions{ImageType: imageType}, bytes.NewReader(data))
+	if pdf.Error() != nil || info == nil {
+		// 图片损坏不影响整本书的生成
+		pdf.ClearError()
+		pdf.SetFont(fontFamily, "", 11)
+		pdf.MultiCell(0, lineHeight, fmt.Sprintf("[图片：%s]", img.Alt), "", "L", false)
+		return
+	}
+
+	pageWidth, pageHeight := pdf.GetPageSize()
+	maxWidth := pageWidth - 2*margin
+	maxHeight := (pageHeight - 2*margin) * 0.6
+	w, h := info.Width(), info.Height()
+	if w > maxWidth {
+		h = h * maxWidth / w
+		w = maxWidth
+	}
+	if h > maxHeight {
+		w = w * maxHeight / h
+		h = maxHeight
+	}
+	if pdf.GetY()+h > pageHeight-margin {
+		pdf.AddPage()
+	}
+
+	y := pdf.GetY()
+	pdf.ImageOptions(name, margin+(maxWidth-w)/2, y, w, h, false, fpdf.ImageOptions{ImageType: imageType}, 0, "")
+	pdf.SetY(y + h + 3)
+}
+
+// fitText 截断超出宽度的文本
+func fitText(pdf *fpdf.Fpdf, s string, width float64) string {
+	if pdf.GetStringWidth(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && pdf.GetStringWidth(string(runes)+"…") > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
+
+func monthTitle(t time.Time) string {
+	return t.Format("2006年01月")
+}
+
+func pageAlias(i int) string {
+	return fmt.Sprintf("{p%d}", i)
+}
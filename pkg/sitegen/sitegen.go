@@ -0,0 +1,231 @@
+// Package sitegen 将日记写成可离线浏览的静态网站（zip 包）
+//
+// 目录结构：
+//
+//	index.html            按年月分组的目录和搜索框
+//	entries/{id}.html     每篇日记
+//	tags/{tag}.html       每个标签的日记列表
+//	images/...            正文引用的图片（由调用方写入）
+//	search.json           搜索索引
+//	assets/search.js      搜索索引（脚本形式，file:// 打开时也能使用）和搜索逻辑
+//	assets/style.css
+package sitegen
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Entry 一篇日记
+type Entry struct {
+	ID    uint
+	Title string
+	Date  time.Time
+	Meta  []string // 天气、心情、地点等
+	Tags  []string
+	HTML  template.HTML // 已渲染并过滤的正文，图片地址相对于 entries/ 目录
+	Text  string        // 纯文本，用于搜索
+}
+
+// Writer 逐篇写入日记页面，Close 时生成目录、标签页和搜索索引
+type Writer struct {
+	zw      *zip.Writer
+	title   string
+	entries []summary
+	tags    map[string][]int
+}
+
+// summary 生成目录时需要的信息，不保留正文
+type summary struct {
+	ID    uint     `json:"id"`
+	Title string   `json:"title"`
+	Date  string   `json:"date"`
+	Tags  []string `json:"tags,omitempty"`
+	URL   string   `json:"url"`
+	Text  string   `json:"text"`
+
+	date time.Time
+}
+
+type link struct {
+	Name string
+	URL  string
+}
+
+func NewWriter(zw *zip.Writer, title string) *Writer {
+	return &Writer{
+		zw:    zw,
+		title: title,
+		tags:  make(map[string][]int),
+	}
+}
+
+// AddEntry 写入单篇日记页面
+func (w *Writer) AddEntry(e Entry) error {
+	var tags []link
+	for _, t := range e.Tags {
+		tags = append(tags, link{Name: t, URL: "../" + tagURL(t)})
+	}
+
+	err := w.render(fmt.Sprintf("entries/%d.html", e.ID), entryTemplate, map[string]interface{}{
+		"SiteTitle": w.title,
+		"Title":     e.Title,
+		"Date":      e.Date.Format("2006-01-02 15:04"),
+		"Meta":      strings.Join(e.Meta, " · "),
+		"Tags":      tags,
+		"Content":   e.HTML,
+	})
+	if err != nil {
+		return err
+	}
+
+	idx := len(w.entries)
+	w.entries = append(w.entries, summary{
+		ID:    e.ID,
+		Title: e.Title,
+		Date:  e.Date.Format("2006-01-02"),
+		Tags:  e.Tags,
+		URL:   fmt.Sprintf("entries/%d.html", e.ID),
+		Text:  e.Text,
+		date:  e.Date,
+	})
+	for _, t := range e.Tags {
+		w.tags[t] = append(w.tags[t], idx)
+	}
+	return nil
+}
+
+// Close 写入目录、标签页、搜索索引和静态资源，不关闭底层的 zip.Writer
+func (w *Writer) Close() error {
+	sort.SliceStable(w.entries, func(i, j int) bool {
+		return w.entries[i].date.After(w.entries[j].date)
+	})
+	// 排序后重建标签索引
+	for t := range w.tags {
+		w.tags[t] = w.tags[t][:0]
+	}
+	for i, e := range w.entries {
+		for _, t := range e.Tags {
+			w.tags[t] = append(w.tags[t], i)
+		}
+	}
+
+	type month struct {
+		Name    string
+		Entries []summary
+	}
+	type year struct {
+		Name   string
+		Months []*month
+	}
+	var years []*year
+	for _, e := range w.entries {
+		y, m := e.date.Format("2006"), e.date.Format("01月")
+		if len(years) == 0 || years[len(years)-1].Name != y {
+			years = append(years, &year{Name: y})
+		}
+		cur := years[len(years)-1]
+		if len(cur.Months) == 0 || cur.Months[len(cur.Months)-1].Name != m {
+			cur.Months = append(cur.Months, &month{Name: m})
+		}
+		last := cur.Months[len(cur.Months)-1]
+		last.Entries = append(last.Entries, e)
+	}
+
+	names := make([]string, 0, len(w.tags))
+	for t := range w.tags {
+		names = append(names, t)
+	}
+	sort.Strings(names)
+	var tags []link
+	for _, t := range names {
+		tags = append(tags, link{Name: fmt.Sprintf("%s (%d)", t, len(w.tags[t])), URL: tagURL(t)})
+	}
+
+	err := w.render("index.html", indexTemplate, map[string]interface{}{
+		"SiteTitle": w.title,
+		"Count":     len(w.entries),
+		"Years":     years,
+		"Tags":      tags,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, t := range names {
+		var entries []summary
+		for _, i := range w.tags[t] {
+			entries = append(entries, w.entries[i])
+		}
+		err := w.render(tagFile(t), tagTemplate, map[string]interface{}{
+			"SiteTitle": w.title,
+			"Tag":       t,
+			"Entries":   entries,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	index, err := json.Marshal(w.entries)
+	if err != nil {
+		return err
+	}
+	if err := w.write("search.json", func(f io.Writer) error {
+		_, err := f.Write(index)
+		return err
+	}); err != nil {
+		return err
+	}
+	if err := w.write("assets/search.js", func(f io.Writer) error {
+		_, err := fmt.Fprintf(f, "window.SEARCH_INDEX = %s;\n%s", index, searchScript)
+		return err
+	}); err != nil {
+		return err
+	}
+	return w.write("assets/style.css", func(f io.Writer) error {
+		_, err := io.WriteString(f, styleSheet)
+		return err
+	})
+}
+
+func (w *Writer) render(name string, tmpl *template.Template, data interface{}) error {
+	return w.write(name, func(f io.Writer) error {
+		return tmpl.Execute(f, data)
+	})
+}
+
+func (w *Writer) write(name string, fn func(f io.Writer) error) error {
+	f, err := w.zw.Create(name)
+	if err != nil {
+		return err
+	}
+	return fn(f)
+}
+
+// tagSlug 去掉文件名中不安全的字符
+func tagSlug(tag string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|#%`, r) || r < ' ' {
+			return '-'
+		}
+		return r
+	}, tag)
+}
+
+// tagFile 标签页在 zip 中的路径
+func tagFile(tag string) string {
+	return "tags/" + tagSlug(tag) + ".html"
+}
+
+// tagURL 标签页的相对链接
+func tagURL(tag string) string {
+	return "tags/" + url.PathEscape(tagSlug(tag)) + ".html"
+}
@@ -0,0 +1,94 @@
+package sitegen
+
+import "html/template"
+
+const layoutHead = `<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+`
+
+var indexTemplate = template.Must(template.New("index").Parse(layoutHead + `<title>{{.SiteTitle}}</title>
+<link rel="stylesheet" href="assets/style.css">
+</head>
+<body>
+<header><h1>{{.SiteTitle}}</h1><p class="meta">共 {{.Count}} 篇</p></header>
+<input id="search" type="search" placeholder="搜索标题、正文或标签">
+<ul id="results"></ul>
+<main id="toc">
+{{range .Years}}<section><h2>{{.Name}}</h2>
+{{range .Months}}<h3>{{.Name}}</h3>
+<ul>{{range .Entries}}<li><span class="date">{{.Date}}</span> <a href="{{.URL}}">{{.Title}}</a></li>{{end}}</ul>
+{{end}}</section>
+{{end}}</main>
+{{if .Tags}}<nav class="tags"><h2>标签</h2>{{range .Tags}}<a href="{{.URL}}">{{.Name}}</a> {{end}}</nav>{{end}}
+<script src="assets/search.js"></script>
+</body>
+</html>
+`))
+
+var entryTemplate = template.Must(template.New("entry").Parse(layoutHead + `<title>{{.Title}} - {{.SiteTitle}}</title>
+<link rel="stylesheet" href="../assets/style.css">
+</head>
+<body>
+<nav><a href="../index.html">← {{.SiteTitle}}</a></nav>
+<article>
+<h1>{{.Title}}</h1>
+<p class="meta">{{.Date}}{{if .Meta}} · {{.Meta}}{{end}}</p>
+{{if .Tags}}<p class="tags">{{range .Tags}}<a href="{{.URL}}">#{{.Name}}</a> {{end}}</p>{{end}}
+<div class="content">{{.Content}}</div>
+</article>
+</body>
+</html>
+`))
+
+var tagTemplate = template.Must(template.New("tag").Parse(layoutHead + `<title>#{{.Tag}} - {{.SiteTitle}}</title>
+<link rel="stylesheet" href="../assets/style.css">
+</head>
+<body>
+<nav><a href="../index.html">← {{.SiteTitle}}</a></nav>
+<h1>#{{.Tag}}</h1>
+<ul>{{range .Entries}}<li><span class="date">{{.Date}}</span> <a href="../{{.URL}}">{{.Title}}</a></li>{{end}}</ul>
+</body>
+</html>
+`))
+
+const searchScript = `(function () {
+  var input = document.getElementById("search");
+  var results = document.getElementById("results");
+  var toc = document.getElementById("toc");
+  if (!input) return;
+  input.addEventListener("input", function () {
+    var q = input.value.trim().toLowerCase();
+    results.innerHTML = "";
+    toc.style.display = q ? "none" : "";
+    if (!q) return;
+    window.SEARCH_INDEX.filter(function (e) {
+      return (e.title + "\n" + e.text + "\n" + (e.tags || []).join(" ")).toLowerCase().indexOf(q) >= 0;
+    }).forEach(function (e) {
+      var li = document.createElement("li");
+      var a = document.createElement("a");
+      a.href = e.url;
+      a.textContent = e.title;
+      var date = document.createElement("span");
+      date.className = "date";
+      date.textContent = e.date + " ";
+      li.appendChild(date);
+      li.appendChild(a);
+      results.appendChild(li);
+    });
+  });
+})();
+`
+
+const styleSheet = `body { max-width: 760px; margin: 0 auto; padding: 24px; font-family: -apple-system, "PingFang SC", "Microsoft YaHei", sans-serif; line-height: 1.7; color: #222; }
+a { color: #2b6cb0; text-decoration: none; }
+a:hover { text-decoration: underline; }
+.meta, .date { color: #888; font-size: 0.9em; }
+.tags a { margin-right: 8px; }
+ul { padding-left: 1.2em; }
+#search { width: 100%; padding: 8px; font-size: 1em; box-sizing: border-box; }
+.content img { max-width: 100%; }
+.content pre { overflow-x: auto; padding: 12px; background: #f6f8fa; }
+`
@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+// SignResource 为资源生成带过期时间的签名
+// resource: 资源标识，例如 "images/12"
+// 返回: 过期时间戳, 签名
+func SignResource(secret []byte, resource string, expires time.Time) (int64, string) {
+	exp := expires.Unix()
+	return exp, computeSignature(secret, resource, exp)
+}
+
+// VerifyResource 校验资源签名是否有效且未过期
+func VerifyResource(secret []byte, resource string, expires int64, signature string) bool {
+	if time.Now().Unix() > expires {
+		return false
+	}
+	expected := computeSignature(secret, resource, expires)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+func computeSignature(secret []byte, resource string, expires int64) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(resource))
+	mac.Write([]byte{':'})
+	mac.Write([]byte(strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}